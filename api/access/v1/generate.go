@@ -0,0 +1,5 @@
+// Package accessv1 contains the protobuf messages and gRPC stubs of the
+// terminal API defined in access.proto.
+package accessv1
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative access.proto
@@ -2,59 +2,395 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"access-system-api/internal/cfg"
 	"access-system-api/internal/client"
+	"access-system-api/internal/door"
+	"access-system-api/internal/events"
+	"access-system-api/internal/grpcserver"
 	"access-system-api/internal/handler"
+	"access-system-api/internal/health"
+	"access-system-api/internal/lifecycle"
+	"access-system-api/internal/logging"
+	"access-system-api/internal/metrics"
+	"access-system-api/internal/openapi"
+	"access-system-api/internal/ratelimit"
 	"access-system-api/internal/repository"
 	"access-system-api/internal/router"
 	"access-system-api/internal/service"
+	"access-system-api/internal/signing"
+	"access-system-api/internal/tracing"
+	"access-system-api/internal/webhook"
 
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 )
 
+// boltOpenTimeout bounds the wait for another process to release the bolt file.
+const boltOpenTimeout = 5 * time.Second
+
 func main() {
-	_, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	log := logrus.New()
 	log.SetOutput(os.Stdout)
 	log.SetFormatter(&logrus.JSONFormatter{
 		TimestampFormat: "2006-01-02T15:04:05Z",
 	})
+	log.AddHook(logging.RedactHook{})
 
-	dbCfg, err := cfg.LoadDbCfg()
+	config, err := cfg.Load(os.Args[1:])
 	if err != nil {
-		log.Fatalf("Error while loading db config: %s", err.Error())
+		log.Fatalf("Error while loading config: %s", err.Error())
 	}
-	log.Info("DB config loaded successfully")
+	logging.Configure(log, &config.Log)
+	log.Info("Config loaded successfully")
 
-	db, err := client.ConnectDB(dbCfg)
+	shutdownTracing, err := tracing.Setup(ctx, &config.Tracing)
 	if err != nil {
-		log.Fatalf("Error while db connection: %s", err.Error())
+		log.Fatalf("Error while setting up tracing: %s", err.Error())
 	}
 	defer func() {
-		err := db.Close()
+		err := shutdownTracing(context.Background())
 		if err != nil {
-			log.Errorf("Error while closing db connection: %s", err.Error())
+			log.Errorf("Error while shutting down tracing: %s", err.Error())
 		}
 	}()
-	log.Info("DB connection successful")
+	log.Infof("Tracing initialized successfully with %s exporter", config.Tracing.Exporter)
+
+	// Without the Postgres backend, the features that keep their state in
+	// Postgres are not available; cfg.Validate rejects enabling them.
+	var db *sql.DB
+	if config.Storage.Backend == cfg.StoragePostgres {
+		db, err = client.ConnectDB(ctx, &config.DB, log)
+		if err != nil {
+			log.Fatalf("Error while db connection: %s", err.Error())
+		}
+		defer func() {
+			err := db.Close()
+			if err != nil {
+				log.Errorf("Error while closing db connection: %s", err.Error())
+			}
+		}()
+		log.Info("DB connection successful")
+	}
+	var boltStore *repository.BoltStore
+	if config.Storage.Backend == cfg.StorageBolt {
+		boltStore, err = repository.OpenBoltStore(config.Storage.Path, boltOpenTimeout)
+		if err != nil {
+			log.Fatalf("Error while opening bolt store: %s", err.Error())
+		}
+		defer func() {
+			err := boltStore.Close()
+			if err != nil {
+				log.Errorf("Error while closing bolt store: %s", err.Error())
+			}
+		}()
+		log.Infof("Bolt store %s opened successfully", config.Storage.Path)
+	}
 
-	embeddingRepo := repository.NewEmbeddingsRepository(db)
-	log.Info("Repository initialized successfully")
+	m := metrics.New()
+	if db != nil {
+		m.RegisterDB(db, "postgres")
+	}
+	log.Info("Metrics initialized successfully")
 
-	embeddingService := service.NewEmbeddingService(embeddingRepo)
+	var embeddingRepo repository.EmbeddingRepository
+	var cachedRepo *repository.CachedEmbeddingRepository
+	switch config.Storage.Backend {
+	case cfg.StorageMemory:
+		embeddingRepo = repository.NewMemoryEmbeddingRepository(config.Matching.Threshold)
+	case cfg.StorageBolt:
+		embeddingRepo, err = repository.NewBoltEmbeddingRepository(boltStore, config.Matching.Threshold)
+		if err != nil {
+			log.Fatalf("Error while loading embeddings: %s", err.Error())
+		}
+	default:
+		embeddingRepo = repository.NewEmbeddingsRepository(db, config.Matching.Threshold)
+		if config.Index.Enabled {
+			newListener := func(onEvent pq.EventCallbackType) *pq.Listener {
+				return client.NewListener(&config.DB, log, onEvent)
+			}
+			cachedRepo = repository.NewCachedEmbeddingRepository(embeddingRepo, newListener, config.Matching.Threshold, config.Index, m, log)
+			embeddingRepo = cachedRepo
+		}
+	}
+	embeddingRepo = repository.NewTracedEmbeddingRepository(embeddingRepo)
+	embeddingRepo = repository.NewInstrumentedEmbeddingRepository(embeddingRepo, m)
+	var webhookRepo repository.WebhookRepository
+	var deviceRepo repository.DeviceRepository
+	var sampleRepo repository.SampleRepository
+	var auditRepo repository.AuditRepository
+	var tenantRepo repository.TenantRepository
+	var transactor repository.Transactor
+	var eventRepo repository.EventRepository
+	if db != nil {
+		webhookRepo = repository.NewWebhookRepository(db)
+		deviceRepo = repository.NewDeviceRepository(db)
+		sampleRepo = repository.NewSampleRepository(db)
+		auditRepo = repository.NewAuditRepository(db)
+		tenantRepo = repository.NewTenantRepository(db)
+		transactor = repository.NewTransactor(db)
+	}
+	if boltStore != nil {
+		eventRepo = repository.NewBoltEventRepository(boltStore)
+	}
+	log.Infof("Repository initialized successfully with %s storage", config.Storage.Backend)
+
+	bus := events.NewBus(config.Events.BufferSize)
+	var publisher events.Publisher = bus
+	var relay *events.PostgresRelay
+	if db != nil {
+		relay = events.NewPostgresRelay(bus, db, client.NewListener(&config.DB, log, nil), log)
+		publisher = relay
+	}
+	var recorder *events.Recorder
+	if eventRepo != nil {
+		recorder = events.NewRecorder(publisher, eventRepo, config.Storage.EventRetention, log)
+		publisher = recorder
+	}
+	log.Info("Event bus initialized successfully")
+
+	embeddingService := service.NewEmbeddingService(embeddingRepo, config.Matching)
+	embeddingService = service.NewCaptureGatedEmbeddingService(embeddingService, config.Capture, config.Door.AccessPoints)
+	var templateService service.TemplateService
+	var templateAdapter *service.TemplateAdapter
+	var webhookService service.WebhookService
+	var deviceService service.DeviceService
+	var storageService service.StorageService
+	var auditService service.AuditService
+	// Without Postgres there is no tenant registry, and every tenant is accepted.
+	var tenantService service.TenantService
+	if boltStore != nil {
+		storageService = service.NewStorageService(eventRepo, boltStore)
+	}
+	if db != nil {
+		templateService = service.NewTemplateService(embeddingRepo, sampleRepo, transactor, config.Adaptive, m)
+		if config.Adaptive.Enabled {
+			templateAdapter = service.NewTemplateAdapter(templateService, log)
+			embeddingService = service.NewAdaptiveEmbeddingService(embeddingService, templateService, templateAdapter, transactor)
+		}
+		embeddingService = service.NewOutboxEmbeddingService(embeddingService, webhookRepo, transactor, config.Events.Zones)
+		embeddingService = service.NewAuditedEmbeddingService(embeddingService, auditRepo, transactor)
+		auditService = service.NewAuditService(auditRepo)
+		webhookService = service.NewWebhookService(webhookRepo, webhook.NewHostPolicy(config.Webhook.AllowedHosts))
+		deviceService = service.NewDeviceService(deviceRepo)
+		tenantService = service.NewTenantService(tenantRepo)
+	}
+	// Lockouts and offline door controllers are delivered to webhooks too.
+	// Only the instance noticing them records them in the outbox.
+	var doorPublisher events.Publisher = bus
+	guardPublisher := publisher
+	if db != nil {
+		doorPublisher = service.NewOutboxPublisher(bus, webhookRepo, config.Events.Zones)
+		guardPublisher = service.NewOutboxPublisher(publisher, webhookRepo, config.Events.Zones)
+	}
+	var doorController *door.Controller
+	if config.Door.Enabled {
+		doorController = door.NewController(config.Door, doorPublisher, m, log)
+		embeddingService = service.NewActuatingEmbeddingService(embeddingService, doorController, config.Door.AlarmOnDenied)
+	}
+	embeddingService = service.NewPublishingEmbeddingService(embeddingService, publisher, config.Events.Zones)
+	// Postgres shares the lockouts between the server instances.
+	lockoutStore := ratelimit.NewMemoryLockoutStore()
+	if db != nil {
+		lockoutStore = repository.NewLockoutRepository(db)
+	}
+	lockouts := ratelimit.NewLockouts(ratelimit.LockoutPolicy{
+		Threshold:   config.RateLimit.LockoutThreshold,
+		Window:      config.RateLimit.LockoutWindow,
+		Duration:    config.RateLimit.LockoutDuration,
+		MaxDuration: config.RateLimit.LockoutMaxDuration,
+	}, lockoutStore, log)
+	// Batches and single validations share the rate limits and lockouts.
+	deviceLimiter := ratelimit.NewLimiter(config.RateLimit.DeviceRate, config.RateLimit.DeviceBurst)
+	globalLimiter := ratelimit.NewLimiter(config.RateLimit.GlobalRate, config.RateLimit.GlobalBurst)
+	embeddingService = service.NewGuardedEmbeddingService(embeddingService, deviceLimiter, globalLimiter, lockouts, guardPublisher, config.Events.Zones, m)
+	embeddingService = service.NewTracedEmbeddingService(embeddingService)
+	embeddingService = service.NewInstrumentedEmbeddingService(embeddingService, m)
+	batchService := service.NewGuardedBatchService(service.NewBatchService(embeddingService, embeddingRepo, config.Matching),
+		deviceLimiter, globalLimiter, lockouts, guardPublisher, config.Events.Zones, m)
+	lockoutService := service.NewLockoutService(lockouts)
+	// Request signing requires Postgres, see cfg.Validate.
+	var nonces *signing.Nonces
+	var signatures *signing.Verifier
+	if config.Auth.RequestSigning != cfg.RequestSigningOff {
+		nonces = signing.NewNonces(repository.NewNonceRepository(db), config.Auth.SignatureSkew, log)
+		signatures = signing.NewVerifier(deviceService, nonces, config.Auth.SignatureSkew)
+	}
 	log.Info("Service initialized successfully")
 
 	v1Handler := handler.NewV1Handler(embeddingService, log)
 	log.Info("Handler initialized successfully")
 
+	batchHandler := handler.NewBatchHandler(batchService, log)
+	log.Info("Batch Handler initialized successfully")
+
 	adminHandler := handler.NewAdminHandler(embeddingService, log)
 	log.Info("Admin Handler initialized successfully")
 
-	r := router.NewRouter(v1Handler, adminHandler, log)
-	r.Run()
-	log.Info("Router started successfully")
+	eventsHandler := handler.NewEventsHandler(bus, config.Events.Heartbeat, log)
+	log.Info("Events Handler initialized successfully")
+
+	lockoutHandler := handler.NewLockoutHandler(lockoutService, log)
+	log.Info("Lockout Handler initialized successfully")
+
+	// The routes of nil handlers are not registered.
+	var webhookHandler handler.WebhookHandler
+	var deviceHandler handler.DeviceHandler
+	var templateHandler handler.TemplateHandler
+	var auditHandler handler.AuditHandler
+	var tenantHandler handler.TenantHandler
+	if db != nil {
+		webhookHandler = handler.NewWebhookHandler(webhookService, log)
+		deviceHandler = handler.NewDeviceHandler(deviceService, log)
+		templateHandler = handler.NewTemplateHandler(templateService, log)
+		auditHandler = handler.NewAuditHandler(auditService, log)
+		tenantHandler = handler.NewTenantHandler(tenantService, log)
+		log.Info("Webhook, Device, Template, Audit and Tenant Handlers initialized successfully")
+	}
+	var storageHandler handler.StorageHandler
+	if storageService != nil {
+		storageHandler = handler.NewStorageHandler(storageService, log)
+		log.Info("Storage Handler initialized successfully")
+	}
+
+	var checks []health.Check
+	if db != nil {
+		checks = health.PostgresChecks(db)
+	}
+	if boltStore != nil {
+		checks = append(checks, boltStore.HealthCheck())
+	}
+	if doorController != nil {
+		checks = append(checks, doorController.HealthCheck())
+	}
+	if cachedRepo != nil {
+		checks = append(checks, cachedRepo.HealthCheck())
+	}
+	checker := health.NewChecker(config.Server.HealthCheckTimeout, checks...)
+	healthHandler := handler.NewHealthHandler(checker)
+	log.Info("Health Handler initialized successfully")
+
+	spec, err := openapi.NewRouter()
+	if err != nil {
+		log.Fatalf("Error while loading OpenAPI spec: %s", err.Error())
+	}
+	log.Info("OpenAPI spec loaded successfully")
+
+	workers := lifecycle.NewGroup(context.Background(), log)
+	if db != nil {
+		workers.Go("event-relay", relay.Run)
+		workers.Go("webhook-dispatcher", webhook.NewDispatcher(webhookRepo, config.Webhook, log).Run)
+	}
+	if recorder != nil {
+		workers.Go("event-recorder", recorder.Run)
+	}
+	if doorController != nil {
+		workers.Go("door-controller", doorController.Run)
+	}
+	if cachedRepo != nil {
+		workers.Go("vector-index", cachedRepo.Run)
+	}
+	if nonces != nil {
+		workers.Go("nonce-pruner", nonces.Run)
+	}
+	if lockouts != nil {
+		workers.Go("lockout-pruner", lockouts.Run)
+	}
+	if templateAdapter != nil {
+		workers.Go("template-adapter", templateAdapter.Run)
+	}
+
+	r := router.NewRouter(config, v1Handler, batchHandler, adminHandler, eventsHandler, webhookHandler, lockoutHandler, deviceHandler, templateHandler, auditHandler, storageHandler, tenantHandler, signatures, tenantService, healthHandler, spec, m, log)
+	log.Info("Router initialized successfully")
+
+	server := &http.Server{
+		Addr:              config.Server.Addr,
+		Handler:           r.Handler(),
+		ReadHeaderTimeout: config.Server.ReadHeaderTimeout,
+	}
+	// End open event streams, which would otherwise hold the drain until the timeout.
+	server.RegisterOnShutdown(bus.Close)
+
+	serverErr := make(chan error, 2)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+	log.Infof("Server listening on %s", config.Server.Addr)
+
+	var grpcServer *grpc.Server
+	if config.Server.GRPCAddr != "" {
+		listener, err := net.Listen("tcp", config.Server.GRPCAddr)
+		if err != nil {
+			log.Fatalf("Error while listening for gRPC: %s", err.Error())
+		}
+		accessServer := grpcserver.NewAccessServer(embeddingService, config.Server.RequestTimeout, log)
+		grpcServer = grpcserver.NewServer(config, accessServer, tenantService, signatures, m, log)
+		go func() {
+			serverErr <- grpcServer.Serve(listener)
+		}()
+		log.Infof("gRPC server listening on %s", config.Server.GRPCAddr)
+	}
+	checker.SetReady(true)
+
+	select {
+	case <-ctx.Done():
+		log.Info("Shutdown signal received")
+	case err := <-serverErr:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("Server failed: %s", err.Error())
+		}
+	}
+	stop()
+
+	// Stop in reverse order of dependencies: reject new traffic and drain
+	// in-flight requests first, then stop workers; the DB pool and the tracer
+	// provider are closed by the deferred calls above.
+	checker.SetReady(false)
+	time.Sleep(config.Server.DrainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Errorf("Error while draining HTTP server: %s", err.Error())
+	}
+	log.Info("HTTP server stopped")
+
+	if grpcServer != nil {
+		stopGRPC(shutdownCtx, grpcServer)
+		log.Info("gRPC server stopped")
+	}
+
+	if err := workers.Stop(shutdownCtx); err != nil {
+		log.Errorf("Error while stopping background workers: %s", err.Error())
+	}
+	log.Info("Background workers stopped")
+}
+
+// stopGRPC waits for in-flight calls to finish and closes the remaining ones,
+// such as long-lived validation streams, when ctx expires.
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		server.Stop()
+	}
 }
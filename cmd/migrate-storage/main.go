@@ -0,0 +1,95 @@
+// Command migrate-storage copies the embeddings between the Postgres
+// database and the bolt file of the server configuration, keeping their IDs.
+//
+// Usage:
+//
+//	migrate-storage -to bolt|postgres [-config config.yaml]
+//
+// The server must be stopped, and the target must have no embeddings.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/client"
+	"access-system-api/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// boltOpenTimeout bounds the wait for a running server to release the bolt file.
+const boltOpenTimeout = time.Second
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	log := logrus.New()
+	log.SetOutput(os.Stderr)
+
+	if err := run(ctx, os.Args[1:], log); err != nil {
+		log.Fatalf("Migration failed: %s", err.Error())
+	}
+}
+
+func run(ctx context.Context, args []string, log *logrus.Logger) error {
+	fs := flag.NewFlagSet("migrate-storage", flag.ContinueOnError)
+	to := fs.String("to", "", "target backend: bolt (from Postgres) or postgres (from bolt)")
+	configPath := fs.String("config", "", "path to a YAML or TOML config file (env CONFIG_FILE)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *to != cfg.StorageBolt && *to != cfg.StoragePostgres {
+		return fmt.Errorf("-to must be %s or %s", cfg.StorageBolt, cfg.StoragePostgres)
+	}
+
+	var configArgs []string
+	if *configPath != "" {
+		configArgs = []string{"-config", *configPath}
+	}
+	config, err := cfg.Load(configArgs)
+	if err != nil {
+		return err
+	}
+
+	db, err := client.ConnectDB(ctx, &config.DB, log)
+	if err != nil {
+		return fmt.Errorf("connect to Postgres: %w", err)
+	}
+	defer db.Close()
+
+	store, err := repository.OpenBoltStore(config.Storage.Path, boltOpenTimeout)
+	if err != nil {
+		return fmt.Errorf("open bolt store (is the server still running?): %w", err)
+	}
+	defer store.Close()
+
+	var copied int
+	if *to == cfg.StorageBolt {
+		copied, err = repository.MigrateEmbeddings(ctx, repository.NewEmbeddingsRepository(db, config.Matching.Threshold), store)
+	} else {
+		var from repository.EmbeddingRepository
+		from, err = repository.NewBoltEmbeddingRepository(store, config.Matching.Threshold)
+		if err != nil {
+			return err
+		}
+		copied, err = repository.MigrateEmbeddings(ctx, from, repository.NewPostgresImporter(db))
+	}
+	if err != nil {
+		return err
+	}
+
+	if *to == cfg.StorageBolt {
+		log.Infof("Copied %d embeddings from Postgres to %s", copied, config.Storage.Path)
+	} else {
+		log.Infof("Copied %d embeddings from %s to Postgres", copied, config.Storage.Path)
+	}
+	return nil
+}
@@ -1,8 +1,16 @@
 CREATE EXTENSION IF NOT EXISTS vector;
 
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT NOT NULL,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (version)
+);
+
 CREATE TABLE IF NOT EXISTS embedding (
     id BIGINT GENERATED ALWAYS AS IDENTITY,
     name TEXT NOT NULL,
     vector_ VECTOR(512) NOT NULL,
     PRIMARY KEY (id)
-)
\ No newline at end of file
+);
+
+INSERT INTO schema_migrations (version) VALUES (1) ON CONFLICT DO NOTHING;
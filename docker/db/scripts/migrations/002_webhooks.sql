@@ -0,0 +1,46 @@
+-- Webhook subscriptions, the transactional outbox and webhook deliveries.
+-- Safe to apply to a database initialized with an older init.sql.
+
+CREATE TABLE IF NOT EXISTS webhook_subscription (
+    id BIGINT GENERATED ALWAYS AS IDENTITY,
+    url TEXT NOT NULL,
+    event_types TEXT[] NOT NULL,
+    secret TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+
+-- Events are written in the same transaction as the change they describe and
+-- fanned out to the matching subscriptions by the webhook dispatcher.
+CREATE TABLE IF NOT EXISTS outbox_event (
+    id BIGINT GENERATED ALWAYS AS IDENTITY,
+    event_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    dispatched_at TIMESTAMPTZ,
+    PRIMARY KEY (id),
+    UNIQUE (event_id)
+);
+
+CREATE INDEX IF NOT EXISTS outbox_event_pending_idx ON outbox_event (id) WHERE dispatched_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+    id BIGINT GENERATED ALWAYS AS IDENTITY,
+    subscription_id BIGINT NOT NULL REFERENCES webhook_subscription (id) ON DELETE CASCADE,
+    outbox_event_id BIGINT NOT NULL REFERENCES outbox_event (id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_status_code INT,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    UNIQUE (subscription_id, outbox_event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_due_idx ON webhook_delivery (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_delivery_status_idx ON webhook_delivery (status, updated_at);
+
+INSERT INTO schema_migrations (version) VALUES (2) ON CONFLICT DO NOTHING;
@@ -0,0 +1,15 @@
+-- Device registry holding the per-device keys terminals sign requests with.
+-- Safe to apply to a database initialized with an older init.sql.
+
+CREATE TABLE IF NOT EXISTS device (
+    id BIGINT GENERATED ALWAYS AS IDENTITY,
+    -- Common name of the device client certificate.
+    name TEXT NOT NULL,
+    signing_key TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    key_rotated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    UNIQUE (name)
+);
+
+INSERT INTO schema_migrations (version) VALUES (3) ON CONFLICT DO NOTHING;
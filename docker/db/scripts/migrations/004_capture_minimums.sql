@@ -0,0 +1,8 @@
+-- Per-enrollment minimum capture scores of validations matching an embedding,
+-- on top of those configured for the access point. 0 disables the check.
+-- Safe to apply to a database initialized with an older init.sql.
+
+ALTER TABLE embedding ADD COLUMN IF NOT EXISTS min_liveness REAL NOT NULL DEFAULT 0;
+ALTER TABLE embedding ADD COLUMN IF NOT EXISTS min_quality REAL NOT NULL DEFAULT 0;
+
+INSERT INTO schema_migrations (version) VALUES (4) ON CONFLICT DO NOTHING;
@@ -0,0 +1,26 @@
+-- Samples of adaptive template updates. The vector of an adapted embedding is
+-- the normalized centroid of its active samples: the enrollment sample plus
+-- the probes of high-confidence matches. Samples are never deleted while the
+-- embedding exists, so every update stays audited and can be reverted.
+-- Safe to apply to a database initialized with an older init.sql.
+
+CREATE TABLE IF NOT EXISTS embedding_sample (
+    id BIGINT GENERATED ALWAYS AS IDENTITY,
+    embedding_id BIGINT NOT NULL REFERENCES embedding (id) ON DELETE CASCADE,
+    -- enrollment or adaptive.
+    source TEXT NOT NULL,
+    -- active, evicted, reverted or reset.
+    status TEXT NOT NULL DEFAULT 'active',
+    vector_ VECTOR(512) NOT NULL,
+    accuracy REAL,
+    liveness REAL,
+    device TEXT,
+    request_id TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    status_changed_at TIMESTAMPTZ,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS embedding_sample_active_idx ON embedding_sample (embedding_id, id) WHERE status = 'active';
+
+INSERT INTO schema_migrations (version) VALUES (5) ON CONFLICT DO NOTHING;
@@ -0,0 +1,30 @@
+-- Notifies every change of the embedding table on the embedding_changes
+-- channel, so that the in-memory vector indexes of the server instances stay
+-- in sync. The payload is {"op": "INSERT" | "UPDATE" | "DELETE", "id": ...};
+-- a TRUNCATE is notified without id. Notifications are sent on commit.
+-- Safe to apply to a database initialized with an older init.sql.
+
+CREATE OR REPLACE FUNCTION notify_embedding_change() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'TRUNCATE' THEN
+        PERFORM pg_notify('embedding_changes', json_build_object('op', TG_OP)::text);
+    ELSIF TG_OP = 'DELETE' THEN
+        PERFORM pg_notify('embedding_changes', json_build_object('op', TG_OP, 'id', OLD.id)::text);
+    ELSE
+        PERFORM pg_notify('embedding_changes', json_build_object('op', TG_OP, 'id', NEW.id)::text);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS embedding_changed ON embedding;
+CREATE TRIGGER embedding_changed
+    AFTER INSERT OR UPDATE OR DELETE ON embedding
+    FOR EACH ROW EXECUTE FUNCTION notify_embedding_change();
+
+DROP TRIGGER IF EXISTS embedding_truncated ON embedding;
+CREATE TRIGGER embedding_truncated
+    AFTER TRUNCATE ON embedding
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_embedding_change();
+
+INSERT INTO schema_migrations (version) VALUES (6) ON CONFLICT DO NOTHING;
@@ -0,0 +1,41 @@
+-- Tenants, e.g. faculties or sites, isolating their embeddings, devices,
+-- webhooks and events from each other. Clients belong to the tenant named by
+-- the organizational unit (OU) of their certificate subject, or to the
+-- default tenant, which owns every row created before this migration.
+-- Safe to apply to a database initialized with an older init.sql.
+
+CREATE TABLE IF NOT EXISTS tenant (
+    id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+
+INSERT INTO tenant (id, name) VALUES ('default', 'Default') ON CONFLICT DO NOTHING;
+
+ALTER TABLE embedding ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenant (id);
+CREATE INDEX IF NOT EXISTS embedding_tenant_idx ON embedding (tenant_id);
+
+ALTER TABLE device ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenant (id);
+-- Device names are unique within a tenant.
+ALTER TABLE device DROP CONSTRAINT IF EXISTS device_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS device_tenant_name_idx ON device (tenant_id, name);
+
+ALTER TABLE webhook_subscription ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenant (id);
+ALTER TABLE outbox_event ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+
+-- Changes are notified with their tenant, see 006_embedding_changes.sql.
+CREATE OR REPLACE FUNCTION notify_embedding_change() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'TRUNCATE' THEN
+        PERFORM pg_notify('embedding_changes', json_build_object('op', TG_OP)::text);
+    ELSIF TG_OP = 'DELETE' THEN
+        PERFORM pg_notify('embedding_changes', json_build_object('op', TG_OP, 'id', OLD.id, 'tenant', OLD.tenant_id)::text);
+    ELSE
+        PERFORM pg_notify('embedding_changes', json_build_object('op', TG_OP, 'id', NEW.id, 'tenant', NEW.tenant_id)::text);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+INSERT INTO schema_migrations (version) VALUES (7) ON CONFLICT DO NOTHING;
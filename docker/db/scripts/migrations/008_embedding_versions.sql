@@ -0,0 +1,7 @@
+-- Version of every embedding, incremented by each change and returned as the
+-- ETag of the admin API, so that concurrent edits are detected (If-Match).
+-- Safe to apply to a database initialized with an older init.sql.
+
+ALTER TABLE embedding ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+INSERT INTO schema_migrations (version) VALUES (8) ON CONFLICT DO NOTHING;
@@ -0,0 +1,18 @@
+-- Nonces of accepted signed requests, shared by every server instance so that
+-- a captured request cannot be replayed against another instance. A nonce is
+-- kept while its request could still be accepted and removed by the servers
+-- after expires_at.
+-- Safe to apply to a database initialized with an older init.sql.
+
+CREATE TABLE IF NOT EXISTS request_nonce (
+    tenant_id TEXT NOT NULL,
+    -- Common name of the device client certificate.
+    device TEXT NOT NULL,
+    nonce TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (tenant_id, device, nonce)
+);
+
+CREATE INDEX IF NOT EXISTS request_nonce_expires_idx ON request_nonce (expires_at);
+
+INSERT INTO schema_migrations (version) VALUES (9) ON CONFLICT DO NOTHING;
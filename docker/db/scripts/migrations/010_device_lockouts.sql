@@ -0,0 +1,21 @@
+-- Lockout state of devices failing validations, next to the device registry.
+-- It is shared by every server instance, so that failures are counted and
+-- lockouts enforced and lifted across all of them. Stale rows are removed by
+-- the servers.
+-- Safe to apply to a database initialized with an older init.sql.
+
+CREATE TABLE IF NOT EXISTS device_lockout (
+    tenant_id TEXT NOT NULL,
+    -- Common name of the device client certificate.
+    device TEXT NOT NULL,
+    failures INT NOT NULL,
+    first_failure TIMESTAMPTZ NOT NULL,
+    level INT NOT NULL,
+    locked_until TIMESTAMPTZ NOT NULL,
+    last_seen TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (tenant_id, device)
+);
+
+CREATE INDEX IF NOT EXISTS device_lockout_locked_until_idx ON device_lockout (locked_until);
+
+INSERT INTO schema_migrations (version) VALUES (10) ON CONFLICT DO NOTHING;
@@ -0,0 +1,9 @@
+-- Drops the approximate (HNSW) index on embedding vectors that older
+-- versions of init.sql created. An approximate search can miss the true
+-- match of a probe, so similarity searches scan the embeddings of a tenant
+-- exactly, like the in-memory index of the servers.
+-- Safe to apply to a database initialized with an older init.sql.
+
+DROP INDEX IF EXISTS embedding_vector_idx;
+
+INSERT INTO schema_migrations (version) VALUES (11) ON CONFLICT DO NOTHING;
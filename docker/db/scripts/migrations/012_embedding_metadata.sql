@@ -0,0 +1,16 @@
+-- Free-form metadata of enrollments, e.g. an employee number, changed with
+-- JSON merge patches. jsonb_object_length is immutable, so that it can bound
+-- the number of keys in a CHECK constraint.
+-- Safe to apply to a database initialized with an older init.sql.
+
+ALTER TABLE embedding ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}';
+
+CREATE OR REPLACE FUNCTION jsonb_object_length(value JSONB) RETURNS INT AS $$
+    SELECT CASE WHEN jsonb_typeof(value) = 'object' THEN (SELECT count(*)::INT FROM jsonb_object_keys(value)) ELSE 0 END
+$$ LANGUAGE sql IMMUTABLE STRICT;
+
+ALTER TABLE embedding DROP CONSTRAINT IF EXISTS embedding_metadata_keys;
+ALTER TABLE embedding ADD CONSTRAINT embedding_metadata_keys
+    CHECK (jsonb_typeof(metadata) = 'object' AND jsonb_object_length(metadata) <= 64);
+
+INSERT INTO schema_migrations (version) VALUES (12) ON CONFLICT DO NOTHING;
@@ -0,0 +1,23 @@
+-- Audit trail of the changes of enrollments: the client and request that
+-- made a change, the fields it changed and the versions of the embedding
+-- before and after it. Entries outlive their embedding, so they have no
+-- foreign key, and are never changed or removed by the servers.
+-- Safe to apply to a database initialized with an older init.sql.
+
+CREATE TABLE IF NOT EXISTS embedding_audit (
+    id BIGINT GENERATED ALWAYS AS IDENTITY,
+    tenant_id TEXT NOT NULL,
+    embedding_id BIGINT NOT NULL,
+    -- Common name of the client certificate that made the change.
+    device TEXT NOT NULL,
+    request_id TEXT NOT NULL,
+    fields TEXT[] NOT NULL,
+    old_version BIGINT NOT NULL,
+    new_version BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS embedding_audit_embedding_idx ON embedding_audit (tenant_id, embedding_id, id);
+
+INSERT INTO schema_migrations (version) VALUES (13) ON CONFLICT DO NOTHING;
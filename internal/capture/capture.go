@@ -0,0 +1,83 @@
+// Package capture carries the liveness and quality scores of face captures
+// through the request context and checks them against minimums.
+package capture
+
+import (
+	"context"
+	"fmt"
+
+	"access-system-api/internal/domain"
+)
+
+// Metadata bounds.
+const (
+	MaxMetadataEntries     = 16
+	MaxMetadataKeyLength   = 64
+	MaxMetadataValueLength = 256
+)
+
+type captureKey struct{}
+
+// WithCapture returns a copy of ctx carrying the capture of the request.
+func WithCapture(ctx context.Context, capture domain.Capture) context.Context {
+	return context.WithValue(ctx, captureKey{}, capture)
+}
+
+// FromContext returns the capture stored in ctx, or an empty capture.
+func FromContext(ctx context.Context) domain.Capture {
+	capture, _ := ctx.Value(captureKey{}).(domain.Capture)
+	return capture
+}
+
+// Validate checks that the scores of capture are in [0, 1] and that its
+// metadata is within bounds.
+func Validate(capture domain.Capture) error {
+	for name, score := range map[string]*float64{"liveness": capture.Liveness, "quality": capture.Quality} {
+		if score != nil && (*score < 0 || *score > 1) {
+			return fmt.Errorf("%w: %s must be in [0, 1], got %v", domain.ErrInvalidInput, name, *score)
+		}
+	}
+
+	if len(capture.Metadata) > MaxMetadataEntries {
+		return fmt.Errorf("%w: capture_metadata must have at most %d entries", domain.ErrInvalidInput, MaxMetadataEntries)
+	}
+	for key, value := range capture.Metadata {
+		if key == "" || len(key) > MaxMetadataKeyLength || len(value) > MaxMetadataValueLength {
+			return fmt.Errorf("%w: capture_metadata keys must be 1 to %d and values at most %d characters",
+				domain.ErrInvalidInput, MaxMetadataKeyLength, MaxMetadataValueLength)
+		}
+	}
+	return nil
+}
+
+// ValidateMinimums checks that minimums are in [0, 1].
+func ValidateMinimums(minimums domain.CaptureMinimums) error {
+	for name, minimum := range map[string]float64{"min_liveness": minimums.Liveness, "min_quality": minimums.Quality} {
+		if minimum < 0 || minimum > 1 {
+			return fmt.Errorf("%w: %s must be in [0, 1], got %v", domain.ErrInvalidInput, name, minimum)
+		}
+	}
+	return nil
+}
+
+// Check returns domain.ErrCaptureRejected if a score of capture is below its
+// minimum. A missing score is below any minimum, so terminals cannot skip the
+// check by leaving a score out.
+func Check(capture domain.Capture, minimums domain.CaptureMinimums) error {
+	if err := checkScore("liveness", capture.Liveness, minimums.Liveness); err != nil {
+		return err
+	}
+	return checkScore("quality", capture.Quality, minimums.Quality)
+}
+
+func checkScore(name string, score *float64, minimum float64) error {
+	switch {
+	case minimum <= 0:
+		return nil
+	case score == nil:
+		return fmt.Errorf("%w: %s score is required, minimum is %v", domain.ErrCaptureRejected, name, minimum)
+	case *score < minimum:
+		return fmt.Errorf("%w: %s %v is below the minimum %v", domain.ErrCaptureRejected, name, *score, minimum)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package capture
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"access-system-api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func score(v float64) *float64 {
+	return &v
+}
+
+func TestContext(t *testing.T) {
+	assert.Equal(t, domain.Capture{}, FromContext(context.Background()))
+
+	capture := domain.Capture{Liveness: score(0.9), Metadata: map[string]string{"camera": "cam-1"}}
+	assert.Equal(t, capture, FromContext(WithCapture(context.Background(), capture)))
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		capture domain.Capture
+		valid   bool
+	}{
+		{"empty", domain.Capture{}, true},
+		{"scores", domain.Capture{Liveness: score(0), Quality: score(1)}, true},
+		{"negative liveness", domain.Capture{Liveness: score(-0.1)}, false},
+		{"quality above one", domain.Capture{Quality: score(1.5)}, false},
+		{"empty metadata key", domain.Capture{Metadata: map[string]string{"": "x"}}, false},
+		{"long metadata value", domain.Capture{Metadata: map[string]string{"camera": strings.Repeat("x", MaxMetadataValueLength+1)}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.capture)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, domain.ErrInvalidInput)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	minimums := domain.CaptureMinimums{Liveness: 0.8, Quality: 0.5}
+
+	tests := []struct {
+		name     string
+		capture  domain.Capture
+		minimums domain.CaptureMinimums
+		message  string
+	}{
+		{"no minimums", domain.Capture{}, domain.CaptureMinimums{}, ""},
+		{"above minimums", domain.Capture{Liveness: score(0.8), Quality: score(0.9)}, minimums, ""},
+		{"low liveness", domain.Capture{Liveness: score(0.42), Quality: score(0.9)}, minimums, "liveness 0.42 is below the minimum 0.8"},
+		{"low quality", domain.Capture{Liveness: score(0.9), Quality: score(0.1)}, minimums, "quality 0.1 is below the minimum 0.5"},
+		{"missing score", domain.Capture{Quality: score(0.9)}, minimums, "liveness score is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.capture, tt.minimums)
+			if tt.message == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, domain.ErrCaptureRejected)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
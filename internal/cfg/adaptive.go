@@ -0,0 +1,17 @@
+package cfg
+
+// AdaptiveCfg holds the parameters of adaptive template updates, which add
+// the probes of high-confidence matches as samples of the matched embedding
+// so that it follows slow changes of the face.
+type AdaptiveCfg struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// Threshold is the minimum match accuracy of a probe to be added. It must
+	// be above the matching threshold.
+	Threshold float64 `yaml:"threshold" toml:"threshold"`
+	// MinLiveness is the minimum liveness score of a probe to be added.
+	// Probes without a liveness score are never added.
+	MinLiveness float64 `yaml:"min_liveness" toml:"min_liveness"`
+	// MaxSamples is the number of adaptive samples kept per embedding; the
+	// oldest are evicted first.
+	MaxSamples int `yaml:"max_samples" toml:"max_samples"`
+}
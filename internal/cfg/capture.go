@@ -0,0 +1,16 @@
+package cfg
+
+// CaptureCfg holds the minimum liveness and quality scores of the face
+// captures of validation and enrollment requests. Scores are in [0, 1] and a
+// minimum of 0 disables the check. Requests without a score whose minimum is
+// set are rejected.
+type CaptureCfg struct {
+	// MinLiveness and MinQuality apply to every device.
+	MinLiveness float64 `yaml:"min_liveness" toml:"min_liveness"`
+	MinQuality  float64 `yaml:"min_quality" toml:"min_quality"`
+	// AccessPointMinLiveness and AccessPointMinQuality override the minimums
+	// for the devices of an access point, as assigned in door.access_points
+	// or named after the device otherwise.
+	AccessPointMinLiveness map[string]float64 `yaml:"access_point_min_liveness" toml:"access_point_min_liveness"`
+	AccessPointMinQuality  map[string]float64 `yaml:"access_point_min_quality" toml:"access_point_min_quality"`
+}
@@ -0,0 +1,410 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Config is the complete application configuration.
+//
+// It is layered from built-in defaults, an optional YAML or TOML file,
+// environment variables (including a .env file) and command line flags,
+// each layer overriding the previous one.
+type Config struct {
+	Server    ServerCfg    `yaml:"server" toml:"server"`
+	DB        DbCfg        `yaml:"db" toml:"db"`
+	Auth      AuthCfg      `yaml:"auth" toml:"auth"`
+	Matching  MatchingCfg  `yaml:"matching" toml:"matching"`
+	Log       LogCfg       `yaml:"log" toml:"log"`
+	Tracing   TracingCfg   `yaml:"tracing" toml:"tracing"`
+	Events    EventsCfg    `yaml:"events" toml:"events"`
+	Webhook   WebhookCfg   `yaml:"webhook" toml:"webhook"`
+	Door      DoorCfg      `yaml:"door" toml:"door"`
+	RateLimit RateLimitCfg `yaml:"rate_limit" toml:"rate_limit"`
+	Capture   CaptureCfg   `yaml:"capture" toml:"capture"`
+	Adaptive  AdaptiveCfg  `yaml:"adaptive" toml:"adaptive"`
+	Index     IndexCfg     `yaml:"index" toml:"index"`
+	Storage   StorageCfg   `yaml:"storage" toml:"storage"`
+}
+
+// ServerCfg holds the HTTP and gRPC server configuration parameters.
+type ServerCfg struct {
+	Addr               string        `yaml:"addr" toml:"addr"`
+	GRPCAddr           string        `yaml:"grpc_addr" toml:"grpc_addr"`
+	RequestTimeout     time.Duration `yaml:"request_timeout" toml:"request_timeout"`
+	ReadHeaderTimeout  time.Duration `yaml:"read_header_timeout" toml:"read_header_timeout"`
+	ShutdownTimeout    time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	DrainDelay         time.Duration `yaml:"drain_delay" toml:"drain_delay"`
+	HealthCheckTimeout time.Duration `yaml:"health_check_timeout" toml:"health_check_timeout"`
+}
+
+// AuthCfg holds the client authentication and authorization parameters.
+// Clients are authenticated with mTLS by Nginx, which forwards the verified
+// certificate subject in ClientSubjectHeader.
+type AuthCfg struct {
+	ClientSubjectHeader string `yaml:"client_subject_header" toml:"client_subject_header"`
+	// AdminSubjects lists the clients allowed to use the admin API of their
+	// tenant, written like SuperAdminSubjects. An empty list allows every
+	// authenticated client.
+	AdminSubjects []string `yaml:"admin_subjects" toml:"admin_subjects"`
+	// SuperAdminSubjects lists the clients allowed to manage the tenants as
+	// <tenant>/<device>, the organizational unit ("default" if it has none)
+	// and the common name of their certificate subject. An empty list
+	// disables tenant management.
+	SuperAdminSubjects []string `yaml:"super_admin_subjects" toml:"super_admin_subjects"`
+	// RequestSigning is whether terminal requests must be signed with the
+	// key of the calling device: off, optional (signatures present are
+	// verified) or required.
+	RequestSigning string `yaml:"request_signing" toml:"request_signing"`
+	// SignatureSkew is the maximum difference between the signature
+	// timestamp and the server clock.
+	SignatureSkew time.Duration `yaml:"signature_skew" toml:"signature_skew"`
+}
+
+// Request signing modes.
+const (
+	RequestSigningOff      = "off"
+	RequestSigningOptional = "optional"
+	RequestSigningRequired = "required"
+)
+
+// MatchingCfg holds the face matching parameters.
+type MatchingCfg struct {
+	// VectorSize must match the dimension of the embedding column in the database.
+	VectorSize int `yaml:"vector_size" toml:"vector_size"`
+	// Threshold is the minimum cosine similarity for a match.
+	Threshold float64 `yaml:"threshold" toml:"threshold"`
+	// Normalize scales vectors to unit L2 norm before they are stored or
+	// matched, for models whose embeddings are meant to be unit vectors.
+	Normalize bool `yaml:"normalize" toml:"normalize"`
+	// MaxBatchSize is the maximum number of probes of a batch validation.
+	MaxBatchSize int `yaml:"max_batch_size" toml:"max_batch_size"`
+	// BatchConcurrency is the number of probes of a batch validation
+	// validated at the same time. The probes are matched beforehand in a
+	// single query.
+	BatchConcurrency int `yaml:"batch_concurrency" toml:"batch_concurrency"`
+}
+
+// Default returns the built-in default configuration.
+func Default() *Config {
+	return &Config{
+		Server: ServerCfg{
+			Addr:               ":8081",
+			GRPCAddr:           ":50051",
+			RequestTimeout:     5 * time.Second,
+			ReadHeaderTimeout:  10 * time.Second,
+			ShutdownTimeout:    15 * time.Second,
+			DrainDelay:         2 * time.Second,
+			HealthCheckTimeout: 2 * time.Second,
+		},
+		DB: DbCfg{
+			Port:              "5432",
+			SSLMode:           "disable",
+			MaxOpenConns:      20,
+			MaxIdleConns:      10,
+			ConnMaxLifetime:   30 * time.Minute,
+			ConnMaxIdleTime:   5 * time.Minute,
+			StatementTimeout:  5 * time.Second,
+			ConnectAttempts:   10,
+			ConnectTimeout:    5 * time.Second,
+			ConnectBackoff:    500 * time.Millisecond,
+			ConnectMaxBackoff: 10 * time.Second,
+		},
+		Auth: AuthCfg{
+			ClientSubjectHeader: "X-Client-DN",
+			RequestSigning:      RequestSigningOff,
+			SignatureSkew:       30 * time.Second,
+		},
+		Matching: MatchingCfg{
+			VectorSize:       512,
+			Threshold:        0.58,
+			MaxBatchSize:     16,
+			BatchConcurrency: 4,
+		},
+		Log: LogCfg{
+			Level:  logrus.InfoLevel,
+			Format: LogFormatJSON,
+		},
+		Tracing: TracingCfg{
+			ServiceName: "access-system-server",
+			Exporter:    TracingExporterNone,
+			SampleRatio: 1,
+		},
+		Events: EventsCfg{
+			BufferSize: 64,
+			Heartbeat:  15 * time.Second,
+		},
+		Webhook: WebhookCfg{
+			PollInterval:   time.Second,
+			BatchSize:      50,
+			Timeout:        10 * time.Second,
+			MaxAttempts:    10,
+			InitialBackoff: 10 * time.Second,
+			MaxBackoff:     time.Hour,
+			Retention:      7 * 24 * time.Hour,
+		},
+		Door: DoorCfg{
+			BrokerURL:      "tcp://localhost:1883",
+			TopicPrefix:    "access/doors",
+			QoS:            1,
+			UnlockPulse:    5 * time.Second,
+			AlarmPulse:     30 * time.Second,
+			PublishTimeout: 2 * time.Second,
+			AckTimeout:     5 * time.Second,
+		},
+		RateLimit: RateLimitCfg{
+			DeviceRate:         5,
+			DeviceBurst:        10,
+			GlobalRate:         200,
+			GlobalBurst:        400,
+			LockoutThreshold:   10,
+			LockoutWindow:      time.Minute,
+			LockoutDuration:    time.Minute,
+			LockoutMaxDuration: time.Hour,
+		},
+		Adaptive: AdaptiveCfg{
+			Threshold:   0.85,
+			MinLiveness: 0.9,
+			MaxSamples:  10,
+		},
+		Index: IndexCfg{
+			CheckInterval:  5 * time.Second,
+			ResyncInterval: 10 * time.Minute,
+		},
+		Storage: StorageCfg{
+			Backend:        StoragePostgres,
+			Path:           "data/access.db",
+			EventRetention: 30 * 24 * time.Hour,
+		},
+	}
+}
+
+// Load builds the configuration from all layers and validates it.
+// args are the command line arguments without the program name.
+func Load(args []string) (*Config, error) {
+	if err := loadDotEnv(".env"); err != nil {
+		return nil, fmt.Errorf("load .env: %w", err)
+	}
+
+	flags, err := parseFlags(args)
+	if err != nil {
+		return nil, err
+	}
+
+	config := Default()
+
+	path := flags.configPath
+	if path == "" {
+		path, err = envValue("CONFIG_FILE")
+		if err != nil {
+			return nil, err
+		}
+	}
+	if path != "" {
+		if err := loadFile(config, path); err != nil {
+			return nil, fmt.Errorf("load config file %s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(config); err != nil {
+		return nil, err
+	}
+	if err := flags.apply(config); err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	return config, nil
+}
+
+// Validate checks the configuration and reports every invalid value.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Addr != "", "server.addr (SERVER_ADDR) is required")
+	check(c.Server.GRPCAddr == "" || c.Server.GRPCAddr != c.Server.Addr, "server.grpc_addr (SERVER_GRPC_ADDR) must differ from server.addr (SERVER_ADDR)")
+	check(c.Server.RequestTimeout > 0, "server.request_timeout (SERVER_REQUEST_TIMEOUT) must be positive")
+	check(c.Server.ReadHeaderTimeout > 0, "server.read_header_timeout (SERVER_READ_HEADER_TIMEOUT) must be positive")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout (SERVER_SHUTDOWN_TIMEOUT) must be positive")
+	check(c.Server.DrainDelay >= 0, "server.drain_delay (SERVER_DRAIN_DELAY) must not be negative")
+	check(c.Server.HealthCheckTimeout > 0, "server.health_check_timeout (SERVER_HEALTH_CHECK_TIMEOUT) must be positive")
+
+	// The database is only used by the Postgres backend.
+	if c.Storage.Backend == StoragePostgres {
+		check(c.DB.Host != "", "db.host (POSTGRES_HOST) is required")
+		check(c.DB.Port != "", "db.port (POSTGRES_PORT) is required")
+		check(c.DB.User != "", "db.user (POSTGRES_USER) is required")
+		check(c.DB.DBName != "", "db.name (POSTGRES_DB) is required")
+		switch c.DB.SSLMode {
+		case "disable", "require", "verify-ca", "verify-full":
+		default:
+			check(false, "db.sslmode (POSTGRES_SSLMODE) must be one of disable, require, verify-ca, verify-full, got %q", c.DB.SSLMode)
+		}
+		check((c.DB.SSLCert == "") == (c.DB.SSLKey == ""), "db.sslcert (POSTGRES_SSLCERT) and db.sslkey (POSTGRES_SSLKEY) must be set together")
+		check(c.DB.MaxOpenConns >= 0, "db.max_open_conns (POSTGRES_MAX_OPEN_CONNS) must not be negative")
+		check(c.DB.MaxIdleConns >= 0, "db.max_idle_conns (POSTGRES_MAX_IDLE_CONNS) must not be negative")
+		check(c.DB.MaxOpenConns == 0 || c.DB.MaxIdleConns <= c.DB.MaxOpenConns,
+			"db.max_idle_conns (POSTGRES_MAX_IDLE_CONNS) must not exceed db.max_open_conns (POSTGRES_MAX_OPEN_CONNS)")
+		check(c.DB.StatementTimeout >= 0, "db.statement_timeout (POSTGRES_STATEMENT_TIMEOUT) must not be negative")
+		check(c.DB.ConnectAttempts > 0, "db.connect_attempts (POSTGRES_CONNECT_ATTEMPTS) must be positive")
+		check(c.DB.ConnectTimeout > 0, "db.connect_timeout (POSTGRES_CONNECT_TIMEOUT) must be positive")
+		check(c.DB.ConnectBackoff > 0 && c.DB.ConnectBackoff <= c.DB.ConnectMaxBackoff,
+			"db.connect_backoff (POSTGRES_CONNECT_BACKOFF) must be positive and not exceed db.connect_max_backoff (POSTGRES_CONNECT_MAX_BACKOFF)")
+	}
+
+	check(c.Auth.ClientSubjectHeader != "", "auth.client_subject_header (AUTH_CLIENT_SUBJECT_HEADER) is required")
+	for _, subject := range c.Auth.AdminSubjects {
+		check(validClient(subject), "auth.admin_subjects (AUTH_ADMIN_SUBJECTS) must be written as <tenant>/<device>, got %q", subject)
+	}
+	for _, subject := range c.Auth.SuperAdminSubjects {
+		check(validClient(subject), "auth.super_admin_subjects (AUTH_SUPER_ADMIN_SUBJECTS) must be written as <tenant>/<device>, got %q", subject)
+	}
+	switch c.Auth.RequestSigning {
+	case RequestSigningOff:
+	case RequestSigningOptional, RequestSigningRequired:
+		check(c.Auth.SignatureSkew > 0, "auth.signature_skew (AUTH_SIGNATURE_SKEW) must be positive")
+	default:
+		check(false, "auth.request_signing (AUTH_REQUEST_SIGNING) must be one of off, optional, required, got %q", c.Auth.RequestSigning)
+	}
+
+	check(c.Matching.VectorSize > 0, "matching.vector_size (MATCHING_VECTOR_SIZE) must be positive, got %d", c.Matching.VectorSize)
+	check(c.Matching.Threshold >= 0 && c.Matching.Threshold < 1,
+		"matching.threshold (MATCHING_THRESHOLD) must be in [0, 1), got %v", c.Matching.Threshold)
+	check(c.Matching.MaxBatchSize > 0, "matching.max_batch_size (MATCHING_MAX_BATCH_SIZE) must be positive, got %d", c.Matching.MaxBatchSize)
+	check(c.Matching.BatchConcurrency > 0, "matching.batch_concurrency (MATCHING_BATCH_CONCURRENCY) must be positive, got %d", c.Matching.BatchConcurrency)
+
+	check(c.Log.Format == LogFormatJSON || c.Log.Format == LogFormatText,
+		"log.format (LOG_FORMAT) must be %q or %q, got %q", LogFormatJSON, LogFormatText, c.Log.Format)
+
+	switch c.Tracing.Exporter {
+	case TracingExporterNone, TracingExporterStdout, TracingExporterOTLP:
+	case TracingExporterFile:
+		check(c.Tracing.FilePath != "", "tracing.file_path (TRACING_FILE_PATH) is required for the %q exporter", TracingExporterFile)
+	default:
+		check(false, "tracing.exporter (TRACING_EXPORTER) must be one of none, stdout, file, otlp, got %q", c.Tracing.Exporter)
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+		"tracing.sample_ratio (TRACING_SAMPLE_RATIO) must be in [0, 1], got %v", c.Tracing.SampleRatio)
+
+	check(c.Events.BufferSize > 0, "events.buffer_size (EVENTS_BUFFER_SIZE) must be positive, got %d", c.Events.BufferSize)
+	check(c.Events.Heartbeat > 0, "events.heartbeat (EVENTS_HEARTBEAT) must be positive")
+
+	check(c.Webhook.PollInterval > 0, "webhook.poll_interval (WEBHOOK_POLL_INTERVAL) must be positive")
+	check(c.Webhook.BatchSize > 0, "webhook.batch_size (WEBHOOK_BATCH_SIZE) must be positive, got %d", c.Webhook.BatchSize)
+	check(c.Webhook.Timeout > 0, "webhook.timeout (WEBHOOK_TIMEOUT) must be positive")
+	check(c.Webhook.MaxAttempts > 0, "webhook.max_attempts (WEBHOOK_MAX_ATTEMPTS) must be positive, got %d", c.Webhook.MaxAttempts)
+	check(c.Webhook.InitialBackoff > 0 && c.Webhook.InitialBackoff <= c.Webhook.MaxBackoff,
+		"webhook.initial_backoff (WEBHOOK_INITIAL_BACKOFF) must be positive and not exceed webhook.max_backoff (WEBHOOK_MAX_BACKOFF)")
+	check(c.Webhook.Retention > 0, "webhook.retention (WEBHOOK_RETENTION) must be positive")
+	for _, host := range c.Webhook.AllowedHosts {
+		check(validAllowedHost(host), "webhook.allowed_hosts (WEBHOOK_ALLOWED_HOSTS) must be host names, IP addresses or CIDR ranges, got %q", host)
+	}
+
+	check(c.RateLimit.DeviceRate >= 0, "rate_limit.device_rate (RATE_LIMIT_DEVICE_RATE) must not be negative")
+	check(c.RateLimit.DeviceRate == 0 || c.RateLimit.DeviceBurst > 0, "rate_limit.device_burst (RATE_LIMIT_DEVICE_BURST) must be positive, got %d", c.RateLimit.DeviceBurst)
+	check(c.RateLimit.GlobalRate >= 0, "rate_limit.global_rate (RATE_LIMIT_GLOBAL_RATE) must not be negative")
+	check(c.RateLimit.GlobalRate == 0 || c.RateLimit.GlobalBurst > 0, "rate_limit.global_burst (RATE_LIMIT_GLOBAL_BURST) must be positive, got %d", c.RateLimit.GlobalBurst)
+	check(c.RateLimit.LockoutThreshold >= 0, "rate_limit.lockout_threshold (RATE_LIMIT_LOCKOUT_THRESHOLD) must not be negative")
+	if c.RateLimit.LockoutThreshold > 0 {
+		check(c.RateLimit.LockoutWindow > 0, "rate_limit.lockout_window (RATE_LIMIT_LOCKOUT_WINDOW) must be positive")
+		check(c.RateLimit.LockoutDuration > 0 && c.RateLimit.LockoutDuration <= c.RateLimit.LockoutMaxDuration,
+			"rate_limit.lockout_duration (RATE_LIMIT_LOCKOUT_DURATION) must be positive and not exceed rate_limit.lockout_max_duration (RATE_LIMIT_LOCKOUT_MAX_DURATION)")
+	}
+
+	check(validScore(c.Capture.MinLiveness), "capture.min_liveness (CAPTURE_MIN_LIVENESS) must be in [0, 1], got %v", c.Capture.MinLiveness)
+	check(validScore(c.Capture.MinQuality), "capture.min_quality (CAPTURE_MIN_QUALITY) must be in [0, 1], got %v", c.Capture.MinQuality)
+	for accessPoint, minimum := range c.Capture.AccessPointMinLiveness {
+		check(validScore(minimum), "capture.access_point_min_liveness (CAPTURE_ACCESS_POINT_MIN_LIVENESS) of %q must be in [0, 1], got %v", accessPoint, minimum)
+	}
+	for accessPoint, minimum := range c.Capture.AccessPointMinQuality {
+		check(validScore(minimum), "capture.access_point_min_quality (CAPTURE_ACCESS_POINT_MIN_QUALITY) of %q must be in [0, 1], got %v", accessPoint, minimum)
+	}
+
+	if c.Adaptive.Enabled {
+		check(c.Adaptive.Threshold > c.Matching.Threshold && c.Adaptive.Threshold <= 1,
+			"adaptive.threshold (ADAPTIVE_THRESHOLD) must be above matching.threshold (MATCHING_THRESHOLD) and at most 1, got %v", c.Adaptive.Threshold)
+		check(c.Adaptive.MinLiveness > 0 && c.Adaptive.MinLiveness <= 1, "adaptive.min_liveness (ADAPTIVE_MIN_LIVENESS) must be in (0, 1], got %v", c.Adaptive.MinLiveness)
+		check(c.Adaptive.MaxSamples > 0, "adaptive.max_samples (ADAPTIVE_MAX_SAMPLES) must be positive, got %d", c.Adaptive.MaxSamples)
+	}
+
+	if c.Index.Enabled {
+		check(c.Index.CheckInterval > 0, "index.check_interval (INDEX_CHECK_INTERVAL) must be positive")
+		check(c.Index.ResyncInterval > 0, "index.resync_interval (INDEX_RESYNC_INTERVAL) must be positive")
+	}
+
+	switch c.Storage.Backend {
+	case StoragePostgres:
+	case StorageMemory, StorageBolt:
+		check(!c.Index.Enabled, "index.enabled (INDEX_ENABLED) requires storage.backend (STORAGE_BACKEND) postgres")
+		check(!c.Adaptive.Enabled, "adaptive.enabled (ADAPTIVE_ENABLED) requires storage.backend (STORAGE_BACKEND) postgres")
+		check(c.Auth.RequestSigning == RequestSigningOff, "auth.request_signing (AUTH_REQUEST_SIGNING) requires storage.backend (STORAGE_BACKEND) postgres")
+	default:
+		check(false, "storage.backend (STORAGE_BACKEND) must be one of postgres, memory, bolt, got %q", c.Storage.Backend)
+	}
+	if c.Storage.Backend == StorageBolt {
+		check(c.Storage.Path != "", "storage.path (STORAGE_PATH) is required")
+		check(c.Storage.EventRetention >= 0, "storage.event_retention (STORAGE_EVENT_RETENTION) must not be negative")
+	}
+
+	if c.Door.Enabled {
+		check(c.Door.BrokerURL != "", "door.broker_url (DOOR_BROKER_URL) is required when door actuation is enabled")
+		check(validTopicPath(c.Door.TopicPrefix), "door.topic_prefix (DOOR_TOPIC_PREFIX) must be a topic without wildcards, got %q", c.Door.TopicPrefix)
+		check(c.Door.QoS >= 0 && c.Door.QoS <= 2, "door.qos (DOOR_QOS) must be 0, 1 or 2, got %d", c.Door.QoS)
+		check(c.Door.UnlockPulse > 0, "door.unlock_pulse (DOOR_UNLOCK_PULSE) must be positive")
+		check(c.Door.AlarmPulse > 0, "door.alarm_pulse (DOOR_ALARM_PULSE) must be positive")
+		check(c.Door.PublishTimeout > 0, "door.publish_timeout (DOOR_PUBLISH_TIMEOUT) must be positive")
+		check(c.Door.AckTimeout > 0, "door.ack_timeout (DOOR_ACK_TIMEOUT) must be positive")
+		for device, accessPoint := range c.Door.AccessPoints {
+			check(validTopicLevel(accessPoint), "door.access_points (DOOR_ACCESS_POINTS) of %q must be a single topic level without wildcards, got %q", device, accessPoint)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validScore reports whether score is a valid capture score minimum.
+func validScore(score float64) bool {
+	return score >= 0 && score <= 1
+}
+
+// validTopicPath reports whether topic is a non-empty MQTT topic without wildcards.
+func validTopicPath(topic string) bool {
+	return topic != "" && !strings.ContainsAny(topic, "+#\x00")
+}
+
+// validTopicLevel reports whether level can be used as a single MQTT topic level.
+func validTopicLevel(level string) bool {
+	return validTopicPath(level) && !strings.Contains(level, "/")
+}
+
+// validAllowedHost reports whether host is a host name, an IP address or a
+// CIDR range.
+func validAllowedHost(host string) bool {
+	if strings.Contains(host, "/") {
+		_, err := netip.ParsePrefix(host)
+		return err == nil
+	}
+	if _, err := netip.ParseAddr(host); err == nil {
+		return true
+	}
+	return host != "" && !strings.ContainsAny(host, ":[] ")
+}
+
+// validClient reports whether client is written as <tenant>/<device>.
+func validClient(client string) bool {
+	tenant, device, ok := strings.Cut(client, "/")
+	return ok && tenant != "" && device != "" && !strings.Contains(device, "/")
+}
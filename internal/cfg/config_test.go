@@ -0,0 +1,279 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func setRequiredEnv(t *testing.T) {
+	t.Setenv("POSTGRES_HOST", "localhost")
+	t.Setenv("POSTGRES_PORT", "5432")
+	t.Setenv("POSTGRES_USER", "postgres")
+	t.Setenv("POSTGRES_DB", "postgres")
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	setRequiredEnv(t)
+
+	config, err := Load(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ":8081", config.Server.Addr)
+	assert.Equal(t, 5*time.Second, config.Server.RequestTimeout)
+	assert.Equal(t, 512, config.Matching.VectorSize)
+	assert.Equal(t, 0.58, config.Matching.Threshold)
+	assert.Equal(t, logrus.InfoLevel, config.Log.Level)
+	assert.Equal(t, "localhost", config.DB.Host)
+}
+
+func TestLoad_YAMLFile(t *testing.T) {
+	setRequiredEnv(t)
+	path := writeFile(t, "config.yaml", `
+server:
+  addr: ":9090"
+  request_timeout: 3s
+auth:
+  admin_subjects: [default/admin-01, law/admin-02]
+matching:
+  threshold: 0.7
+log:
+  level: debug
+`)
+
+	config, err := Load([]string{"-config", path})
+	assert.NoError(t, err)
+	assert.Equal(t, ":9090", config.Server.Addr)
+	assert.Equal(t, 3*time.Second, config.Server.RequestTimeout)
+	assert.Equal(t, []string{"default/admin-01", "law/admin-02"}, config.Auth.AdminSubjects)
+	assert.Equal(t, 0.7, config.Matching.Threshold)
+	assert.Equal(t, logrus.DebugLevel, config.Log.Level)
+	assert.Equal(t, 512, config.Matching.VectorSize)
+}
+
+func TestLoad_TOMLFile(t *testing.T) {
+	setRequiredEnv(t)
+	path := writeFile(t, "config.toml", `
+[server]
+addr = ":9091"
+shutdown_timeout = "30s"
+
+[tracing]
+exporter = "stdout"
+`)
+	t.Setenv("CONFIG_FILE", path)
+
+	config, err := Load(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ":9091", config.Server.Addr)
+	assert.Equal(t, 30*time.Second, config.Server.ShutdownTimeout)
+	assert.Equal(t, TracingExporterStdout, config.Tracing.Exporter)
+}
+
+func TestLoad_UnknownFileKey(t *testing.T) {
+	setRequiredEnv(t)
+	path := writeFile(t, "config.yaml", "server:\n  port: 8081\n")
+
+	_, err := Load([]string{"-config", path})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "port")
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	setRequiredEnv(t)
+	path := writeFile(t, "config.yaml", "server:\n  addr: \":9090\"\nlog:\n  format: text\n")
+	t.Setenv("SERVER_ADDR", ":9092")
+
+	config, err := Load([]string{"-config", path, "-addr", ":9093"})
+	assert.NoError(t, err)
+	assert.Equal(t, ":9093", config.Server.Addr)
+	assert.Equal(t, LogFormatText, config.Log.Format)
+
+	config, err = Load([]string{"-config", path})
+	assert.NoError(t, err)
+	assert.Equal(t, ":9092", config.Server.Addr)
+}
+
+func TestLoad_SecretFromFile(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("POSTGRES_PASSWORD_FILE", writeFile(t, "password", "s3cret\n"))
+
+	config, err := Load(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cret", config.DB.Password)
+}
+
+func TestLoad_SecretFileMissing(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("POSTGRES_PASSWORD_FILE", filepath.Join(t.TempDir(), "missing"))
+
+	_, err := Load(nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "POSTGRES_PASSWORD_FILE")
+}
+
+func TestLoad_InvalidEnvValue(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("SERVER_REQUEST_TIMEOUT", "five seconds")
+
+	_, err := Load(nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "SERVER_REQUEST_TIMEOUT")
+}
+
+func TestLoad_ZonesFromEnv(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("EVENTS_ZONES", "gate-01=north, gate-02=south")
+
+	config, err := Load(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"gate-01": "north", "gate-02": "south"}, config.Events.Zones)
+
+	t.Setenv("EVENTS_ZONES", "gate-01")
+	_, err = Load(nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "EVENTS_ZONES")
+}
+
+func TestValidate_ReportsAllErrors(t *testing.T) {
+	config := Default()
+	config.Matching.Threshold = 1.5
+	config.Tracing.Exporter = TracingExporterFile
+	config.Auth.RequestSigning = "always"
+
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "db.host (POSTGRES_HOST) is required")
+	assert.Contains(t, err.Error(), "matching.threshold (MATCHING_THRESHOLD) must be in [0, 1), got 1.5")
+	assert.Contains(t, err.Error(), "tracing.file_path (TRACING_FILE_PATH) is required")
+	assert.Contains(t, err.Error(), `auth.request_signing (AUTH_REQUEST_SIGNING) must be one of off, optional, required, got "always"`)
+}
+
+func TestValidate_SuperAdminSubjects(t *testing.T) {
+	config := Default()
+	config.DB.Host, config.DB.User, config.DB.DBName = "localhost", "user", "db"
+	config.Auth.SuperAdminSubjects = []string{"default/root-01", "platform/ops-01"}
+	assert.NoError(t, config.Validate())
+
+	// A common name alone does not identify a client across tenants.
+	config.Auth.SuperAdminSubjects = []string{"root-01"}
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `auth.super_admin_subjects (AUTH_SUPER_ADMIN_SUBJECTS) must be written as <tenant>/<device>, got "root-01"`)
+}
+
+func TestValidate_AdminSubjects(t *testing.T) {
+	config := Default()
+	config.DB.Host, config.DB.User, config.DB.DBName = "localhost", "user", "db"
+	config.Auth.AdminSubjects = []string{"law/admin-01"}
+	assert.NoError(t, config.Validate())
+
+	// The same common name may be issued by every tenant.
+	config.Auth.AdminSubjects = []string{"admin-01"}
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `auth.admin_subjects (AUTH_ADMIN_SUBJECTS) must be written as <tenant>/<device>, got "admin-01"`)
+}
+
+func TestValidate_WebhookAllowedHosts(t *testing.T) {
+	config := Default()
+	config.DB.Host, config.DB.User, config.DB.DBName = "localhost", "user", "db"
+	config.Webhook.AllowedHosts = []string{"helpdesk.internal", "10.0.0.0/8", "192.168.1.5", "fd00::1"}
+	assert.NoError(t, config.Validate())
+
+	config.Webhook.AllowedHosts = []string{"10.0.0.0/33"}
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `webhook.allowed_hosts (WEBHOOK_ALLOWED_HOSTS) must be host names, IP addresses or CIDR ranges, got "10.0.0.0/33"`)
+}
+
+func TestValidate_DoorTopics(t *testing.T) {
+	config := Default()
+	config.DB.Host, config.DB.User, config.DB.DBName = "localhost", "user", "db"
+	config.Door.AccessPoints = map[string]string{"gate-01": "north/door"}
+	config.Door.TopicPrefix = "access/#"
+
+	// Door settings are only checked when door actuation is enabled.
+	assert.NoError(t, config.Validate())
+
+	config.Door.Enabled = true
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `door.topic_prefix (DOOR_TOPIC_PREFIX) must be a topic without wildcards, got "access/#"`)
+	assert.Contains(t, err.Error(), `door.access_points (DOOR_ACCESS_POINTS) of "gate-01" must be a single topic level`)
+}
+
+func TestLoad_CaptureMinimumsFromEnv(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("CAPTURE_MIN_LIVENESS", "0.5")
+	t.Setenv("CAPTURE_ACCESS_POINT_MIN_LIVENESS", "north-door=0.9, lobby=0.7")
+
+	config, err := Load(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, config.Capture.MinLiveness)
+	assert.Equal(t, map[string]float64{"north-door": 0.9, "lobby": 0.7}, config.Capture.AccessPointMinLiveness)
+
+	t.Setenv("CAPTURE_ACCESS_POINT_MIN_LIVENESS", "north-door=high")
+	_, err = Load(nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "CAPTURE_ACCESS_POINT_MIN_LIVENESS")
+
+	t.Setenv("CAPTURE_ACCESS_POINT_MIN_LIVENESS", "north-door=1.2")
+	_, err = Load(nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `capture.access_point_min_liveness (CAPTURE_ACCESS_POINT_MIN_LIVENESS) of "north-door" must be in [0, 1], got 1.2`)
+}
+
+func TestValidate_Adaptive(t *testing.T) {
+	config := Default()
+	config.DB.Host, config.DB.User, config.DB.DBName = "localhost", "user", "db"
+	config.Adaptive.Threshold = 0.5
+
+	// Adaptive settings are only checked when adaptive updates are enabled.
+	assert.NoError(t, config.Validate())
+
+	config.Adaptive.Enabled = true
+	config.Adaptive.MinLiveness = 0
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "adaptive.threshold (ADAPTIVE_THRESHOLD) must be above matching.threshold (MATCHING_THRESHOLD) and at most 1, got 0.5")
+	assert.Contains(t, err.Error(), "adaptive.min_liveness (ADAPTIVE_MIN_LIVENESS) must be in (0, 1], got 0")
+}
+
+func TestValidate_Storage(t *testing.T) {
+	config := Default()
+	config.Storage.Backend = StorageMemory
+
+	// The database settings are not needed without the Postgres backend.
+	assert.NoError(t, config.Validate())
+
+	config.Index.Enabled = true
+	config.Auth.RequestSigning = RequestSigningRequired
+	err := config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "index.enabled (INDEX_ENABLED) requires storage.backend (STORAGE_BACKEND) postgres")
+	assert.Contains(t, err.Error(), "auth.request_signing (AUTH_REQUEST_SIGNING) requires storage.backend (STORAGE_BACKEND) postgres")
+
+	config.Storage.Backend = StorageBolt
+	config.Storage.Path = ""
+	err = config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "index.enabled (INDEX_ENABLED) requires storage.backend (STORAGE_BACKEND) postgres")
+	assert.Contains(t, err.Error(), "storage.path (STORAGE_PATH) is required")
+
+	config.Storage.Backend = "sqlite"
+	err = config.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `storage.backend (STORAGE_BACKEND) must be one of postgres, memory, bolt, got "sqlite"`)
+}
@@ -1,48 +1,91 @@
 package cfg
 
 import (
+	"errors"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 // DbCfg holds the database configuration parameters.
 type DbCfg struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
+	Host     string `yaml:"host" toml:"host"`
+	Port     string `yaml:"port" toml:"port"`
+	User     string `yaml:"user" toml:"user"`
+	Password string `yaml:"password" toml:"password"`
+	DBName   string `yaml:"name" toml:"name"`
+
+	// SSLMode is one of the libpq modes: disable, require, verify-ca or verify-full.
+	SSLMode     string `yaml:"sslmode" toml:"sslmode"`
+	SSLRootCert string `yaml:"sslrootcert" toml:"sslrootcert"`
+	SSLCert     string `yaml:"sslcert" toml:"sslcert"`
+	SSLKey      string `yaml:"sslkey" toml:"sslkey"`
+
+	MaxOpenConns     int           `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns     int           `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime  time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+	ConnMaxIdleTime  time.Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time"`
+	StatementTimeout time.Duration `yaml:"statement_timeout" toml:"statement_timeout"`
+
+	// ConnectAttempts bounds the connection attempts at startup; the delay
+	// between attempts doubles from ConnectBackoff up to ConnectMaxBackoff.
+	ConnectAttempts   int           `yaml:"connect_attempts" toml:"connect_attempts"`
+	ConnectTimeout    time.Duration `yaml:"connect_timeout" toml:"connect_timeout"`
+	ConnectBackoff    time.Duration `yaml:"connect_backoff" toml:"connect_backoff"`
+	ConnectMaxBackoff time.Duration `yaml:"connect_max_backoff" toml:"connect_max_backoff"`
 }
 
-// LoadDbCfg loads database configuration from environment variables.
-func LoadDbCfg() (*DbCfg, error) {
-	err := godotenv.Load(".env")
-	if err != nil {
+// LoadTestDbCfg loads test database configuration from environment variables
+// on top of the default pool settings.
+// The nearest .env file in the working directory or its parents is loaded if present.
+func LoadTestDbCfg() (*DbCfg, error) {
+	if err := loadDotEnv(findDotEnv()); err != nil {
 		return nil, err
 	}
 
-	return &DbCfg{
-		Host:     os.Getenv("POSTGRES_HOST"),
-		Port:     os.Getenv("POSTGRES_PORT"),
-		User:     os.Getenv("POSTGRES_USER"),
-		Password: os.Getenv("POSTGRES_PASSWORD"),
-		DBName:   os.Getenv("POSTGRES_DB"),
-	}, nil
+	dbCfg := Default().DB
+	dbCfg.Host = os.Getenv("POSTGRES_TEST_HOST")
+	dbCfg.Port = os.Getenv("POSTGRES_TEST_PORT")
+	dbCfg.User = os.Getenv("POSTGRES_TEST_USER")
+	dbCfg.Password = os.Getenv("POSTGRES_TEST_PASSWORD")
+	dbCfg.DBName = os.Getenv("POSTGRES_TEST_DB")
+	dbCfg.ConnectAttempts = 1
+
+	return &dbCfg, nil
 }
 
-// LoadTestDbCfg loads test database configuration from environment variables.
-func LoadTestDbCfg() (*DbCfg, error) {
-	err := godotenv.Load("../../.env")
-	if err != nil {
-		return nil, err
+// loadDotEnv loads environment variables from path without overriding the
+// ones already set. A missing file is not an error.
+func loadDotEnv(path string) error {
+	if path == "" {
+		return nil
 	}
+	err := godotenv.Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
 
-	return &DbCfg{
-		Host:     os.Getenv("POSTGRES_TEST_HOST"),
-		Port:     os.Getenv("POSTGRES_TEST_PORT"),
-		User:     os.Getenv("POSTGRES_TEST_USER"),
-		Password: os.Getenv("POSTGRES_TEST_PASSWORD"),
-		DBName:   os.Getenv("POSTGRES_TEST_DB"),
-	}, nil
+// findDotEnv returns the path of the nearest .env file in the working
+// directory or its parents, or an empty string if there is none.
+func findDotEnv() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, ".env")
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
 }
@@ -0,0 +1,34 @@
+package cfg
+
+import "time"
+
+// DoorCfg holds the door controller actuation parameters. Commands are
+// published to "<TopicPrefix>/<access point>/command" and door state
+// acknowledgements are read from "<TopicPrefix>/<access point>/state".
+type DoorCfg struct {
+	// Enabled turns on publishing of door commands.
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// BrokerURL is the MQTT broker, e.g. tcp://mosquitto:1883 or ssl://broker:8883.
+	BrokerURL string `yaml:"broker_url" toml:"broker_url"`
+	// ClientID must be unique per instance; a random one is used if empty.
+	ClientID string `yaml:"client_id" toml:"client_id"`
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+	// TopicPrefix is the first part of every command and state topic.
+	TopicPrefix string `yaml:"topic_prefix" toml:"topic_prefix"`
+	QoS         int    `yaml:"qos" toml:"qos"`
+	// UnlockPulse is how long a door is released after a match.
+	UnlockPulse time.Duration `yaml:"unlock_pulse" toml:"unlock_pulse"`
+	// AlarmPulse is how long the alarm output is driven.
+	AlarmPulse time.Duration `yaml:"alarm_pulse" toml:"alarm_pulse"`
+	// AlarmOnDenied sends an alarm command when a validation finds no match.
+	AlarmOnDenied bool `yaml:"alarm_on_denied" toml:"alarm_on_denied"`
+	// AccessPoints maps device certificate common names to the access point
+	// they control. Devices that are not listed control the access point
+	// named after their common name.
+	AccessPoints map[string]string `yaml:"access_points" toml:"access_points"`
+	// PublishTimeout bounds the wait for the broker to accept a command.
+	PublishTimeout time.Duration `yaml:"publish_timeout" toml:"publish_timeout"`
+	// AckTimeout is how long a controller has to acknowledge a command.
+	AckTimeout time.Duration `yaml:"ack_timeout" toml:"ack_timeout"`
+}
@@ -0,0 +1,275 @@
+package cfg
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// envBinding maps an environment variable to a configuration field.
+type envBinding struct {
+	name string
+	set  func(value string) error
+}
+
+// envBindings returns the environment variables understood by the configuration.
+func envBindings(c *Config) []envBinding {
+	return []envBinding{
+		{"SERVER_ADDR", setString(&c.Server.Addr)},
+		{"SERVER_GRPC_ADDR", setString(&c.Server.GRPCAddr)},
+		{"SERVER_REQUEST_TIMEOUT", setDuration(&c.Server.RequestTimeout)},
+		{"SERVER_READ_HEADER_TIMEOUT", setDuration(&c.Server.ReadHeaderTimeout)},
+		{"SERVER_SHUTDOWN_TIMEOUT", setDuration(&c.Server.ShutdownTimeout)},
+		{"SERVER_DRAIN_DELAY", setDuration(&c.Server.DrainDelay)},
+		{"SERVER_HEALTH_CHECK_TIMEOUT", setDuration(&c.Server.HealthCheckTimeout)},
+
+		{"POSTGRES_HOST", setString(&c.DB.Host)},
+		{"POSTGRES_PORT", setString(&c.DB.Port)},
+		{"POSTGRES_USER", setString(&c.DB.User)},
+		{"POSTGRES_PASSWORD", setString(&c.DB.Password)},
+		{"POSTGRES_DB", setString(&c.DB.DBName)},
+		{"POSTGRES_SSLMODE", setString(&c.DB.SSLMode)},
+		{"POSTGRES_SSLROOTCERT", setString(&c.DB.SSLRootCert)},
+		{"POSTGRES_SSLCERT", setString(&c.DB.SSLCert)},
+		{"POSTGRES_SSLKEY", setString(&c.DB.SSLKey)},
+		{"POSTGRES_MAX_OPEN_CONNS", setInt(&c.DB.MaxOpenConns)},
+		{"POSTGRES_MAX_IDLE_CONNS", setInt(&c.DB.MaxIdleConns)},
+		{"POSTGRES_CONN_MAX_LIFETIME", setDuration(&c.DB.ConnMaxLifetime)},
+		{"POSTGRES_CONN_MAX_IDLE_TIME", setDuration(&c.DB.ConnMaxIdleTime)},
+		{"POSTGRES_STATEMENT_TIMEOUT", setDuration(&c.DB.StatementTimeout)},
+		{"POSTGRES_CONNECT_ATTEMPTS", setInt(&c.DB.ConnectAttempts)},
+		{"POSTGRES_CONNECT_TIMEOUT", setDuration(&c.DB.ConnectTimeout)},
+		{"POSTGRES_CONNECT_BACKOFF", setDuration(&c.DB.ConnectBackoff)},
+		{"POSTGRES_CONNECT_MAX_BACKOFF", setDuration(&c.DB.ConnectMaxBackoff)},
+
+		{"AUTH_CLIENT_SUBJECT_HEADER", setString(&c.Auth.ClientSubjectHeader)},
+		{"AUTH_ADMIN_SUBJECTS", setList(&c.Auth.AdminSubjects)},
+		{"AUTH_SUPER_ADMIN_SUBJECTS", setList(&c.Auth.SuperAdminSubjects)},
+		{"AUTH_REQUEST_SIGNING", setString(&c.Auth.RequestSigning)},
+		{"AUTH_SIGNATURE_SKEW", setDuration(&c.Auth.SignatureSkew)},
+
+		{"MATCHING_VECTOR_SIZE", setInt(&c.Matching.VectorSize)},
+		{"MATCHING_THRESHOLD", setFloat(&c.Matching.Threshold)},
+		{"MATCHING_NORMALIZE", setBool(&c.Matching.Normalize)},
+		{"MATCHING_MAX_BATCH_SIZE", setInt(&c.Matching.MaxBatchSize)},
+		{"MATCHING_BATCH_CONCURRENCY", setInt(&c.Matching.BatchConcurrency)},
+
+		{"LOG_LEVEL", setLevel(&c.Log.Level)},
+		{"LOG_FORMAT", setString(&c.Log.Format)},
+
+		{"TRACING_SERVICE_NAME", setString(&c.Tracing.ServiceName)},
+		{"TRACING_EXPORTER", setString(&c.Tracing.Exporter)},
+		{"TRACING_FILE_PATH", setString(&c.Tracing.FilePath)},
+		{"TRACING_OTLP_ENDPOINT", setString(&c.Tracing.OTLPEndpoint)},
+		{"TRACING_OTLP_INSECURE", setBool(&c.Tracing.OTLPInsecure)},
+		{"TRACING_SAMPLE_RATIO", setFloat(&c.Tracing.SampleRatio)},
+
+		{"EVENTS_BUFFER_SIZE", setInt(&c.Events.BufferSize)},
+		{"EVENTS_HEARTBEAT", setDuration(&c.Events.Heartbeat)},
+		{"EVENTS_ZONES", setMap(&c.Events.Zones)},
+
+		{"WEBHOOK_POLL_INTERVAL", setDuration(&c.Webhook.PollInterval)},
+		{"WEBHOOK_BATCH_SIZE", setInt(&c.Webhook.BatchSize)},
+		{"WEBHOOK_TIMEOUT", setDuration(&c.Webhook.Timeout)},
+		{"WEBHOOK_MAX_ATTEMPTS", setInt(&c.Webhook.MaxAttempts)},
+		{"WEBHOOK_INITIAL_BACKOFF", setDuration(&c.Webhook.InitialBackoff)},
+		{"WEBHOOK_MAX_BACKOFF", setDuration(&c.Webhook.MaxBackoff)},
+		{"WEBHOOK_RETENTION", setDuration(&c.Webhook.Retention)},
+		{"WEBHOOK_ALLOWED_HOSTS", setList(&c.Webhook.AllowedHosts)},
+
+		{"DOOR_ENABLED", setBool(&c.Door.Enabled)},
+		{"DOOR_BROKER_URL", setString(&c.Door.BrokerURL)},
+		{"DOOR_CLIENT_ID", setString(&c.Door.ClientID)},
+		{"DOOR_USERNAME", setString(&c.Door.Username)},
+		{"DOOR_PASSWORD", setString(&c.Door.Password)},
+		{"DOOR_TOPIC_PREFIX", setString(&c.Door.TopicPrefix)},
+		{"DOOR_QOS", setInt(&c.Door.QoS)},
+		{"DOOR_UNLOCK_PULSE", setDuration(&c.Door.UnlockPulse)},
+		{"DOOR_ALARM_PULSE", setDuration(&c.Door.AlarmPulse)},
+		{"DOOR_ALARM_ON_DENIED", setBool(&c.Door.AlarmOnDenied)},
+		{"DOOR_ACCESS_POINTS", setMap(&c.Door.AccessPoints)},
+		{"DOOR_PUBLISH_TIMEOUT", setDuration(&c.Door.PublishTimeout)},
+		{"DOOR_ACK_TIMEOUT", setDuration(&c.Door.AckTimeout)},
+
+		{"RATE_LIMIT_DEVICE_RATE", setFloat(&c.RateLimit.DeviceRate)},
+		{"RATE_LIMIT_DEVICE_BURST", setInt(&c.RateLimit.DeviceBurst)},
+		{"RATE_LIMIT_GLOBAL_RATE", setFloat(&c.RateLimit.GlobalRate)},
+		{"RATE_LIMIT_GLOBAL_BURST", setInt(&c.RateLimit.GlobalBurst)},
+		{"RATE_LIMIT_LOCKOUT_THRESHOLD", setInt(&c.RateLimit.LockoutThreshold)},
+		{"RATE_LIMIT_LOCKOUT_WINDOW", setDuration(&c.RateLimit.LockoutWindow)},
+		{"RATE_LIMIT_LOCKOUT_DURATION", setDuration(&c.RateLimit.LockoutDuration)},
+		{"RATE_LIMIT_LOCKOUT_MAX_DURATION", setDuration(&c.RateLimit.LockoutMaxDuration)},
+
+		{"CAPTURE_MIN_LIVENESS", setFloat(&c.Capture.MinLiveness)},
+		{"CAPTURE_MIN_QUALITY", setFloat(&c.Capture.MinQuality)},
+		{"CAPTURE_ACCESS_POINT_MIN_LIVENESS", setFloatMap(&c.Capture.AccessPointMinLiveness)},
+		{"CAPTURE_ACCESS_POINT_MIN_QUALITY", setFloatMap(&c.Capture.AccessPointMinQuality)},
+
+		{"ADAPTIVE_ENABLED", setBool(&c.Adaptive.Enabled)},
+		{"ADAPTIVE_THRESHOLD", setFloat(&c.Adaptive.Threshold)},
+		{"ADAPTIVE_MIN_LIVENESS", setFloat(&c.Adaptive.MinLiveness)},
+		{"ADAPTIVE_MAX_SAMPLES", setInt(&c.Adaptive.MaxSamples)},
+
+		{"INDEX_ENABLED", setBool(&c.Index.Enabled)},
+		{"INDEX_CHECK_INTERVAL", setDuration(&c.Index.CheckInterval)},
+		{"INDEX_RESYNC_INTERVAL", setDuration(&c.Index.ResyncInterval)},
+
+		{"STORAGE_BACKEND", setString(&c.Storage.Backend)},
+		{"STORAGE_PATH", setString(&c.Storage.Path)},
+		{"STORAGE_EVENT_RETENTION", setDuration(&c.Storage.EventRetention)},
+	}
+}
+
+// applyEnv overrides the configuration with the environment variables that are set.
+func applyEnv(c *Config) error {
+	for _, binding := range envBindings(c) {
+		value, err := envValue(binding.name)
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			continue
+		}
+		if err := binding.set(value); err != nil {
+			return fmt.Errorf("%s: %w", binding.name, err)
+		}
+	}
+	return nil
+}
+
+// envValue returns the value of the environment variable name.
+// If name_FILE is set instead, the value is read from that file, so secrets
+// can be mounted as files rather than passed in the environment.
+func envValue(name string) (string, error) {
+	if value := os.Getenv(name); value != "" {
+		return value, nil
+	}
+
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%s_FILE: %w", name, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func setString(field *string) func(string) error {
+	return func(value string) error {
+		*field = value
+		return nil
+	}
+}
+
+func setList(field *[]string) func(string) error {
+	return func(value string) error {
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		*field = list
+		return nil
+	}
+}
+
+// setMap parses comma separated key=value pairs, e.g. "gate-01=north,gate-02=south".
+func setMap(field *map[string]string) func(string) error {
+	return func(value string) error {
+		parsed := map[string]string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			key, val, ok := strings.Cut(item, "=")
+			if !ok || strings.TrimSpace(key) == "" {
+				return fmt.Errorf("invalid entry %q, expected key=value", item)
+			}
+			parsed[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		}
+		*field = parsed
+		return nil
+	}
+}
+
+// setFloatMap parses comma separated key=number pairs, e.g. "north-door=0.9,lobby=0.7".
+func setFloatMap(field *map[string]float64) func(string) error {
+	return func(value string) error {
+		var pairs map[string]string
+		if err := setMap(&pairs)(value); err != nil {
+			return err
+		}
+		parsed := make(map[string]float64, len(pairs))
+		for key, val := range pairs {
+			number, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				return fmt.Errorf("invalid value of %q: %w", key, err)
+			}
+			parsed[key] = number
+		}
+		*field = parsed
+		return nil
+	}
+}
+
+func setDuration(field *time.Duration) func(string) error {
+	return func(value string) error {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field = parsed
+		return nil
+	}
+}
+
+func setInt(field *int) func(string) error {
+	return func(value string) error {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*field = parsed
+		return nil
+	}
+}
+
+func setFloat(field *float64) func(string) error {
+	return func(value string) error {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		*field = parsed
+		return nil
+	}
+}
+
+func setBool(field *bool) func(string) error {
+	return func(value string) error {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*field = parsed
+		return nil
+	}
+}
+
+func setLevel(field *logrus.Level) func(string) error {
+	return func(value string) error {
+		parsed, err := logrus.ParseLevel(value)
+		if err != nil {
+			return err
+		}
+		*field = parsed
+		return nil
+	}
+}
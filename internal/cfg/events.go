@@ -0,0 +1,14 @@
+package cfg
+
+import "time"
+
+// EventsCfg holds the live access event stream parameters.
+type EventsCfg struct {
+	// BufferSize is the number of events buffered per subscriber; events for
+	// a subscriber that falls further behind are dropped.
+	BufferSize int `yaml:"buffer_size" toml:"buffer_size"`
+	// Heartbeat is the interval of keep-alive comments on idle streams.
+	Heartbeat time.Duration `yaml:"heartbeat" toml:"heartbeat"`
+	// Zones maps device certificate common names to the zone they guard.
+	Zones map[string]string `yaml:"zones" toml:"zones"`
+}
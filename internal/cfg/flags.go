@@ -0,0 +1,64 @@
+package cfg
+
+import (
+	"flag"
+	"fmt"
+)
+
+// flagBindings maps command line flags to the environment variable whose
+// setter they share. Flags override every other configuration layer.
+var flagBindings = map[string]string{
+	"addr":             "SERVER_ADDR",
+	"grpc-addr":        "SERVER_GRPC_ADDR",
+	"log-level":        "LOG_LEVEL",
+	"log-format":       "LOG_FORMAT",
+	"tracing-exporter": "TRACING_EXPORTER",
+	"vector-size":      "MATCHING_VECTOR_SIZE",
+	"threshold":        "MATCHING_THRESHOLD",
+}
+
+// flags holds the parsed command line flags.
+type flags struct {
+	configPath string
+	values     map[string]string
+}
+
+// parseFlags parses the command line arguments.
+func parseFlags(args []string) (*flags, error) {
+	fs := flag.NewFlagSet("access-system-server", flag.ContinueOnError)
+	result := &flags{values: map[string]string{}}
+
+	fs.StringVar(&result.configPath, "config", "", "path to a YAML or TOML config file (env CONFIG_FILE)")
+	values := map[string]*string{}
+	for name, env := range flagBindings {
+		values[name] = fs.String(name, "", fmt.Sprintf("overrides %s", env))
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// Only flags given explicitly override the other layers.
+	fs.Visit(func(f *flag.Flag) {
+		if value, ok := values[f.Name]; ok {
+			result.values[f.Name] = *value
+		}
+	})
+
+	return result, nil
+}
+
+// apply overrides the configuration with the explicitly set flags.
+func (f *flags) apply(c *Config) error {
+	setters := map[string]func(string) error{}
+	for _, binding := range envBindings(c) {
+		setters[binding.name] = binding.set
+	}
+
+	for name, value := range f.values {
+		if err := setters[flagBindings[name]](value); err != nil {
+			return fmt.Errorf("-%s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package cfg
+
+import "time"
+
+// IndexCfg holds the parameters of the in-memory vector index, which answers
+// similarity searches without a database query.
+type IndexCfg struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// CheckInterval is how often the change listener is checked. While it is
+	// disconnected, searches fall back to the database.
+	CheckInterval time.Duration `yaml:"check_interval" toml:"check_interval"`
+	// ResyncInterval is how often the index is reloaded from the database, as
+	// a safety net for missed changes.
+	ResyncInterval time.Duration `yaml:"resync_interval" toml:"resync_interval"`
+}
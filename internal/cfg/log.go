@@ -0,0 +1,17 @@
+package cfg
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// Supported log formats.
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// LogCfg holds the logging configuration parameters.
+type LogCfg struct {
+	Level  logrus.Level `yaml:"level" toml:"level"`
+	Format string       `yaml:"format" toml:"format"`
+}
@@ -0,0 +1,24 @@
+package cfg
+
+import "time"
+
+// RateLimitCfg holds the validation rate limits and the brute-force lockout
+// parameters. Limits are enforced per server instance.
+type RateLimitCfg struct {
+	// DeviceRate is the sustained number of validations per second allowed
+	// for one device, and DeviceBurst the number allowed at once. A rate of
+	// 0 disables the limit.
+	DeviceRate  float64 `yaml:"device_rate" toml:"device_rate"`
+	DeviceBurst int     `yaml:"device_burst" toml:"device_burst"`
+	// GlobalRate and GlobalBurst limit the validations of all devices together.
+	GlobalRate  float64 `yaml:"global_rate" toml:"global_rate"`
+	GlobalBurst int     `yaml:"global_burst" toml:"global_burst"`
+	// LockoutThreshold is the number of consecutive validations without a
+	// match within LockoutWindow that locks a device out. 0 disables lockouts.
+	LockoutThreshold int           `yaml:"lockout_threshold" toml:"lockout_threshold"`
+	LockoutWindow    time.Duration `yaml:"lockout_window" toml:"lockout_window"`
+	// LockoutDuration is the length of the first lockout; it doubles with
+	// every further lockout of the device up to LockoutMaxDuration.
+	LockoutDuration    time.Duration `yaml:"lockout_duration" toml:"lockout_duration"`
+	LockoutMaxDuration time.Duration `yaml:"lockout_max_duration" toml:"lockout_max_duration"`
+}
@@ -0,0 +1,26 @@
+package cfg
+
+import "time"
+
+// Storage backends.
+const (
+	// StoragePostgres keeps all data in Postgres.
+	StoragePostgres = "postgres"
+	// StorageMemory keeps the embeddings in memory, for development and
+	// tests. They are lost on restart, and the features that keep their
+	// state in Postgres are not available.
+	StorageMemory = "memory"
+	// StorageBolt keeps the embeddings and access events in an embedded
+	// bbolt file, for single-node sites without Postgres. The features that
+	// keep their state in Postgres are not available.
+	StorageBolt = "bolt"
+)
+
+// StorageCfg selects where the server keeps its data.
+type StorageCfg struct {
+	Backend string `yaml:"backend" toml:"backend"`
+	// Path is the bbolt file of the bolt backend.
+	Path string `yaml:"path" toml:"path"`
+	// EventRetention is how long the bolt backend keeps access events; 0 keeps them forever.
+	EventRetention time.Duration `yaml:"event_retention" toml:"event_retention"`
+}
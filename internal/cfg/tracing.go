@@ -0,0 +1,19 @@
+package cfg
+
+// Supported tracing exporters.
+const (
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterFile   = "file"
+	TracingExporterOTLP   = "otlp"
+)
+
+// TracingCfg holds the OpenTelemetry tracing configuration parameters.
+type TracingCfg struct {
+	ServiceName  string  `yaml:"service_name" toml:"service_name"`
+	Exporter     string  `yaml:"exporter" toml:"exporter"`
+	FilePath     string  `yaml:"file_path" toml:"file_path"`
+	OTLPEndpoint string  `yaml:"otlp_endpoint" toml:"otlp_endpoint"`
+	OTLPInsecure bool    `yaml:"otlp_insecure" toml:"otlp_insecure"`
+	SampleRatio  float64 `yaml:"sample_ratio" toml:"sample_ratio"`
+}
@@ -0,0 +1,25 @@
+package cfg
+
+import "time"
+
+// WebhookCfg holds the webhook delivery parameters.
+type WebhookCfg struct {
+	// PollInterval is how often the outbox and due deliveries are polled.
+	PollInterval time.Duration `yaml:"poll_interval" toml:"poll_interval"`
+	// BatchSize bounds the events fanned out and deliveries sent per poll.
+	BatchSize int `yaml:"batch_size" toml:"batch_size"`
+	// Timeout bounds a single delivery attempt.
+	Timeout time.Duration `yaml:"timeout" toml:"timeout"`
+	// MaxAttempts is the number of attempts before a delivery is dead-lettered.
+	MaxAttempts int `yaml:"max_attempts" toml:"max_attempts"`
+	// InitialBackoff is the delay before the first retry; it doubles with
+	// every attempt up to MaxBackoff.
+	InitialBackoff time.Duration `yaml:"initial_backoff" toml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" toml:"max_backoff"`
+	// Retention is how long delivered deliveries and their events are kept.
+	Retention time.Duration `yaml:"retention" toml:"retention"`
+	// AllowedHosts lists the internal hosts webhooks may be sent to, as host
+	// names, IP addresses or CIDR ranges. Loopback, link-local and private
+	// addresses are rejected otherwise.
+	AllowedHosts []string `yaml:"allowed_hosts" toml:"allowed_hosts"`
+}
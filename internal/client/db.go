@@ -1,21 +1,133 @@
 package client
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
 
 	"access-system-api/internal/cfg"
 
-	_ "github.com/lib/pq"
+	"github.com/XSAM/otelsql"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
-// ConnectDB establishes a connection to the PostgreSQL database using the provided configuration.
-func ConnectDB(dbCfg *cfg.DbCfg) (*sql.DB, error) {
-	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", dbCfg.User, dbCfg.Password, dbCfg.Host, dbCfg.Port, dbCfg.DBName)
-	db, err := sql.Open(`postgres`, connStr)
+// OpenDB creates a PostgreSQL connection pool using the provided configuration
+// without connecting to the database.
+// Pings and queries are traced with the global OpenTelemetry tracer provider.
+func OpenDB(dbCfg *cfg.DbCfg) (*sql.DB, error) {
+	db, err := otelsql.Open(`postgres`, connString(dbCfg),
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{Ping: true, OmitRows: true}),
+	)
 	if err != nil {
 		return nil, err
 	}
 
+	// Broken connections are discarded by database/sql and replaced on the next
+	// call; the lifetimes make sure connections to a restarted or failed-over
+	// server do not linger in the pool.
+	db.SetMaxOpenConns(dbCfg.MaxOpenConns)
+	db.SetMaxIdleConns(dbCfg.MaxIdleConns)
+	db.SetConnMaxLifetime(dbCfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(dbCfg.ConnMaxIdleTime)
+
 	return db, nil
 }
+
+// NewListener creates a LISTEN/NOTIFY listener on its own connection, outside
+// of the pool. The listener reconnects with backoff after connection failures.
+// Connection problems are logged and, like every connection event, passed to
+// onEvent if it is not nil.
+func NewListener(dbCfg *cfg.DbCfg, log *logrus.Logger, onEvent pq.EventCallbackType) *pq.Listener {
+	return pq.NewListener(connString(dbCfg), dbCfg.ConnectBackoff, dbCfg.ConnectMaxBackoff,
+		func(event pq.ListenerEventType, err error) {
+			if err != nil {
+				log.WithError(err).Warn("Postgres listener connection problem")
+			}
+			if onEvent != nil {
+				onEvent(event, err)
+			}
+		})
+}
+
+// ConnectDB establishes a connection to the PostgreSQL database using the provided configuration.
+// Connecting is retried with exponential backoff until it succeeds, the
+// configured attempts are exhausted or ctx is done.
+func ConnectDB(ctx context.Context, dbCfg *cfg.DbCfg, log *logrus.Logger) (*sql.DB, error) {
+	db, err := OpenDB(dbCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := max(dbCfg.ConnectAttempts, 1)
+	backoff := dbCfg.ConnectBackoff
+	for attempt := 1; ; attempt++ {
+		err = ping(ctx, db, dbCfg.ConnectTimeout)
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= attempts {
+			break
+		}
+
+		log.Warnf("DB connection attempt %d/%d failed, retrying in %s: %s", attempt, attempts, backoff, err.Error())
+		select {
+		case <-ctx.Done():
+			_ = db.Close()
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, max(dbCfg.ConnectMaxBackoff, backoff))
+	}
+
+	_ = db.Close()
+	return nil, fmt.Errorf("connect to database after %d attempts: %w", attempts, err)
+}
+
+func ping(ctx context.Context, db *sql.DB, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return db.PingContext(ctx)
+}
+
+// connString builds the lib/pq connection URL. Unknown parameters such as
+// statement_timeout are sent to the server as run-time parameters.
+func connString(dbCfg *cfg.DbCfg) string {
+	query := url.Values{}
+	sslMode := dbCfg.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	query.Set("sslmode", sslMode)
+	if dbCfg.SSLRootCert != "" {
+		query.Set("sslrootcert", dbCfg.SSLRootCert)
+	}
+	if dbCfg.SSLCert != "" {
+		query.Set("sslcert", dbCfg.SSLCert)
+		query.Set("sslkey", dbCfg.SSLKey)
+	}
+	if dbCfg.ConnectTimeout > 0 {
+		query.Set("connect_timeout", strconv.Itoa(int(max(dbCfg.ConnectTimeout/time.Second, 1))))
+	}
+	if dbCfg.StatementTimeout > 0 {
+		query.Set("statement_timeout", strconv.FormatInt(dbCfg.StatementTimeout.Milliseconds(), 10))
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(dbCfg.User, dbCfg.Password),
+		Host:     net.JoinHostPort(dbCfg.Host, dbCfg.Port),
+		Path:     "/" + dbCfg.DBName,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"access-system-api/internal/cfg"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnString(t *testing.T) {
+	dbCfg := cfg.Default().DB
+	dbCfg.Host = "db.internal"
+	dbCfg.User = "app"
+	dbCfg.Password = "p@ss/word"
+	dbCfg.DBName = "access"
+	dbCfg.SSLMode = "verify-full"
+	dbCfg.SSLRootCert = "/certs/ca.crt"
+	dbCfg.SSLCert = "/certs/client.crt"
+	dbCfg.SSLKey = "/certs/client.key"
+
+	u, err := url.Parse(connString(&dbCfg))
+	assert.NoError(t, err)
+
+	password, _ := u.User.Password()
+	assert.Equal(t, "p@ss/word", password)
+	assert.Equal(t, "db.internal:5432", u.Host)
+	assert.Equal(t, "/access", u.Path)
+
+	query := u.Query()
+	assert.Equal(t, "verify-full", query.Get("sslmode"))
+	assert.Equal(t, "/certs/ca.crt", query.Get("sslrootcert"))
+	assert.Equal(t, "/certs/client.crt", query.Get("sslcert"))
+	assert.Equal(t, "/certs/client.key", query.Get("sslkey"))
+	assert.Equal(t, "5000", query.Get("statement_timeout"))
+	assert.Equal(t, "5", query.Get("connect_timeout"))
+}
+
+func TestConnString_Defaults(t *testing.T) {
+	u, err := url.Parse(connString(&cfg.DbCfg{Host: "localhost", Port: "5432", DBName: "db"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "disable", u.Query().Get("sslmode"))
+	assert.False(t, u.Query().Has("statement_timeout"))
+}
+
+func TestConnectDB_RetriesThenFails(t *testing.T) {
+	// Reserve a free port and close it, so connecting is refused.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	_ = listener.Close()
+
+	dbCfg := cfg.Default().DB
+	dbCfg.Host = "127.0.0.1"
+	dbCfg.Port = port
+	dbCfg.DBName = "db"
+	dbCfg.ConnectAttempts = 3
+	dbCfg.ConnectBackoff = time.Millisecond
+	dbCfg.ConnectMaxBackoff = 2 * time.Millisecond
+
+	log, hook := test.NewNullLogger()
+	db, err := ConnectDB(context.Background(), &dbCfg, log)
+	assert.Nil(t, db)
+	assert.ErrorContains(t, err, "after 3 attempts")
+	assert.Len(t, hook.AllEntries(), 2)
+}
+
+func TestConnectDB_StopsOnContextCancel(t *testing.T) {
+	dbCfg := cfg.Default().DB
+	dbCfg.Host = "127.0.0.1"
+	dbCfg.Port = "1"
+	dbCfg.DBName = "db"
+	dbCfg.ConnectBackoff = time.Hour
+	dbCfg.ConnectMaxBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	log, _ := test.NewNullLogger()
+	_, err := ConnectDB(ctx, &dbCfg, log)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,24 @@
+package domain
+
+// Capture holds the scores a terminal computed for the face capture of a
+// request. Scores are in [0, 1] and nil if the terminal did not compute them.
+type Capture struct {
+	// Liveness is the anti-spoofing score; low values suggest a photo, screen or mask.
+	Liveness *float64
+	// Quality is the face quality score, e.g. sharpness, pose and lighting.
+	Quality *float64
+	// Metadata describes the capture, e.g. the camera or model version.
+	Metadata map[string]string
+}
+
+// CaptureMinimums are the minimum capture scores of a request. A minimum of 0
+// disables the check.
+type CaptureMinimums struct {
+	Liveness float64 `json:"liveness"`
+	Quality  float64 `json:"quality"`
+}
+
+// Max returns the stricter of each minimum of m and other.
+func (m CaptureMinimums) Max(other CaptureMinimums) CaptureMinimums {
+	return CaptureMinimums{Liveness: max(m.Liveness, other.Liveness), Quality: max(m.Quality, other.Quality)}
+}
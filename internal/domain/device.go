@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// Device is a registered terminal. Name is the common name of its client
+// certificate and SigningKey the key it signs requests with.
+type Device struct {
+	ID           int64
+	Name         string
+	SigningKey   string
+	CreatedAt    time.Time
+	KeyRotatedAt time.Time
+}
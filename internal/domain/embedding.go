@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"maps"
+	"time"
+
 	"github.com/pgvector/pgvector-go"
 )
 
@@ -10,4 +13,186 @@ type Embedding struct {
 	Name     string          `json:"name"`
 	Vector   pgvector.Vector `json:"vector"`
 	Accuracy float32         `json:"accuracy,omitempty"`
+	// Tenant is the tenant the embedding belongs to. Embeddings only match
+	// probes of devices of the same tenant.
+	Tenant string `json:"tenant,omitempty"`
+	// CaptureMinimums are the minimum capture scores of validations matching
+	// this embedding, on top of those of the access point.
+	CaptureMinimums CaptureMinimums `json:"capture_minimums"`
+	// Metadata are free-form attributes of the enrollment for the systems
+	// integrating with the server, e.g. an employee number. They play no part
+	// in matching.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Version starts at 1 and is incremented by every change of the
+	// embedding, so that concurrent changes can be detected.
+	Version int64 `json:"version"`
+}
+
+// Limits of the metadata of an embedding, lengths in bytes.
+const (
+	MaxMetadataKeys        = 64
+	MaxMetadataKeyLength   = 64
+	MaxMetadataValueLength = 1024
+)
+
+// EmbeddingPatch is a partial change of an embedding: only the fields that
+// are not nil are changed.
+type EmbeddingPatch struct {
+	Name   *string
+	Vector *pgvector.Vector
+	// MinLiveness and MinQuality change the CaptureMinimums.
+	MinLiveness *float64
+	MinQuality  *float64
+	Metadata    *MetadataPatch
+}
+
+// MetadataPatch is a JSON merge patch of the metadata of an embedding.
+type MetadataPatch struct {
+	// Clear removes every key before Set is applied, for a patch setting the
+	// metadata to null.
+	Clear bool
+	// Set maps the keys to change to their new value, or to nil to remove them.
+	Set map[string]*string
+}
+
+// Apply returns a copy of metadata with the patch applied, nil if it has no
+// keys left.
+func (p MetadataPatch) Apply(metadata map[string]string) map[string]string {
+	patched := map[string]string{}
+	if !p.Clear {
+		maps.Copy(patched, metadata)
+	}
+	for key, value := range p.Set {
+		if value == nil {
+			delete(patched, key)
+		} else {
+			patched[key] = *value
+		}
+	}
+	if len(patched) == 0 {
+		return nil
+	}
+	return patched
+}
+
+// Fields returns the JSON names of the fields the patch changes.
+func (p EmbeddingPatch) Fields() []string {
+	var fields []string
+	if p.Name != nil {
+		fields = append(fields, "name")
+	}
+	if p.Vector != nil {
+		fields = append(fields, "vector")
+	}
+	if p.MinLiveness != nil {
+		fields = append(fields, "min_liveness")
+	}
+	if p.MinQuality != nil {
+		fields = append(fields, "min_quality")
+	}
+	if p.Metadata != nil {
+		fields = append(fields, "metadata")
+	}
+	return fields
+}
+
+// Apply changes the fields of embedding set in the patch. The vector is
+// shared with the patch.
+func (p EmbeddingPatch) Apply(embedding *Embedding) {
+	if p.Name != nil {
+		embedding.Name = *p.Name
+	}
+	if p.Vector != nil {
+		embedding.Vector = *p.Vector
+	}
+	if p.MinLiveness != nil {
+		embedding.CaptureMinimums.Liveness = *p.MinLiveness
+	}
+	if p.MinQuality != nil {
+		embedding.CaptureMinimums.Quality = *p.MinQuality
+	}
+	if p.Metadata != nil {
+		embedding.Metadata = p.Metadata.Apply(embedding.Metadata)
+	}
+}
+
+// EmbeddingAudit records a change of an embedding: who made it, which fields
+// it changed and the versions of the embedding before and after it.
+type EmbeddingAudit struct {
+	ID          int64
+	EmbeddingID int64
+	// Device and RequestID identify the client and request that made the
+	// change, within the tenant of the embedding.
+	Device     string
+	RequestID  string
+	Fields     []string
+	OldVersion int64
+	NewVersion int64
+	CreatedAt  time.Time
+}
+
+// InvalidEmbedding is a stored embedding whose vector fails the checks of new vectors.
+type InvalidEmbedding struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Problem string `json:"problem"`
+}
+
+// Probe is one face of a batch validation, identified by the client.
+type Probe struct {
+	ID      string
+	Vector  []float32
+	Capture Capture
+}
+
+// ProbeResult is the outcome of a Probe: the matching embedding, or the
+// error the probe failed with, e.g. ErrNoMatch.
+type ProbeResult struct {
+	ID        string
+	Embedding *Embedding
+	Err       error
+}
+
+// Match is the match of a probe looked up beforehand: the matching embedding,
+// nil if there is none, or the error the lookup failed with.
+type Match struct {
+	Embedding *Embedding
+	Err       error
+}
+
+// Embedding sample sources.
+const (
+	// SampleSourceEnrollment is the vector an embedding had before its first
+	// adaptive update.
+	SampleSourceEnrollment = "enrollment"
+	// SampleSourceAdaptive is the probe of a high-confidence match.
+	SampleSourceAdaptive = "adaptive"
+)
+
+// Embedding sample statuses. Only active samples contribute to the vector of
+// their embedding.
+const (
+	SampleActive   = "active"
+	SampleEvicted  = "evicted"
+	SampleReverted = "reverted"
+	// SampleReset samples were replaced by updating the embedding.
+	SampleReset = "reset"
+)
+
+// EmbeddingSample is a vector contributing to the template of an adapted
+// embedding, whose vector is the normalized centroid of its active samples.
+type EmbeddingSample struct {
+	ID          int64
+	EmbeddingID int64
+	Source      string
+	Status      string
+	Vector      pgvector.Vector
+	// Accuracy, Liveness, Device and RequestID describe the validation that
+	// added an adaptive sample.
+	Accuracy        float32
+	Liveness        *float64
+	Device          string
+	RequestID       string
+	CreatedAt       time.Time
+	StatusChangedAt *time.Time
 }
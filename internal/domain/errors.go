@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Domain errors returned by the service and repository layers.
+// They are wrapped with details and mapped to HTTP status codes by the handlers.
+var (
+	ErrInvalidInput     = errors.New("invalid input")
+	ErrInvalidDimension = errors.New("invalid vector dimension")
+	ErrInvalidVector    = errors.New("invalid vector")
+	ErrNotFound         = errors.New("not found")
+	ErrNoMatch          = errors.New("no matching embedding")
+	ErrCaptureRejected  = errors.New("capture rejected")
+	ErrConflict         = errors.New("conflict")
+	ErrVersionConflict  = errors.New("version conflict")
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrReplayed         = errors.New("replayed request")
+	ErrForbidden        = errors.New("forbidden")
+	ErrRateLimited      = errors.New("rate limit exceeded")
+	ErrLockedOut        = errors.New("device locked out")
+)
+
+// RetryAfterError wraps an error of a request that may be retried after a delay.
+type RetryAfterError struct {
+	Err   error
+	After time.Duration
+}
+
+// RetryAfter returns a RetryAfterError wrapping err.
+func RetryAfter(err error, after time.Duration) error {
+	return &RetryAfterError{Err: err, After: after}
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%s, retry after %ds", e.Err.Error(), e.Seconds())
+}
+
+// Seconds returns the delay in whole seconds, rounded up and at least one.
+func (e *RetryAfterError) Seconds() int {
+	return max(int((e.After+time.Second-1)/time.Second), 1)
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// Lockout is a device locked out after too many validations without a match.
+type Lockout struct {
+	Tenant string
+	Device string
+	// Level counts the consecutive lockouts of the device; each one lasts
+	// twice as long as the previous one.
+	Level       int
+	LockedUntil time.Time
+}
+
+// LockoutState is what is tracked of a device to lock it out: its recent
+// failed validations and its lockouts.
+type LockoutState struct {
+	Tenant string
+	Device string
+	// Failures counts the consecutive failed validations since FirstFailure.
+	Failures     int
+	FirstFailure time.Time
+	// Level is the number of lockouts so far; it is reset by a success or
+	// after the device stayed quiet for the maximum lockout after its last
+	// lockout.
+	Level       int
+	LockedUntil time.Time
+	LastSeen    time.Time
+}
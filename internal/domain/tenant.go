@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// Tenant is a faculty or site whose embeddings, devices, webhooks and events
+// are isolated from those of the other tenants. ID is the organizational unit
+// (OU) of the client certificates of its devices.
+type Tenant struct {
+	ID        string
+	Name      string
+	CreatedAt time.Time
+}
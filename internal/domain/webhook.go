@@ -0,0 +1,77 @@
+package domain
+
+import "time"
+
+// Webhook event types.
+const (
+	WebhookAccessGranted     = "access.granted"
+	WebhookAccessDenied      = "access.denied"
+	WebhookEnrollmentCreated = "enrollment.created"
+	WebhookEnrollmentUpdated = "enrollment.updated"
+	WebhookEnrollmentDeleted = "enrollment.deleted"
+	// WebhookDeviceLockedOut is sent when a device is locked out after
+	// repeated validations without a match, e.g. someone unknown trying
+	// their luck.
+	WebhookDeviceLockedOut = "device.locked_out"
+	// WebhookDeviceOffline is sent when the door controller of a device did
+	// not acknowledge a command in time.
+	WebhookDeviceOffline = "device.offline"
+)
+
+// WebhookEventTypes lists every event type a subscription can receive.
+// Watchlist hits are not among them: there is no watchlist to detect them
+// yet.
+var WebhookEventTypes = []string{
+	WebhookAccessGranted,
+	WebhookAccessDenied,
+	WebhookEnrollmentCreated,
+	WebhookEnrollmentUpdated,
+	WebhookEnrollmentDeleted,
+	WebhookDeviceLockedOut,
+	WebhookDeviceOffline,
+}
+
+// Webhook delivery statuses.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// WebhookSubscription is a registered webhook endpoint.
+type WebhookSubscription struct {
+	ID         int64
+	URL        string
+	EventTypes []string
+	// Secret is the HMAC key used to sign the deliveries.
+	Secret    string
+	CreatedAt time.Time
+}
+
+// OutboxEvent is an event waiting in the transactional outbox.
+// Payload is the exact JSON body delivered to the subscribers.
+type OutboxEvent struct {
+	EventID string
+	Type    string
+	Payload []byte
+}
+
+// WebhookDelivery is the delivery of one event to one subscription.
+type WebhookDelivery struct {
+	ID             int64
+	SubscriptionID int64
+	EventID        string
+	EventType      string
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	// Set on claimed deliveries only.
+	URL     string
+	Secret  string
+	Payload []byte
+}
@@ -0,0 +1,303 @@
+package door
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/events"
+	"access-system-api/internal/health"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/logging"
+	"access-system-api/internal/metrics"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/sirupsen/logrus"
+)
+
+// Command results reported to metrics.
+const (
+	resultAcknowledged   = "acknowledged"
+	resultUnacknowledged = "unacknowledged"
+	resultFailed         = "failed"
+)
+
+// disconnectQuiesce is how long in-flight messages may take to complete on shutdown, in milliseconds.
+const disconnectQuiesce = 250
+
+// pendingCommand is a command waiting for its acknowledgement.
+type pendingCommand struct {
+	command     Command
+	accessPoint string
+	timer       *time.Timer
+}
+
+// Controller publishes door commands to an MQTT broker and consumes the
+// state acknowledgements of the door controllers. State changes are
+// published as events, so guards see them on the event stream.
+type Controller struct {
+	client    mqtt.Client
+	cfg       cfg.DoorCfg
+	publisher events.Publisher
+	metrics   *metrics.Metrics
+	log       *logrus.Logger
+
+	mu      sync.Mutex
+	pending map[string]*pendingCommand
+}
+
+// NewController creates a new Controller. It connects once Run is called.
+func NewController(doorCfg cfg.DoorCfg, publisher events.Publisher, m *metrics.Metrics, log *logrus.Logger) *Controller {
+	c := &Controller{
+		cfg:       doorCfg,
+		publisher: publisher,
+		metrics:   m,
+		log:       log,
+		pending:   make(map[string]*pendingCommand),
+	}
+
+	clientID := doorCfg.ClientID
+	if clientID == "" {
+		clientID = "access-system-" + newID()[:12]
+	}
+	options := mqtt.NewClientOptions().
+		AddBroker(doorCfg.BrokerURL).
+		SetClientID(clientID).
+		SetUsername(doorCfg.Username).
+		SetPassword(doorCfg.Password).
+		SetCleanSession(true).
+		SetOrderMatters(false).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetMaxReconnectInterval(30 * time.Second).
+		SetOnConnectHandler(c.onConnect).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			log.WithError(err).Warn("MQTT connection lost")
+		})
+	c.client = mqtt.NewClient(options)
+	return c
+}
+
+// Run connects to the broker, reconnecting as needed, until ctx is done.
+func (c *Controller) Run(ctx context.Context) error {
+	token := c.client.Connect()
+	select {
+	case <-token.Done():
+		if err := token.Error(); err != nil {
+			return fmt.Errorf("connect to MQTT broker: %w", err)
+		}
+	case <-ctx.Done():
+	}
+
+	<-ctx.Done()
+	c.client.Disconnect(disconnectQuiesce)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, pending := range c.pending {
+		pending.timer.Stop()
+		delete(c.pending, id)
+	}
+	return nil
+}
+
+// Unlock releases the door controlled by device for the unlock pulse.
+func (c *Controller) Unlock(ctx context.Context, device string) error {
+	return c.send(ctx, device, CommandUnlock, c.cfg.UnlockPulse)
+}
+
+// Alarm drives the alarm output of the access point controlled by device for the alarm pulse.
+func (c *Controller) Alarm(ctx context.Context, device string) error {
+	return c.send(ctx, device, CommandAlarm, c.cfg.AlarmPulse)
+}
+
+// HealthCheck returns a non-critical readiness check reporting the broker connection.
+func (c *Controller) HealthCheck() health.Check {
+	return health.Check{
+		Name:     "mqtt",
+		Critical: false,
+		Run: func(context.Context) (map[string]any, error) {
+			if !c.client.IsConnectionOpen() {
+				return nil, ErrNotConnected
+			}
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return map[string]any{"broker": c.cfg.BrokerURL, "pending_commands": len(c.pending)}, nil
+		},
+	}
+}
+
+// accessPoint returns the access point controlled by device.
+func (c *Controller) accessPoint(device string) (string, error) {
+	accessPoint, ok := c.cfg.AccessPoints[device]
+	if !ok {
+		accessPoint = device
+	}
+	if device == identity.UnknownDevice || accessPoint == "" || strings.ContainsAny(accessPoint, "/+#\x00") {
+		return "", fmt.Errorf("%w: %q", ErrUnknownAccessPoint, device)
+	}
+	return accessPoint, nil
+}
+
+func (c *Controller) topic(accessPoint, kind string) string {
+	return c.cfg.TopicPrefix + "/" + accessPoint + "/" + kind
+}
+
+// send publishes a command and waits until the broker accepted it. The
+// acknowledgement of the door controller is awaited in the background.
+func (c *Controller) send(ctx context.Context, device, command string, pulse time.Duration) error {
+	accessPoint, err := c.accessPoint(device)
+	if err != nil {
+		return err
+	}
+	if !c.client.IsConnectionOpen() {
+		c.metrics.ObserveDoorCommand(command, resultFailed)
+		return ErrNotConnected
+	}
+
+	now := time.Now().UTC()
+	cmd := Command{
+		ID:        newID(),
+		Command:   command,
+		PulseMs:   pulse.Milliseconds(),
+		Device:    device,
+		RequestID: logging.RequestIDFromContext(ctx),
+		IssuedAt:  now,
+		ExpiresAt: now.Add(c.cfg.AckTimeout),
+	}
+	payload, err := json.Marshal(cmd)
+	if err != nil {
+		return err
+	}
+
+	c.track(cmd, accessPoint, identity.TenantFromContext(ctx))
+	if err := c.publish(ctx, c.topic(accessPoint, "command"), payload); err != nil {
+		c.untrack(cmd.ID)
+		c.metrics.ObserveDoorCommand(command, resultFailed)
+		return fmt.Errorf("publish %s command for %s: %w", command, accessPoint, err)
+	}
+
+	c.log.WithFields(logrus.Fields{
+		"command_id":   cmd.ID,
+		"command":      command,
+		"access_point": accessPoint,
+		"device":       device,
+	}).Info("Door command sent")
+	return nil
+}
+
+// publish publishes payload and waits for the broker to accept it.
+func (c *Controller) publish(ctx context.Context, topic string, payload []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.PublishTimeout)
+	defer cancel()
+
+	token := c.client.Publish(topic, byte(c.cfg.QoS), false, payload)
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// track registers cmd as waiting for an acknowledgement until the ack timeout.
+// Unacknowledged commands are published as offline events of the device.
+func (c *Controller) track(cmd Command, accessPoint, tenant string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pending[cmd.ID] = &pendingCommand{
+		command:     cmd,
+		accessPoint: accessPoint,
+		timer: time.AfterFunc(c.cfg.AckTimeout, func() {
+			if c.untrack(cmd.ID) == nil {
+				return
+			}
+			c.metrics.ObserveDoorCommand(cmd.Command, resultUnacknowledged)
+			c.log.WithFields(logrus.Fields{
+				"command_id":   cmd.ID,
+				"command":      cmd.Command,
+				"access_point": accessPoint,
+			}).Warn("Door command not acknowledged")
+
+			c.publisher.Publish(context.Background(), events.Event{
+				ID:          newID(),
+				Type:        events.TypeDeviceOffline,
+				Time:        time.Now().UTC(),
+				Tenant:      tenant,
+				Device:      cmd.Device,
+				AccessPoint: accessPoint,
+			})
+		}),
+	}
+}
+
+// untrack removes and returns the pending command with the given ID, if any.
+func (c *Controller) untrack(id string) *pendingCommand {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pending, ok := c.pending[id]
+	if !ok {
+		return nil
+	}
+	pending.timer.Stop()
+	delete(c.pending, id)
+	return pending
+}
+
+// onConnect subscribes to the state topics. It runs on every (re)connect,
+// as subscriptions do not survive clean sessions.
+func (c *Controller) onConnect(client mqtt.Client) {
+	c.log.WithField("broker", c.cfg.BrokerURL).Info("MQTT connected")
+
+	token := client.Subscribe(c.topic("+", "state"), byte(c.cfg.QoS), c.onState)
+	go func() {
+		if token.WaitTimeout(c.cfg.PublishTimeout) && token.Error() != nil {
+			c.log.WithError(token.Error()).Error("Error subscribing to door state topics")
+		}
+	}()
+}
+
+// onState consumes a door state message.
+func (c *Controller) onState(_ mqtt.Client, message mqtt.Message) {
+	accessPoint := strings.TrimSuffix(strings.TrimPrefix(message.Topic(), c.cfg.TopicPrefix+"/"), "/state")
+	entry := c.log.WithFields(logrus.Fields{"access_point": accessPoint, "topic": message.Topic()})
+
+	var state State
+	if err := json.Unmarshal(message.Payload(), &state); err != nil || state.State == "" {
+		entry.WithError(err).Warn("Invalid door state message")
+		return
+	}
+	c.metrics.ObserveDoorState(accessPoint, state.State)
+
+	event := events.Event{
+		ID:          newID(),
+		Type:        events.TypeDoorState,
+		Time:        time.Now().UTC(),
+		AccessPoint: accessPoint,
+		State:       state.State,
+	}
+	if state.CommandID != "" {
+		if pending := c.untrack(state.CommandID); pending != nil {
+			c.metrics.ObserveDoorCommand(pending.command.Command, resultAcknowledged)
+			event.Device = pending.command.Device
+			entry = entry.WithField("command_id", state.CommandID)
+		}
+	}
+	entry.WithField("state", state.State).Info("Door state received")
+
+	c.publisher.Publish(context.Background(), event)
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,208 @@
+package door
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/events"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/metrics"
+
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startBroker starts an embedded MQTT broker and returns its URL.
+func startBroker(t *testing.T) (*mochi.Server, string) {
+	server := mochi.New(&mochi.Options{
+		InlineClient: true,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	require.NoError(t, server.AddHook(new(auth.AllowHook), nil))
+
+	listener := listeners.NewTCP(listeners.Config{ID: "tcp", Address: "127.0.0.1:0"})
+	require.NoError(t, server.AddListener(listener))
+	require.NoError(t, server.Serve())
+	t.Cleanup(func() { _ = server.Close() })
+
+	return server, "tcp://" + listener.Address()
+}
+
+// startController runs a controller connected to brokerURL until the test ends.
+func startController(t *testing.T, brokerURL string, bus *events.Bus) *Controller {
+	doorCfg := cfg.Default().Door
+	doorCfg.Enabled = true
+	doorCfg.BrokerURL = brokerURL
+	doorCfg.AccessPoints = map[string]string{"gate-01": "north-door"}
+	doorCfg.AckTimeout = 500 * time.Millisecond
+
+	controller := NewController(doorCfg, bus, metrics.New(), logrus.New())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- controller.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	require.Eventually(t, controller.client.IsConnectionOpen, 5*time.Second, 10*time.Millisecond)
+	return controller
+}
+
+// subscribe forwards the messages published to filter on the broker.
+func subscribe(t *testing.T, server *mochi.Server, filter string) <-chan packets.Packet {
+	received := make(chan packets.Packet, 10)
+	require.NoError(t, server.Subscribe(filter, 1, func(_ *mochi.Client, _ packets.Subscription, pk packets.Packet) {
+		received <- pk
+	}))
+	return received
+}
+
+func TestController_Unlock(t *testing.T) {
+	server, brokerURL := startBroker(t)
+	controller := startController(t, brokerURL, events.NewBus(1))
+	commands := subscribe(t, server, "access/doors/+/command")
+
+	require.NoError(t, controller.Unlock(context.Background(), "gate-01"))
+
+	select {
+	case pk := <-commands:
+		assert.Equal(t, "access/doors/north-door/command", pk.TopicName)
+
+		var cmd Command
+		require.NoError(t, json.Unmarshal(pk.Payload, &cmd))
+		assert.Equal(t, CommandUnlock, cmd.Command)
+		assert.Equal(t, int64(5000), cmd.PulseMs)
+		assert.Equal(t, "gate-01", cmd.Device)
+		assert.NotEmpty(t, cmd.ID)
+		assert.True(t, cmd.ExpiresAt.After(cmd.IssuedAt))
+	case <-time.After(5 * time.Second):
+		t.Fatal("command not published")
+	}
+}
+
+func TestController_Alarm_DefaultAccessPoint(t *testing.T) {
+	server, brokerURL := startBroker(t)
+	controller := startController(t, brokerURL, events.NewBus(1))
+	commands := subscribe(t, server, "access/doors/+/command")
+
+	require.NoError(t, controller.Alarm(context.Background(), "gate-02"))
+
+	select {
+	case pk := <-commands:
+		assert.Equal(t, "access/doors/gate-02/command", pk.TopicName)
+
+		var cmd Command
+		require.NoError(t, json.Unmarshal(pk.Payload, &cmd))
+		assert.Equal(t, CommandAlarm, cmd.Command)
+		assert.Equal(t, int64(30000), cmd.PulseMs)
+	case <-time.After(5 * time.Second):
+		t.Fatal("command not published")
+	}
+}
+
+func TestController_UnknownAccessPoint(t *testing.T) {
+	_, brokerURL := startBroker(t)
+	controller := startController(t, brokerURL, events.NewBus(1))
+
+	assert.ErrorIs(t, controller.Unlock(context.Background(), "unknown"), ErrUnknownAccessPoint)
+	assert.ErrorIs(t, controller.Unlock(context.Background(), "gate/#"), ErrUnknownAccessPoint)
+}
+
+func TestController_NotConnected(t *testing.T) {
+	doorCfg := cfg.Default().Door
+	controller := NewController(doorCfg, events.NewBus(1), metrics.New(), logrus.New())
+
+	assert.ErrorIs(t, controller.Unlock(context.Background(), "gate-01"), ErrNotConnected)
+
+	_, err := controller.HealthCheck().Run(context.Background())
+	assert.ErrorIs(t, err, ErrNotConnected)
+}
+
+func TestController_StateAcknowledgement(t *testing.T) {
+	server, brokerURL := startBroker(t)
+	bus := events.NewBus(10)
+	stream := bus.Subscribe(events.Filter{})
+	defer stream.Close()
+
+	controller := startController(t, brokerURL, bus)
+	commands := subscribe(t, server, "access/doors/+/command")
+
+	// Wait until the controller subscribed to the state topics.
+	require.Eventually(t, func() bool {
+		return len(server.Topics.Subscribers("access/doors/north-door/state").Subscriptions) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, controller.Unlock(context.Background(), "gate-01"))
+
+	var cmd Command
+	select {
+	case pk := <-commands:
+		require.NoError(t, json.Unmarshal(pk.Payload, &cmd))
+	case <-time.After(5 * time.Second):
+		t.Fatal("command not published")
+	}
+
+	payload, _ := json.Marshal(State{CommandID: cmd.ID, State: "unlocked"})
+	require.NoError(t, server.Publish("access/doors/north-door/state", payload, false, 1))
+
+	select {
+	case event := <-stream.Events():
+		assert.Equal(t, events.TypeDoorState, event.Type)
+		assert.Equal(t, "north-door", event.AccessPoint)
+		assert.Equal(t, "unlocked", event.State)
+		assert.Equal(t, "gate-01", event.Device)
+	case <-time.After(5 * time.Second):
+		t.Fatal("state event not published")
+	}
+
+	assert.Eventually(t, func() bool {
+		controller.mu.Lock()
+		defer controller.mu.Unlock()
+		return len(controller.pending) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestController_Unacknowledged(t *testing.T) {
+	_, brokerURL := startBroker(t)
+	bus := events.NewBus(10)
+	stream := bus.Subscribe(events.Filter{})
+	defer stream.Close()
+
+	controller := startController(t, brokerURL, bus)
+
+	ctx := identity.WithTenant(context.Background(), "acme")
+	require.NoError(t, controller.Unlock(ctx, "gate-01"))
+
+	controller.mu.Lock()
+	assert.Len(t, controller.pending, 1)
+	controller.mu.Unlock()
+
+	// The command expires after the ack timeout.
+	assert.Eventually(t, func() bool {
+		controller.mu.Lock()
+		defer controller.mu.Unlock()
+		return len(controller.pending) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+
+	select {
+	case event := <-stream.Events():
+		assert.Equal(t, events.TypeDeviceOffline, event.Type)
+		assert.Equal(t, "acme", event.Tenant)
+		assert.Equal(t, "gate-01", event.Device)
+		assert.Equal(t, "north-door", event.AccessPoint)
+	case <-time.After(5 * time.Second):
+		t.Fatal("offline event not published")
+	}
+}
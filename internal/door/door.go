@@ -0,0 +1,52 @@
+// Package door drives door controllers over MQTT. Commands such as unlock
+// and alarm are published to a topic per access point, and the controllers
+// acknowledge them by publishing the resulting door state.
+package door
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Commands sent to door controllers.
+const (
+	CommandUnlock = "unlock"
+	CommandAlarm  = "alarm"
+)
+
+// ErrUnknownAccessPoint is returned for devices that do not control an access point.
+var ErrUnknownAccessPoint = errors.New("device does not control an access point")
+
+// ErrNotConnected is returned when a command is sent while the broker is unreachable.
+// Commands are never queued, so a door is not released long after the decision.
+var ErrNotConnected = errors.New("not connected to the MQTT broker")
+
+// Command is the JSON payload published to "<prefix>/<access point>/command".
+type Command struct {
+	ID      string `json:"id"`
+	Command string `json:"command"`
+	// PulseMs is how long the output is driven, in milliseconds.
+	PulseMs   int64     `json:"pulse_ms"`
+	Device    string    `json:"device"`
+	RequestID string    `json:"request_id,omitempty"`
+	IssuedAt  time.Time `json:"issued_at"`
+	// ExpiresAt is when controllers must stop acting on the command, in case
+	// it is delivered late.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// State is the JSON payload door controllers publish to
+// "<prefix>/<access point>/state", e.g. {"command_id":"...","state":"unlocked"}.
+type State struct {
+	// CommandID is the ID of the acknowledged command, if the state change
+	// was caused by one.
+	CommandID string `json:"command_id,omitempty"`
+	State     string `json:"state"`
+}
+
+// Actuator sends commands to the door controlled by a device.
+type Actuator interface {
+	Unlock(ctx context.Context, device string) error
+	Alarm(ctx context.Context, device string) error
+}
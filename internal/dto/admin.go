@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type UpdateEmbeddingRequest struct {
+	ID     int64     `json:"id" encrypt:"id"`
+	Name   string    `json:"name" encrypt:"name"`
+	Vector []float32 `json:"vector" encrypt:"vector"`
+}
+
+// PatchEmbeddingRequest is a JSON merge patch of an embedding: absent fields
+// are nil, and fields set to null hold the JSON null.
+type PatchEmbeddingRequest struct {
+	Name        json.RawMessage `json:"name" encrypt:"name"`
+	Vector      json.RawMessage `json:"vector" encrypt:"vector"`
+	MinLiveness json.RawMessage `json:"min_liveness" encrypt:"min_liveness"`
+	MinQuality  json.RawMessage `json:"min_quality" encrypt:"min_quality"`
+	Metadata    json.RawMessage `json:"metadata" encrypt:"metadata"`
+}
+
+type EmbeddingResponse struct {
+	ID          int64             `json:"id" encrypt:"id"`
+	Name        string            `json:"name" encrypt:"name"`
+	Vector      []float32         `json:"vector" encrypt:"vector"`
+	MinLiveness float64           `json:"min_liveness" encrypt:"min_liveness"`
+	MinQuality  float64           `json:"min_quality" encrypt:"min_quality"`
+	Metadata    map[string]string `json:"metadata" encrypt:"metadata"`
+	Version     int64             `json:"version" encrypt:"version"`
+}
+
+type InvalidEmbeddingResponse struct {
+	ID      int64  `json:"id" encrypt:"id"`
+	Name    string `json:"name" encrypt:"name"`
+	Problem string `json:"problem" encrypt:"problem"`
+}
+
+type CaptureMinimumsRequest struct {
+	MinLiveness float64 `json:"min_liveness" encrypt:"min_liveness"`
+	MinQuality  float64 `json:"min_quality" encrypt:"min_quality"`
+}
+
+type EmbeddingSampleResponse struct {
+	ID              int64      `json:"id" encrypt:"id"`
+	Source          string     `json:"source" encrypt:"source"`
+	Status          string     `json:"status" encrypt:"status"`
+	Accuracy        float32    `json:"accuracy" encrypt:"accuracy"`
+	Liveness        *float64   `json:"liveness,omitempty" encrypt:"liveness"`
+	Device          string     `json:"device,omitempty" encrypt:"device"`
+	RequestID       string     `json:"request_id,omitempty" encrypt:"request_id"`
+	CreatedAt       time.Time  `json:"created_at" encrypt:"created_at"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty" encrypt:"status_changed_at"`
+}
+
+type EmbeddingAuditResponse struct {
+	ID         int64     `json:"id" encrypt:"id"`
+	Device     string    `json:"device" encrypt:"device"`
+	RequestID  string    `json:"request_id,omitempty" encrypt:"request_id"`
+	Fields     []string  `json:"fields" encrypt:"fields"`
+	OldVersion int64     `json:"old_version" encrypt:"old_version"`
+	NewVersion int64     `json:"new_version" encrypt:"new_version"`
+	CreatedAt  time.Time `json:"created_at" encrypt:"created_at"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+type RegisterDeviceRequest struct {
+	Name       string `json:"name" encrypt:"name"`
+	SigningKey string `json:"signing_key" encrypt:"signing_key"`
+}
+
+// DeviceResponse describes a registered device. The signing key is only
+// returned when the device is registered and when its key is rotated.
+type DeviceResponse struct {
+	ID           int64     `json:"id" encrypt:"id"`
+	Name         string    `json:"name" encrypt:"name"`
+	SigningKey   string    `json:"signing_key,omitempty" encrypt:"signing_key"`
+	CreatedAt    time.Time `json:"created_at" encrypt:"created_at"`
+	KeyRotatedAt time.Time `json:"key_rotated_at" encrypt:"key_rotated_at"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+import "time"
+
+type LockoutResponse struct {
+	Device      string    `json:"device" encrypt:"device"`
+	Level       int       `json:"level" encrypt:"level"`
+	LockedUntil time.Time `json:"locked_until" encrypt:"locked_until"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+import "time"
+
+type CreateTenantRequest struct {
+	ID   string `json:"id" encrypt:"id"`
+	Name string `json:"name" encrypt:"name"`
+}
+
+type TenantResponse struct {
+	ID        string    `json:"id" encrypt:"id"`
+	Name      string    `json:"name" encrypt:"name"`
+	CreatedAt time.Time `json:"created_at" encrypt:"created_at"`
+}
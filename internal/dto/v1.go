@@ -1,12 +1,21 @@
 package dto
 
+// Capture holds the optional scores a terminal computed for the face capture of a request.
+type Capture struct {
+	Liveness        *float64          `json:"liveness,omitempty" encrypt:"liveness"`
+	Quality         *float64          `json:"quality,omitempty" encrypt:"quality"`
+	CaptureMetadata map[string]string `json:"capture_metadata,omitempty" encrypt:"capture_metadata"`
+}
+
 type AddEmbeddingRequest struct {
 	Name   string    `json:"name" encrypt:"name"`
 	Vector []float32 `json:"vector" encrypt:"vector"`
+	Capture
 }
 
 type ValidateEmbeddingRequest struct {
 	Vector []float32 `json:"vector" encrypt:"vector"`
+	Capture
 }
 
 type ValidateEmbeddingResponse struct {
@@ -19,3 +28,31 @@ type ValidateEmbeddingResponse struct {
 type DeleteEmbeddingRequest struct {
 	ID int64 `json:"id" encrypt:"id"`
 }
+
+type ValidateBatchRequest struct {
+	Probes []BatchProbe `json:"probes" encrypt:"probes"`
+}
+
+type BatchProbe struct {
+	ID     string    `json:"id" encrypt:"id"`
+	Vector []float32 `json:"vector" encrypt:"vector"`
+	Capture
+}
+
+type ValidateBatchResponse struct {
+	Results []BatchResult `json:"results" encrypt:"results"`
+}
+
+// BatchResult is the outcome of one probe: its decision, and the match or
+// the error that rejected it.
+type BatchResult struct {
+	ID       string                     `json:"id" encrypt:"id"`
+	Decision string                     `json:"decision" encrypt:"decision"`
+	Match    *ValidateEmbeddingResponse `json:"match,omitempty" encrypt:"match"`
+	Error    *BatchError                `json:"error,omitempty" encrypt:"error"`
+}
+
+type BatchError struct {
+	Code    string `json:"code" encrypt:"code"`
+	Message string `json:"message" encrypt:"message"`
+}
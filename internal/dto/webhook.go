@@ -0,0 +1,33 @@
+package dto
+
+import "time"
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" encrypt:"url"`
+	EventTypes []string `json:"event_types" encrypt:"event_types"`
+	Secret     string   `json:"secret" encrypt:"secret"`
+}
+
+// WebhookResponse describes a subscription. The secret is only returned when
+// the subscription is created.
+type WebhookResponse struct {
+	ID         int64     `json:"id" encrypt:"id"`
+	URL        string    `json:"url" encrypt:"url"`
+	EventTypes []string  `json:"event_types" encrypt:"event_types"`
+	Secret     string    `json:"secret,omitempty" encrypt:"secret"`
+	CreatedAt  time.Time `json:"created_at" encrypt:"created_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             int64     `json:"id" encrypt:"id"`
+	SubscriptionID int64     `json:"subscription_id" encrypt:"subscription_id"`
+	EventID        string    `json:"event_id" encrypt:"event_id"`
+	EventType      string    `json:"event_type" encrypt:"event_type"`
+	Status         string    `json:"status" encrypt:"status"`
+	Attempts       int       `json:"attempts" encrypt:"attempts"`
+	NextAttemptAt  time.Time `json:"next_attempt_at" encrypt:"next_attempt_at"`
+	LastStatusCode int       `json:"last_status_code,omitempty" encrypt:"last_status_code"`
+	LastError      string    `json:"last_error,omitempty" encrypt:"last_error"`
+	CreatedAt      time.Time `json:"created_at" encrypt:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" encrypt:"updated_at"`
+}
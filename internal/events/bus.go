@@ -0,0 +1,100 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Bus delivers published events to the subscriptions of this instance.
+type Bus struct {
+	mu         sync.RWMutex
+	subs       map[*Subscription]struct{}
+	closed     bool
+	bufferSize int
+}
+
+// NewBus creates a Bus buffering up to bufferSize events per subscription.
+func NewBus(bufferSize int) *Bus {
+	return &Bus{subs: map[*Subscription]struct{}{}, bufferSize: bufferSize}
+}
+
+// Publish delivers event to every matching subscription. Subscriptions whose
+// buffer is full miss the event instead of blocking the publisher.
+func (b *Bus) Publish(_ context.Context, event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subs {
+		if !sub.filter.Match(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribe returns a subscription receiving the events matching filter.
+// The subscription of a closed bus is already closed.
+func (b *Bus) Subscribe(filter Filter) *Subscription {
+	sub := &Subscription{bus: b, filter: filter, events: make(chan Event, b.bufferSize)}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(sub.events)
+		return sub
+	}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+// Close closes every subscription, ending the streams reading them.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		close(sub.events)
+	}
+}
+
+func (b *Bus) unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.events)
+	}
+}
+
+// Subscription receives the events of a Bus matching its filter.
+type Subscription struct {
+	bus     *Bus
+	filter  Filter
+	events  chan Event
+	dropped atomic.Uint64
+}
+
+// Events returns the channel of received events. It is closed when the
+// subscription or the bus is closed.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Dropped returns the number of events missed because the buffer was full.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close stops the subscription.
+func (s *Subscription) Close() {
+	s.bus.unsubscribe(s)
+}
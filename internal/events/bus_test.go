@@ -0,0 +1,94 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus_Filter(t *testing.T) {
+	bus := NewBus(4)
+	all := bus.Subscribe(Filter{})
+	north := bus.Subscribe(Filter{Zones: []string{"north"}})
+	gate := bus.Subscribe(Filter{Devices: []string{"gate-02"}})
+
+	bus.Publish(context.Background(), Event{ID: "1", Device: "gate-01", Zone: "north"})
+	bus.Publish(context.Background(), Event{ID: "2", Device: "gate-02", Zone: "south"})
+	bus.Close()
+
+	assert.Equal(t, []string{"1", "2"}, ids(all))
+	assert.Equal(t, []string{"1"}, ids(north))
+	assert.Equal(t, []string{"2"}, ids(gate))
+}
+
+func TestBus_DropsForSlowSubscriber(t *testing.T) {
+	bus := NewBus(1)
+	sub := bus.Subscribe(Filter{})
+
+	bus.Publish(context.Background(), Event{ID: "1"})
+	bus.Publish(context.Background(), Event{ID: "2"})
+	bus.Close()
+
+	assert.Equal(t, []string{"1"}, ids(sub))
+	assert.Equal(t, uint64(1), sub.Dropped())
+}
+
+func TestBus_CloseSubscription(t *testing.T) {
+	bus := NewBus(1)
+	sub := bus.Subscribe(Filter{})
+	sub.Close()
+	sub.Close()
+
+	bus.Publish(context.Background(), Event{ID: "1"})
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+
+	bus.Close()
+	_, ok = <-bus.Subscribe(Filter{}).Events()
+	assert.False(t, ok)
+}
+
+func TestPostgresRelay_Receive(t *testing.T) {
+	bus := NewBus(4)
+	sub := bus.Subscribe(Filter{})
+	relay := NewPostgresRelay(bus, nil, nil, logrus.New())
+
+	remote, err := json.Marshal(Event{ID: "remote", Origin: "other-instance"})
+	require.NoError(t, err)
+	own, err := json.Marshal(Event{ID: "own", Origin: relay.origin})
+	require.NoError(t, err)
+
+	relay.receive(context.Background(), &pq.Notification{Extra: string(remote)})
+	relay.receive(context.Background(), &pq.Notification{Extra: string(own)})
+	relay.receive(context.Background(), &pq.Notification{Extra: "not json"})
+	relay.receive(context.Background(), nil)
+	bus.Close()
+
+	assert.Equal(t, []string{"remote"}, ids(sub))
+}
+
+func TestPostgresRelay_PublishesLocally(t *testing.T) {
+	bus := NewBus(4)
+	sub := bus.Subscribe(Filter{})
+	relay := NewPostgresRelay(bus, nil, nil, logrus.New())
+
+	relay.Publish(context.Background(), Event{ID: "1"})
+	bus.Close()
+
+	received := <-sub.Events()
+	assert.Equal(t, relay.origin, received.Origin)
+	assert.Equal(t, relay.origin, (<-relay.outgoing).Origin)
+}
+
+func ids(sub *Subscription) []string {
+	var result []string
+	for event := range sub.Events() {
+		result = append(result, event.ID)
+	}
+	return result
+}
@@ -0,0 +1,116 @@
+// Package events publishes access events to live subscribers, such as the
+// admin event stream, within this instance and across instances.
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"access-system-api/internal/capture"
+	"access-system-api/internal/identity"
+)
+
+// Type identifies what happened.
+type Type string
+
+// Event types.
+const (
+	TypeValidation       Type = "validation"
+	TypeEmbeddingCreated Type = "embedding.created"
+	TypeEmbeddingUpdated Type = "embedding.updated"
+	TypeEmbeddingDeleted Type = "embedding.deleted"
+	TypeDoorState        Type = "door.state"
+	TypeDeviceLockedOut  Type = "device.locked_out"
+	TypeDeviceOffline    Type = "device.offline"
+)
+
+// Validation decisions.
+const (
+	DecisionMatch   = "match"
+	DecisionNoMatch = "no_match"
+	DecisionError   = "error"
+	// DecisionRejected is a validation whose capture scores are below their minimums.
+	DecisionRejected = "rejected"
+)
+
+// Event is a single access event. Vectors are never part of an event.
+type Event struct {
+	ID          string    `json:"id"`
+	Type        Type      `json:"type"`
+	Time        time.Time `json:"time"`
+	Tenant      string    `json:"tenant,omitempty"`
+	Device      string    `json:"device"`
+	Zone        string    `json:"zone,omitempty"`
+	Decision    string    `json:"decision,omitempty"`
+	EmbeddingID int64     `json:"embedding_id,omitempty"`
+	Name        string    `json:"name,omitempty"`
+	Accuracy    float32   `json:"accuracy,omitempty"`
+	// Fields are the changed fields of embedding.updated events of partial updates.
+	Fields []string `json:"fields,omitempty"`
+	// Liveness, Quality and CaptureMetadata describe the face capture of the request, if sent.
+	Liveness        *float64          `json:"liveness,omitempty"`
+	Quality         *float64          `json:"quality,omitempty"`
+	CaptureMetadata map[string]string `json:"capture_metadata,omitempty"`
+	// AccessPoint and State are set for door state events.
+	AccessPoint string `json:"access_point,omitempty"`
+	State       string `json:"state,omitempty"`
+	// LockedUntil and LockoutLevel are set for lockout events.
+	LockedUntil  *time.Time `json:"locked_until,omitempty"`
+	LockoutLevel int        `json:"lockout_level,omitempty"`
+	// Origin identifies the instance that published the event.
+	Origin string `json:"origin,omitempty"`
+}
+
+// New returns an event of the given type caused by the device calling in ctx,
+// within its tenant, with the capture scores of its request.
+func New(ctx context.Context, typ Type) Event {
+	captured := capture.FromContext(ctx)
+	return Event{
+		ID:              newID(),
+		Type:            typ,
+		Time:            time.Now().UTC(),
+		Tenant:          identity.TenantFromContext(ctx),
+		Device:          identity.DeviceFromContext(ctx),
+		Liveness:        captured.Liveness,
+		Quality:         captured.Quality,
+		CaptureMetadata: captured.Metadata,
+	}
+}
+
+// Publisher publishes events. Publishing never blocks the caller on slow subscribers.
+type Publisher interface {
+	Publish(ctx context.Context, event Event)
+}
+
+// Filter selects events by tenant, device and zone. An empty Tenant or list
+// matches every value.
+type Filter struct {
+	Tenant  string
+	Devices []string
+	Zones   []string
+}
+
+// Match reports whether event passes the filter.
+func (f Filter) Match(event Event) bool {
+	return (f.Tenant == "" || f.Tenant == event.Tenant) && contains(f.Devices, event.Device) && contains(f.Zones, event.Zone)
+}
+
+func contains(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,111 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// recordBuffer bounds the events waiting to be written to the store.
+const recordBuffer = 1024
+
+// cleanupInterval is how often events past retention are removed.
+const cleanupInterval = time.Hour
+
+// Store keeps a log of events.
+type Store interface {
+	AddEvents(ctx context.Context, batch []Event) error
+	DeleteEventsBefore(ctx context.Context, t time.Time) (int, error)
+}
+
+// Recorder is a Publisher that publishes events to the next Publisher and
+// writes them to a Store, for the event log of the bolt storage backend.
+type Recorder struct {
+	next      Publisher
+	store     Store
+	retention time.Duration
+	pending   chan Event
+	log       *logrus.Logger
+	now       func() time.Time
+}
+
+// NewRecorder creates a Recorder keeping events in store for retention, or
+// forever if retention is 0. Run must be running for events to be written.
+func NewRecorder(next Publisher, store Store, retention time.Duration, log *logrus.Logger) *Recorder {
+	return &Recorder{
+		next:      next,
+		store:     store,
+		retention: retention,
+		pending:   make(chan Event, recordBuffer),
+		log:       log,
+		now:       time.Now,
+	}
+}
+
+// Publish publishes event to next and queues it for the store.
+func (r *Recorder) Publish(ctx context.Context, event Event) {
+	r.next.Publish(ctx, event)
+
+	select {
+	case r.pending <- event:
+	default:
+		r.log.WithField("event_id", event.ID).Warn("Event recorder is behind, event not recorded")
+	}
+}
+
+// Run writes queued events to the store, all those queued at once in a
+// single write, and removes the events past retention until ctx is done.
+// Events still queued when ctx is done are written before Run returns.
+func (r *Recorder) Run(ctx context.Context) error {
+	cleanup := time.NewTicker(cleanupInterval)
+	defer cleanup.Stop()
+
+	r.cleanup(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			r.write(context.WithoutCancel(ctx), r.drain(nil))
+			return nil
+		case event := <-r.pending:
+			r.write(ctx, r.drain([]Event{event}))
+		case <-cleanup.C:
+			r.cleanup(ctx)
+		}
+	}
+}
+
+// drain appends the queued events to batch without waiting for more.
+func (r *Recorder) drain(batch []Event) []Event {
+	for {
+		select {
+		case event := <-r.pending:
+			batch = append(batch, event)
+		default:
+			return batch
+		}
+	}
+}
+
+func (r *Recorder) write(ctx context.Context, batch []Event) {
+	if len(batch) == 0 {
+		return
+	}
+	if err := r.store.AddEvents(ctx, batch); err != nil {
+		r.log.WithError(err).WithField("events", len(batch)).Error("Error recording events")
+	}
+}
+
+func (r *Recorder) cleanup(ctx context.Context) {
+	if r.retention == 0 {
+		return
+	}
+	deleted, err := r.store.DeleteEventsBefore(ctx, r.now().Add(-r.retention))
+	if err != nil {
+		r.log.WithError(err).Error("Error removing old events")
+		return
+	}
+	if deleted > 0 {
+		r.log.WithField("events", deleted).Info("Old events removed")
+	}
+}
@@ -0,0 +1,88 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is a Store recording its calls.
+type memoryStore struct {
+	mu      sync.Mutex
+	batches [][]Event
+	before  []time.Time
+}
+
+func (s *memoryStore) AddEvents(_ context.Context, batch []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, batch)
+	return nil
+}
+
+func (s *memoryStore) DeleteEventsBefore(_ context.Context, t time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.before = append(s.before, t)
+	return 0, nil
+}
+
+func (s *memoryStore) recorded() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []string
+	for _, batch := range s.batches {
+		for _, event := range batch {
+			ids = append(ids, event.ID)
+		}
+	}
+	return ids
+}
+
+func TestRecorder(t *testing.T) {
+	bus := NewBus(4)
+	sub := bus.Subscribe(Filter{})
+	store := &memoryStore{}
+	recorder := NewRecorder(bus, store, time.Hour, logrus.New())
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	recorder.now = func() time.Time { return now }
+
+	// Events published before Run are queued.
+	recorder.Publish(context.Background(), Event{ID: "1"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- recorder.Run(ctx) }()
+
+	recorder.Publish(context.Background(), Event{ID: "2"})
+	assert.Eventually(t, func() bool { return len(store.recorded()) == 2 }, time.Second, time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	bus.Close()
+
+	assert.Equal(t, []string{"1", "2"}, store.recorded())
+	assert.Equal(t, []string{"1", "2"}, ids(sub))
+	assert.Equal(t, []time.Time{now.Add(-time.Hour)}, store.before)
+}
+
+func TestRecorder_WritesQueuedEventsOnStop(t *testing.T) {
+	store := &memoryStore{}
+	recorder := NewRecorder(NewBus(1), store, 0, logrus.New())
+	recorder.Publish(context.Background(), Event{ID: "1"})
+	recorder.Publish(context.Background(), Event{ID: "2"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, recorder.Run(ctx))
+
+	// Without retention, no event is removed.
+	assert.Empty(t, store.before)
+	assert.Equal(t, []string{"1", "2"}, store.recorded())
+}
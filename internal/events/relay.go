@@ -0,0 +1,112 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// notifyChannel is the Postgres channel events are relayed on.
+const notifyChannel = "access_events"
+
+// outgoingBuffer bounds the events waiting to be relayed to other instances.
+const outgoingBuffer = 256
+
+// PostgresRelay publishes events to the local Bus and, through Postgres
+// LISTEN/NOTIFY, to the buses of every other instance sharing the database.
+type PostgresRelay struct {
+	bus      *Bus
+	db       *sql.DB
+	listener *pq.Listener
+	origin   string
+	outgoing chan Event
+	log      *logrus.Logger
+}
+
+// NewPostgresRelay creates a relay between bus and the other instances.
+// Run must be running for events to leave or reach this instance.
+func NewPostgresRelay(bus *Bus, db *sql.DB, listener *pq.Listener, log *logrus.Logger) *PostgresRelay {
+	return &PostgresRelay{
+		bus:      bus,
+		db:       db,
+		listener: listener,
+		origin:   newID(),
+		outgoing: make(chan Event, outgoingBuffer),
+		log:      log,
+	}
+}
+
+// Publish delivers event to the local subscribers and queues it for the other instances.
+func (r *PostgresRelay) Publish(ctx context.Context, event Event) {
+	event.Origin = r.origin
+	r.bus.Publish(ctx, event)
+
+	select {
+	case r.outgoing <- event:
+	default:
+		r.log.WithField("event_id", event.ID).Warn("Event relay is behind, event not sent to other instances")
+	}
+}
+
+// Run sends queued events to and receives events from the other instances
+// until ctx is done. The listener reconnects on its own after connection
+// failures; events published meanwhile by other instances are lost.
+func (r *PostgresRelay) Run(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, func() { _ = r.listener.Close() })
+	defer stop()
+
+	if err := r.listener.Listen(notifyChannel); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("listen on %s: %w", notifyChannel, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-r.outgoing:
+			r.send(ctx, event)
+		case notification, ok := <-r.listener.Notify:
+			if !ok {
+				return nil
+			}
+			r.receive(ctx, notification)
+		}
+	}
+}
+
+func (r *PostgresRelay) send(ctx context.Context, event Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		r.log.WithError(err).Error("Error encoding event")
+		return
+	}
+	if _, err := r.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, notifyChannel, string(payload)); err != nil {
+		r.log.WithError(err).Warn("Error sending event to other instances")
+	}
+}
+
+// receive publishes an event of another instance to the local bus. A nil
+// notification signals a reconnect of the listener.
+func (r *PostgresRelay) receive(ctx context.Context, notification *pq.Notification) {
+	if notification == nil {
+		r.log.Info("Event listener reconnected")
+		return
+	}
+
+	var event Event
+	if err := json.Unmarshal([]byte(notification.Extra), &event); err != nil {
+		r.log.WithError(err).Warn("Error decoding event from other instance")
+		return
+	}
+	if event.Origin == r.origin {
+		return
+	}
+	r.bus.Publish(ctx, event)
+}
@@ -0,0 +1,154 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	accessv1 "access-system-api/api/access/v1"
+	"access-system-api/internal/capture"
+	"access-system-api/internal/domain"
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/logging"
+	"access-system-api/internal/service"
+
+	"github.com/sirupsen/logrus"
+)
+
+// accessServer implements the AccessService gRPC API on top of EmbeddingService.
+type accessServer struct {
+	accessv1.UnimplementedAccessServiceServer
+	embeddingService service.EmbeddingService
+	timeout          time.Duration
+	log              *logrus.Logger
+}
+
+// NewAccessServer creates the AccessService implementation. Every call, and
+// every frame of ValidateStream, is bounded by timeout.
+func NewAccessServer(embeddingService service.EmbeddingService, timeout time.Duration, log *logrus.Logger) accessv1.AccessServiceServer {
+	return &accessServer{embeddingService: embeddingService, timeout: timeout, log: log}
+}
+
+func (s *accessServer) logger(ctx context.Context) *logrus.Entry {
+	return logging.FromContext(ctx, s.log)
+}
+
+// Enroll stores a new face embedding.
+func (s *accessServer) Enroll(ctx context.Context, req *accessv1.EnrollRequest) (*accessv1.EnrollResponse, error) {
+	if req.GetName() == "" {
+		return nil, toStatus(fmt.Errorf("%w: name is required", domain.ErrInvalidInput))
+	}
+
+	ctx, err := withCapture(ctx, req.GetCapture())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if err := s.embeddingService.AddEmbedding(ctx, req.GetName(), req.GetVector()); err != nil {
+		s.logger(ctx).WithError(err).Error("Error adding embedding")
+		return nil, toStatus(err)
+	}
+	return &accessv1.EnrollResponse{}, nil
+}
+
+// Validate looks up the most similar enrolled embedding.
+func (s *accessServer) Validate(ctx context.Context, req *accessv1.ValidateRequest) (*accessv1.ValidateResponse, error) {
+	resp, err := s.validate(ctx, req)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return resp, nil
+}
+
+// ValidateStream answers every received frame in order until the client
+// closes its side of the stream. Frames rejected because of the request are
+// reported in-band; server errors end the stream.
+func (s *accessServer) ValidateStream(stream accessv1.AccessService_ValidateStreamServer) error {
+	ctx := stream.Context()
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resp, err := s.validate(ctx, req)
+		if err != nil {
+			if !isClientError(err) {
+				return toStatus(err)
+			}
+			_, detail := httperr.Resolve(err)
+			resp = &accessv1.ValidateResponse{
+				Sequence: req.GetSequence(),
+				Decision: accessv1.Decision_DECISION_REJECTED,
+				Error:    &accessv1.Error{Code: detail.Code, Message: detail.Message},
+			}
+		}
+
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// validate matches a single frame. A missing match is a decision, not an error.
+func (s *accessServer) validate(ctx context.Context, req *accessv1.ValidateRequest) (*accessv1.ValidateResponse, error) {
+	ctx, err := withCapture(ctx, req.GetCapture())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	embedding, err := s.embeddingService.ValidateEmbedding(ctx, req.GetVector())
+	switch {
+	case errors.Is(err, domain.ErrNoMatch):
+		s.logger(ctx).Info("No relevant matches found")
+		return &accessv1.ValidateResponse{
+			Sequence: req.GetSequence(),
+			Decision: accessv1.Decision_DECISION_NO_MATCH,
+		}, nil
+	case errors.Is(err, domain.ErrCaptureRejected):
+		s.logger(ctx).WithError(err).Info("Capture rejected")
+		return nil, err
+	case err != nil:
+		s.logger(ctx).WithError(err).Error("Error validating embedding")
+		return nil, err
+	}
+
+	s.logger(ctx).WithFields(logrus.Fields{
+		"embedding_id": embedding.ID,
+		"accuracy":     embedding.Accuracy,
+	}).Info("Relevant match found")
+
+	return &accessv1.ValidateResponse{
+		Sequence: req.GetSequence(),
+		Decision: accessv1.Decision_DECISION_MATCH,
+		Match: &accessv1.Match{
+			Id:       embedding.ID,
+			Name:     embedding.Name,
+			Accuracy: embedding.Accuracy,
+		},
+	}, nil
+}
+
+// withCapture validates the capture scores of a request and returns a copy of
+// ctx carrying them.
+func withCapture(ctx context.Context, req *accessv1.Capture) (context.Context, error) {
+	if req == nil {
+		return ctx, nil
+	}
+	captured := domain.Capture{Liveness: req.Liveness, Quality: req.Quality, Metadata: req.GetMetadata()}
+	if err := capture.Validate(captured); err != nil {
+		return ctx, err
+	}
+	return capture.WithCapture(ctx, captured), nil
+}
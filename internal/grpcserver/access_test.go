@@ -0,0 +1,385 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	accessv1 "access-system-api/api/access/v1"
+	"access-system-api/internal/capture"
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/domain"
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/metrics"
+	mocks "access-system-api/internal/mocks/service"
+	"access-system-api/internal/signing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// registeredTenants is a service.TenantChecker accepting the listed tenants.
+type registeredTenants []string
+
+func (r registeredTenants) CheckTenant(_ context.Context, tenant string) error {
+	if !slices.Contains(r, tenant) {
+		return fmt.Errorf("%w: tenant %s is not registered", domain.ErrForbidden, tenant)
+	}
+	return nil
+}
+
+// signingKeys is a signing.Keys backed by a map.
+type signingKeys map[string]string
+
+func (k signingKeys) SigningKey(_ context.Context, device string) (string, error) {
+	key, ok := k[device]
+	if !ok {
+		return "", fmt.Errorf("%w: device %s", domain.ErrNotFound, device)
+	}
+	return key, nil
+}
+
+// nonceStore is a signing.NonceStore backed by a map, ignoring expiry.
+type nonceStore map[string]bool
+
+func (s nonceStore) UseNonce(_ context.Context, device, nonce string, _ time.Duration) (bool, error) {
+	key := device + "/" + nonce
+	if s[key] {
+		return false, nil
+	}
+	s[key] = true
+	return true, nil
+}
+
+func (s nonceStore) DeleteExpiredNonces(context.Context) error {
+	return nil
+}
+
+func setupClient(t *testing.T) (accessv1.AccessServiceClient, *mocks.MockEmbeddingService) {
+	return setupClientWithConfig(t, cfg.Default(), nil)
+}
+
+func setupClientWithConfig(t *testing.T, config *cfg.Config, signatures *signing.Verifier) (accessv1.AccessServiceClient, *mocks.MockEmbeddingService) {
+	ctrl := gomock.NewController(t)
+	service := mocks.NewMockEmbeddingService(ctrl)
+	log := logrus.New()
+
+	tenants := registeredTenants{identity.DefaultTenant, "engineering"}
+	server := NewServer(config, NewAccessServer(service, time.Second, log), tenants, signatures, metrics.New(), log)
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return accessv1.NewAccessServiceClient(conn), service
+}
+
+func deviceContext(device string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "x-client-dn", "CN="+device+",O=University")
+}
+
+func errorReason(t *testing.T, err error) string {
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func TestEnroll_Success(t *testing.T) {
+	client, service := setupClient(t)
+	vector := []float32{0.1, 0.2}
+
+	service.EXPECT().AddEmbedding(gomock.Any(), "test", vector).
+		DoAndReturn(func(ctx context.Context, _ string, _ []float32) error {
+			assert.Equal(t, "gate-01", identity.DeviceFromContext(ctx))
+			return nil
+		})
+
+	var header metadata.MD
+	_, err := client.Enroll(deviceContext("gate-01"), &accessv1.EnrollRequest{Name: "test", Vector: vector}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Len(t, header.Get("x-request-id"), 1)
+}
+
+func TestEnroll_Tenant(t *testing.T) {
+	client, service := setupClient(t)
+	vector := []float32{0.1, 0.2}
+
+	service.EXPECT().AddEmbedding(gomock.Any(), "test", vector).
+		DoAndReturn(func(ctx context.Context, _ string, _ []float32) error {
+			assert.Equal(t, "engineering", identity.TenantFromContext(ctx))
+			return nil
+		})
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-client-dn", "CN=gate-01,OU=engineering,O=University")
+	_, err := client.Enroll(ctx, &accessv1.EnrollRequest{Name: "test", Vector: vector})
+	require.NoError(t, err)
+
+	// Calls of unregistered tenants never reach the service.
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "x-client-dn", "CN=gate-01,OU=law,O=University")
+	_, err = client.Enroll(ctx, &accessv1.EnrollRequest{Name: "test", Vector: vector})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, httperr.CodeForbidden, errorReason(t, err))
+}
+
+func TestEnroll_MissingName(t *testing.T) {
+	client, _ := setupClient(t)
+
+	_, err := client.Enroll(context.Background(), &accessv1.EnrollRequest{Vector: []float32{0.1}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, httperr.CodeInvalidInput, errorReason(t, err))
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   *domain.Embedding
+		err      error
+		decision accessv1.Decision
+		code     codes.Code
+		reason   string
+	}{
+		{"match", &domain.Embedding{ID: 1, Name: "test", Accuracy: 0.9}, nil, accessv1.Decision_DECISION_MATCH, codes.OK, ""},
+		{"no match", nil, domain.ErrNoMatch, accessv1.Decision_DECISION_NO_MATCH, codes.OK, ""},
+		{"invalid dimension", nil, fmt.Errorf("%w: vector size must be 512, got 1", domain.ErrInvalidDimension), 0, codes.InvalidArgument, httperr.CodeInvalidDimension},
+		{"internal error", nil, errors.New("connection reset"), 0, codes.Internal, httperr.CodeInternal},
+		{"rate limited", nil, domain.RetryAfter(domain.ErrRateLimited, time.Second), 0, codes.ResourceExhausted, httperr.CodeRateLimited},
+		{"capture rejected", nil, fmt.Errorf("%w: liveness 0.4 is below the minimum 0.8", domain.ErrCaptureRejected), 0, codes.FailedPrecondition, httperr.CodeCaptureRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, service := setupClient(t)
+			service.EXPECT().ValidateEmbedding(gomock.Any(), []float32{0.1}).Return(tt.result, tt.err)
+
+			resp, err := client.Validate(context.Background(), &accessv1.ValidateRequest{Vector: []float32{0.1}, Sequence: 7})
+			assert.Equal(t, tt.code, status.Code(err))
+			if tt.code != codes.OK {
+				assert.Equal(t, tt.reason, errorReason(t, err))
+				assert.NotContains(t, status.Convert(err).Message(), "connection reset")
+				return
+			}
+
+			assert.Equal(t, uint64(7), resp.GetSequence())
+			assert.Equal(t, tt.decision, resp.GetDecision())
+			if tt.result != nil {
+				assert.Equal(t, tt.result.ID, resp.GetMatch().GetId())
+				assert.Equal(t, tt.result.Name, resp.GetMatch().GetName())
+				assert.Equal(t, tt.result.Accuracy, resp.GetMatch().GetAccuracy())
+			}
+		})
+	}
+}
+
+func TestValidate_Capture(t *testing.T) {
+	client, service := setupClient(t)
+	liveness := 0.93
+
+	service.EXPECT().ValidateEmbedding(gomock.Any(), []float32{0.1}).
+		DoAndReturn(func(ctx context.Context, _ []float32) (*domain.Embedding, error) {
+			captured := capture.FromContext(ctx)
+			assert.Equal(t, &liveness, captured.Liveness)
+			assert.Nil(t, captured.Quality)
+			assert.Equal(t, map[string]string{"camera": "front"}, captured.Metadata)
+			return nil, domain.ErrNoMatch
+		})
+
+	_, err := client.Validate(context.Background(), &accessv1.ValidateRequest{
+		Vector:  []float32{0.1},
+		Capture: &accessv1.Capture{Liveness: &liveness, Metadata: map[string]string{"camera": "front"}},
+	})
+	require.NoError(t, err)
+
+	invalid := 1.5
+	_, err = client.Validate(context.Background(), &accessv1.ValidateRequest{
+		Vector:  []float32{0.1},
+		Capture: &accessv1.Capture{Quality: &invalid},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestValidate_RetryInfo(t *testing.T) {
+	client, service := setupClient(t)
+	service.EXPECT().ValidateEmbedding(gomock.Any(), []float32{0.1}).
+		Return(nil, domain.RetryAfter(fmt.Errorf("%w: gate-01", domain.ErrLockedOut), time.Minute))
+
+	_, err := client.Validate(context.Background(), &accessv1.ValidateRequest{Vector: []float32{0.1}})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, httperr.CodeLockedOut, errorReason(t, err))
+
+	var delay time.Duration
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			delay = info.GetRetryDelay().AsDuration()
+		}
+	}
+	assert.Equal(t, time.Minute, delay)
+}
+
+func TestValidateStream(t *testing.T) {
+	client, service := setupClient(t)
+
+	gomock.InOrder(
+		service.EXPECT().ValidateEmbedding(gomock.Any(), []float32{1}).Return(&domain.Embedding{ID: 1, Name: "test", Accuracy: 0.9}, nil),
+		service.EXPECT().ValidateEmbedding(gomock.Any(), []float32{2}).Return(nil, fmt.Errorf("%w: vector size must be 512, got 1", domain.ErrInvalidDimension)),
+		service.EXPECT().ValidateEmbedding(gomock.Any(), []float32{3}).Return(nil, domain.ErrNoMatch),
+	)
+
+	stream, err := client.ValidateStream(deviceContext("gate-01"))
+	require.NoError(t, err)
+	for i := uint64(1); i <= 3; i++ {
+		require.NoError(t, stream.Send(&accessv1.ValidateRequest{Vector: []float32{float32(i)}, Sequence: i}))
+	}
+	require.NoError(t, stream.CloseSend())
+
+	var responses []*accessv1.ValidateResponse
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		responses = append(responses, resp)
+	}
+
+	require.Len(t, responses, 3)
+	assert.Equal(t, uint64(1), responses[0].GetSequence())
+	assert.Equal(t, accessv1.Decision_DECISION_MATCH, responses[0].GetDecision())
+	assert.Equal(t, uint64(2), responses[1].GetSequence())
+	assert.Equal(t, accessv1.Decision_DECISION_REJECTED, responses[1].GetDecision())
+	assert.Equal(t, httperr.CodeInvalidDimension, responses[1].GetError().GetCode())
+	assert.Equal(t, uint64(3), responses[2].GetSequence())
+	assert.Equal(t, accessv1.Decision_DECISION_NO_MATCH, responses[2].GetDecision())
+}
+
+func TestValidateStream_ServerErrorEndsStream(t *testing.T) {
+	client, service := setupClient(t)
+	service.EXPECT().ValidateEmbedding(gomock.Any(), gomock.Any()).Return(nil, errors.New("connection reset"))
+
+	stream, err := client.ValidateStream(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&accessv1.ValidateRequest{Vector: []float32{1}}))
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestValidate_Signature(t *testing.T) {
+	const key = "0123456789abcdef0123456789abcdef"
+	const method = "/access.v1.AccessService/Validate"
+	config := cfg.Default()
+	config.Auth.RequestSigning = cfg.RequestSigningRequired
+	signatures := signing.NewVerifier(signingKeys{"gate-01": key}, signing.NewNonces(nonceStore{}, 30*time.Second, logrus.New()), 30*time.Second)
+	client, service := setupClientWithConfig(t, config, signatures)
+
+	req := &accessv1.ValidateRequest{Vector: []float32{0.1}, Sequence: 7}
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	require.NoError(t, err)
+	signed := func(key, nonce string, body []byte) context.Context {
+		now := time.Now()
+		return metadata.AppendToOutgoingContext(deviceContext("gate-01"),
+			"x-signature-timestamp", strconv.FormatInt(now.Unix(), 10),
+			"x-signature-nonce", nonce,
+			"x-signature", signing.Sign(key, "POST", method, now, nonce, body),
+		)
+	}
+
+	service.EXPECT().ValidateEmbedding(gomock.Any(), []float32{0.1}).Return(nil, domain.ErrNoMatch)
+	_, err = client.Validate(signed(key, "3b2f6c1d8e9a4f70", body), req)
+	assert.NoError(t, err)
+
+	// Replayed, unsigned, tampered and wrongly signed calls never reach the service.
+	_, err = client.Validate(signed(key, "3b2f6c1d8e9a4f70", body), req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, httperr.CodeReplayed, errorReason(t, err))
+
+	_, err = client.Validate(deviceContext("gate-01"), req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.Validate(signed(key, "9c4e2b7a1f3d5e60", body), &accessv1.ValidateRequest{Vector: []float32{0.2}, Sequence: 7})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.Validate(signed(strings.Repeat("x", 32), "7d1a3c5e9b2f4a68", body), req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, httperr.CodeUnauthorized, errorReason(t, err))
+}
+
+func TestValidateStream_Signature(t *testing.T) {
+	const key = "0123456789abcdef0123456789abcdef"
+	signatures := signing.NewVerifier(signingKeys{"gate-01": key}, signing.NewNonces(nonceStore{}, 30*time.Second, logrus.New()), 30*time.Second)
+	now := time.Now()
+	signed := metadata.AppendToOutgoingContext(deviceContext("gate-01"),
+		"x-signature-timestamp", strconv.FormatInt(now.Unix(), 10),
+		"x-signature-nonce", "3b2f6c1d8e9a4f70",
+		"x-signature", signing.Sign(key, "POST", "/access.v1.AccessService/ValidateStream", now, "3b2f6c1d8e9a4f70", nil),
+	)
+
+	// A signature would only cover the opening of the stream, not the frames
+	// sent on it, so streams are rejected when signatures are required, and
+	// signed streams are rejected whenever signatures are verified.
+	tests := []struct {
+		name    string
+		signing string
+		ctx     context.Context
+	}{
+		{"unsigned, required", cfg.RequestSigningRequired, deviceContext("gate-01")},
+		{"signed, required", cfg.RequestSigningRequired, signed},
+		{"signed, optional", cfg.RequestSigningOptional, signed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := cfg.Default()
+			config.Auth.RequestSigning = tt.signing
+			client, _ := setupClientWithConfig(t, config, signatures)
+
+			stream, err := client.ValidateStream(tt.ctx)
+			require.NoError(t, err)
+			_, err = stream.Recv()
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		})
+	}
+
+	// Unsigned streams are still accepted while signatures are optional.
+	config := cfg.Default()
+	config.Auth.RequestSigning = cfg.RequestSigningOptional
+	client, service := setupClientWithConfig(t, config, signatures)
+	service.EXPECT().ValidateEmbedding(gomock.Any(), []float32{1}).Return(nil, domain.ErrNoMatch)
+
+	stream, err := client.ValidateStream(deviceContext("gate-01"))
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&accessv1.ValidateRequest{Vector: []float32{1}, Sequence: 1}))
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, accessv1.Decision_DECISION_NO_MATCH, resp.GetDecision())
+	require.NoError(t, stream.CloseSend())
+}
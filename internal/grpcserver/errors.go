@@ -0,0 +1,77 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/httperr"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// errorDomain identifies this service in the ErrorInfo detail of a status.
+const errorDomain = "access-system"
+
+// mapping associates a domain error with its gRPC status code.
+type mapping struct {
+	err  error
+	code codes.Code
+}
+
+var mappings = []mapping{
+	{domain.ErrInvalidInput, codes.InvalidArgument},
+	{domain.ErrInvalidDimension, codes.InvalidArgument},
+	{domain.ErrInvalidVector, codes.InvalidArgument},
+	{domain.ErrUnauthorized, codes.Unauthenticated},
+	{domain.ErrReplayed, codes.Unauthenticated},
+	{domain.ErrForbidden, codes.PermissionDenied},
+	{domain.ErrNoMatch, codes.NotFound},
+	{domain.ErrNotFound, codes.NotFound},
+	{domain.ErrConflict, codes.AlreadyExists},
+	{domain.ErrVersionConflict, codes.Aborted},
+	{domain.ErrCaptureRejected, codes.FailedPrecondition},
+	{domain.ErrRateLimited, codes.ResourceExhausted},
+	{domain.ErrLockedOut, codes.ResourceExhausted},
+	{context.DeadlineExceeded, codes.DeadlineExceeded},
+	{context.Canceled, codes.Canceled},
+}
+
+// toStatus converts err into a gRPC status error. The stable error code of
+// the HTTP API is attached as the reason of an ErrorInfo detail, the delay of
+// retryable errors as a RetryInfo detail, and messages of unknown errors are
+// not returned to the client.
+func toStatus(err error) error {
+	_, detail := httperr.Resolve(err)
+
+	code := codes.Internal
+	for _, m := range mappings {
+		if errors.Is(err, m.err) {
+			code = m.code
+			break
+		}
+	}
+
+	st := status.New(code, detail.Message)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: detail.Code, Domain: errorDomain}}
+	var retry *domain.RetryAfterError
+	if errors.As(err, &retry) {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(retry.After)})
+	}
+	if withDetails, detailsErr := st.WithDetails(details...); detailsErr == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// isClientError reports whether err was caused by the request rather than
+// by the server, as decided by the HTTP error mapping.
+func isClientError(err error) bool {
+	status, _ := httperr.Resolve(err)
+	return status < http.StatusInternalServerError
+}
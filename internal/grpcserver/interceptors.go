@@ -0,0 +1,229 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/logging"
+	"access-system-api/internal/metrics"
+	"access-system-api/internal/service"
+	"access-system-api/internal/signing"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// requestIDKey is the metadata key used to propagate the request ID, the gRPC
+// counterpart of the X-Request-ID header.
+var requestIDKey = strings.ToLower(logging.RequestIDHeader)
+
+// Metadata keys carrying the signature of a call, the gRPC counterparts of
+// the signature headers.
+var (
+	timestampKey = strings.ToLower(signing.TimestampHeader)
+	nonceKey     = strings.ToLower(signing.NonceHeader)
+	signatureKey = strings.ToLower(signing.SignatureHeader)
+)
+
+// interceptors give gRPC calls the same request ID, device identity, tenant
+// check, request signature verification, request-scoped logging, metrics and
+// panic recovery as the HTTP middleware.
+type interceptors struct {
+	subjectKey string
+	// tenants rejects calls of unregistered tenants, if set.
+	tenants service.TenantChecker
+	// signatures verifies signed calls, if set; unsigned calls are rejected
+	// if signaturesRequired is set.
+	signatures         *signing.Verifier
+	signaturesRequired bool
+	metrics            *metrics.Metrics
+	log                *logrus.Logger
+}
+
+// unary intercepts unary calls.
+func (i *interceptors) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	start := time.Now()
+	ctx, entry := i.begin(ctx, info.FullMethod)
+	defer func() { i.finish(ctx, entry, info.FullMethod, start, err) }()
+	defer i.recover(entry, &err)
+
+	if err := i.checkTenant(ctx); err != nil {
+		return nil, err
+	}
+	if err := i.checkSignature(ctx, info.FullMethod, req); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// stream intercepts streaming calls. The stream is logged and observed once, when it ends.
+func (i *interceptors) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	start := time.Now()
+	ctx, entry := i.begin(ss.Context(), info.FullMethod)
+	defer func() { i.finish(ctx, entry, info.FullMethod, start, err) }()
+	defer i.recover(entry, &err)
+
+	if err := i.checkTenant(ctx); err != nil {
+		return err
+	}
+	if err := i.checkStreamSignature(ctx); err != nil {
+		return err
+	}
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+// begin stores the request ID, the calling device, its tenant and the
+// request-scoped log entry in the call context and returns the request ID in
+// the response headers.
+func (i *interceptors) begin(ctx context.Context, method string) (context.Context, *logrus.Entry) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	id := logging.ResolveRequestID(first(md, requestIDKey))
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+	ctx = logging.WithRequestID(ctx, id)
+
+	subject := first(md, i.subjectKey)
+	device, tenant := identity.DeviceFromSubject(subject), identity.TenantFromSubject(subject)
+	ctx = identity.WithTenant(identity.WithDevice(ctx, device), tenant)
+
+	entry := i.log.WithFields(logrus.Fields{
+		"request_id": id,
+		"tenant":     tenant,
+		"device":     device,
+		"method":     method,
+	})
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.HasTraceID() {
+		entry = entry.WithField("trace_id", spanCtx.TraceID().String())
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		entry = entry.WithField("client_ip", p.Addr.String())
+	}
+	return logging.WithEntry(ctx, entry), entry
+}
+
+// checkTenant returns a PermissionDenied status if the tenant of the call is
+// not registered.
+func (i *interceptors) checkTenant(ctx context.Context) error {
+	if i.tenants == nil {
+		return nil
+	}
+	if err := i.tenants.CheckTenant(ctx, identity.TenantFromContext(ctx)); err != nil {
+		return toStatus(err)
+	}
+	return nil
+}
+
+// checkSignature returns an Unauthenticated status if the call is not signed
+// while signatures are required, or its signature is invalid or replayed.
+// Calls are signed like HTTP requests to their path, the full method name,
+// with method POST and the request message in deterministic protobuf
+// encoding as body.
+func (i *interceptors) checkSignature(ctx context.Context, method string, req any) error {
+	if i.signatures == nil {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	signature := first(md, signatureKey)
+	if signature == "" {
+		if i.signaturesRequired {
+			return toStatus(fmt.Errorf("%w: request signature is required", domain.ErrUnauthorized))
+		}
+		return nil
+	}
+
+	var body []byte
+	if message, ok := req.(proto.Message); ok {
+		var err error
+		body, err = proto.MarshalOptions{Deterministic: true}.Marshal(message)
+		if err != nil {
+			return toStatus(fmt.Errorf("encode request for signature: %w", err))
+		}
+	}
+
+	err := i.signatures.Verify(ctx, identity.DeviceFromContext(ctx), signing.Request{
+		Method:    http.MethodPost,
+		Path:      method,
+		Timestamp: first(md, timestampKey),
+		Nonce:     first(md, nonceKey),
+		Signature: signature,
+		Body:      body,
+	})
+	if err != nil {
+		return toStatus(err)
+	}
+	return nil
+}
+
+// checkStreamSignature returns an Unauthenticated status for streams opened
+// while signatures are required, or with a signature. A signature at open
+// would not cover the messages sent afterwards, which could be replaced
+// without notice, so signed terminals must use unary calls.
+func (i *interceptors) checkStreamSignature(ctx context.Context) error {
+	if i.signatures == nil {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if i.signaturesRequired || first(md, signatureKey) != "" {
+		return toStatus(fmt.Errorf("%w: streams cannot be signed, signed terminals must use unary calls", domain.ErrUnauthorized))
+	}
+	return nil
+}
+
+// finish logs and observes a completed call.
+func (i *interceptors) finish(ctx context.Context, entry *logrus.Entry, method string, start time.Time, err error) {
+	duration := time.Since(start)
+	code := status.Code(err)
+	i.metrics.ObserveGRPCRequest(method, code.String(), identity.DeviceFromContext(ctx), duration)
+
+	entry = entry.WithFields(logrus.Fields{
+		"code":       code.String(),
+		"latency_ms": duration.Milliseconds(),
+	})
+	switch code {
+	case codes.OK:
+		entry.Info("Request completed")
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		entry.Error("Request completed")
+	default:
+		entry.Warn("Request completed")
+	}
+}
+
+// recover turns a panic in the handler into an Internal status.
+func (i *interceptors) recover(entry *logrus.Entry, err *error) {
+	if r := recover(); r != nil {
+		entry.Errorf("Panic recovered: %v", r)
+		*err = toStatus(fmt.Errorf("panic: %v", r))
+	}
+}
+
+// serverStream overrides the context of a server stream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// first returns the first metadata value for key, or an empty string.
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
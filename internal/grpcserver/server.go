@@ -0,0 +1,44 @@
+// Package grpcserver serves the gRPC terminal API defined in api/access/v1.
+package grpcserver
+
+import (
+	"strings"
+
+	accessv1 "access-system-api/api/access/v1"
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/metrics"
+	"access-system-api/internal/service"
+	"access-system-api/internal/signing"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+)
+
+// NewServer creates a gRPC server exposing access with tracing, request IDs,
+// device identity, logging, metrics and panic recovery. Like the HTTP API, it
+// expects Nginx to terminate mTLS and forward the verified client certificate
+// subject in the metadata key named by the client subject header. Calls of
+// tenants rejected by tenants fail; a nil tenants accepts every tenant.
+// Signed calls are verified with signatures according to the request signing
+// mode, which must be off if signatures is nil.
+func NewServer(config *cfg.Config, access accessv1.AccessServiceServer, tenants service.TenantChecker, signatures *signing.Verifier, m *metrics.Metrics, log *logrus.Logger) *grpc.Server {
+	i := &interceptors{
+		subjectKey:         strings.ToLower(config.Auth.ClientSubjectHeader),
+		tenants:            tenants,
+		signaturesRequired: config.Auth.RequestSigning == cfg.RequestSigningRequired,
+		metrics:            m,
+		log:                log,
+	}
+	if config.Auth.RequestSigning != cfg.RequestSigningOff {
+		i.signatures = signatures
+	}
+
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(i.unary),
+		grpc.ChainStreamInterceptor(i.stream),
+	)
+	accessv1.RegisterAccessServiceServer(server, access)
+	return server
+}
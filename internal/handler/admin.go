@@ -2,13 +2,18 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"strconv"
-	"time"
 
+	"access-system-api/internal/domain"
+	"access-system-api/internal/dto"
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/logging"
 	"access-system-api/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pgvector/pgvector-go"
 	"github.com/sirupsen/logrus"
 )
 
@@ -16,7 +21,10 @@ type AdminHandler interface {
 	AddEmbeddingHandler(c *gin.Context)
 	GetEmbeddingHandler(c *gin.Context)
 	ListEmbeddingsHandler(c *gin.Context)
+	ListInvalidEmbeddingsHandler(c *gin.Context)
 	UpdateEmbeddingHandler(c *gin.Context)
+	PatchEmbeddingHandler(c *gin.Context)
+	SetCaptureMinimumsHandler(c *gin.Context)
 	DeleteEmbeddingHandler(c *gin.Context)
 }
 
@@ -32,25 +40,40 @@ func NewAdminHandler(embeddingService service.EmbeddingService, log *logrus.Logg
 	}
 }
 
+// logger returns the request-scoped log entry, falling back to the handler logger.
+func (h *adminHandler) logger(ctx context.Context) *logrus.Entry {
+	return logging.FromContext(ctx, h.log)
+}
+
 func (h *adminHandler) AddEmbeddingHandler(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
-	var data struct {
-		Name   string    `json:"name" binding:"required"`
-		Vector []float32 `json:"vector" binding:"required"`
-	}
+	var data dto.AddEmbeddingRequest
 
 	if err := c.ShouldBindJSON(&data); err != nil {
-		h.log.Errorln("Error binding JSON:", err)
-		c.String(http.StatusBadRequest, "Bad Request: %v", err)
+		h.logger(ctx).WithError(err).Error("Error binding JSON")
+		httperr.Respond(c, invalidInput(err.Error()))
 		return
 	}
 
-	err := h.embeddingService.AddEmbedding(ctx, data.Name, data.Vector)
+	// Validate required fields
+	if data.Name == "" || len(data.Vector) == 0 {
+		h.logger(ctx).Error("Missing required fields: name or vector")
+		httperr.Respond(c, invalidInput("name and vector are required"))
+		return
+	}
+
+	ctx, err := withCapture(ctx, data.Capture)
 	if err != nil {
-		h.log.Errorln("Error adding embedding:", err)
-		c.String(http.StatusInternalServerError, "Internal Server Error: %v", err)
+		h.logger(ctx).WithError(err).Error("Invalid capture")
+		httperr.Respond(c, err)
+		return
+	}
+
+	err = h.embeddingService.AddEmbedding(ctx, data.Name, data.Vector)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error adding embedding")
+		httperr.Respond(c, err)
 		return
 	}
 
@@ -58,96 +81,317 @@ func (h *adminHandler) AddEmbeddingHandler(c *gin.Context) {
 }
 
 func (h *adminHandler) GetEmbeddingHandler(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	id := c.Param("id")
 	intId, err := strconv.ParseInt(id, 10, 64)
 	if err != nil {
-		h.log.Errorln("Invalid ID parameter:", err)
-		c.String(http.StatusBadRequest, "Bad Request: invalid ID parameter")
+		h.logger(ctx).WithError(err).Error("Invalid ID parameter")
+		httperr.Respond(c, invalidInput("invalid ID parameter"))
 		return
 	}
 
 	embedding, err := h.embeddingService.GetEmbedding(ctx, intId)
 	if err != nil {
-		h.log.Errorln("Error getting embedding:", err)
-		c.String(http.StatusInternalServerError, "Internal Server Error: %v", err)
+		h.logger(ctx).WithError(err).Error("Error getting embedding")
+		httperr.Respond(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, embedding)
+	c.Header("ETag", etag(embedding.Version))
+	c.JSON(http.StatusOK, embeddingResponse(embedding))
 }
 
 func (h *adminHandler) ListEmbeddingsHandler(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	embeddings, err := h.embeddingService.ListEmbeddings(ctx)
 	if err != nil {
-		h.log.Errorln("Error listing embeddings:", err)
-		c.String(http.StatusInternalServerError, "Internal Server Error: %v", err)
+		h.logger(ctx).WithError(err).Error("Error listing embeddings")
+		httperr.Respond(c, err)
 		return
 	}
 
-	var response []gin.H
+	response := make([]dto.EmbeddingResponse, 0, len(embeddings))
 	for _, embedding := range embeddings {
-		response = append(response, gin.H{
-			"id":     embedding.ID,
-			"name":   embedding.Name,
-			"vector": embedding.Vector,
+		response = append(response, embeddingResponse(embedding))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListInvalidEmbeddingsHandler lists the stored embeddings whose vectors are
+// not finite, have no direction or are not normalized as configured.
+func (h *adminHandler) ListInvalidEmbeddingsHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	invalid, err := h.embeddingService.ListInvalidEmbeddings(ctx)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error listing invalid embeddings")
+		httperr.Respond(c, err)
+		return
+	}
+
+	response := make([]dto.InvalidEmbeddingResponse, 0, len(invalid))
+	for _, embedding := range invalid {
+		response = append(response, dto.InvalidEmbeddingResponse{
+			ID:      embedding.ID,
+			Name:    embedding.Name,
+			Problem: embedding.Problem,
 		})
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// UpdateEmbeddingHandler replaces the name and vector of an embedding. With
+// an If-Match header, only the version it names is replaced.
 func (h *adminHandler) UpdateEmbeddingHandler(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
+
+	version, err := ifMatch(c)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Invalid If-Match header")
+		httperr.Respond(c, err)
+		return
+	}
+
+	var data dto.UpdateEmbeddingRequest
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.logger(ctx).WithError(err).Error("Error binding JSON")
+		httperr.Respond(c, invalidInput(err.Error()))
+		return
+	}
+
+	// Validate required fields
+	if data.ID == 0 || data.Name == "" || len(data.Vector) == 0 {
+		h.logger(ctx).Error("Missing required fields: id, name or vector")
+		httperr.Respond(c, invalidInput("id, name and vector are required"))
+		return
+	}
+
+	version, err = h.embeddingService.UpdateEmbedding(ctx, data.ID, data.Name, data.Vector, version)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error updating embedding")
+		httperr.Respond(c, err)
+		return
+	}
+
+	c.Header("ETag", etag(version))
+	c.Status(http.StatusOK)
+}
+
+// PatchEmbeddingHandler applies a JSON merge patch (RFC 7396) to an
+// embedding: only the fields of the body change, null resets a capture
+// minimum, and the metadata are merged key by key. With an If-Match header,
+// only the version it names is changed.
+func (h *adminHandler) PatchEmbeddingHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Invalid ID parameter")
+		httperr.Respond(c, invalidInput("invalid ID parameter"))
+		return
+	}
+
+	version, err := ifMatch(c)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Invalid If-Match header")
+		httperr.Respond(c, err)
+		return
+	}
+
+	var data dto.PatchEmbeddingRequest
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&data); err != nil {
+		h.logger(ctx).WithError(err).Error("Error decoding merge patch")
+		httperr.Respond(c, invalidInput(err.Error()))
+		return
+	}
 
-	var data struct {
-		ID     int64     `json:"id" binding:"required"`
-		Name   string    `json:"name" binding:"required"`
-		Vector []float32 `json:"vector" binding:"required"`
+	patch, err := embeddingPatch(data)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Invalid merge patch")
+		httperr.Respond(c, err)
+		return
 	}
 
+	embedding, err := h.embeddingService.PatchEmbedding(ctx, id, patch, version)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error patching embedding")
+		httperr.Respond(c, err)
+		return
+	}
+
+	c.Header("ETag", etag(embedding.Version))
+	c.JSON(http.StatusOK, embeddingResponse(embedding))
+}
+
+// SetCaptureMinimumsHandler sets the minimum capture scores of validations
+// matching an embedding. With an If-Match header, only the version it names
+// is changed.
+func (h *adminHandler) SetCaptureMinimumsHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Invalid ID parameter")
+		httperr.Respond(c, invalidInput("invalid ID parameter"))
+		return
+	}
+
+	version, err := ifMatch(c)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Invalid If-Match header")
+		httperr.Respond(c, err)
+		return
+	}
+
+	var data dto.CaptureMinimumsRequest
 	if err := c.ShouldBindJSON(&data); err != nil {
-		h.log.Errorln("Error binding JSON:", err)
-		c.String(http.StatusBadRequest, "Bad Request: %v", err)
+		h.logger(ctx).WithError(err).Error("Error binding JSON")
+		httperr.Respond(c, invalidInput(err.Error()))
 		return
 	}
 
-	err := h.embeddingService.UpdateEmbedding(ctx, data.ID, data.Name, data.Vector)
+	minimums := domain.CaptureMinimums{Liveness: data.MinLiveness, Quality: data.MinQuality}
+	version, err = h.embeddingService.SetCaptureMinimums(ctx, id, minimums, version)
 	if err != nil {
-		h.log.Errorln("Error updating embedding:", err)
-		c.String(http.StatusInternalServerError, "Internal Server Error: %v", err)
+		h.logger(ctx).WithError(err).Error("Error setting capture minimums")
+		httperr.Respond(c, err)
 		return
 	}
 
+	c.Header("ETag", etag(version))
 	c.Status(http.StatusOK)
 }
 
+// DeleteEmbeddingHandler removes an embedding. With an If-Match header,
+// only the version it names is removed.
 func (h *adminHandler) DeleteEmbeddingHandler(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
-	var data struct {
-		ID int64 `json:"id" binding:"required"`
+	version, err := ifMatch(c)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Invalid If-Match header")
+		httperr.Respond(c, err)
+		return
 	}
 
+	var data dto.DeleteEmbeddingRequest
+
 	if err := c.ShouldBindJSON(&data); err != nil {
-		h.log.Errorln("Error binding JSON:", err)
-		c.String(http.StatusBadRequest, "Bad Request: %v", err)
+		h.logger(ctx).WithError(err).Error("Error binding JSON")
+		httperr.Respond(c, invalidInput(err.Error()))
+		return
+	}
+
+	// Validate required fields
+	if data.ID == 0 {
+		h.logger(ctx).Error("Missing required field: id")
+		httperr.Respond(c, invalidInput("id is required"))
 		return
 	}
 
-	err := h.embeddingService.DeleteEmbedding(ctx, data.ID)
+	err = h.embeddingService.DeleteEmbedding(ctx, data.ID, version)
 	if err != nil {
-		h.log.Errorln("Error deleting embedding:", err)
-		c.String(http.StatusInternalServerError, "Internal Server Error: %v", err)
+		h.logger(ctx).WithError(err).Error("Error deleting embedding")
+		httperr.Respond(c, err)
 		return
 	}
 
 	c.Status(http.StatusOK)
 }
+
+func embeddingResponse(embedding *domain.Embedding) dto.EmbeddingResponse {
+	response := dto.EmbeddingResponse{
+		ID:          embedding.ID,
+		Name:        embedding.Name,
+		Vector:      embedding.Vector.Slice(),
+		MinLiveness: embedding.CaptureMinimums.Liveness,
+		MinQuality:  embedding.CaptureMinimums.Quality,
+		Metadata:    embedding.Metadata,
+		Version:     embedding.Version,
+	}
+	if response.Metadata == nil {
+		response.Metadata = map[string]string{}
+	}
+	return response
+}
+
+// embeddingPatch converts a merge patch into the fields to change. Name and
+// vector cannot be removed; removing a capture minimum resets it to 0.
+func embeddingPatch(data dto.PatchEmbeddingRequest) (domain.EmbeddingPatch, error) {
+	var patch domain.EmbeddingPatch
+	if data.Name != nil {
+		var name string
+		if err := decodeRequired(data.Name, "name", &name); err != nil {
+			return patch, err
+		}
+		patch.Name = &name
+	}
+	if data.Vector != nil {
+		var values []float32
+		if err := decodeRequired(data.Vector, "vector", &values); err != nil {
+			return patch, err
+		}
+		vector := pgvector.NewVector(values)
+		patch.Vector = &vector
+	}
+	var err error
+	if patch.MinLiveness, err = decodeMinimum(data.MinLiveness, "min_liveness"); err != nil {
+		return patch, err
+	}
+	if patch.MinQuality, err = decodeMinimum(data.MinQuality, "min_quality"); err != nil {
+		return patch, err
+	}
+	if patch.Metadata, err = decodeMetadata(data.Metadata); err != nil {
+		return patch, err
+	}
+	return patch, nil
+}
+
+// decodeMetadata returns the merge patch of the metadata given by raw: null
+// removes every key, and an object sets its keys, removing those set to
+// null. It returns nil if raw is absent.
+func decodeMetadata(raw json.RawMessage) (*domain.MetadataPatch, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	if string(raw) == "null" {
+		return &domain.MetadataPatch{Clear: true}, nil
+	}
+	var set map[string]*string
+	if err := json.Unmarshal(raw, &set); err != nil || set == nil {
+		return nil, invalidInput("metadata must be an object of strings or null")
+	}
+	return &domain.MetadataPatch{Set: set}, nil
+}
+
+func decodeRequired(raw json.RawMessage, field string, value any) error {
+	if string(raw) == "null" {
+		return invalidInput(field + " cannot be removed")
+	}
+	if err := json.Unmarshal(raw, value); err != nil {
+		return invalidInput(field + ": " + err.Error())
+	}
+	return nil
+}
+
+// decodeMinimum returns the capture minimum set by raw, 0 if it is null, or
+// nil if it is absent.
+func decodeMinimum(raw json.RawMessage, field string) (*float64, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var minimum float64
+	if string(raw) != "null" {
+		if err := json.Unmarshal(raw, &minimum); err != nil {
+			return nil, invalidInput(field + ": " + err.Error())
+		}
+	}
+	return &minimum, nil
+}
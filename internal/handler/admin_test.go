@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/dto"
+	mocks "access-system-api/internal/mocks/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/pgvector/pgvector-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAdminRouter(t *testing.T) (*gin.Engine, *mocks.MockEmbeddingService) {
+	gin.SetMode(gin.TestMode)
+	service := mocks.NewMockEmbeddingService(gomock.NewController(t))
+	handler := NewAdminHandler(service, logrus.New())
+
+	r := gin.New()
+	r.GET("/embedding/:id", handler.GetEmbeddingHandler)
+	r.PUT("/embedding", handler.UpdateEmbeddingHandler)
+	r.PATCH("/embedding/:id", handler.PatchEmbeddingHandler)
+	r.DELETE("/embedding", handler.DeleteEmbeddingHandler)
+	r.PUT("/embedding/:id/capture-minimums", handler.SetCaptureMinimumsHandler)
+	return r, service
+}
+
+func TestGetEmbeddingHandler_ETag(t *testing.T) {
+	r, service := setupAdminRouter(t)
+
+	service.EXPECT().GetEmbedding(gomock.Any(), int64(1)).
+		Return(&domain.Embedding{ID: 1, Name: "alice", Vector: pgvector.NewVector([]float32{1}), Version: 3}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/embedding/1", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), `"version":3`)
+}
+
+func TestUpdateEmbeddingHandler_IfMatch(t *testing.T) {
+	body, _ := json.Marshal(map[string]interface{}{"id": 1, "name": "alice", "vector": []float32{1}})
+	tests := []struct {
+		name    string
+		ifMatch string
+		version int64
+		err     error
+		status  int
+	}{
+		{"unconditional", "", 0, nil, http.StatusOK},
+		{"any version", "*", 0, nil, http.StatusOK},
+		{"current version", `"3"`, 3, nil, http.StatusOK},
+		{"stale version", `"2"`, 2, fmt.Errorf("%w: embedding 1 is at version 3", domain.ErrVersionConflict), http.StatusPreconditionFailed},
+		{"missing", `"3"`, 3, fmt.Errorf("%w: embedding 1", domain.ErrNotFound), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, service := setupAdminRouter(t)
+			var updated int64
+			if tt.err == nil {
+				updated = 4
+			}
+			service.EXPECT().UpdateEmbedding(gomock.Any(), int64(1), "alice", []float32{1}, tt.version).Return(updated, tt.err)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", "/embedding", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.err == nil {
+				assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestDeleteEmbeddingHandler_IfMatch(t *testing.T) {
+	r, service := setupAdminRouter(t)
+	body, _ := json.Marshal(map[string]interface{}{"id": 1})
+
+	service.EXPECT().DeleteEmbedding(gomock.Any(), int64(1), int64(2)).
+		Return(fmt.Errorf("%w: embedding 1 is at version 3", domain.ErrVersionConflict))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/embedding", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"2"`)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	// Weak and malformed tags never reach the service.
+	for _, tag := range []string{`W/"3"`, "3", `"0"`, `"3", "4"`} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/embedding", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", tag)
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, tag)
+	}
+}
+
+func TestSetCaptureMinimumsHandler_IfMatch(t *testing.T) {
+	body, _ := json.Marshal(map[string]interface{}{"min_liveness": 0.8, "min_quality": 0.5})
+	minimums := domain.CaptureMinimums{Liveness: 0.8, Quality: 0.5}
+	tests := []struct {
+		name    string
+		ifMatch string
+		version int64
+		err     error
+		status  int
+	}{
+		{"unconditional", "", 0, nil, http.StatusOK},
+		{"current version", `"3"`, 3, nil, http.StatusOK},
+		{"stale version", `"2"`, 2, fmt.Errorf("%w: embedding 1 is at version 3", domain.ErrVersionConflict), http.StatusPreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, service := setupAdminRouter(t)
+			var updated int64
+			if tt.err == nil {
+				updated = 4
+			}
+			service.EXPECT().SetCaptureMinimums(gomock.Any(), int64(1), minimums, tt.version).Return(updated, tt.err)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", "/embedding/1/capture-minimums", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.err == nil {
+				assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestPatchEmbeddingHandler(t *testing.T) {
+	r, service := setupAdminRouter(t)
+
+	// Absent fields are kept, and null resets a capture minimum.
+	name := "alice b."
+	zero := 0.0
+	service.EXPECT().PatchEmbedding(gomock.Any(), int64(1), domain.EmbeddingPatch{Name: &name, MinQuality: &zero}, int64(3)).
+		Return(&domain.Embedding{ID: 1, Name: name, Vector: pgvector.NewVector([]float32{1}), Version: 4}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/embedding/1", bytes.NewReader([]byte(`{"name": "alice b.", "min_quality": null}`)))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req.Header.Set("If-Match", `"3"`)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	var response dto.EmbeddingResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "alice b.", response.Name)
+	assert.Equal(t, []float32{1}, response.Vector)
+	assert.Equal(t, map[string]string{}, response.Metadata)
+}
+
+func TestPatchEmbeddingHandler_Metadata(t *testing.T) {
+	r, service := setupAdminRouter(t)
+
+	// Metadata are merged key by key, and null removes a key.
+	badge := "B-1042"
+	patch := domain.EmbeddingPatch{Metadata: &domain.MetadataPatch{Set: map[string]*string{"badge": &badge, "team": nil}}}
+	service.EXPECT().PatchEmbedding(gomock.Any(), int64(1), patch, int64(0)).
+		Return(&domain.Embedding{ID: 1, Metadata: map[string]string{"badge": badge}, Version: 2}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/embedding/1", bytes.NewReader([]byte(`{"metadata": {"badge": "B-1042", "team": null}}`)))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.EmbeddingResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, map[string]string{"badge": badge}, response.Metadata)
+
+	// Null removes every key.
+	service.EXPECT().PatchEmbedding(gomock.Any(), int64(1), domain.EmbeddingPatch{Metadata: &domain.MetadataPatch{Clear: true}}, int64(0)).
+		Return(&domain.Embedding{ID: 1, Version: 3}, nil)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PATCH", "/embedding/1", bytes.NewReader([]byte(`{"metadata": null}`)))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestPatchEmbeddingHandler_Invalid(t *testing.T) {
+	r, _ := setupAdminRouter(t)
+
+	for _, body := range []string{`{"name": null}`, `{"vector": null}`, `{"vector": "none"}`, `{"min_liveness": "high"}`, `{"zone": "north"}`, `[]`,
+		`{"metadata": "B-1042"}`, `{"metadata": {"badge": 1042}}`, `{"metadata": []}`} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PATCH", "/embedding/1", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
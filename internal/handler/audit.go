@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"access-system-api/internal/dto"
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/logging"
+	"access-system-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// AuditHandler defines the interface for the audit trail API.
+type AuditHandler interface {
+	ListEmbeddingAuditHandler(c *gin.Context)
+}
+
+// auditHandler implements the AuditHandler interface.
+type auditHandler struct {
+	auditService service.AuditService
+	log          *logrus.Logger
+}
+
+// NewAuditHandler creates a new instance of auditHandler.
+func NewAuditHandler(auditService service.AuditService, log *logrus.Logger) AuditHandler {
+	return &auditHandler{auditService: auditService, log: log}
+}
+
+// logger returns the request-scoped log entry, falling back to the handler logger.
+func (h *auditHandler) logger(ctx context.Context) *logrus.Entry {
+	return logging.FromContext(ctx, h.log)
+}
+
+// ListEmbeddingAuditHandler lists the changes of an embedding, oldest first.
+// The changes of deleted embeddings are listed too.
+func (h *auditHandler) ListEmbeddingAuditHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Invalid ID parameter")
+		httperr.Respond(c, invalidInput("invalid ID parameter"))
+		return
+	}
+
+	audits, err := h.auditService.ListEmbeddingAudit(ctx, id)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error listing embedding audit")
+		httperr.Respond(c, err)
+		return
+	}
+
+	response := make([]dto.EmbeddingAuditResponse, 0, len(audits))
+	for _, audit := range audits {
+		response = append(response, dto.EmbeddingAuditResponse{
+			ID:         audit.ID,
+			Device:     audit.Device,
+			RequestID:  audit.RequestID,
+			Fields:     audit.Fields,
+			OldVersion: audit.OldVersion,
+			NewVersion: audit.NewVersion,
+			CreatedAt:  audit.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/dto"
+	mocks "access-system-api/internal/mocks/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListEmbeddingAuditHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := mocks.NewMockAuditService(gomock.NewController(t))
+	r := gin.New()
+	r.GET("/embedding/:id/audit", NewAuditHandler(service, logrus.New()).ListEmbeddingAuditHandler)
+
+	created := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	service.EXPECT().ListEmbeddingAudit(gomock.Any(), int64(1)).Return([]*domain.EmbeddingAudit{
+		{ID: 7, EmbeddingID: 1, Device: "enroll-01", RequestID: "req-1", Fields: []string{"metadata"}, OldVersion: 2, NewVersion: 3, CreatedAt: created},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/embedding/1/audit", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []dto.EmbeddingAuditResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []dto.EmbeddingAuditResponse{
+		{ID: 7, Device: "enroll-01", RequestID: "req-1", Fields: []string{"metadata"}, OldVersion: 2, NewVersion: 3, CreatedAt: created},
+	}, response)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/embedding/abc/audit", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/dto"
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/logging"
+	"access-system-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Decisions of batch validation results.
+const (
+	decisionMatch    = "match"
+	decisionNoMatch  = "no_match"
+	decisionRejected = "rejected"
+	decisionError    = "error"
+)
+
+// BatchHandler defines the interface for batch validation handlers.
+type BatchHandler interface {
+	ValidateBatchHandler(c *gin.Context)
+}
+
+// batchHandler implements the BatchHandler interface.
+type batchHandler struct {
+	batchService service.BatchService
+	log          *logrus.Logger
+}
+
+// NewBatchHandler creates a new instance of batchHandler.
+func NewBatchHandler(batchService service.BatchService, log *logrus.Logger) BatchHandler {
+	return &batchHandler{batchService: batchService, log: log}
+}
+
+// logger returns the request-scoped log entry, falling back to the handler logger.
+func (h *batchHandler) logger(ctx context.Context) *logrus.Entry {
+	return logging.FromContext(ctx, h.log)
+}
+
+// ValidateBatchHandler validates the faces of a frame. The response reports
+// every probe, so a probe that fails does not fail the others.
+func (h *batchHandler) ValidateBatchHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var data dto.ValidateBatchRequest
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.logger(ctx).WithError(err).Error("Error binding JSON")
+		httperr.Respond(c, invalidInput(err.Error()))
+		return
+	}
+
+	probes := make([]domain.Probe, 0, len(data.Probes))
+	for _, probe := range data.Probes {
+		probes = append(probes, domain.Probe{
+			ID:     probe.ID,
+			Vector: probe.Vector,
+			Capture: domain.Capture{
+				Liveness: probe.Liveness,
+				Quality:  probe.Quality,
+				Metadata: probe.CaptureMetadata,
+			},
+		})
+	}
+
+	results, err := h.batchService.ValidateBatch(ctx, probes)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error validating batch")
+		httperr.Respond(c, err)
+		return
+	}
+
+	response := dto.ValidateBatchResponse{Results: make([]dto.BatchResult, 0, len(results))}
+	for _, result := range results {
+		response.Results = append(response.Results, h.batchResult(ctx, result))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// batchResult logs the outcome of a probe and converts it to its response.
+func (h *batchHandler) batchResult(ctx context.Context, result domain.ProbeResult) dto.BatchResult {
+	entry := h.logger(ctx).WithField("probe_id", result.ID)
+	response := dto.BatchResult{ID: result.ID}
+
+	switch {
+	case result.Err == nil:
+		entry.WithFields(logrus.Fields{
+			"embedding_id": result.Embedding.ID,
+			"accuracy":     result.Embedding.Accuracy,
+		}).Info("Relevant match found")
+		response.Decision = decisionMatch
+		response.Match = &dto.ValidateEmbeddingResponse{
+			ID:       result.Embedding.ID,
+			Name:     result.Embedding.Name,
+			Vector:   result.Embedding.Vector.Slice(),
+			Accuracy: result.Embedding.Accuracy,
+		}
+		return response
+	case errors.Is(result.Err, domain.ErrNoMatch):
+		entry.Info("No relevant matches found")
+		response.Decision = decisionNoMatch
+		return response
+	}
+
+	status, detail := httperr.Resolve(result.Err)
+	if status < http.StatusInternalServerError {
+		entry.WithError(result.Err).Info("Probe rejected")
+		response.Decision = decisionRejected
+	} else {
+		entry.WithError(result.Err).Error("Error validating probe")
+		response.Decision = decisionError
+	}
+	response.Error = &dto.BatchError{Code: detail.Code, Message: detail.Message}
+	return response
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/dto"
+	"access-system-api/internal/httperr"
+	mocks "access-system-api/internal/mocks/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/pgvector/pgvector-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupBatchRouter(t *testing.T) (*gin.Engine, *mocks.MockBatchService) {
+	ctrl := gomock.NewController(t)
+	service := mocks.NewMockBatchService(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/validate/batch", NewBatchHandler(service, logrus.New()).ValidateBatchHandler)
+	return r, service
+}
+
+func TestValidateBatchHandler_Results(t *testing.T) {
+	r, service := setupBatchRouter(t)
+	liveness := 0.95
+
+	service.EXPECT().ValidateBatch(gomock.Any(), []domain.Probe{
+		{ID: "a", Vector: []float32{1}, Capture: domain.Capture{Liveness: &liveness}},
+		{ID: "b", Vector: []float32{2}},
+		{ID: "c", Vector: []float32{3}},
+		{ID: "d", Vector: []float32{4}},
+	}).Return([]domain.ProbeResult{
+		{ID: "a", Embedding: &domain.Embedding{ID: 1, Name: "test", Vector: pgvector.NewVector([]float32{1}), Accuracy: 0.9}},
+		{ID: "b", Err: domain.ErrNoMatch},
+		{ID: "c", Err: fmt.Errorf("%w: vector size must be 512, got 1", domain.ErrInvalidDimension)},
+		{ID: "d", Err: errors.New("connection reset")},
+	}, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"probes": []map[string]interface{}{
+			{"id": "a", "vector": []float32{1}, "liveness": liveness},
+			{"id": "b", "vector": []float32{2}},
+			{"id": "c", "vector": []float32{3}},
+			{"id": "d", "vector": []float32{4}},
+		},
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/validate/batch", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response dto.ValidateBatchResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Results, 4)
+
+	assert.Equal(t, "match", response.Results[0].Decision)
+	assert.Equal(t, int64(1), response.Results[0].Match.ID)
+	assert.Equal(t, "no_match", response.Results[1].Decision)
+	assert.Nil(t, response.Results[1].Error)
+	assert.Equal(t, "rejected", response.Results[2].Decision)
+	assert.Equal(t, httperr.CodeInvalidDimension, response.Results[2].Error.Code)
+	assert.Equal(t, "error", response.Results[3].Decision)
+	assert.Equal(t, httperr.CodeInternal, response.Results[3].Error.Code)
+	assert.NotContains(t, w.Body.String(), "connection reset")
+}
+
+func TestValidateBatchHandler_InvalidBatch(t *testing.T) {
+	r, service := setupBatchRouter(t)
+	service.EXPECT().ValidateBatch(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("%w: probe id %q is not unique", domain.ErrInvalidInput, "a"))
+
+	body := `{"probes":[{"id":"a","vector":[1]},{"id":"a","vector":[2]}]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/validate/batch", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"context"
+
+	"access-system-api/internal/capture"
+	"access-system-api/internal/domain"
+	"access-system-api/internal/dto"
+)
+
+// withCapture validates the capture scores of a request and returns a copy of
+// ctx carrying them.
+func withCapture(ctx context.Context, data dto.Capture) (context.Context, error) {
+	captured := domain.Capture{Liveness: data.Liveness, Quality: data.Quality, Metadata: data.CaptureMetadata}
+	if err := capture.Validate(captured); err != nil {
+		return ctx, err
+	}
+	return capture.WithCapture(ctx, captured), nil
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/dto"
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/logging"
+	"access-system-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// DeviceHandler defines the interface for the device registry administration API.
+type DeviceHandler interface {
+	RegisterDeviceHandler(c *gin.Context)
+	GetDeviceHandler(c *gin.Context)
+	ListDevicesHandler(c *gin.Context)
+	DeleteDeviceHandler(c *gin.Context)
+	RotateKeyHandler(c *gin.Context)
+}
+
+// deviceHandler implements the DeviceHandler interface.
+type deviceHandler struct {
+	deviceService service.DeviceService
+	log           *logrus.Logger
+}
+
+// NewDeviceHandler creates a new instance of deviceHandler.
+func NewDeviceHandler(deviceService service.DeviceService, log *logrus.Logger) DeviceHandler {
+	return &deviceHandler{deviceService: deviceService, log: log}
+}
+
+// logger returns the request-scoped log entry, falling back to the handler logger.
+func (h *deviceHandler) logger(ctx context.Context) *logrus.Entry {
+	return logging.FromContext(ctx, h.log)
+}
+
+// idParam parses the id path parameter.
+func (h *deviceHandler) idParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger(c.Request.Context()).WithError(err).Error("Invalid ID parameter")
+		httperr.Respond(c, invalidInput("invalid ID parameter"))
+		return 0, false
+	}
+	return id, true
+}
+
+// RegisterDeviceHandler registers a device and returns it with its signing key.
+func (h *deviceHandler) RegisterDeviceHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var data dto.RegisterDeviceRequest
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.logger(ctx).WithError(err).Error("Error binding JSON")
+		httperr.Respond(c, invalidInput(err.Error()))
+		return
+	}
+
+	device, err := h.deviceService.RegisterDevice(ctx, data.Name, data.SigningKey)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error registering device")
+		httperr.Respond(c, err)
+		return
+	}
+
+	h.logger(ctx).WithField("registered_device", device.Name).Info("Device registered")
+	response := deviceResponse(device)
+	response.SigningKey = device.SigningKey
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *deviceHandler) GetDeviceHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := h.idParam(c)
+	if !ok {
+		return
+	}
+
+	device, err := h.deviceService.GetDevice(ctx, id)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error getting device")
+		httperr.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deviceResponse(device))
+}
+
+func (h *deviceHandler) ListDevicesHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	devices, err := h.deviceService.ListDevices(ctx)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error listing devices")
+		httperr.Respond(c, err)
+		return
+	}
+
+	response := make([]dto.DeviceResponse, 0, len(devices))
+	for _, device := range devices {
+		response = append(response, deviceResponse(device))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteDeviceHandler removes a device from the registry. Its signed requests
+// are rejected from then on.
+func (h *deviceHandler) DeleteDeviceHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := h.idParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.deviceService.DeleteDevice(ctx, id); err != nil {
+		h.logger(ctx).WithError(err).Error("Error deleting device")
+		httperr.Respond(c, err)
+		return
+	}
+
+	h.logger(ctx).WithField("device_id", id).Info("Device deleted")
+	c.Status(http.StatusOK)
+}
+
+// RotateKeyHandler replaces the signing key of a device and returns the new key.
+func (h *deviceHandler) RotateKeyHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := h.idParam(c)
+	if !ok {
+		return
+	}
+
+	device, err := h.deviceService.RotateSigningKey(ctx, id)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error rotating device signing key")
+		httperr.Respond(c, err)
+		return
+	}
+
+	h.logger(ctx).WithField("device_id", id).Info("Device signing key rotated")
+	response := deviceResponse(device)
+	response.SigningKey = device.SigningKey
+	c.JSON(http.StatusOK, response)
+}
+
+func deviceResponse(device *domain.Device) dto.DeviceResponse {
+	return dto.DeviceResponse{
+		ID:           device.ID,
+		Name:         device.Name,
+		CreatedAt:    device.CreatedAt,
+		KeyRotatedAt: device.KeyRotatedAt,
+	}
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/dto"
+	mocks "access-system-api/internal/mocks/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDeviceRouter(t *testing.T) (*gin.Engine, *mocks.MockDeviceService) {
+	gin.SetMode(gin.TestMode)
+	service := mocks.NewMockDeviceService(gomock.NewController(t))
+	handler := NewDeviceHandler(service, logrus.New())
+
+	r := gin.New()
+	r.POST("/devices", handler.RegisterDeviceHandler)
+	r.GET("/devices", handler.ListDevicesHandler)
+	r.GET("/devices/:id", handler.GetDeviceHandler)
+	r.DELETE("/devices/:id", handler.DeleteDeviceHandler)
+	r.POST("/devices/:id/rotate-key", handler.RotateKeyHandler)
+	return r, service
+}
+
+func TestRegisterDeviceHandler_ReturnsKey(t *testing.T) {
+	r, service := setupDeviceRouter(t)
+
+	service.EXPECT().RegisterDevice(gomock.Any(), "gate-01", "").
+		Return(&domain.Device{ID: 1, Name: "gate-01", SigningKey: "generated-key"}, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{"name": "gate-01"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/devices", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var response dto.DeviceResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, int64(1), response.ID)
+	assert.Equal(t, "generated-key", response.SigningKey)
+}
+
+func TestRegisterDeviceHandler_Conflict(t *testing.T) {
+	r, service := setupDeviceRouter(t)
+
+	service.EXPECT().RegisterDevice(gomock.Any(), "gate-01", "").
+		Return(nil, fmt.Errorf("%w: device gate-01 is already registered", domain.ErrConflict))
+
+	body, _ := json.Marshal(map[string]interface{}{"name": "gate-01"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/devices", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestListDevicesHandler_HidesKeys(t *testing.T) {
+	r, service := setupDeviceRouter(t)
+
+	service.EXPECT().ListDevices(gomock.Any()).
+		Return([]*domain.Device{{ID: 1, Name: "gate-01", SigningKey: "signing-key"}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/devices", nil)
+
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "gate-01")
+	assert.NotContains(t, w.Body.String(), "signing-key")
+}
+
+func TestRotateKeyHandler(t *testing.T) {
+	r, service := setupDeviceRouter(t)
+
+	service.EXPECT().RotateSigningKey(gomock.Any(), int64(1)).
+		Return(&domain.Device{ID: 1, Name: "gate-01", SigningKey: "new-key"}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/devices/1/rotate-key", nil)
+
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.DeviceResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "new-key", response.SigningKey)
+}
+
+func TestDeleteDeviceHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		err  error
+		code int
+	}{
+		{"deleted", "/devices/1", nil, http.StatusOK},
+		{"not found", "/devices/1", fmt.Errorf("%w: device 1", domain.ErrNotFound), http.StatusNotFound},
+		{"invalid id", "/devices/abc", nil, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, service := setupDeviceRouter(t)
+			if tt.code != http.StatusBadRequest {
+				service.EXPECT().DeleteDevice(gomock.Any(), int64(1)).Return(tt.err)
+			}
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", tt.path, nil)
+
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"fmt"
+
+	"access-system-api/internal/domain"
+)
+
+// invalidInput wraps a request validation problem as a domain.ErrInvalidInput.
+func invalidInput(message string) error {
+	return fmt.Errorf("%w: %s", domain.ErrInvalidInput, message)
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"access-system-api/internal/events"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/logging"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// EventsHandler defines the interface for the live access event stream.
+type EventsHandler interface {
+	StreamEventsHandler(c *gin.Context)
+}
+
+// eventsHandler implements the EventsHandler interface.
+type eventsHandler struct {
+	bus       *events.Bus
+	heartbeat time.Duration
+	log       *logrus.Logger
+}
+
+// NewEventsHandler creates a new instance of eventsHandler. Idle streams
+// receive a keep-alive comment every heartbeat.
+func NewEventsHandler(bus *events.Bus, heartbeat time.Duration, log *logrus.Logger) EventsHandler {
+	return &eventsHandler{bus: bus, heartbeat: heartbeat, log: log}
+}
+
+// logger returns the request-scoped log entry, falling back to the handler logger.
+func (h *eventsHandler) logger(ctx context.Context) *logrus.Entry {
+	return logging.FromContext(ctx, h.log)
+}
+
+// StreamEventsHandler streams access events as server-sent events until the
+// client disconnects or the server shuts down. Only events of the tenant of
+// the client are streamed. The optional device and zone query parameters,
+// which may be repeated, filter the events.
+func (h *eventsHandler) StreamEventsHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	sub := h.bus.Subscribe(events.Filter{
+		Tenant:  identity.TenantFromContext(ctx),
+		Devices: c.QueryArray("device"),
+		Zones:   c.QueryArray("zone"),
+	})
+	defer sub.Close()
+
+	c.Header("Content-Type", sse.ContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+	h.logger(ctx).Info("Event stream opened")
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.logger(ctx).WithField("dropped", sub.Dropped()).Info("Event stream closed by client")
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-sub.Events():
+			if !ok {
+				h.logger(ctx).WithField("dropped", sub.Dropped()).Info("Event stream closed by server")
+				return
+			}
+			c.Render(-1, sse.Event{Id: event.ID, Event: string(event.Type), Data: event})
+		}
+		c.Writer.Flush()
+	}
+}
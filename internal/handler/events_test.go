@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"access-system-api/internal/events"
+	"access-system-api/internal/identity"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamEventsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	bus := events.NewBus(4)
+	r := gin.New()
+	r.GET("/events", NewEventsHandler(bus, time.Hour, logrus.New()).StreamEventsHandler)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/events?zone=north", nil)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream"))
+
+	bus.Publish(ctx, events.Event{ID: "1", Type: events.TypeValidation, Tenant: identity.DefaultTenant, Device: "gate-02", Zone: "south"})
+	// Events of other tenants are not streamed.
+	bus.Publish(ctx, events.Event{ID: "2", Type: events.TypeValidation, Tenant: "site", Device: "gate-01", Zone: "north"})
+	bus.Publish(ctx, events.Event{ID: "3", Type: events.TypeValidation, Tenant: identity.DefaultTenant, Device: "gate-01", Zone: "north", Decision: events.DecisionMatch})
+
+	reader := bufio.NewReader(resp.Body)
+	fields := map[string]string{}
+	for len(fields) < 3 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), ":"); ok && key != "" {
+			fields[key] = value
+		}
+	}
+
+	assert.Equal(t, "3", fields["id"])
+	assert.Equal(t, string(events.TypeValidation), fields["event"])
+	var event events.Event
+	require.NoError(t, json.Unmarshal([]byte(fields["data"]), &event))
+	assert.Equal(t, "gate-01", event.Device)
+	assert.Equal(t, events.DecisionMatch, event.Decision)
+}
+
+func TestStreamEventsHandler_EndsWhenBusCloses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	bus := events.NewBus(4)
+	r := gin.New()
+	r.GET("/events", NewEventsHandler(bus, time.Hour, logrus.New()).StreamEventsHandler)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/events", nil)
+		r.ServeHTTP(w, req)
+	}()
+
+	// The handler may subscribe after Close; the subscription is then already closed.
+	time.Sleep(10 * time.Millisecond)
+	bus.Close()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stream did not end after the bus was closed")
+	}
+}
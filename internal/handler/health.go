@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+
+	"access-system-api/internal/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler defines the interface for liveness and readiness probes.
+type HealthHandler interface {
+	LiveHandler(c *gin.Context)
+	ReadyHandler(c *gin.Context)
+}
+
+// healthHandler implements the HealthHandler interface.
+type healthHandler struct {
+	checker *health.Checker
+}
+
+// NewHealthHandler creates a new instance of healthHandler.
+func NewHealthHandler(checker *health.Checker) HealthHandler {
+	return &healthHandler{checker: checker}
+}
+
+// LiveHandler reports that the process is running. It never checks dependencies.
+func (h *healthHandler) LiveHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// ReadyHandler reports whether the instance can serve traffic, with per-check details.
+func (h *healthHandler) ReadyHandler(c *gin.Context) {
+	if !h.checker.Ready() {
+		c.JSON(http.StatusServiceUnavailable, health.Report{Status: health.StatusFail, Checks: []health.Result{}})
+		return
+	}
+
+	report := h.checker.Run(c.Request.Context())
+	if report.Status == health.StatusFail {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
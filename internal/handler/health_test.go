@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"access-system-api/internal/health"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupHealthRouter(checker *health.Checker) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handler := NewHealthHandler(checker)
+	r.GET("/health/live", handler.LiveHandler)
+	r.GET("/health/ready", handler.ReadyHandler)
+	return r
+}
+
+func TestLiveHandler(t *testing.T) {
+	r := setupHealthRouter(health.NewChecker(time.Second))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/health/live", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestReadyHandler_Ready(t *testing.T) {
+	checker := health.NewChecker(time.Second, health.Check{
+		Name:     "database",
+		Critical: true,
+		Run: func(ctx context.Context) (map[string]any, error) {
+			return nil, nil
+		},
+	})
+	checker.SetReady(true)
+	r := setupHealthRouter(checker)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/health/ready", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var report health.Report
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, health.StatusOK, report.Status)
+	assert.Equal(t, "database", report.Checks[0].Name)
+}
+
+func TestReadyHandler_CheckFailed(t *testing.T) {
+	checker := health.NewChecker(time.Second, health.Check{
+		Name:     "database",
+		Critical: true,
+		Run: func(ctx context.Context) (map[string]any, error) {
+			return nil, assert.AnError
+		},
+	})
+	checker.SetReady(true)
+	r := setupHealthRouter(checker)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/health/ready", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestReadyHandler_ShuttingDown(t *testing.T) {
+	r := setupHealthRouter(health.NewChecker(time.Second))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/health/ready", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"access-system-api/internal/dto"
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/logging"
+	"access-system-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// LockoutHandler defines the interface for the device lockout administration API.
+type LockoutHandler interface {
+	ListLockoutsHandler(c *gin.Context)
+	ClearLockoutHandler(c *gin.Context)
+}
+
+// lockoutHandler implements the LockoutHandler interface.
+type lockoutHandler struct {
+	lockoutService service.LockoutService
+	log            *logrus.Logger
+}
+
+// NewLockoutHandler creates a new instance of lockoutHandler.
+func NewLockoutHandler(lockoutService service.LockoutService, log *logrus.Logger) LockoutHandler {
+	return &lockoutHandler{lockoutService: lockoutService, log: log}
+}
+
+// logger returns the request-scoped log entry, falling back to the handler logger.
+func (h *lockoutHandler) logger(ctx context.Context) *logrus.Entry {
+	return logging.FromContext(ctx, h.log)
+}
+
+// ListLockoutsHandler lists the devices that are currently locked out.
+func (h *lockoutHandler) ListLockoutsHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	lockouts, err := h.lockoutService.ListLockouts(ctx)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error listing lockouts")
+		httperr.Respond(c, err)
+		return
+	}
+
+	response := make([]dto.LockoutResponse, 0, len(lockouts))
+	for _, lockout := range lockouts {
+		response = append(response, dto.LockoutResponse{
+			Device:      lockout.Device,
+			Level:       lockout.Level,
+			LockedUntil: lockout.LockedUntil,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ClearLockoutHandler lifts the lockout of a device.
+func (h *lockoutHandler) ClearLockoutHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	device := c.Param("device")
+
+	if err := h.lockoutService.ClearLockout(ctx, device); err != nil {
+		h.logger(ctx).WithError(err).Error("Error clearing lockout")
+		httperr.Respond(c, err)
+		return
+	}
+
+	h.logger(ctx).WithField("locked_out_device", device).Info("Lockout cleared")
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/dto"
+	mocks "access-system-api/internal/mocks/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupLockoutRouter(t *testing.T) (*gin.Engine, *mocks.MockLockoutService) {
+	gin.SetMode(gin.TestMode)
+	service := mocks.NewMockLockoutService(gomock.NewController(t))
+	handler := NewLockoutHandler(service, logrus.New())
+
+	r := gin.New()
+	r.GET("/lockouts", handler.ListLockoutsHandler)
+	r.DELETE("/lockouts/:device", handler.ClearLockoutHandler)
+	return r, service
+}
+
+func TestListLockoutsHandler(t *testing.T) {
+	r, service := setupLockoutRouter(t)
+	until := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	service.EXPECT().ListLockouts(gomock.Any()).
+		Return([]*domain.Lockout{{Device: "gate-01", Level: 2, LockedUntil: until}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/lockouts", nil)
+
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response []dto.LockoutResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []dto.LockoutResponse{{Device: "gate-01", Level: 2, LockedUntil: until}}, response)
+}
+
+func TestListLockoutsHandler_Error(t *testing.T) {
+	r, service := setupLockoutRouter(t)
+	service.EXPECT().ListLockouts(gomock.Any()).Return(nil, errors.New("connection refused"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/lockouts", nil)
+
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestClearLockoutHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"cleared", nil, http.StatusOK},
+		{"not locked out", fmt.Errorf("%w: device gate-01 is not locked out", domain.ErrNotFound), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, service := setupLockoutRouter(t)
+			service.EXPECT().ClearLockout(gomock.Any(), "gate-01").Return(tt.err)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("DELETE", "/lockouts/gate-01", nil)
+
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
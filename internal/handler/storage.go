@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"access-system-api/internal/events"
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/logging"
+	"access-system-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultEventLimit and maxEventLimit bound the recorded events listed at once.
+const (
+	defaultEventLimit = 100
+	maxEventLimit     = 1000
+)
+
+// StorageHandler defines the interface for the event log and backups of the
+// bolt storage backend.
+type StorageHandler interface {
+	ListEventsHandler(c *gin.Context)
+	BackupHandler(c *gin.Context)
+}
+
+// storageHandler implements the StorageHandler interface.
+type storageHandler struct {
+	storageService service.StorageService
+	log            *logrus.Logger
+}
+
+// NewStorageHandler creates a new instance of storageHandler.
+func NewStorageHandler(storageService service.StorageService, log *logrus.Logger) StorageHandler {
+	return &storageHandler{storageService: storageService, log: log}
+}
+
+// logger returns the request-scoped log entry, falling back to the handler logger.
+func (h *storageHandler) logger(ctx context.Context) *logrus.Entry {
+	return logging.FromContext(ctx, h.log)
+}
+
+// ListEventsHandler lists the recorded access events, the most recent first.
+func (h *storageHandler) ListEventsHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	limit := defaultEventLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxEventLimit {
+			httperr.Respond(c, invalidInput("limit must be between 1 and "+strconv.Itoa(maxEventLimit)))
+			return
+		}
+		limit = parsed
+	}
+
+	list, err := h.storageService.ListEvents(ctx, limit)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error listing recorded events")
+		httperr.Respond(c, err)
+		return
+	}
+	if list == nil {
+		list = []events.Event{}
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// BackupHandler streams a consistent snapshot of the store. Once streaming
+// has started, errors can only be logged; clients detect them by the
+// truncated body.
+func (h *storageHandler) BackupHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	filename := fmt.Sprintf("access-%s.db", time.Now().UTC().Format("20060102T150405Z"))
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	size, err := h.storageService.Backup(ctx, c.Writer)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error writing backup")
+		return
+	}
+	h.logger(ctx).WithField("size_bytes", size).Info("Backup written")
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"access-system-api/internal/events"
+	mocks "access-system-api/internal/mocks/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStorageRouter(t *testing.T) (*gin.Engine, *mocks.MockStorageService) {
+	gin.SetMode(gin.TestMode)
+	service := mocks.NewMockStorageService(gomock.NewController(t))
+	handler := NewStorageHandler(service, logrus.New())
+
+	r := gin.New()
+	r.GET("/events/history", handler.ListEventsHandler)
+	r.GET("/backup", handler.BackupHandler)
+	return r, service
+}
+
+func TestListEventsHandler(t *testing.T) {
+	r, service := setupStorageRouter(t)
+
+	service.EXPECT().ListEvents(gomock.Any(), 100).Return(nil, nil)
+	service.EXPECT().ListEvents(gomock.Any(), 2).
+		Return([]events.Event{{ID: "2", Type: events.TypeValidation}, {ID: "1", Type: events.TypeEmbeddingCreated}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/events/history", nil)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[]`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/events/history?limit=2", nil)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response []events.Event
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response, 2)
+	assert.Equal(t, "2", response[0].ID)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/events/history?limit=1001", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestBackupHandler(t *testing.T) {
+	r, service := setupStorageRouter(t)
+
+	service.EXPECT().Backup(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, w io.Writer) (int64, error) {
+			n, err := io.WriteString(w, "snapshot")
+			return int64(n), err
+		})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/backup", nil)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/octet-stream", w.Header().Get("Content-Type"))
+	assert.Regexp(t, `^attachment; filename="access-\d{8}T\d{6}Z\.db"$`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "snapshot", w.Body.String())
+}
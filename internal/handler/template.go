@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"access-system-api/internal/dto"
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/logging"
+	"access-system-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// TemplateHandler defines the interface for the adaptive template administration API.
+type TemplateHandler interface {
+	ListSamplesHandler(c *gin.Context)
+	RevertSampleHandler(c *gin.Context)
+}
+
+// templateHandler implements the TemplateHandler interface.
+type templateHandler struct {
+	templateService service.TemplateService
+	log             *logrus.Logger
+}
+
+// NewTemplateHandler creates a new instance of templateHandler.
+func NewTemplateHandler(templateService service.TemplateService, log *logrus.Logger) TemplateHandler {
+	return &templateHandler{templateService: templateService, log: log}
+}
+
+// logger returns the request-scoped log entry, falling back to the handler logger.
+func (h *templateHandler) logger(ctx context.Context) *logrus.Entry {
+	return logging.FromContext(ctx, h.log)
+}
+
+// int64Param parses the named path parameter.
+func (h *templateHandler) int64Param(c *gin.Context, name string) (int64, bool) {
+	value, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil {
+		h.logger(c.Request.Context()).WithError(err).Errorf("Invalid %s parameter", name)
+		httperr.Respond(c, invalidInput("invalid "+name+" parameter"))
+		return 0, false
+	}
+	return value, true
+}
+
+// ListSamplesHandler lists the samples of an embedding template, including
+// the evicted, reverted and reset ones, oldest first.
+func (h *templateHandler) ListSamplesHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := h.int64Param(c, "id")
+	if !ok {
+		return
+	}
+
+	samples, err := h.templateService.ListSamples(ctx, id)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error listing embedding samples")
+		httperr.Respond(c, err)
+		return
+	}
+
+	response := make([]dto.EmbeddingSampleResponse, 0, len(samples))
+	for _, sample := range samples {
+		response = append(response, dto.EmbeddingSampleResponse{
+			ID:              sample.ID,
+			Source:          sample.Source,
+			Status:          sample.Status,
+			Accuracy:        sample.Accuracy,
+			Liveness:        sample.Liveness,
+			Device:          sample.Device,
+			RequestID:       sample.RequestID,
+			CreatedAt:       sample.CreatedAt,
+			StatusChangedAt: sample.StatusChangedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevertSampleHandler removes an adaptive sample from an embedding template
+// and recomputes its vector. With an If-Match header, only the version of the
+// embedding it names is changed.
+func (h *templateHandler) RevertSampleHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := h.int64Param(c, "id")
+	if !ok {
+		return
+	}
+	sampleID, ok := h.int64Param(c, "sample")
+	if !ok {
+		return
+	}
+
+	version, err := ifMatch(c)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Invalid If-Match header")
+		httperr.Respond(c, err)
+		return
+	}
+
+	version, err = h.templateService.RevertSample(ctx, id, sampleID, version)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error reverting embedding sample")
+		httperr.Respond(c, err)
+		return
+	}
+
+	c.Header("ETag", etag(version))
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"access-system-api/internal/domain"
+	mocks "access-system-api/internal/mocks/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupTemplateRouter(t *testing.T) (*gin.Engine, *mocks.MockTemplateService) {
+	gin.SetMode(gin.TestMode)
+	service := mocks.NewMockTemplateService(gomock.NewController(t))
+	handler := NewTemplateHandler(service, logrus.New())
+
+	r := gin.New()
+	r.POST("/embedding/:id/samples/:sample/revert", handler.RevertSampleHandler)
+	return r, service
+}
+
+func TestRevertSampleHandler_IfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		version int64
+		err     error
+		status  int
+	}{
+		{"unconditional", "", 0, nil, http.StatusOK},
+		{"current version", `"3"`, 3, nil, http.StatusOK},
+		{"stale version", `"2"`, 2, fmt.Errorf("%w: embedding 1 is at version 3", domain.ErrVersionConflict), http.StatusPreconditionFailed},
+		{"missing sample", `"3"`, 3, fmt.Errorf("%w: active adaptive sample 5 of embedding 1", domain.ErrNotFound), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, service := setupTemplateRouter(t)
+			var updated int64
+			if tt.err == nil {
+				updated = 4
+			}
+			service.EXPECT().RevertSample(gomock.Any(), int64(1), int64(5), tt.version).Return(updated, tt.err)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/embedding/1/samples/5/revert", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.err == nil {
+				assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+			}
+		})
+	}
+
+	// A malformed tag never reaches the service.
+	r, _ := setupTemplateRouter(t)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/embedding/1/samples/5/revert", nil)
+	req.Header.Set("If-Match", `W/"3"`)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
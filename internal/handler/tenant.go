@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/dto"
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/logging"
+	"access-system-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// TenantHandler defines the interface for the tenant registry super-admin API.
+type TenantHandler interface {
+	CreateTenantHandler(c *gin.Context)
+	GetTenantHandler(c *gin.Context)
+	ListTenantsHandler(c *gin.Context)
+	DeleteTenantHandler(c *gin.Context)
+}
+
+// tenantHandler implements the TenantHandler interface.
+type tenantHandler struct {
+	tenantService service.TenantService
+	log           *logrus.Logger
+}
+
+// NewTenantHandler creates a new instance of tenantHandler.
+func NewTenantHandler(tenantService service.TenantService, log *logrus.Logger) TenantHandler {
+	return &tenantHandler{tenantService: tenantService, log: log}
+}
+
+// logger returns the request-scoped log entry, falling back to the handler logger.
+func (h *tenantHandler) logger(ctx context.Context) *logrus.Entry {
+	return logging.FromContext(ctx, h.log)
+}
+
+// CreateTenantHandler registers a tenant. Devices whose certificate has the
+// tenant ID as organizational unit are accepted from then on.
+func (h *tenantHandler) CreateTenantHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var data dto.CreateTenantRequest
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.logger(ctx).WithError(err).Error("Error binding JSON")
+		httperr.Respond(c, invalidInput(err.Error()))
+		return
+	}
+
+	tenant, err := h.tenantService.CreateTenant(ctx, data.ID, data.Name)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error creating tenant")
+		httperr.Respond(c, err)
+		return
+	}
+
+	h.logger(ctx).WithField("tenant_id", tenant.ID).Info("Tenant created")
+	c.JSON(http.StatusCreated, tenantResponse(tenant))
+}
+
+func (h *tenantHandler) GetTenantHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	tenant, err := h.tenantService.GetTenant(ctx, c.Param("id"))
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error getting tenant")
+		httperr.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tenantResponse(tenant))
+}
+
+func (h *tenantHandler) ListTenantsHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	tenants, err := h.tenantService.ListTenants(ctx)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error listing tenants")
+		httperr.Respond(c, err)
+		return
+	}
+
+	response := make([]dto.TenantResponse, 0, len(tenants))
+	for _, tenant := range tenants {
+		response = append(response, tenantResponse(tenant))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteTenantHandler removes a tenant that has no embeddings, devices or
+// webhooks left. Its clients are rejected from then on.
+func (h *tenantHandler) DeleteTenantHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id := c.Param("id")
+	if err := h.tenantService.DeleteTenant(ctx, id); err != nil {
+		h.logger(ctx).WithError(err).Error("Error deleting tenant")
+		httperr.Respond(c, err)
+		return
+	}
+
+	h.logger(ctx).WithField("tenant_id", id).Info("Tenant deleted")
+	c.Status(http.StatusOK)
+}
+
+func tenantResponse(tenant *domain.Tenant) dto.TenantResponse {
+	return dto.TenantResponse{
+		ID:        tenant.ID,
+		Name:      tenant.Name,
+		CreatedAt: tenant.CreatedAt,
+	}
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/dto"
+	mocks "access-system-api/internal/mocks/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTenantRouter(t *testing.T) (*gin.Engine, *mocks.MockTenantService) {
+	gin.SetMode(gin.TestMode)
+	service := mocks.NewMockTenantService(gomock.NewController(t))
+	handler := NewTenantHandler(service, logrus.New())
+
+	r := gin.New()
+	r.POST("/tenants", handler.CreateTenantHandler)
+	r.GET("/tenants", handler.ListTenantsHandler)
+	r.GET("/tenants/:id", handler.GetTenantHandler)
+	r.DELETE("/tenants/:id", handler.DeleteTenantHandler)
+	return r, service
+}
+
+func TestCreateTenantHandler(t *testing.T) {
+	r, service := setupTenantRouter(t)
+
+	service.EXPECT().CreateTenant(gomock.Any(), "engineering", "Faculty of Engineering").
+		Return(&domain.Tenant{ID: "engineering", Name: "Faculty of Engineering"}, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{"id": "engineering", "name": "Faculty of Engineering"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tenants", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var response dto.TenantResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "engineering", response.ID)
+	assert.Equal(t, "Faculty of Engineering", response.Name)
+}
+
+func TestListTenantsHandler_Empty(t *testing.T) {
+	r, service := setupTenantRouter(t)
+
+	service.EXPECT().ListTenants(gomock.Any()).Return(nil, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tenants", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[]`, w.Body.String())
+}
+
+func TestDeleteTenantHandler_InUse(t *testing.T) {
+	r, service := setupTenantRouter(t)
+
+	service.EXPECT().DeleteTenant(gomock.Any(), "engineering").
+		Return(fmt.Errorf("%w: tenant engineering still has embeddings, devices or webhooks", domain.ErrConflict))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/tenants/engineering", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
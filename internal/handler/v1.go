@@ -2,12 +2,13 @@ package handler
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"net/http"
-	"time"
 
+	"access-system-api/internal/domain"
 	"access-system-api/internal/dto"
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/logging"
 	"access-system-api/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -35,30 +36,41 @@ func NewV1Handler(embeddingService service.EmbeddingService, log *logrus.Logger)
 	}
 }
 
+// logger returns the request-scoped log entry, falling back to the handler logger.
+func (h *v1Handler) logger(ctx context.Context) *logrus.Entry {
+	return logging.FromContext(ctx, h.log)
+}
+
 // AddEmbeddingHandler handles the addition of a new embedding.
 func (h *v1Handler) AddEmbeddingHandler(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	var data dto.AddEmbeddingRequest
 
 	if err := c.ShouldBindJSON(&data); err != nil {
-		h.log.Errorln("Error binding JSON:", err)
-		c.String(http.StatusBadRequest, "Bad Request: %v", err)
+		h.logger(ctx).WithError(err).Error("Error binding JSON")
+		httperr.Respond(c, invalidInput(err.Error()))
 		return
 	}
 
 	// Validate required fields
 	if data.Name == "" || len(data.Vector) == 0 {
-		h.log.Errorln("Missing required fields: name or vector")
-		c.String(http.StatusBadRequest, "Bad Request: name and vector are required")
+		h.logger(ctx).Error("Missing required fields: name or vector")
+		httperr.Respond(c, invalidInput("name and vector are required"))
+		return
+	}
+
+	ctx, err := withCapture(ctx, data.Capture)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Invalid capture")
+		httperr.Respond(c, err)
 		return
 	}
 
-	err := h.embeddingService.AddEmbedding(ctx, data.Name, data.Vector)
+	err = h.embeddingService.AddEmbedding(ctx, data.Name, data.Vector)
 	if err != nil {
-		h.log.Errorln("Error adding embedding:", err)
-		c.String(http.StatusInternalServerError, "Internal Server Error: %v", err)
+		h.logger(ctx).WithError(err).Error("Error adding embedding")
+		httperr.Respond(c, err)
 		return
 	}
 
@@ -67,70 +79,79 @@ func (h *v1Handler) AddEmbeddingHandler(c *gin.Context) {
 
 // ValidateEmbeddingHandler handles the validation of an embedding.
 func (h *v1Handler) ValidateEmbeddingHandler(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	var data dto.ValidateEmbeddingRequest
 
 	if err := c.ShouldBindJSON(&data); err != nil {
-		h.log.Errorln("Error binding JSON:", err)
-		c.String(http.StatusBadRequest, "Bad Request: %v", err)
+		h.logger(ctx).WithError(err).Error("Error binding JSON")
+		httperr.Respond(c, invalidInput(err.Error()))
 		return
 	}
 
 	// Validate required fields
 	if len(data.Vector) == 0 {
-		h.log.Errorln("Missing required field: vector")
-		c.String(http.StatusBadRequest, "Bad Request: vector is required")
+		h.logger(ctx).Error("Missing required field: vector")
+		httperr.Respond(c, invalidInput("vector is required"))
+		return
+	}
+
+	ctx, err := withCapture(ctx, data.Capture)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Invalid capture")
+		httperr.Respond(c, err)
 		return
 	}
 
 	embedding, err := h.embeddingService.ValidateEmbedding(ctx, data.Vector)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			h.log.Infoln("No relevant matches found:", err)
-			c.Status(http.StatusNotFound)
-			return
+		switch {
+		case errors.Is(err, domain.ErrNoMatch):
+			h.logger(ctx).Info("No relevant matches found")
+		case errors.Is(err, domain.ErrCaptureRejected):
+			h.logger(ctx).WithError(err).Info("Capture rejected")
+		default:
+			h.logger(ctx).WithError(err).Error("Error validating embedding")
 		}
-		h.log.Errorln("Error validating embedding:", err)
-		c.Status(http.StatusInternalServerError)
+		httperr.Respond(c, err)
 		return
 	}
 
-	h.log.Infoln("Relevant match found")
-	c.JSON(http.StatusOK, gin.H{
-		"id":       embedding.ID,
-		"name":     embedding.Name,
-		"vector":   embedding.Vector,
-		"accuracy": embedding.Accuracy,
+	h.logger(ctx).WithFields(logrus.Fields{
+		"embedding_id": embedding.ID,
+		"accuracy":     embedding.Accuracy,
+	}).Info("Relevant match found")
+	c.JSON(http.StatusOK, dto.ValidateEmbeddingResponse{
+		ID:       embedding.ID,
+		Name:     embedding.Name,
+		Vector:   embedding.Vector.Slice(),
+		Accuracy: embedding.Accuracy,
 	})
-	return
 }
 
 // DeleteEmbeddingHandler handles the deletion of an embedding.
 func (h *v1Handler) DeleteEmbeddingHandler(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	var data dto.DeleteEmbeddingRequest
 
 	if err := c.ShouldBindJSON(&data); err != nil {
-		h.log.Errorln("Error binding JSON:", err)
-		c.String(http.StatusBadRequest, "Bad Request: %v", err)
+		h.logger(ctx).WithError(err).Error("Error binding JSON")
+		httperr.Respond(c, invalidInput(err.Error()))
 		return
 	}
 
 	// Validate required fields
 	if data.ID == 0 {
-		h.log.Errorln("Missing required field: id")
-		c.String(http.StatusBadRequest, "Bad Request: id is required")
+		h.logger(ctx).Error("Missing required field: id")
+		httperr.Respond(c, invalidInput("id is required"))
 		return
 	}
 
-	err := h.embeddingService.DeleteEmbedding(ctx, data.ID)
+	err := h.embeddingService.DeleteEmbedding(ctx, data.ID, 0)
 	if err != nil {
-		h.log.Errorln("Error deleting embedding:", err)
-		c.String(http.StatusInternalServerError, "Internal Server Error: %v", err)
+		h.logger(ctx).WithError(err).Error("Error deleting embedding")
+		httperr.Respond(c, err)
 		return
 	}
 
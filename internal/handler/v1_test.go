@@ -2,13 +2,16 @@ package handler
 
 import (
 	"bytes"
-	"database/sql"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"access-system-api/internal/capture"
 	"access-system-api/internal/domain"
+	"access-system-api/internal/httperr"
 	mocks "access-system-api/internal/mocks/service"
 
 	"github.com/gin-gonic/gin"
@@ -111,12 +114,17 @@ func TestAddEmbeddingHandler_InvalidVectorSize(t *testing.T) {
 		"name":   "test",
 		"vector": vector,
 	})
-	service.EXPECT().AddEmbedding(gomock.Any(), "test", vector).Return(assert.AnError)
+	service.EXPECT().AddEmbedding(gomock.Any(), "test", vector).Return(fmt.Errorf("%w: vector size must be 512, got 100", domain.ErrInvalidDimension))
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("POST", "/add", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	r.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var errBody httperr.Body
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &errBody))
+	assert.Equal(t, httperr.CodeInvalidDimension, errBody.Error.Code)
+	assert.Equal(t, "invalid vector dimension: vector size must be 512, got 100", errBody.Error.Message)
 }
 
 func TestValidateEmbeddingHandler_Success(t *testing.T) {
@@ -209,12 +217,16 @@ func TestValidateEmbeddingHandler_NotFound(t *testing.T) {
 		"vector": vector,
 	})
 	// Return not found error
-	service.EXPECT().ValidateEmbedding(gomock.Any(), vector).Return(nil, sql.ErrNoRows)
+	service.EXPECT().ValidateEmbedding(gomock.Any(), vector).Return(nil, domain.ErrNoMatch)
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("POST", "/validate", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var errBody httperr.Body
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &errBody))
+	assert.Equal(t, httperr.CodeNoMatch, errBody.Error.Code)
 }
 
 func TestValidateEmbeddingHandler_InvalidVectorSize(t *testing.T) {
@@ -230,12 +242,12 @@ func TestValidateEmbeddingHandler_InvalidVectorSize(t *testing.T) {
 		"vector": vector,
 	})
 	// Return size validation error
-	service.EXPECT().ValidateEmbedding(gomock.Any(), vector).Return(nil, assert.AnError)
+	service.EXPECT().ValidateEmbedding(gomock.Any(), vector).Return(nil, fmt.Errorf("%w: vector size must be 512, got 100", domain.ErrInvalidDimension))
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("POST", "/validate", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	r.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
 func TestDeleteEmbeddingHandler_Success(t *testing.T) {
@@ -251,7 +263,7 @@ func TestDeleteEmbeddingHandler_Success(t *testing.T) {
 		"id": int64(123),
 	})
 
-	service.EXPECT().DeleteEmbedding(gomock.Any(), int64(123)).Return(nil)
+	service.EXPECT().DeleteEmbedding(gomock.Any(), int64(123), int64(0)).Return(nil)
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("POST", "/delete", bytes.NewReader(body))
@@ -288,10 +300,66 @@ func TestDeleteEmbeddingHandler_ServiceError(t *testing.T) {
 	body, _ := json.Marshal(map[string]interface{}{
 		"id": int64(123),
 	})
-	service.EXPECT().DeleteEmbedding(gomock.Any(), int64(123)).Return(assert.AnError)
+	service.EXPECT().DeleteEmbedding(gomock.Any(), int64(123), int64(0)).Return(assert.AnError)
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("POST", "/delete", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	// Internal errors must not leak to the client
+	var errBody httperr.Body
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &errBody))
+	assert.Equal(t, httperr.CodeInternal, errBody.Error.Code)
+	assert.NotContains(t, w.Body.String(), assert.AnError.Error())
+}
+
+func TestValidateEmbeddingHandler_Capture(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	service := mocks.NewMockEmbeddingService(ctrl)
+	handler := NewV1Handler(service, logrus.New())
+	r := setupRouter(handler)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"vector":           []float32{0.1},
+		"liveness":         0.4,
+		"quality":          0.9,
+		"capture_metadata": map[string]string{"camera": "front"},
+	})
+	service.EXPECT().ValidateEmbedding(gomock.Any(), []float32{0.1}).
+		DoAndReturn(func(ctx context.Context, _ []float32) (*domain.Embedding, error) {
+			captured := capture.FromContext(ctx)
+			assert.Equal(t, 0.4, *captured.Liveness)
+			assert.Equal(t, 0.9, *captured.Quality)
+			assert.Equal(t, map[string]string{"camera": "front"}, captured.Metadata)
+			return nil, fmt.Errorf("%w: liveness 0.4 is below the minimum 0.8", domain.ErrCaptureRejected)
+		})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/validate", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	var errBody httperr.Body
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &errBody))
+	assert.Equal(t, httperr.CodeCaptureRejected, errBody.Error.Code)
+}
+
+func TestValidateEmbeddingHandler_InvalidCapture(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	service := mocks.NewMockEmbeddingService(ctrl)
+	handler := NewV1Handler(service, logrus.New())
+	r := setupRouter(handler)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"vector":   []float32{0.1},
+		"liveness": 1.5,
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/validate", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
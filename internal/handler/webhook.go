@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/dto"
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/logging"
+	"access-system-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultDeliveryLimit and maxDeliveryLimit bound the deliveries listed at once.
+const (
+	defaultDeliveryLimit = 100
+	maxDeliveryLimit     = 1000
+)
+
+// WebhookHandler defines the interface for the webhook administration API.
+type WebhookHandler interface {
+	CreateWebhookHandler(c *gin.Context)
+	GetWebhookHandler(c *gin.Context)
+	ListWebhooksHandler(c *gin.Context)
+	DeleteWebhookHandler(c *gin.Context)
+	ListDeliveriesHandler(c *gin.Context)
+	RedeliverHandler(c *gin.Context)
+}
+
+// webhookHandler implements the WebhookHandler interface.
+type webhookHandler struct {
+	webhookService service.WebhookService
+	log            *logrus.Logger
+}
+
+// NewWebhookHandler creates a new instance of webhookHandler.
+func NewWebhookHandler(webhookService service.WebhookService, log *logrus.Logger) WebhookHandler {
+	return &webhookHandler{webhookService: webhookService, log: log}
+}
+
+// logger returns the request-scoped log entry, falling back to the handler logger.
+func (h *webhookHandler) logger(ctx context.Context) *logrus.Entry {
+	return logging.FromContext(ctx, h.log)
+}
+
+// idParam parses the id path parameter.
+func (h *webhookHandler) idParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger(c.Request.Context()).WithError(err).Error("Invalid ID parameter")
+		httperr.Respond(c, invalidInput("invalid ID parameter"))
+		return 0, false
+	}
+	return id, true
+}
+
+// CreateWebhookHandler registers a subscription and returns it with its signing secret.
+func (h *webhookHandler) CreateWebhookHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var data dto.CreateWebhookRequest
+
+	if err := c.ShouldBindJSON(&data); err != nil {
+		h.logger(ctx).WithError(err).Error("Error binding JSON")
+		httperr.Respond(c, invalidInput(err.Error()))
+		return
+	}
+
+	subscription, err := h.webhookService.CreateSubscription(ctx, data.URL, data.EventTypes, data.Secret)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error creating webhook subscription")
+		httperr.Respond(c, err)
+		return
+	}
+
+	h.logger(ctx).WithField("subscription_id", subscription.ID).Info("Webhook subscription created")
+	response := webhookResponse(subscription)
+	response.Secret = subscription.Secret
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *webhookHandler) GetWebhookHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := h.idParam(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.webhookService.GetSubscription(ctx, id)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error getting webhook subscription")
+		httperr.Respond(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookResponse(subscription))
+}
+
+func (h *webhookHandler) ListWebhooksHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	subscriptions, err := h.webhookService.ListSubscriptions(ctx)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error listing webhook subscriptions")
+		httperr.Respond(c, err)
+		return
+	}
+
+	response := make([]dto.WebhookResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		response = append(response, webhookResponse(subscription))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteWebhookHandler removes a subscription together with its pending and dead deliveries.
+func (h *webhookHandler) DeleteWebhookHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := h.idParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(ctx, id); err != nil {
+		h.logger(ctx).WithError(err).Error("Error deleting webhook subscription")
+		httperr.Respond(c, err)
+		return
+	}
+
+	h.logger(ctx).WithField("subscription_id", id).Info("Webhook subscription deleted")
+	c.Status(http.StatusOK)
+}
+
+// ListDeliveriesHandler lists deliveries, most recently updated first. The
+// dead-letter list is status=dead.
+func (h *webhookHandler) ListDeliveriesHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	limit := defaultDeliveryLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxDeliveryLimit {
+			httperr.Respond(c, invalidInput("limit must be between 1 and "+strconv.Itoa(maxDeliveryLimit)))
+			return
+		}
+		limit = parsed
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(ctx, c.Query("status"), limit)
+	if err != nil {
+		h.logger(ctx).WithError(err).Error("Error listing webhook deliveries")
+		httperr.Respond(c, err)
+		return
+	}
+
+	response := make([]dto.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		response = append(response, deliveryResponse(delivery))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RedeliverHandler schedules a delivery, typically a dead-lettered one, for immediate redelivery.
+func (h *webhookHandler) RedeliverHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, ok := h.idParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.RedeliverDelivery(ctx, id); err != nil {
+		h.logger(ctx).WithError(err).Error("Error scheduling webhook redelivery")
+		httperr.Respond(c, err)
+		return
+	}
+
+	h.logger(ctx).WithField("delivery_id", id).Info("Webhook redelivery scheduled")
+	c.Status(http.StatusAccepted)
+}
+
+func webhookResponse(subscription *domain.WebhookSubscription) dto.WebhookResponse {
+	return dto.WebhookResponse{
+		ID:         subscription.ID,
+		URL:        subscription.URL,
+		EventTypes: subscription.EventTypes,
+		CreatedAt:  subscription.CreatedAt,
+	}
+}
+
+func deliveryResponse(delivery *domain.WebhookDelivery) dto.WebhookDeliveryResponse {
+	return dto.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		UpdatedAt:      delivery.UpdatedAt,
+	}
+}
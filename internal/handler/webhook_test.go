@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/dto"
+	mocks "access-system-api/internal/mocks/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupWebhookRouter(t *testing.T) (*gin.Engine, *mocks.MockWebhookService) {
+	gin.SetMode(gin.TestMode)
+	service := mocks.NewMockWebhookService(gomock.NewController(t))
+	handler := NewWebhookHandler(service, logrus.New())
+
+	r := gin.New()
+	r.POST("/webhooks", handler.CreateWebhookHandler)
+	r.GET("/webhooks", handler.ListWebhooksHandler)
+	r.GET("/webhooks/:id", handler.GetWebhookHandler)
+	r.GET("/webhooks/deliveries", handler.ListDeliveriesHandler)
+	r.POST("/webhooks/deliveries/:id/redeliver", handler.RedeliverHandler)
+	return r, service
+}
+
+func TestCreateWebhookHandler_ReturnsSecret(t *testing.T) {
+	r, service := setupWebhookRouter(t)
+
+	service.EXPECT().CreateSubscription(gomock.Any(), "https://example.com/hook", []string{domain.WebhookAccessDenied}, "").
+		Return(&domain.WebhookSubscription{
+			ID:         1,
+			URL:        "https://example.com/hook",
+			EventTypes: []string{domain.WebhookAccessDenied},
+			Secret:     "generated-secret",
+		}, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"url":         "https://example.com/hook",
+		"event_types": []string{domain.WebhookAccessDenied},
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/webhooks", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var response dto.WebhookResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, int64(1), response.ID)
+	assert.Equal(t, "generated-secret", response.Secret)
+}
+
+func TestGetWebhookHandler_HidesSecret(t *testing.T) {
+	r, service := setupWebhookRouter(t)
+
+	service.EXPECT().GetSubscription(gomock.Any(), int64(1)).
+		Return(&domain.WebhookSubscription{ID: 1, Secret: "secret"}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/webhooks/1", nil)
+
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret")
+}
+
+func TestGetWebhookHandler_NotFound(t *testing.T) {
+	r, service := setupWebhookRouter(t)
+
+	service.EXPECT().GetSubscription(gomock.Any(), int64(1)).Return(nil, domain.ErrNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/webhooks/1", nil)
+
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestListDeliveriesHandler(t *testing.T) {
+	r, service := setupWebhookRouter(t)
+
+	service.EXPECT().ListDeliveries(gomock.Any(), domain.DeliveryDead, 5).
+		Return([]*domain.WebhookDelivery{{ID: 3, Status: domain.DeliveryDead, Attempts: 10}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/webhooks/deliveries?status=dead&limit=5", nil)
+
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response []dto.WebhookDeliveryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response, 1)
+	assert.Equal(t, int64(3), response[0].ID)
+}
+
+func TestListDeliveriesHandler_InvalidLimit(t *testing.T) {
+	r, _ := setupWebhookRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/webhooks/deliveries?limit=0", nil)
+
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRedeliverHandler(t *testing.T) {
+	r, service := setupWebhookRouter(t)
+
+	service.EXPECT().RedeliverDelivery(gomock.Any(), int64(3)).Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/webhooks/deliveries/3/redeliver", nil)
+
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+}
@@ -0,0 +1,67 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func okCheck(name string, critical bool) Check {
+	return Check{Name: name, Critical: critical, Run: func(ctx context.Context) (map[string]any, error) {
+		return map[string]any{"name": name}, nil
+	}}
+}
+
+func failingCheck(name string, critical bool) Check {
+	return Check{Name: name, Critical: critical, Run: func(ctx context.Context) (map[string]any, error) {
+		return nil, assert.AnError
+	}}
+}
+
+func TestChecker_AllOK(t *testing.T) {
+	checker := NewChecker(time.Second, okCheck("database", true), okCheck("vector_index", false))
+
+	report := checker.Run(context.Background())
+	assert.Equal(t, StatusOK, report.Status)
+	assert.Len(t, report.Checks, 2)
+	assert.Equal(t, "database", report.Checks[0].Name)
+	assert.Equal(t, map[string]any{"name": "database"}, report.Checks[0].Details)
+}
+
+func TestChecker_NonCriticalFailureDegrades(t *testing.T) {
+	checker := NewChecker(time.Second, okCheck("database", true), failingCheck("vector_index", false))
+
+	report := checker.Run(context.Background())
+	assert.Equal(t, StatusDegraded, report.Status)
+	assert.Equal(t, StatusFail, report.Checks[1].Status)
+	assert.Equal(t, assert.AnError.Error(), report.Checks[1].Error)
+}
+
+func TestChecker_CriticalFailureFails(t *testing.T) {
+	checker := NewChecker(time.Second, failingCheck("database", true), failingCheck("vector_index", false))
+
+	report := checker.Run(context.Background())
+	assert.Equal(t, StatusFail, report.Status)
+}
+
+func TestChecker_Timeout(t *testing.T) {
+	slow := Check{Name: "database", Critical: true, Run: func(ctx context.Context) (map[string]any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}
+	checker := NewChecker(10*time.Millisecond, slow)
+
+	report := checker.Run(context.Background())
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks[0].Error)
+}
+
+func TestChecker_Ready(t *testing.T) {
+	checker := NewChecker(time.Second)
+	assert.False(t, checker.Ready())
+
+	checker.SetReady(true)
+	assert.True(t, checker.Ready())
+}
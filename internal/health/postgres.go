@@ -0,0 +1,101 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// SchemaVersion is the database schema version this build of the server expects.
+const SchemaVersion = 13
+
+// vectorIndexName is the name of the approximate similarity search index
+// that older schemas had on the embedding table.
+const vectorIndexName = "embedding_vector_idx"
+
+// PostgresChecks returns the readiness checks for the PostgreSQL database.
+func PostgresChecks(db *sql.DB) []Check {
+	return []Check{
+		{Name: "database", Critical: true, Run: databaseCheck(db)},
+		{Name: "schema", Critical: true, Run: schemaCheck(db)},
+		{Name: "pgvector", Critical: true, Run: pgvectorCheck(db)},
+		{Name: "vector_index", Critical: false, Run: vectorIndexCheck(db)},
+	}
+}
+
+// databaseCheck verifies connectivity and reports the connection pool usage.
+func databaseCheck(db *sql.DB) func(ctx context.Context) (map[string]any, error) {
+	return func(ctx context.Context) (map[string]any, error) {
+		if err := db.PingContext(ctx); err != nil {
+			return nil, err
+		}
+		stats := db.Stats()
+		return map[string]any{
+			"open_connections": stats.OpenConnections,
+			"in_use":           stats.InUse,
+			"idle":             stats.Idle,
+		}, nil
+	}
+}
+
+// schemaCheck verifies that the database schema is at least at SchemaVersion.
+func schemaCheck(db *sql.DB) func(ctx context.Context) (map[string]any, error) {
+	return func(ctx context.Context) (map[string]any, error) {
+		const query = "SELECT COALESCE(MAX(version), 0) FROM schema_migrations"
+		var version int64
+		if err := db.QueryRowContext(ctx, query).Scan(&version); err != nil {
+			return nil, err
+		}
+		details := map[string]any{"version": version, "expected": SchemaVersion}
+		if version < SchemaVersion {
+			return details, fmt.Errorf("schema version %d is older than expected %d", version, SchemaVersion)
+		}
+		return details, nil
+	}
+}
+
+// pgvectorCheck verifies that the vector extension is installed.
+func pgvectorCheck(db *sql.DB) func(ctx context.Context) (map[string]any, error) {
+	return func(ctx context.Context) (map[string]any, error) {
+		const query = "SELECT extversion FROM pg_extension WHERE extname = 'vector'"
+		var version string
+		err := db.QueryRowContext(ctx, query).Scan(&version)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("vector extension is not installed")
+		}
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"version": version}, nil
+	}
+}
+
+// vectorIndexCheck reports whether similarity searches are exact. Without an
+// index they are; an approximate index left over from an older schema must at
+// least be usable.
+func vectorIndexCheck(db *sql.DB) func(ctx context.Context) (map[string]any, error) {
+	return func(ctx context.Context) (map[string]any, error) {
+		const query = `SELECT am.amname, i.indisvalid, i.indisready
+			FROM pg_index i
+			JOIN pg_class c ON c.oid = i.indexrelid
+			JOIN pg_am am ON am.oid = c.relam
+			WHERE c.relname = $1`
+		var (
+			method       string
+			valid, ready bool
+		)
+		err := db.QueryRowContext(ctx, query, vectorIndexName).Scan(&method, &valid, &ready)
+		if errors.Is(err, sql.ErrNoRows) {
+			return map[string]any{"search": "exact"}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		details := map[string]any{"search": "approximate", "index": vectorIndexName, "method": method, "valid": valid, "ready": ready}
+		if !valid || !ready {
+			return details, fmt.Errorf("index %s is not valid or not ready", vectorIndexName)
+		}
+		return details, nil
+	}
+}
@@ -0,0 +1,90 @@
+package httperr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Stable error codes returned to clients.
+const (
+	CodeInvalidInput     = "invalid_input"
+	CodeInvalidDimension = "invalid_dimension"
+	CodeInvalidVector    = "invalid_vector"
+	CodeUnauthorized     = "unauthorized"
+	CodeReplayed         = "replayed_request"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeNoMatch          = "no_match"
+	CodeConflict         = "conflict"
+	CodeVersionConflict  = "precondition_failed"
+	CodeCaptureRejected  = "capture_rejected"
+	CodeRateLimited      = "rate_limited"
+	CodeLockedOut        = "locked_out"
+	CodeTimeout          = "timeout"
+	CodeInternal         = "internal_error"
+)
+
+// Body is the JSON error envelope returned by every endpoint.
+type Body struct {
+	Error Detail `json:"error"`
+}
+
+// Detail describes a single error.
+type Detail struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// mapping associates a domain error with its HTTP status and error code.
+type mapping struct {
+	err    error
+	status int
+	code   string
+}
+
+var mappings = []mapping{
+	{domain.ErrInvalidInput, http.StatusBadRequest, CodeInvalidInput},
+	{domain.ErrInvalidDimension, http.StatusBadRequest, CodeInvalidDimension},
+	{domain.ErrInvalidVector, http.StatusBadRequest, CodeInvalidVector},
+	{domain.ErrUnauthorized, http.StatusUnauthorized, CodeUnauthorized},
+	{domain.ErrReplayed, http.StatusUnauthorized, CodeReplayed},
+	{domain.ErrForbidden, http.StatusForbidden, CodeForbidden},
+	{domain.ErrNoMatch, http.StatusNotFound, CodeNoMatch},
+	{domain.ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{domain.ErrConflict, http.StatusConflict, CodeConflict},
+	{domain.ErrVersionConflict, http.StatusPreconditionFailed, CodeVersionConflict},
+	{domain.ErrCaptureRejected, http.StatusUnprocessableEntity, CodeCaptureRejected},
+	{domain.ErrRateLimited, http.StatusTooManyRequests, CodeRateLimited},
+	{domain.ErrLockedOut, http.StatusTooManyRequests, CodeLockedOut},
+	{context.DeadlineExceeded, http.StatusGatewayTimeout, CodeTimeout},
+}
+
+// Respond aborts the request with the status and error envelope matching err.
+// Messages of unknown errors are not returned to the client.
+func Respond(c *gin.Context, err error) {
+	var retry *domain.RetryAfterError
+	if errors.As(err, &retry) {
+		c.Header("Retry-After", strconv.Itoa(retry.Seconds()))
+	}
+	status, detail := Resolve(err)
+	detail.RequestID = logging.RequestIDFromContext(c.Request.Context())
+	c.AbortWithStatusJSON(status, Body{Error: detail})
+}
+
+// Resolve returns the HTTP status and error detail matching err.
+func Resolve(err error) (int, Detail) {
+	for _, m := range mappings {
+		if errors.Is(err, m.err) {
+			return m.status, Detail{Code: m.code, Message: err.Error()}
+		}
+	}
+	return http.StatusInternalServerError, Detail{Code: CodeInternal, Message: "internal server error"}
+}
@@ -0,0 +1,90 @@
+package httperr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"invalid input", fmt.Errorf("%w: id is required", domain.ErrInvalidInput), http.StatusBadRequest, CodeInvalidInput},
+		{"invalid dimension", fmt.Errorf("%w: got 100", domain.ErrInvalidDimension), http.StatusBadRequest, CodeInvalidDimension},
+		{"invalid vector", fmt.Errorf("%w: vector[3] is not a finite number", domain.ErrInvalidVector), http.StatusBadRequest, CodeInvalidVector},
+		{"unauthorized", fmt.Errorf("%w: request signature is required", domain.ErrUnauthorized), http.StatusUnauthorized, CodeUnauthorized},
+		{"replayed", domain.ErrReplayed, http.StatusUnauthorized, CodeReplayed},
+		{"forbidden", domain.ErrForbidden, http.StatusForbidden, CodeForbidden},
+		{"not found", fmt.Errorf("%w: embedding 1", domain.ErrNotFound), http.StatusNotFound, CodeNotFound},
+		{"no match", domain.ErrNoMatch, http.StatusNotFound, CodeNoMatch},
+		{"conflict", domain.ErrConflict, http.StatusConflict, CodeConflict},
+		{"version conflict", fmt.Errorf("%w: embedding 1 is at version 3", domain.ErrVersionConflict), http.StatusPreconditionFailed, CodeVersionConflict},
+		{"capture rejected", fmt.Errorf("%w: liveness 0.4 is below the minimum 0.8", domain.ErrCaptureRejected), http.StatusUnprocessableEntity, CodeCaptureRejected},
+		{"rate limited", domain.RetryAfter(domain.ErrRateLimited, time.Second), http.StatusTooManyRequests, CodeRateLimited},
+		{"locked out", domain.RetryAfter(fmt.Errorf("%w: gate-01", domain.ErrLockedOut), time.Minute), http.StatusTooManyRequests, CodeLockedOut},
+		{"timeout", fmt.Errorf("query: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, CodeTimeout},
+		{"unknown", assert.AnError, http.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, detail := Resolve(tt.err)
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.code, detail.Code)
+		})
+	}
+}
+
+func TestRespond_Envelope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), "req-1"))
+
+	Respond(c, fmt.Errorf("%w: embedding 7", domain.ErrNotFound))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	var body Body
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, Detail{Code: CodeNotFound, Message: "not found: embedding 7", RequestID: "req-1"}, body.Error)
+}
+
+func TestRespond_HidesInternalErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	Respond(c, fmt.Errorf("pq: relation \"embedding\" does not exist"))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "relation")
+}
+
+func TestRespond_RetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	Respond(c, domain.RetryAfter(domain.ErrRateLimited, 1500*time.Millisecond))
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "rate limit exceeded, retry after 2s")
+}
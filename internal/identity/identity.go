@@ -0,0 +1,136 @@
+package identity
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+)
+
+// UnknownDevice is reported when the client certificate subject is not available.
+const UnknownDevice = "unknown"
+
+type deviceKey struct{}
+
+// WithDevice returns a copy of ctx carrying the given device identifier.
+func WithDevice(ctx context.Context, device string) context.Context {
+	return context.WithValue(ctx, deviceKey{}, device)
+}
+
+// DeviceFromContext returns the device identifier stored in ctx, or UnknownDevice.
+func DeviceFromContext(ctx context.Context) string {
+	if device, ok := ctx.Value(deviceKey{}).(string); ok && device != "" {
+		return device
+	}
+	return UnknownDevice
+}
+
+// DeviceFromSubject extracts the common name from a client certificate subject DN
+// as forwarded by Nginx (e.g. "CN=gate-01,O=University").
+func DeviceFromSubject(subject string) string {
+	return attribute(subject, "CN", UnknownDevice)
+}
+
+// DefaultTenant is the tenant of clients whose certificate subject names no
+// tenant, and of the data stored before tenants were introduced.
+const DefaultTenant = "default"
+
+type tenantKey struct{}
+
+// WithTenant returns a copy of ctx carrying the given tenant identifier.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant identifier stored in ctx, or DefaultTenant.
+func TenantFromContext(ctx context.Context) string {
+	if tenant, ok := ctx.Value(tenantKey{}).(string); ok && tenant != "" {
+		return tenant
+	}
+	return DefaultTenant
+}
+
+// TenantFromSubject extracts the tenant, the organizational unit, from a
+// client certificate subject DN (e.g. "CN=gate-01,OU=engineering,O=University").
+// Subjects without organizational unit belong to DefaultTenant.
+func TenantFromSubject(subject string) string {
+	return attribute(subject, "OU", DefaultTenant)
+}
+
+// Client identifies a client across tenants as <tenant>/<device>, e.g.
+// "engineering/gate-01", since device names are only unique within a tenant.
+func Client(tenant, device string) string {
+	return tenant + "/" + device
+}
+
+// attribute returns the first non-empty value of the named attribute of a
+// subject DN, or fallback. Values are unescaped as in RFC 2253, so a common
+// name like "gate\,OU=other" cannot pass for another organizational unit. A
+// malformed subject has no attributes.
+func attribute(subject, name, fallback string) string {
+	attributes, ok := parseDN(subject)
+	if !ok {
+		return fallback
+	}
+	for _, attr := range attributes {
+		if strings.EqualFold(attr.key, name) && attr.value != "" {
+			return attr.value
+		}
+	}
+	return fallback
+}
+
+// dnAttribute is an attribute type and value of a subject DN.
+type dnAttribute struct {
+	key, value string
+}
+
+// parseDN splits an RFC 2253 subject DN, as forwarded by Nginx, into its
+// attributes. RDNs are separated by "," and the attributes of multi-valued
+// RDNs by "+". Values may escape special characters with "\", as "\," or as
+// hex pairs like "\2C", or be quoted.
+func parseDN(subject string) ([]dnAttribute, bool) {
+	var (
+		attributes []dnAttribute
+		key        string
+		value      strings.Builder
+		inValue    bool
+		quoted     bool
+	)
+	for i := 0; i < len(subject); i++ {
+		ch := subject[i]
+		switch {
+		case !inValue && ch == '=':
+			key, inValue = strings.TrimSpace(value.String()), true
+			value.Reset()
+		case ch == '\\':
+			if i+1 >= len(subject) {
+				return nil, false
+			}
+			if b, err := hex.DecodeString(subject[i+1 : min(i+3, len(subject))]); err == nil && len(b) == 1 {
+				value.WriteByte(b[0])
+				i += 2
+			} else {
+				value.WriteByte(subject[i+1])
+				i++
+			}
+		case inValue && ch == '"':
+			quoted = !quoted
+		case !quoted && (ch == ',' || ch == '+'):
+			if !inValue {
+				return nil, false
+			}
+			attributes = append(attributes, dnAttribute{key: key, value: strings.TrimSpace(value.String())})
+			value.Reset()
+			inValue = false
+		default:
+			value.WriteByte(ch)
+		}
+	}
+	if quoted || (!inValue && strings.TrimSpace(value.String()) != "") {
+		return nil, false
+	}
+	if inValue {
+		attributes = append(attributes, dnAttribute{key: key, value: strings.TrimSpace(value.String())})
+	}
+	return attributes, true
+}
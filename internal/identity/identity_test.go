@@ -0,0 +1,38 @@
+package identity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromSubject(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject string
+		device  string
+		tenant  string
+	}{
+		{"device and tenant", "CN=gate-01,OU=engineering,O=University", "gate-01", "engineering"},
+		{"no tenant", "CN=gate-01,O=University", "gate-01", DefaultTenant},
+		{"spaces", "CN = gate-01, OU = engineering", "gate-01", "engineering"},
+		{"lower case types", "cn=gate-01,ou=engineering", "gate-01", "engineering"},
+		{"escaped comma", `CN=gate-01\,OU=law,OU=engineering`, "gate-01,OU=law", "engineering"},
+		{"escaped comma without tenant", `CN=gate-01\,OU=law,O=University`, "gate-01,OU=law", DefaultTenant},
+		{"hex escape", `CN=gate-01\2COU=law`, "gate-01,OU=law", DefaultTenant},
+		{"utf-8 hex escapes", `CN=t\C3\BCr-01,OU=law`, "tür-01", "law"},
+		{"quoted value", `CN="gate-01,OU=law",OU=engineering`, "gate-01,OU=law", "engineering"},
+		{"multi-valued RDN", "CN=gate-01+OU=engineering,O=University", "gate-01", "engineering"},
+		{"empty", "", UnknownDevice, DefaultTenant},
+		{"unterminated quote", `CN="gate-01,OU=law`, UnknownDevice, DefaultTenant},
+		{"trailing backslash", `CN=gate-01\`, UnknownDevice, DefaultTenant},
+		{"attribute without value", "CN=gate-01,engineering", UnknownDevice, DefaultTenant},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.device, DeviceFromSubject(tt.subject))
+			assert.Equal(t, tt.tenant, TenantFromSubject(tt.subject))
+		})
+	}
+}
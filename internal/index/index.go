@@ -0,0 +1,258 @@
+// Package index provides an in-memory exact nearest neighbour index of
+// embeddings by cosine similarity.
+package index
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"access-system-api/internal/domain"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// entry is an indexed embedding with its vector scaled to unit length.
+type entry struct {
+	embedding domain.Embedding
+	unit      []float32
+}
+
+// chunkSize is the number of entries of a chunk. A change copies the chunk
+// it touches and the list of chunks, not the whole index.
+const chunkSize = 256
+
+// snapshot is an immutable state of the index. Its entries are split into
+// chunks of chunkSize, except the last one, which are never changed once
+// published: changes copy the chunks they touch.
+type snapshot struct {
+	chunks [][]entry
+	len    int
+}
+
+// Index holds embeddings in memory and finds the most similar one to a probe
+// by scanning all of them, which matches pgvector's exact cosine distance.
+// Searches never block: changes build a new snapshot that replaces the
+// current one, sharing the chunks they do not touch, so a change costs
+// O(N/chunkSize + chunkSize) instead of a copy of the whole index.
+type Index struct {
+	mu       sync.Mutex    // serializes changes
+	position map[int64]int // position of the entries of current, guarded by mu
+	current  atomic.Pointer[snapshot]
+}
+
+// New creates an empty Index.
+func New() *Index {
+	idx := &Index{position: map[int64]int{}}
+	idx.current.Store(&snapshot{})
+	return idx
+}
+
+// Len returns the number of indexed embeddings.
+func (idx *Index) Len() int {
+	return idx.current.Load().len
+}
+
+// Replace replaces the content of the index with embeddings.
+func (idx *Index) Replace(embeddings []*domain.Embedding) {
+	next := &snapshot{}
+	position := make(map[int64]int, len(embeddings))
+	for _, embedding := range embeddings {
+		e, ok := newEntry(embedding)
+		if !ok {
+			continue
+		}
+		if i, found := position[e.embedding.ID]; found {
+			next.chunks[i/chunkSize][i%chunkSize] = e
+			continue
+		}
+		if next.len%chunkSize == 0 {
+			next.chunks = append(next.chunks, make([]entry, 0, chunkSize))
+		}
+		last := len(next.chunks) - 1
+		next.chunks[last] = append(next.chunks[last], e)
+		position[e.embedding.ID] = next.len
+		next.len++
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.position = position
+	idx.current.Store(next)
+}
+
+// Upsert adds embedding, or replaces the embedding with the same ID.
+// Embeddings whose vector has no direction never match, so they are removed.
+func (idx *Index) Upsert(embedding *domain.Embedding) {
+	e, ok := newEntry(embedding)
+	if !ok {
+		idx.Remove(embedding.ID)
+		return
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	next := idx.current.Load().edit()
+	if i, found := idx.position[e.embedding.ID]; found {
+		next.set(i, e)
+	} else {
+		idx.position[e.embedding.ID] = next.len
+		next.push(e)
+	}
+	idx.current.Store(next.snapshot)
+}
+
+// Remove removes the embedding with id, if it is indexed.
+func (idx *Index) Remove(id int64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	i, found := idx.position[id]
+	if !found {
+		return
+	}
+
+	// The last entry takes the place of the removed one.
+	next := idx.current.Load().edit()
+	last := next.len - 1
+	if i != last {
+		moved := next.at(last)
+		next.set(i, moved)
+		idx.position[moved.embedding.ID] = i
+	}
+	next.pop()
+	delete(idx.position, id)
+	idx.current.Store(next.snapshot)
+}
+
+// Search returns the embedding of tenant most similar to vector, with its
+// cosine similarity as Accuracy, if the similarity is above threshold.
+// Embeddings of other tenants are never returned.
+func (idx *Index) Search(tenant string, vector []float32, threshold float64) (*domain.Embedding, bool) {
+	probe, ok := unit(vector)
+	if !ok {
+		return nil, false
+	}
+
+	var best *entry
+	bestSimilarity := float32(math.Inf(-1))
+	for _, entries := range idx.current.Load().chunks {
+		for i := range entries {
+			if entries[i].embedding.Tenant != tenant || len(entries[i].unit) != len(probe) {
+				continue
+			}
+			if similarity := dot(entries[i].unit, probe); similarity > bestSimilarity {
+				best, bestSimilarity = &entries[i], similarity
+			}
+		}
+	}
+	if best == nil || float64(bestSimilarity) <= threshold {
+		return nil, false
+	}
+
+	match := best.embedding
+	match.Vector = pgvector.NewVector(slices.Clone(match.Vector.Slice()))
+	match.Accuracy = bestSimilarity
+	return &match, true
+}
+
+// edit is a copy of a snapshot being changed. It copies a chunk of the
+// snapshot the first time it changes it; the other chunks stay shared.
+type edit struct {
+	*snapshot
+	copied map[int]bool
+}
+
+// edit returns a copy of s that can be changed.
+func (s *snapshot) edit() *edit {
+	return &edit{snapshot: &snapshot{chunks: slices.Clone(s.chunks), len: s.len}, copied: map[int]bool{}}
+}
+
+// at returns the entry at position i.
+func (s *snapshot) at(i int) entry {
+	return s.chunks[i/chunkSize][i%chunkSize]
+}
+
+// chunk returns chunk k, copied if it is still shared.
+func (e *edit) chunk(k int) []entry {
+	if !e.copied[k] {
+		c := make([]entry, len(e.chunks[k]), chunkSize)
+		copy(c, e.chunks[k])
+		e.chunks[k] = c
+		e.copied[k] = true
+	}
+	return e.chunks[k]
+}
+
+// set replaces the entry at position i.
+func (e *edit) set(i int, en entry) {
+	e.chunk(i / chunkSize)[i%chunkSize] = en
+}
+
+// push appends en.
+func (e *edit) push(en entry) {
+	if e.len%chunkSize == 0 {
+		e.chunks = append(e.chunks, nil)
+	}
+	last := len(e.chunks) - 1
+	e.chunks[last] = append(e.chunk(last), en)
+	e.len++
+}
+
+// pop removes the last entry.
+func (e *edit) pop() {
+	e.len--
+	last := len(e.chunks) - 1
+	if e.len%chunkSize == 0 {
+		e.chunks = e.chunks[:last]
+		return
+	}
+	c := e.chunk(last)
+	e.chunks[last] = c[:len(c)-1]
+}
+
+func newEntry(embedding *domain.Embedding) (entry, bool) {
+	u, ok := unit(embedding.Vector.Slice())
+	if !ok {
+		return entry{}, false
+	}
+	e := entry{embedding: *embedding, unit: u}
+	e.embedding.Accuracy = 0
+	return e, true
+}
+
+// unit returns a copy of vector scaled to unit length, unless it has no
+// direction or a non-finite norm.
+func unit(vector []float32) ([]float32, bool) {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	norm := math.Sqrt(sum)
+	if norm == 0 || math.IsNaN(norm) || math.IsInf(norm, 0) {
+		return nil, false
+	}
+
+	scaled := make([]float32, len(vector))
+	for i, v := range vector {
+		scaled[i] = float32(float64(v) / norm)
+	}
+	return scaled, true
+}
+
+// dot returns the dot product of a and b, which have the same length. The
+// loop is unrolled with independent sums so that the compiler can pipeline it.
+func dot(a, b []float32) float32 {
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+	return s0 + s1 + s2 + s3
+}
@@ -0,0 +1,140 @@
+package index
+
+import (
+	"testing"
+
+	"access-system-api/internal/domain"
+
+	"github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func embedding(id int64, vector ...float32) *domain.Embedding {
+	return &domain.Embedding{ID: id, Tenant: tenant, Name: "test", Vector: pgvector.NewVector(vector)}
+}
+
+const tenant = "faculty"
+
+func TestIndex_Search(t *testing.T) {
+	idx := New()
+	idx.Replace([]*domain.Embedding{
+		embedding(1, 1, 0, 0, 0, 0),
+		embedding(2, 0, 2, 0, 0, 0),
+		embedding(3, 0, 0, 0, 0, 0),
+	})
+	assert.Equal(t, 2, idx.Len())
+
+	match, ok := idx.Search(tenant, []float32{0.1, 1, 0, 0, 0}, 0.5)
+	require.True(t, ok)
+	assert.Equal(t, int64(2), match.ID)
+	assert.InDelta(t, 0.995, match.Accuracy, 0.001)
+	assert.Equal(t, []float32{0, 2, 0, 0, 0}, match.Vector.Slice())
+
+	_, ok = idx.Search(tenant, []float32{1, 1, 0, 0, 0}, 0.8)
+	assert.False(t, ok, "similarity 0.707 is not above the threshold")
+
+	_, ok = idx.Search(tenant, []float32{0, 0, 0, 0, 0}, 0)
+	assert.False(t, ok, "a probe without direction never matches")
+}
+
+func TestIndex_SearchTenant(t *testing.T) {
+	idx := New()
+	other := embedding(1, 1, 0)
+	other.Tenant = "site"
+	idx.Replace([]*domain.Embedding{other, embedding(2, 0, 1)})
+
+	_, ok := idx.Search(tenant, []float32{1, 0}, 0.5)
+	assert.False(t, ok, "embeddings of other tenants never match")
+
+	match, ok := idx.Search("site", []float32{1, 0.1}, 0.5)
+	require.True(t, ok)
+	assert.Equal(t, int64(1), match.ID)
+}
+
+func TestIndex_Changes(t *testing.T) {
+	idx := New()
+	idx.Upsert(embedding(1, 1, 0))
+	idx.Upsert(embedding(2, 0, 1))
+	idx.Upsert(embedding(3, -1, 0))
+
+	idx.Upsert(embedding(1, 0, -1))
+	match, ok := idx.Search(tenant, []float32{0, -1}, 0.5)
+	require.True(t, ok)
+	assert.Equal(t, int64(1), match.ID)
+
+	idx.Remove(1)
+	idx.Remove(1)
+	assert.Equal(t, 2, idx.Len())
+	_, ok = idx.Search(tenant, []float32{0, -1}, 0.5)
+	assert.False(t, ok)
+
+	match, ok = idx.Search(tenant, []float32{-1, 0.1}, 0.5)
+	require.True(t, ok)
+	assert.Equal(t, int64(3), match.ID)
+
+	idx.Upsert(embedding(3, 0, 0))
+	assert.Equal(t, 1, idx.Len())
+}
+
+func TestIndex_ChangesAcrossChunks(t *testing.T) {
+	// Every embedding points in its own direction, so it is its own best match.
+	n := 2*chunkSize + 3
+	vector := func(id int) []float32 {
+		v := make([]float32, n+1)
+		v[id] = 1
+		return v
+	}
+	idx := New()
+	for id := 1; id <= n; id++ {
+		idx.Upsert(embedding(int64(id), vector(id)...))
+	}
+	before := idx.current.Load()
+
+	// Removing entries of the first chunks moves the last entries into them.
+	removed := map[int]bool{1: true, chunkSize: true, chunkSize + 1: true, n: true}
+	for id := range removed {
+		idx.Remove(int64(id))
+	}
+	idx.Upsert(embedding(2, vector(3)...))
+	assert.Equal(t, n-len(removed), idx.Len())
+
+	for id := 3; id <= n; id++ {
+		match, ok := idx.Search(tenant, vector(id), 0.5)
+		if removed[id] {
+			assert.False(t, ok, id)
+			continue
+		}
+		require.True(t, ok, id)
+		if id == 3 {
+			assert.Contains(t, []int64{2, 3}, match.ID)
+		} else {
+			assert.Equal(t, int64(id), match.ID)
+		}
+	}
+
+	// Snapshots searched before the changes are not affected by them.
+	assert.Equal(t, n, before.len)
+	for id := 1; id <= n; id++ {
+		assert.Equal(t, int64(id), before.at(id-1).embedding.ID)
+	}
+}
+
+func BenchmarkIndex_Search(b *testing.B) {
+	embeddings := make([]*domain.Embedding, 10000)
+	for i := range embeddings {
+		vector := make([]float32, 512)
+		for j := range vector {
+			vector[j] = float32((i*31+j*17)%97) - 48
+		}
+		embeddings[i] = embedding(int64(i+1), vector...)
+	}
+	idx := New()
+	idx.Replace(embeddings)
+	probe := embeddings[4242].Vector.Slice()
+
+	b.ResetTimer()
+	for range b.N {
+		idx.Search(tenant, probe, 0.58)
+	}
+}
@@ -0,0 +1,55 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Group runs background workers until it is stopped.
+// All workers share a context that is cancelled by Stop.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	log    *logrus.Logger
+}
+
+// NewGroup creates a new Group whose workers run until parent is done or Stop is called.
+func NewGroup(parent context.Context, log *logrus.Logger) *Group {
+	ctx, cancel := context.WithCancel(parent)
+	return &Group{ctx: ctx, cancel: cancel, log: log}
+}
+
+// Go starts fn in a new goroutine. fn must return once its context is cancelled.
+func (g *Group) Go(name string, fn func(ctx context.Context) error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		g.log.Infof("Worker %s started", name)
+		if err := fn(g.ctx); err != nil && g.ctx.Err() == nil {
+			g.log.Errorf("Worker %s failed: %s", name, err.Error())
+			return
+		}
+		g.log.Infof("Worker %s stopped", name)
+	}()
+}
+
+// Stop cancels the workers and waits for them to return or for ctx to be done.
+func (g *Group) Stop(ctx context.Context) error {
+	g.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
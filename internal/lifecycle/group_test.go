@@ -0,0 +1,64 @@
+package lifecycle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup_StopCancelsWorkers(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	group := NewGroup(context.Background(), log)
+
+	stopped := make(chan struct{})
+	group.Go("test", func(ctx context.Context) error {
+		<-ctx.Done()
+		close(stopped)
+		return ctx.Err()
+	})
+
+	err := group.Stop(context.Background())
+	assert.NoError(t, err)
+
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("worker was not stopped")
+	}
+}
+
+func TestGroup_StopTimeout(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	group := NewGroup(context.Background(), log)
+
+	release := make(chan struct{})
+	defer close(release)
+	group.Go("stuck", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := group.Stop(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestGroup_LogsWorkerFailure(t *testing.T) {
+	log, hook := test.NewNullLogger()
+	group := NewGroup(context.Background(), log)
+
+	group.Go("failing", func(ctx context.Context) error {
+		return assert.AnError
+	})
+
+	assert.Eventually(t, func() bool {
+		entry := hook.LastEntry()
+		return entry != nil && entry.Message == "Worker failing failed: "+assert.AnError.Error()
+	}, time.Second, time.Millisecond)
+	assert.NoError(t, group.Stop(context.Background()))
+}
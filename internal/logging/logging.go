@@ -0,0 +1,46 @@
+package logging
+
+import (
+	"context"
+
+	"access-system-api/internal/cfg"
+
+	"github.com/sirupsen/logrus"
+)
+
+const timestampFormat = "2006-01-02T15:04:05Z"
+
+type entryKey struct{}
+
+// Configure applies the level and format from logCfg to log.
+func Configure(log *logrus.Logger, logCfg *cfg.LogCfg) {
+	log.SetLevel(logCfg.Level)
+	switch logCfg.Format {
+	case cfg.LogFormatText:
+		log.SetFormatter(&logrus.TextFormatter{
+			TimestampFormat: timestampFormat,
+			FullTimestamp:   true,
+		})
+	default:
+		log.SetFormatter(&logrus.JSONFormatter{
+			TimestampFormat: timestampFormat,
+		})
+	}
+}
+
+// WithEntry returns a copy of ctx carrying the given request-scoped log entry.
+func WithEntry(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, entryKey{}, entry)
+}
+
+// FromContext returns the request-scoped log entry stored in ctx.
+// If there is none, an entry of fallback (or the standard logger if fallback is nil) is returned.
+func FromContext(ctx context.Context, fallback *logrus.Logger) *logrus.Entry {
+	if entry, ok := ctx.Value(entryKey{}).(*logrus.Entry); ok {
+		return entry
+	}
+	if fallback == nil {
+		fallback = logrus.StandardLogger()
+	}
+	return logrus.NewEntry(fallback)
+}
@@ -0,0 +1,45 @@
+package logging
+
+import (
+	"strings"
+
+	"access-system-api/internal/domain"
+
+	"github.com/pgvector/pgvector-go"
+	"github.com/sirupsen/logrus"
+)
+
+// Redacted replaces the value of fields that must never reach the logs.
+const Redacted = "[REDACTED]"
+
+// redactedFields lists field names whose values are always redacted.
+var redactedFields = map[string]struct{}{
+	"body":      {},
+	"embedding": {},
+	"vector":    {},
+	"vectors":   {},
+}
+
+// RedactHook removes biometric data from log entries before they are written.
+// Fields named like a vector or request body, and any vector or embedding value, are redacted.
+type RedactHook struct{}
+
+// Levels returns all log levels, so every entry is redacted.
+func (RedactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire redacts the fields of entry in place.
+func (RedactHook) Fire(entry *logrus.Entry) error {
+	for key, value := range entry.Data {
+		if _, ok := redactedFields[strings.ToLower(key)]; ok {
+			entry.Data[key] = Redacted
+			continue
+		}
+		switch value.(type) {
+		case []float32, []float64, [][]float32, pgvector.Vector, domain.Embedding, *domain.Embedding:
+			entry.Data[key] = Redacted
+		}
+	}
+	return nil
+}
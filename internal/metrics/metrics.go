@@ -0,0 +1,224 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"access-system-api/internal/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "access_system"
+
+// Validation outcomes reported by ObserveValidation.
+const (
+	OutcomeMatch   = "match"
+	OutcomeNoMatch = "no_match"
+	OutcomeError   = "error"
+	// OutcomeRejected is a validation refused by rate limits, a lockout or
+	// capture scores below their minimums.
+	OutcomeRejected = "rejected"
+)
+
+// Metrics holds the Prometheus collectors exposed by the server.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests   *prometheus.CounterVec
+	httpDuration   *prometheus.HistogramVec
+	grpcRequests   *prometheus.CounterVec
+	grpcDuration   *prometheus.HistogramVec
+	deviceRequests *prometheus.CounterVec
+	validations    *prometheus.CounterVec
+	matchAccuracy  prometheus.Histogram
+	repoDuration   *prometheus.HistogramVec
+	doorCommands   *prometheus.CounterVec
+	doorStates     *prometheus.CounterVec
+	lockouts       *prometheus.CounterVec
+	samples        *prometheus.CounterVec
+	indexLookups   *prometheus.CounterVec
+	indexSize      prometheus.Gauge
+}
+
+// New creates a new Metrics instance with its own registry.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Total number of HTTP requests by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method and route.",
+			Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+		}, []string{"method", "route"}),
+		grpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "grpc_requests_total",
+			Help:      "Total number of gRPC calls by method and status code.",
+		}, []string{"method", "code"}),
+		grpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "grpc_request_duration_seconds",
+			Help:      "gRPC call latency by method. Streams are observed once, when they end.",
+			Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+		}, []string{"method"}),
+		deviceRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "device_requests_total",
+			Help:      "Total number of HTTP requests and gRPC calls by client device.",
+		}, []string{"device"}),
+		validations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "validations_total",
+			Help:      "Total number of embedding validations by device and outcome.",
+		}, []string{"device", "outcome"}),
+		matchAccuracy: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "match_accuracy",
+			Help:      "Accuracy of successful embedding matches.",
+			Buckets:   prometheus.LinearBuckets(0.55, 0.05, 10),
+		}),
+		repoDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "repository_query_duration_seconds",
+			Help:      "Repository call latency by method and result.",
+			Buckets:   []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+		}, []string{"method", "result"}),
+		doorCommands: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "door_commands_total",
+			Help:      "Total number of door commands by command and result.",
+		}, []string{"command", "result"}),
+		doorStates: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "door_states_total",
+			Help:      "Total number of door state messages by access point and state.",
+		}, []string{"access_point", "state"}),
+		lockouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "lockouts_total",
+			Help:      "Total number of devices locked out after repeated validations without a match.",
+		}, []string{"device"}),
+		samples: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "template_samples_total",
+			Help:      "Total number of adaptive template samples by status: active when added, evicted or reverted.",
+		}, []string{"status"}),
+		indexLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "index_lookups_total",
+			Help:      "Total number of similarity searches by source: the in-memory index, or the database while the index is stale.",
+		}, []string{"source"}),
+		indexSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "index_embeddings",
+			Help:      "Number of embeddings in the in-memory index.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.grpcRequests,
+		m.grpcDuration,
+		m.deviceRequests,
+		m.validations,
+		m.matchAccuracy,
+		m.repoDuration,
+		m.doorCommands,
+		m.doorStates,
+		m.lockouts,
+		m.samples,
+		m.indexLookups,
+		m.indexSize,
+	)
+
+	return m
+}
+
+// RegisterDB exposes connection pool statistics of the given database.
+func (m *Metrics) RegisterDB(db *sql.DB, name string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler returns the HTTP handler serving the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveHTTPRequest records a finished HTTP request.
+func (m *Metrics) ObserveHTTPRequest(method, route, status, device string, duration time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, status).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+	m.deviceRequests.WithLabelValues(device).Inc()
+}
+
+// ObserveGRPCRequest records a finished gRPC call.
+func (m *Metrics) ObserveGRPCRequest(method, code, device string, duration time.Duration) {
+	m.grpcRequests.WithLabelValues(method, code).Inc()
+	m.grpcDuration.WithLabelValues(method).Observe(duration.Seconds())
+	m.deviceRequests.WithLabelValues(device).Inc()
+}
+
+// ObserveValidation records the outcome of an embedding validation.
+// The accuracy is only recorded for successful matches.
+func (m *Metrics) ObserveValidation(device, outcome string, accuracy float32) {
+	m.validations.WithLabelValues(device, outcome).Inc()
+	if outcome == OutcomeMatch {
+		m.matchAccuracy.Observe(float64(accuracy))
+	}
+}
+
+// ObserveRepositoryCall records the latency of a repository call.
+func (m *Metrics) ObserveRepositoryCall(method string, duration time.Duration, err error) {
+	result := "ok"
+	switch {
+	case errors.Is(err, domain.ErrNotFound), errors.Is(err, domain.ErrNoMatch):
+		result = "not_found"
+	case err != nil:
+		result = "error"
+	}
+	m.repoDuration.WithLabelValues(method, result).Observe(duration.Seconds())
+}
+
+// ObserveDoorCommand records the result of a door command: acknowledged,
+// unacknowledged or failed.
+func (m *Metrics) ObserveDoorCommand(command, result string) {
+	m.doorCommands.WithLabelValues(command, result).Inc()
+}
+
+// ObserveDoorState records a door state message of an access point.
+func (m *Metrics) ObserveDoorState(accessPoint, state string) {
+	m.doorStates.WithLabelValues(accessPoint, state).Inc()
+}
+
+// ObserveLockout records a device being locked out.
+func (m *Metrics) ObserveLockout(device string) {
+	m.lockouts.WithLabelValues(device).Inc()
+}
+
+// ObserveTemplateSamples records count adaptive template samples changing to status.
+func (m *Metrics) ObserveTemplateSamples(status string, count int) {
+	m.samples.WithLabelValues(status).Add(float64(count))
+}
+
+// ObserveIndexLookup records a similarity search answered by source.
+func (m *Metrics) ObserveIndexLookup(source string) {
+	m.indexLookups.WithLabelValues(source).Inc()
+}
+
+// SetIndexSize records the number of embeddings in the in-memory index.
+func (m *Metrics) SetIndexSize(n int) {
+	m.indexSize.Set(float64(n))
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"fmt"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RestrictClients only lets the listed clients through, written as for
+// RequireClients. An empty list allows every client.
+func RestrictClients(clients []string) gin.HandlerFunc {
+	if len(clients) == 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	return RequireClients(clients)
+}
+
+// RequireClients only lets the listed clients through, each written as
+// <tenant>/<device>: the organizational unit and the common name of its
+// certificate subject, see identity.Client. A device name only matches in its
+// tenant. Unlike RestrictClients, an empty list denies every client.
+func RequireClients(clients []string) gin.HandlerFunc {
+	allowed := make(map[string]struct{}, len(clients))
+	for _, client := range clients {
+		allowed[client] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		client := identity.Client(identity.TenantFromContext(ctx), identity.DeviceFromContext(ctx))
+		if _, ok := allowed[client]; !ok {
+			logging.FromContext(ctx, nil).Warn("Client is not allowed to access this route")
+			httperr.Respond(c, fmt.Errorf("%w: client %s is not allowed to access this route", domain.ErrForbidden, client))
+			return
+		}
+
+		c.Next()
+	}
+}
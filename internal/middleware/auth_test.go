@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAuthRouter(clients []string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Identity(ClientSubjectHeader), RestrictClients(clients))
+	r.GET("/admin", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func TestRestrictClients(t *testing.T) {
+	tests := []struct {
+		name    string
+		clients []string
+		subject string
+		code    int
+	}{
+		{"empty list allows all", nil, "CN=gate-01", http.StatusOK},
+		{"listed client", []string{"law/admin-01"}, "CN=admin-01,OU=law", http.StatusOK},
+		{"unlisted device", []string{"law/admin-01"}, "CN=gate-01,OU=law", http.StatusForbidden},
+		{"same name in another tenant", []string{"law/admin-01"}, "CN=admin-01,OU=engineering", http.StatusForbidden},
+		{"escaped tenant in name", []string{"law/admin-01"}, `CN=admin-01\,OU=law,OU=engineering`, http.StatusForbidden},
+		{"missing subject", []string{"law/admin-01"}, "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupAuthRouter(tt.clients)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/admin", nil)
+			req.Header.Set(ClientSubjectHeader, tt.subject)
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+}
+
+func TestRequireClients(t *testing.T) {
+	tests := []struct {
+		name    string
+		clients []string
+		subject string
+		code    int
+	}{
+		{"empty list denies all", nil, "CN=root-01", http.StatusForbidden},
+		{"listed client", []string{"platform/root-01"}, "CN=root-01,OU=platform", http.StatusOK},
+		{"default tenant", []string{"default/root-01"}, "CN=root-01", http.StatusOK},
+		{"same name in another tenant", []string{"platform/root-01"}, "CN=root-01,OU=law", http.StatusForbidden},
+		{"missing subject", []string{"platform/root-01"}, "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(Identity(ClientSubjectHeader), RequireClients(tt.clients))
+			r.GET("/superadmin", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/superadmin", nil)
+			req.Header.Set(ClientSubjectHeader, tt.subject)
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+}
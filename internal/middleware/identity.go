@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"access-system-api/internal/identity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientSubjectHeader is the default header Nginx uses to forward the verified client certificate subject.
+const ClientSubjectHeader = "X-Client-DN"
+
+// Identity resolves the calling device and its tenant from the client
+// certificate subject forwarded in header and stores them in the request context.
+func Identity(header string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subject := c.GetHeader(header)
+		ctx := identity.WithDevice(c.Request.Context(), identity.DeviceFromSubject(subject))
+		c.Request = c.Request.WithContext(identity.WithTenant(ctx, identity.TenantFromSubject(subject)))
+		c.Next()
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Logger attaches a request-scoped log entry to the request context and logs
+// every finished request. Request and response bodies are never logged.
+func Logger(log *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx := c.Request.Context()
+		entry := log.WithFields(logrus.Fields{
+			"request_id": logging.RequestIDFromContext(ctx),
+			"tenant":     identity.TenantFromContext(ctx),
+			"device":     identity.DeviceFromContext(ctx),
+			"method":     c.Request.Method,
+			"route":      route,
+		})
+		if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.HasTraceID() {
+			entry = entry.WithField("trace_id", spanCtx.TraceID().String())
+		}
+		c.Request = c.Request.WithContext(logging.WithEntry(ctx, entry))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		entry = entry.WithFields(logrus.Fields{
+			"status":     status,
+			"latency_ms": time.Since(start).Milliseconds(),
+			"client_ip":  c.ClientIP(),
+		})
+
+		switch {
+		case status >= http.StatusInternalServerError:
+			entry.Error("Request completed")
+		case status >= http.StatusBadRequest:
+			entry.Warn("Request completed")
+		default:
+			entry.Info("Request completed")
+		}
+	}
+}
+
+// Recovery recovers from panics in handlers and logs them with the request-scoped entry.
+func Recovery(log *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if err := recover(); err != nil {
+				logging.FromContext(c.Request.Context(), log).Errorf("Panic recovered: %v", err)
+				httperr.Respond(c, fmt.Errorf("panic: %v", err))
+			}
+		}()
+		c.Next()
+	}
+}
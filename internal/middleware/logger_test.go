@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"access-system-api/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupLoggerRouter(log *logrus.Logger) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestID(), Identity(ClientSubjectHeader), Logger(log), Recovery(log))
+	r.POST("/validate", func(c *gin.Context) {
+		logging.FromContext(c.Request.Context(), nil).
+			WithField("vector", []float32{0.1, 0.2}).
+			Info("Validating")
+		c.Status(http.StatusOK)
+	})
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	return r
+}
+
+func TestRequestID_Generated(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	r := setupLoggerRouter(log)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/validate", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, w.Header().Get(logging.RequestIDHeader), 32)
+}
+
+func TestRequestID_Propagated(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	r := setupLoggerRouter(log)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/validate", nil)
+	req.Header.Set(logging.RequestIDHeader, "terminal-42.abc")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, "terminal-42.abc", w.Header().Get(logging.RequestIDHeader))
+}
+
+func TestRequestID_InvalidReplaced(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	r := setupLoggerRouter(log)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/validate", nil)
+	req.Header.Set(logging.RequestIDHeader, "bad id\nwith newline")
+	r.ServeHTTP(w, req)
+
+	assert.NotEqual(t, "bad id\nwith newline", w.Header().Get(logging.RequestIDHeader))
+	assert.Len(t, w.Header().Get(logging.RequestIDHeader), 32)
+}
+
+func TestLogger_RequestScopedEntry(t *testing.T) {
+	log, hook := test.NewNullLogger()
+	log.AddHook(logging.RedactHook{})
+	r := setupLoggerRouter(log)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/validate", nil)
+	req.Header.Set(logging.RequestIDHeader, "req-1")
+	req.Header.Set(ClientSubjectHeader, "CN=gate-01")
+	r.ServeHTTP(w, req)
+
+	entries := hook.AllEntries()
+	assert.Len(t, entries, 2)
+
+	handlerEntry := entries[0]
+	assert.Equal(t, "Validating", handlerEntry.Message)
+	assert.Equal(t, "req-1", handlerEntry.Data["request_id"])
+	assert.Equal(t, "gate-01", handlerEntry.Data["device"])
+	assert.Equal(t, "/validate", handlerEntry.Data["route"])
+	assert.Equal(t, logging.Redacted, handlerEntry.Data["vector"])
+
+	requestEntry := entries[1]
+	assert.Equal(t, "Request completed", requestEntry.Message)
+	assert.Equal(t, http.StatusOK, requestEntry.Data["status"])
+	assert.Contains(t, requestEntry.Data, "latency_ms")
+}
+
+func TestRecovery_LogsPanic(t *testing.T) {
+	log, hook := test.NewNullLogger()
+	r := setupLoggerRouter(log)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/panic", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "Panic recovered: boom", hook.AllEntries()[0].Message)
+	assert.Equal(t, logrus.ErrorLevel, hook.LastEntry().Level)
+}
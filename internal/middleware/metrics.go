@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"access-system-api/internal/identity"
+	"access-system-api/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records request counts and latencies per route.
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		// Use the route template to keep label cardinality bounded.
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		m.ObserveHTTPRequest(
+			c.Request.Method,
+			route,
+			strconv.Itoa(c.Writer.Status()),
+			identity.DeviceFromContext(c.Request.Context()),
+			time.Since(start),
+		)
+	}
+}
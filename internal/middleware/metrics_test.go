@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"access-system-api/internal/identity"
+	"access-system-api/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRouter(m *metrics.Metrics) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Identity(ClientSubjectHeader), Metrics(m))
+	r.GET("/embedding/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, identity.DeviceFromContext(c.Request.Context()))
+	})
+	r.GET("/metrics", gin.WrapH(m.Handler()))
+	return r
+}
+
+func scrape(t *testing.T, r *gin.Engine) string {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	return w.Body.String()
+}
+
+func TestIdentity_DeviceFromSubject(t *testing.T) {
+	r := setupRouter(metrics.New())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/embedding/1", nil)
+	req.Header.Set(ClientSubjectHeader, "CN=gate-01,O=University")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gate-01", w.Body.String())
+}
+
+func TestIdentity_MissingSubject(t *testing.T) {
+	r := setupRouter(metrics.New())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/embedding/1", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, identity.UnknownDevice, w.Body.String())
+}
+
+func TestMetrics_RecordsRouteTemplate(t *testing.T) {
+	r := setupRouter(metrics.New())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/embedding/42", nil)
+	req.Header.Set(ClientSubjectHeader, "CN=gate-01")
+	r.ServeHTTP(w, req)
+
+	body := scrape(t, r)
+	assert.Contains(t, body, `access_system_http_requests_total{method="GET",route="/embedding/:id",status="200"} 1`)
+	assert.Contains(t, body, `access_system_device_requests_total{device="gate-01"} 1`)
+	assert.False(t, strings.Contains(body, `route="/embedding/42"`))
+}
+
+func TestMetrics_UnmatchedRoute(t *testing.T) {
+	r := setupRouter(metrics.New())
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/unknown", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	body := scrape(t, r)
+	assert.Contains(t, body, `route="unmatched",status="404"`)
+}
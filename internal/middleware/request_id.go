@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"access-system-api/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestID assigns a request ID, or propagates the one sent by the client,
+// stores it in the request context and returns it in the response headers.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := logging.ResolveRequestID(c.GetHeader(logging.RequestIDHeader))
+		c.Header(logging.RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
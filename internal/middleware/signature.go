@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/logging"
+	"access-system-api/internal/signing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VerifySignature authenticates requests signed by the calling device with
+// its registered key, and rejects stale and replayed requests, see
+// signing.Verifier. Unsigned requests are rejected if required is set and
+// passed through otherwise.
+func VerifySignature(verifier *signing.Verifier, required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		log := logging.FromContext(ctx, nil)
+
+		signature := c.GetHeader(signing.SignatureHeader)
+		if signature == "" {
+			if required {
+				log.Warn("Request is not signed")
+				httperr.Respond(c, fmt.Errorf("%w: request signature is required", domain.ErrUnauthorized))
+				return
+			}
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			log.WithError(err).Warn("Error reading request body")
+			httperr.Respond(c, fmt.Errorf("%w: cannot read request body", domain.ErrInvalidInput))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		err = verifier.Verify(ctx, identity.DeviceFromContext(ctx), signing.Request{
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Timestamp: c.GetHeader(signing.TimestampHeader),
+			Nonce:     c.GetHeader(signing.NonceHeader),
+			Signature: signature,
+			Body:      body,
+		})
+		if err != nil {
+			if errors.Is(err, domain.ErrUnauthorized) || errors.Is(err, domain.ErrReplayed) {
+				log.WithError(err).Warn("Request signature rejected")
+			} else {
+				log.WithError(err).Error("Error verifying request signature")
+			}
+			httperr.Respond(c, err)
+			return
+		}
+
+		c.Next()
+	}
+}
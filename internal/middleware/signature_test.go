@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/signing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSigningKey = "0123456789abcdef0123456789abcdef"
+
+// signingKeys is a signing.Keys backed by a map.
+type signingKeys map[string]string
+
+func (k signingKeys) SigningKey(_ context.Context, device string) (string, error) {
+	if device == "broken" {
+		return "", assert.AnError
+	}
+	key, ok := k[device]
+	if !ok {
+		return "", fmt.Errorf("%w: device %s", domain.ErrNotFound, device)
+	}
+	return key, nil
+}
+
+// nonceStore is a signing.NonceStore backed by a map, ignoring expiry.
+type nonceStore map[string]bool
+
+func (s nonceStore) UseNonce(_ context.Context, device, nonce string, _ time.Duration) (bool, error) {
+	key := device + "/" + nonce
+	if s[key] {
+		return false, nil
+	}
+	s[key] = true
+	return true, nil
+}
+
+func (s nonceStore) DeleteExpiredNonces(context.Context) error {
+	return nil
+}
+
+func setupSignatureRouter(required bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	keys := signingKeys{"gate-01": testSigningKey, "broken": testSigningKey}
+	r.Use(Identity(ClientSubjectHeader), VerifySignature(signing.NewVerifier(keys, signing.NewNonces(nonceStore{}, 30*time.Second, logrus.New()), 30*time.Second), required))
+	r.POST("/api/v1/embedding/validate", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "%s", body)
+	})
+	return r
+}
+
+func signedRequest(device, key, nonce, body string, t time.Time) *http.Request {
+	req, _ := http.NewRequest("POST", "/api/v1/embedding/validate", strings.NewReader(body))
+	req.Header.Set(ClientSubjectHeader, "CN="+device)
+	req.Header.Set(signing.TimestampHeader, strconv.FormatInt(t.Unix(), 10))
+	req.Header.Set(signing.NonceHeader, nonce)
+	req.Header.Set(signing.SignatureHeader, signing.Sign(key, "POST", "/api/v1/embedding/validate", t, nonce, []byte(body)))
+	return req
+}
+
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	var body httperr.Body
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body.Error.Code
+}
+
+func TestVerifySignature(t *testing.T) {
+	const body = `{"vector":[0.1]}`
+	const nonce = "3b2f6c1d8e9a4f70"
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		code int
+		err  string
+	}{
+		{"valid", signedRequest("gate-01", testSigningKey, nonce, body, now), http.StatusOK, ""},
+		{"wrong key", signedRequest("gate-01", strings.Repeat("x", 32), nonce, body, now), http.StatusUnauthorized, httperr.CodeUnauthorized},
+		{"stale", signedRequest("gate-01", testSigningKey, nonce, body, now.Add(-time.Minute)), http.StatusUnauthorized, httperr.CodeUnauthorized},
+		{"unregistered device", signedRequest("gate-02", testSigningKey, nonce, body, now), http.StatusUnauthorized, httperr.CodeUnauthorized},
+		{"key lookup error", signedRequest("broken", testSigningKey, nonce, body, now), http.StatusInternalServerError, httperr.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupSignatureRouter(true)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, tt.req)
+			assert.Equal(t, tt.code, w.Code)
+			if tt.err != "" {
+				assert.Equal(t, tt.err, errorCode(t, w))
+				return
+			}
+			// The handler still reads the complete body.
+			assert.Equal(t, body, w.Body.String())
+		})
+	}
+}
+
+func TestVerifySignature_Tampered(t *testing.T) {
+	r := setupSignatureRouter(true)
+	req := signedRequest("gate-01", testSigningKey, "3b2f6c1d8e9a4f70", `{"vector":[0.1]}`, time.Now())
+	req.Body = io.NopCloser(strings.NewReader(`{"vector":[0.2]}`))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestVerifySignature_Replay(t *testing.T) {
+	r := setupSignatureRouter(true)
+	now := time.Now()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, signedRequest("gate-01", testSigningKey, "3b2f6c1d8e9a4f70", `{"vector":[0.1]}`, now))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, signedRequest("gate-01", testSigningKey, "3b2f6c1d8e9a4f70", `{"vector":[0.1]}`, now))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, httperr.CodeReplayed, errorCode(t, w))
+}
+
+func TestVerifySignature_Unsigned(t *testing.T) {
+	tests := []struct {
+		name     string
+		required bool
+		code     int
+	}{
+		{"optional", false, http.StatusOK},
+		{"required", true, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupSignatureRouter(tt.required)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/v1/embedding/validate", strings.NewReader(`{}`))
+			req.Header.Set(ClientSubjectHeader, "CN=gate-01")
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/logging"
+	"access-system-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireTenant rejects requests of clients whose tenant, see Identity, is
+// not registered. A nil checker lets every tenant through.
+func RequireTenant(checker service.TenantChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if checker == nil {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		if err := checker.CheckTenant(ctx, identity.TenantFromContext(ctx)); err != nil {
+			logging.FromContext(ctx, nil).WithError(err).Warn("Tenant is not allowed")
+			httperr.Respond(c, err)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/logging"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+)
+
+// mergePatchContentType is the media type of JSON merge patches (RFC 7396).
+const mergePatchContentType = "application/merge-patch+json"
+
+func init() {
+	openapi3filter.RegisterBodyDecoder(mergePatchContentType, openapi3filter.JSONBodyDecoder)
+}
+
+// ValidateRequest rejects requests whose parameters or body do not match the
+// OpenAPI operation they resolve to. Requests for paths the spec does not
+// describe are passed through so the router can answer them.
+func ValidateRequest(spec routers.Router) gin.HandlerFunc {
+	options := &openapi3filter.Options{
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(c *gin.Context) {
+		route, pathParams, err := spec.FindRoute(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		err = openapi3filter.ValidateRequest(ctx, &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		})
+		if err != nil {
+			message := validationMessage(err)
+			logging.FromContext(ctx, nil).WithField("reason", message).Warn("Request does not match the API specification")
+			httperr.Respond(c, fmt.Errorf("%w: %s", domain.ErrInvalidInput, message))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// validationMessage turns a kin-openapi error into a short, client-facing
+// message without the schema dump included in its Error output.
+func validationMessage(err error) string {
+	var reqErr *openapi3filter.RequestError
+	if !errors.As(err, &reqErr) {
+		return "request does not match the API specification"
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(reqErr.Err, &schemaErr) {
+		location := "request body"
+		if reqErr.Parameter != nil {
+			location = "parameter " + reqErr.Parameter.Name
+		}
+		if pointer := schemaErr.JSONPointer(); len(pointer) > 0 {
+			location += " field " + strings.Join(pointer, ".")
+		}
+		return location + ": " + schemaErr.Reason
+	}
+
+	if reqErr.Parameter != nil {
+		return "parameter " + reqErr.Parameter.Name + ": " + reqErr.Reason
+	}
+	if reqErr.Reason != "" {
+		return reqErr.Reason
+	}
+	return reqErr.Error()
+}
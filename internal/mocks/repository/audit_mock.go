@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: access-system-api/internal/repository (interfaces: AuditRepository)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	domain "access-system-api/internal/domain"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAuditRepository is a mock of AuditRepository interface.
+type MockAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepositoryMockRecorder
+}
+
+// MockAuditRepositoryMockRecorder is the mock recorder for MockAuditRepository.
+type MockAuditRepositoryMockRecorder struct {
+	mock *MockAuditRepository
+}
+
+// NewMockAuditRepository creates a new mock instance.
+func NewMockAuditRepository(ctrl *gomock.Controller) *MockAuditRepository {
+	mock := &MockAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepository) EXPECT() *MockAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddEmbeddingAudit mocks base method.
+func (m *MockAuditRepository) AddEmbeddingAudit(arg0 context.Context, arg1 *domain.EmbeddingAudit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddEmbeddingAudit", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddEmbeddingAudit indicates an expected call of AddEmbeddingAudit.
+func (mr *MockAuditRepositoryMockRecorder) AddEmbeddingAudit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddEmbeddingAudit", reflect.TypeOf((*MockAuditRepository)(nil).AddEmbeddingAudit), arg0, arg1)
+}
+
+// ListEmbeddingAudit mocks base method.
+func (m *MockAuditRepository) ListEmbeddingAudit(arg0 context.Context, arg1 int64) ([]*domain.EmbeddingAudit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEmbeddingAudit", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.EmbeddingAudit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEmbeddingAudit indicates an expected call of ListEmbeddingAudit.
+func (mr *MockAuditRepositoryMockRecorder) ListEmbeddingAudit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEmbeddingAudit", reflect.TypeOf((*MockAuditRepository)(nil).ListEmbeddingAudit), arg0, arg1)
+}
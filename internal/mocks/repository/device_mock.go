@@ -0,0 +1,124 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: access-system-api/internal/repository (interfaces: DeviceRepository)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	domain "access-system-api/internal/domain"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDeviceRepository is a mock of DeviceRepository interface.
+type MockDeviceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeviceRepositoryMockRecorder
+}
+
+// MockDeviceRepositoryMockRecorder is the mock recorder for MockDeviceRepository.
+type MockDeviceRepositoryMockRecorder struct {
+	mock *MockDeviceRepository
+}
+
+// NewMockDeviceRepository creates a new mock instance.
+func NewMockDeviceRepository(ctrl *gomock.Controller) *MockDeviceRepository {
+	mock := &MockDeviceRepository{ctrl: ctrl}
+	mock.recorder = &MockDeviceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeviceRepository) EXPECT() *MockDeviceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateDevice mocks base method.
+func (m *MockDeviceRepository) CreateDevice(arg0 context.Context, arg1 *domain.Device) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDevice", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDevice indicates an expected call of CreateDevice.
+func (mr *MockDeviceRepositoryMockRecorder) CreateDevice(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDevice", reflect.TypeOf((*MockDeviceRepository)(nil).CreateDevice), arg0, arg1)
+}
+
+// DeleteDeviceById mocks base method.
+func (m *MockDeviceRepository) DeleteDeviceById(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDeviceById", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDeviceById indicates an expected call of DeleteDeviceById.
+func (mr *MockDeviceRepositoryMockRecorder) DeleteDeviceById(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDeviceById", reflect.TypeOf((*MockDeviceRepository)(nil).DeleteDeviceById), arg0, arg1)
+}
+
+// GetDeviceById mocks base method.
+func (m *MockDeviceRepository) GetDeviceById(arg0 context.Context, arg1 int64) (*domain.Device, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeviceById", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Device)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeviceById indicates an expected call of GetDeviceById.
+func (mr *MockDeviceRepositoryMockRecorder) GetDeviceById(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeviceById", reflect.TypeOf((*MockDeviceRepository)(nil).GetDeviceById), arg0, arg1)
+}
+
+// GetDeviceByName mocks base method.
+func (m *MockDeviceRepository) GetDeviceByName(arg0 context.Context, arg1 string) (*domain.Device, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeviceByName", arg0, arg1)
+	ret0, _ := ret[0].(*domain.Device)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeviceByName indicates an expected call of GetDeviceByName.
+func (mr *MockDeviceRepositoryMockRecorder) GetDeviceByName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeviceByName", reflect.TypeOf((*MockDeviceRepository)(nil).GetDeviceByName), arg0, arg1)
+}
+
+// ListDevices mocks base method.
+func (m *MockDeviceRepository) ListDevices(arg0 context.Context) ([]*domain.Device, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDevices", arg0)
+	ret0, _ := ret[0].([]*domain.Device)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDevices indicates an expected call of ListDevices.
+func (mr *MockDeviceRepositoryMockRecorder) ListDevices(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDevices", reflect.TypeOf((*MockDeviceRepository)(nil).ListDevices), arg0)
+}
+
+// UpdateSigningKey mocks base method.
+func (m *MockDeviceRepository) UpdateSigningKey(arg0 context.Context, arg1 int64, arg2 string) (*domain.Device, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSigningKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Device)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSigningKey indicates an expected call of UpdateSigningKey.
+func (mr *MockDeviceRepositoryMockRecorder) UpdateSigningKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSigningKey", reflect.TypeOf((*MockDeviceRepository)(nil).UpdateSigningKey), arg0, arg1, arg2)
+}
@@ -51,17 +51,17 @@ func (mr *MockEmbeddingRepositoryMockRecorder) CreateEmbedding(arg0, arg1 interf
 }
 
 // DeleteEmbeddingById mocks base method.
-func (m *MockEmbeddingRepository) DeleteEmbeddingById(arg0 context.Context, arg1 int64) error {
+func (m *MockEmbeddingRepository) DeleteEmbeddingById(arg0 context.Context, arg1, arg2 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteEmbeddingById", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteEmbeddingById", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteEmbeddingById indicates an expected call of DeleteEmbeddingById.
-func (mr *MockEmbeddingRepositoryMockRecorder) DeleteEmbeddingById(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockEmbeddingRepositoryMockRecorder) DeleteEmbeddingById(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEmbeddingById", reflect.TypeOf((*MockEmbeddingRepository)(nil).DeleteEmbeddingById), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEmbeddingById", reflect.TypeOf((*MockEmbeddingRepository)(nil).DeleteEmbeddingById), arg0, arg1, arg2)
 }
 
 // GetEmbeddingById mocks base method.
@@ -94,6 +94,36 @@ func (mr *MockEmbeddingRepositoryMockRecorder) GetSimilarEmbeddingByVector(arg0,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSimilarEmbeddingByVector", reflect.TypeOf((*MockEmbeddingRepository)(nil).GetSimilarEmbeddingByVector), arg0, arg1)
 }
 
+// GetSimilarEmbeddingsByVectors mocks base method.
+func (m *MockEmbeddingRepository) GetSimilarEmbeddingsByVectors(arg0 context.Context, arg1 []pgvector.Vector) ([]*domain.Embedding, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSimilarEmbeddingsByVectors", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.Embedding)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSimilarEmbeddingsByVectors indicates an expected call of GetSimilarEmbeddingsByVectors.
+func (mr *MockEmbeddingRepositoryMockRecorder) GetSimilarEmbeddingsByVectors(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSimilarEmbeddingsByVectors", reflect.TypeOf((*MockEmbeddingRepository)(nil).GetSimilarEmbeddingsByVectors), arg0, arg1)
+}
+
+// ListAllEmbeddings mocks base method.
+func (m *MockEmbeddingRepository) ListAllEmbeddings(arg0 context.Context) ([]*domain.Embedding, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAllEmbeddings", arg0)
+	ret0, _ := ret[0].([]*domain.Embedding)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAllEmbeddings indicates an expected call of ListAllEmbeddings.
+func (mr *MockEmbeddingRepositoryMockRecorder) ListAllEmbeddings(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllEmbeddings", reflect.TypeOf((*MockEmbeddingRepository)(nil).ListAllEmbeddings), arg0)
+}
+
 // ListEmbeddings mocks base method.
 func (m *MockEmbeddingRepository) ListEmbeddings(arg0 context.Context) ([]*domain.Embedding, error) {
 	m.ctrl.T.Helper()
@@ -109,6 +139,36 @@ func (mr *MockEmbeddingRepositoryMockRecorder) ListEmbeddings(arg0 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEmbeddings", reflect.TypeOf((*MockEmbeddingRepository)(nil).ListEmbeddings), arg0)
 }
 
+// PatchEmbedding mocks base method.
+func (m *MockEmbeddingRepository) PatchEmbedding(arg0 context.Context, arg1 int64, arg2 domain.EmbeddingPatch, arg3 int64) (*domain.Embedding, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchEmbedding", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*domain.Embedding)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchEmbedding indicates an expected call of PatchEmbedding.
+func (mr *MockEmbeddingRepositoryMockRecorder) PatchEmbedding(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchEmbedding", reflect.TypeOf((*MockEmbeddingRepository)(nil).PatchEmbedding), arg0, arg1, arg2, arg3)
+}
+
+// UpdateCaptureMinimums mocks base method.
+func (m *MockEmbeddingRepository) UpdateCaptureMinimums(arg0 context.Context, arg1 int64, arg2 domain.CaptureMinimums, arg3 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCaptureMinimums", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCaptureMinimums indicates an expected call of UpdateCaptureMinimums.
+func (mr *MockEmbeddingRepositoryMockRecorder) UpdateCaptureMinimums(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCaptureMinimums", reflect.TypeOf((*MockEmbeddingRepository)(nil).UpdateCaptureMinimums), arg0, arg1, arg2, arg3)
+}
+
 // UpdateEmbedding mocks base method.
 func (m *MockEmbeddingRepository) UpdateEmbedding(arg0 context.Context, arg1 *domain.Embedding) error {
 	m.ctrl.T.Helper()
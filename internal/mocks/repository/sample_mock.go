@@ -0,0 +1,122 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: access-system-api/internal/repository (interfaces: SampleRepository)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	domain "access-system-api/internal/domain"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSampleRepository is a mock of SampleRepository interface.
+type MockSampleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSampleRepositoryMockRecorder
+}
+
+// MockSampleRepositoryMockRecorder is the mock recorder for MockSampleRepository.
+type MockSampleRepositoryMockRecorder struct {
+	mock *MockSampleRepository
+}
+
+// NewMockSampleRepository creates a new mock instance.
+func NewMockSampleRepository(ctrl *gomock.Controller) *MockSampleRepository {
+	mock := &MockSampleRepository{ctrl: ctrl}
+	mock.recorder = &MockSampleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSampleRepository) EXPECT() *MockSampleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddSample mocks base method.
+func (m *MockSampleRepository) AddSample(arg0 context.Context, arg1 *domain.EmbeddingSample) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSample", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddSample indicates an expected call of AddSample.
+func (mr *MockSampleRepositoryMockRecorder) AddSample(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSample", reflect.TypeOf((*MockSampleRepository)(nil).AddSample), arg0, arg1)
+}
+
+// EvictSamples mocks base method.
+func (m *MockSampleRepository) EvictSamples(arg0 context.Context, arg1 int64, arg2 int) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EvictSamples", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EvictSamples indicates an expected call of EvictSamples.
+func (mr *MockSampleRepositoryMockRecorder) EvictSamples(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EvictSamples", reflect.TypeOf((*MockSampleRepository)(nil).EvictSamples), arg0, arg1, arg2)
+}
+
+// ListSamples mocks base method.
+func (m *MockSampleRepository) ListSamples(arg0 context.Context, arg1 int64) ([]*domain.EmbeddingSample, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSamples", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.EmbeddingSample)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSamples indicates an expected call of ListSamples.
+func (mr *MockSampleRepositoryMockRecorder) ListSamples(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSamples", reflect.TypeOf((*MockSampleRepository)(nil).ListSamples), arg0, arg1)
+}
+
+// ResetSamples mocks base method.
+func (m *MockSampleRepository) ResetSamples(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetSamples", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetSamples indicates an expected call of ResetSamples.
+func (mr *MockSampleRepositoryMockRecorder) ResetSamples(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetSamples", reflect.TypeOf((*MockSampleRepository)(nil).ResetSamples), arg0, arg1)
+}
+
+// RevertSample mocks base method.
+func (m *MockSampleRepository) RevertSample(arg0 context.Context, arg1, arg2, arg3 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevertSample", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevertSample indicates an expected call of RevertSample.
+func (mr *MockSampleRepositoryMockRecorder) RevertSample(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevertSample", reflect.TypeOf((*MockSampleRepository)(nil).RevertSample), arg0, arg1, arg2, arg3)
+}
+
+// UpdateCentroid mocks base method.
+func (m *MockSampleRepository) UpdateCentroid(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCentroid", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCentroid indicates an expected call of UpdateCentroid.
+func (mr *MockSampleRepositoryMockRecorder) UpdateCentroid(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCentroid", reflect.TypeOf((*MockSampleRepository)(nil).UpdateCentroid), arg0, arg1)
+}
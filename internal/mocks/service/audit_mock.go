@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: access-system-api/internal/service (interfaces: AuditService)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	domain "access-system-api/internal/domain"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAuditService is a mock of AuditService interface.
+type MockAuditService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditServiceMockRecorder
+}
+
+// MockAuditServiceMockRecorder is the mock recorder for MockAuditService.
+type MockAuditServiceMockRecorder struct {
+	mock *MockAuditService
+}
+
+// NewMockAuditService creates a new mock instance.
+func NewMockAuditService(ctrl *gomock.Controller) *MockAuditService {
+	mock := &MockAuditService{ctrl: ctrl}
+	mock.recorder = &MockAuditServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditService) EXPECT() *MockAuditServiceMockRecorder {
+	return m.recorder
+}
+
+// ListEmbeddingAudit mocks base method.
+func (m *MockAuditService) ListEmbeddingAudit(arg0 context.Context, arg1 int64) ([]*domain.EmbeddingAudit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEmbeddingAudit", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.EmbeddingAudit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEmbeddingAudit indicates an expected call of ListEmbeddingAudit.
+func (mr *MockAuditServiceMockRecorder) ListEmbeddingAudit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEmbeddingAudit", reflect.TypeOf((*MockAuditService)(nil).ListEmbeddingAudit), arg0, arg1)
+}
@@ -50,17 +50,17 @@ func (mr *MockEmbeddingServiceMockRecorder) AddEmbedding(arg0, arg1, arg2 interf
 }
 
 // DeleteEmbedding mocks base method.
-func (m *MockEmbeddingService) DeleteEmbedding(arg0 context.Context, arg1 int64) error {
+func (m *MockEmbeddingService) DeleteEmbedding(arg0 context.Context, arg1, arg2 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteEmbedding", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteEmbedding", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteEmbedding indicates an expected call of DeleteEmbedding.
-func (mr *MockEmbeddingServiceMockRecorder) DeleteEmbedding(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockEmbeddingServiceMockRecorder) DeleteEmbedding(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEmbedding", reflect.TypeOf((*MockEmbeddingService)(nil).DeleteEmbedding), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEmbedding", reflect.TypeOf((*MockEmbeddingService)(nil).DeleteEmbedding), arg0, arg1, arg2)
 }
 
 // GetEmbedding mocks base method.
@@ -93,18 +93,64 @@ func (mr *MockEmbeddingServiceMockRecorder) ListEmbeddings(arg0 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEmbeddings", reflect.TypeOf((*MockEmbeddingService)(nil).ListEmbeddings), arg0)
 }
 
+// ListInvalidEmbeddings mocks base method.
+func (m *MockEmbeddingService) ListInvalidEmbeddings(arg0 context.Context) ([]*domain.InvalidEmbedding, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInvalidEmbeddings", arg0)
+	ret0, _ := ret[0].([]*domain.InvalidEmbedding)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInvalidEmbeddings indicates an expected call of ListInvalidEmbeddings.
+func (mr *MockEmbeddingServiceMockRecorder) ListInvalidEmbeddings(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInvalidEmbeddings", reflect.TypeOf((*MockEmbeddingService)(nil).ListInvalidEmbeddings), arg0)
+}
+
+// PatchEmbedding mocks base method.
+func (m *MockEmbeddingService) PatchEmbedding(arg0 context.Context, arg1 int64, arg2 domain.EmbeddingPatch, arg3 int64) (*domain.Embedding, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchEmbedding", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*domain.Embedding)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchEmbedding indicates an expected call of PatchEmbedding.
+func (mr *MockEmbeddingServiceMockRecorder) PatchEmbedding(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchEmbedding", reflect.TypeOf((*MockEmbeddingService)(nil).PatchEmbedding), arg0, arg1, arg2, arg3)
+}
+
+// SetCaptureMinimums mocks base method.
+func (m *MockEmbeddingService) SetCaptureMinimums(arg0 context.Context, arg1 int64, arg2 domain.CaptureMinimums, arg3 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCaptureMinimums", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetCaptureMinimums indicates an expected call of SetCaptureMinimums.
+func (mr *MockEmbeddingServiceMockRecorder) SetCaptureMinimums(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCaptureMinimums", reflect.TypeOf((*MockEmbeddingService)(nil).SetCaptureMinimums), arg0, arg1, arg2, arg3)
+}
+
 // UpdateEmbedding mocks base method.
-func (m *MockEmbeddingService) UpdateEmbedding(arg0 context.Context, arg1 int64, arg2 string, arg3 []float32) error {
+func (m *MockEmbeddingService) UpdateEmbedding(arg0 context.Context, arg1 int64, arg2 string, arg3 []float32, arg4 int64) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateEmbedding", arg0, arg1, arg2, arg3)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "UpdateEmbedding", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // UpdateEmbedding indicates an expected call of UpdateEmbedding.
-func (mr *MockEmbeddingServiceMockRecorder) UpdateEmbedding(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+func (mr *MockEmbeddingServiceMockRecorder) UpdateEmbedding(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEmbedding", reflect.TypeOf((*MockEmbeddingService)(nil).UpdateEmbedding), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEmbedding", reflect.TypeOf((*MockEmbeddingService)(nil).UpdateEmbedding), arg0, arg1, arg2, arg3, arg4)
 }
 
 // ValidateEmbedding mocks base method.
@@ -121,3 +167,18 @@ func (mr *MockEmbeddingServiceMockRecorder) ValidateEmbedding(arg0, arg1 interfa
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateEmbedding", reflect.TypeOf((*MockEmbeddingService)(nil).ValidateEmbedding), arg0, arg1)
 }
+
+// ValidateMatchedEmbedding mocks base method.
+func (m *MockEmbeddingService) ValidateMatchedEmbedding(arg0 context.Context, arg1 []float32, arg2 domain.Match) (*domain.Embedding, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateMatchedEmbedding", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*domain.Embedding)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidateMatchedEmbedding indicates an expected call of ValidateMatchedEmbedding.
+func (mr *MockEmbeddingServiceMockRecorder) ValidateMatchedEmbedding(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateMatchedEmbedding", reflect.TypeOf((*MockEmbeddingService)(nil).ValidateMatchedEmbedding), arg0, arg1, arg2)
+}
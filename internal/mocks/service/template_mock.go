@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: access-system-api/internal/service (interfaces: TemplateService)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	domain "access-system-api/internal/domain"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTemplateService is a mock of TemplateService interface.
+type MockTemplateService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTemplateServiceMockRecorder
+}
+
+// MockTemplateServiceMockRecorder is the mock recorder for MockTemplateService.
+type MockTemplateServiceMockRecorder struct {
+	mock *MockTemplateService
+}
+
+// NewMockTemplateService creates a new mock instance.
+func NewMockTemplateService(ctrl *gomock.Controller) *MockTemplateService {
+	mock := &MockTemplateService{ctrl: ctrl}
+	mock.recorder = &MockTemplateServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTemplateService) EXPECT() *MockTemplateServiceMockRecorder {
+	return m.recorder
+}
+
+// Adapt mocks base method.
+func (m *MockTemplateService) Adapt(arg0 context.Context, arg1 *domain.Embedding, arg2 []float32) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Adapt", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Adapt indicates an expected call of Adapt.
+func (mr *MockTemplateServiceMockRecorder) Adapt(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Adapt", reflect.TypeOf((*MockTemplateService)(nil).Adapt), arg0, arg1, arg2)
+}
+
+// ListSamples mocks base method.
+func (m *MockTemplateService) ListSamples(arg0 context.Context, arg1 int64) ([]*domain.EmbeddingSample, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSamples", arg0, arg1)
+	ret0, _ := ret[0].([]*domain.EmbeddingSample)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSamples indicates an expected call of ListSamples.
+func (mr *MockTemplateServiceMockRecorder) ListSamples(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSamples", reflect.TypeOf((*MockTemplateService)(nil).ListSamples), arg0, arg1)
+}
+
+// ResetSamples mocks base method.
+func (m *MockTemplateService) ResetSamples(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetSamples", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetSamples indicates an expected call of ResetSamples.
+func (mr *MockTemplateServiceMockRecorder) ResetSamples(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetSamples", reflect.TypeOf((*MockTemplateService)(nil).ResetSamples), arg0, arg1)
+}
+
+// RevertSample mocks base method.
+func (m *MockTemplateService) RevertSample(arg0 context.Context, arg1, arg2, arg3 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevertSample", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevertSample indicates an expected call of RevertSample.
+func (mr *MockTemplateServiceMockRecorder) RevertSample(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevertSample", reflect.TypeOf((*MockTemplateService)(nil).RevertSample), arg0, arg1, arg2, arg3)
+}
@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l := NewLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		ok, _ := l.Allow("gate-01")
+		assert.True(t, ok, "request %d within burst", i)
+	}
+
+	ok, wait := l.Allow("gate-01")
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	// Other keys have their own bucket.
+	ok, _ = l.Allow("gate-02")
+	assert.True(t, ok)
+
+	// A rejected request does not take a token.
+	now = now.Add(500 * time.Millisecond)
+	ok, _ = l.Allow("gate-01")
+	assert.True(t, ok)
+	ok, _ = l.Allow("gate-01")
+	assert.False(t, ok)
+}
+
+func TestLimiter_Prune(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l := NewLimiter(1, 1)
+	l.now = func() time.Time { return now }
+
+	l.Allow("gate-01")
+	now = now.Add(2 * pruneInterval)
+	l.Allow("gate-02")
+
+	assert.NotContains(t, l.buckets, "gate-01")
+	assert.Contains(t, l.buckets, "gate-02")
+}
+
+func TestLimiter_Disabled(t *testing.T) {
+	l := NewLimiter(0, 10)
+	assert.Nil(t, l)
+
+	ok, _ := l.Allow("gate-01")
+	assert.True(t, ok)
+}
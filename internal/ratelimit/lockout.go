@@ -0,0 +1,199 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"access-system-api/internal/domain"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LockoutPolicy configures when and for how long devices are locked out.
+type LockoutPolicy struct {
+	// Threshold is the number of consecutive failures within Window that locks a device out.
+	Threshold int
+	Window    time.Duration
+	// Duration is the length of the first lockout; it doubles with every
+	// further lockout up to MaxDuration.
+	Duration    time.Duration
+	MaxDuration time.Duration
+}
+
+// LockoutStore keeps the lockout state of devices. Every method but
+// DeleteStaleLockoutStates is scoped to the tenant of ctx, so devices with the
+// same name in different tenants are tracked apart. The Postgres store is
+// shared by every server instance, so that they agree on lockouts.
+type LockoutStore interface {
+	// GetLockoutState returns the state of device, or nil if it has none.
+	GetLockoutState(ctx context.Context, device string) (*domain.LockoutState, error)
+	// UpdateLockoutState passes the state of device to update, a zero state if
+	// it has none, and stores the changed state, or removes it if update
+	// returns false. Concurrent updates of a device are applied one after the
+	// other.
+	UpdateLockoutState(ctx context.Context, device string, update func(state *domain.LockoutState) bool) error
+	// ListLockoutStates returns the states of the devices locked out after
+	// now, ordered by device.
+	ListLockoutStates(ctx context.Context, now time.Time) ([]*domain.LockoutState, error)
+	// DeleteLockoutState removes the state of device and returns it, or nil if
+	// it has none.
+	DeleteLockoutState(ctx context.Context, device string) (*domain.LockoutState, error)
+	// DeleteStaleLockoutStates removes the states of every tenant last seen
+	// before seenBefore and locked out until before lockedBefore.
+	DeleteStaleLockoutStates(ctx context.Context, seenBefore, lockedBefore time.Time) error
+}
+
+// Lockouts tracks consecutive failed validations per device and locks out
+// devices that fail too often. A nil Lockouts never locks a device out.
+type Lockouts struct {
+	policy LockoutPolicy
+	store  LockoutStore
+	now    func() time.Time
+	log    *logrus.Logger
+}
+
+// NewLockouts creates a new Lockouts keeping its state in store. It returns
+// nil if the threshold is 0.
+func NewLockouts(policy LockoutPolicy, store LockoutStore, log *logrus.Logger) *Lockouts {
+	if policy.Threshold <= 0 {
+		return nil
+	}
+	return &Lockouts{policy: policy, store: store, now: time.Now, log: log}
+}
+
+// State returns the lockout state of device, or nil if it has none. Pass it
+// to Remaining and Succeed, so a validation reads it only once.
+func (l *Lockouts) State(ctx context.Context, device string) (*domain.LockoutState, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return l.store.GetLockoutState(ctx, device)
+}
+
+// Remaining returns how long the device of state stays locked out, or 0 if it
+// is not locked out.
+func (l *Lockouts) Remaining(state *domain.LockoutState) time.Duration {
+	if l == nil || state == nil {
+		return 0
+	}
+	return max(state.LockedUntil.Sub(l.now()), 0)
+}
+
+// Fail records a failed validation of device. It returns the lockout if the
+// failure locked the device out, or nil otherwise.
+func (l *Lockouts) Fail(ctx context.Context, device string) (*domain.Lockout, error) {
+	if l == nil {
+		return nil, nil
+	}
+
+	var locked *domain.Lockout
+	err := l.store.UpdateLockoutState(ctx, device, func(state *domain.LockoutState) bool {
+		now := l.now()
+		state.LastSeen = now
+
+		if state.Level > 0 && now.Sub(state.LockedUntil) > l.policy.MaxDuration {
+			state.Level = 0
+		}
+		if state.Failures == 0 || now.Sub(state.FirstFailure) > l.policy.Window {
+			state.Failures = 0
+			state.FirstFailure = now
+		}
+		state.Failures++
+		if state.Failures < l.policy.Threshold {
+			return true
+		}
+
+		state.Failures = 0
+		state.Level++
+		state.LockedUntil = now.Add(l.duration(state.Level))
+		locked = lockout(state)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return locked, nil
+}
+
+// Succeed records a successful validation of device, whose state was read by
+// State before, which resets its failures. The lockout level is kept until it
+// decays, so a single match does not undo the escalation of lockouts. The
+// store is only updated if the device has failures.
+func (l *Lockouts) Succeed(ctx context.Context, device string, state *domain.LockoutState) error {
+	if l == nil || state == nil || state.Failures == 0 {
+		return nil
+	}
+	return l.store.UpdateLockoutState(ctx, device, func(state *domain.LockoutState) bool {
+		state.Failures = 0
+		return state.Level > 0
+	})
+}
+
+// List returns the devices that are currently locked out, ordered by device.
+func (l *Lockouts) List(ctx context.Context) ([]*domain.Lockout, error) {
+	if l == nil {
+		return nil, nil
+	}
+
+	states, err := l.store.ListLockoutStates(ctx, l.now())
+	if err != nil {
+		return nil, err
+	}
+	lockouts := make([]*domain.Lockout, 0, len(states))
+	for _, state := range states {
+		lockouts = append(lockouts, lockout(state))
+	}
+	return lockouts, nil
+}
+
+// Clear lifts the lockout of device and resets its failures. It reports
+// whether the device was locked out.
+func (l *Lockouts) Clear(ctx context.Context, device string) (bool, error) {
+	if l == nil {
+		return false, nil
+	}
+
+	state, err := l.store.DeleteLockoutState(ctx, device)
+	if err != nil || state == nil {
+		return false, err
+	}
+	return state.LockedUntil.After(l.now()), nil
+}
+
+// Run removes the state of devices that are neither locked out nor failing
+// anymore every minute until ctx is done.
+func (l *Lockouts) Run(ctx context.Context) error {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := l.prune(ctx); err != nil {
+				l.log.WithError(err).Error("Error removing stale device lockouts")
+			}
+		}
+	}
+}
+
+// prune removes the state of devices that are neither locked out nor failing
+// anymore.
+func (l *Lockouts) prune(ctx context.Context) error {
+	now := l.now()
+	return l.store.DeleteStaleLockoutStates(ctx, now.Add(-l.policy.Window), now.Add(-l.policy.MaxDuration))
+}
+
+// duration returns the length of the lockout with the given level.
+func (l *Lockouts) duration(level int) time.Duration {
+	d := l.policy.Duration
+	for i := 1; i < level && d < l.policy.MaxDuration; i++ {
+		d *= 2
+	}
+	return min(d, l.policy.MaxDuration)
+}
+
+func lockout(state *domain.LockoutState) *domain.Lockout {
+	return &domain.Lockout{Tenant: state.Tenant, Device: state.Device, Level: state.Level, LockedUntil: state.LockedUntil}
+}
@@ -0,0 +1,240 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLockouts() (*Lockouts, *time.Time) {
+	now := time.Unix(1700000000, 0)
+	l := NewLockouts(LockoutPolicy{Threshold: 3, Window: time.Minute, Duration: time.Minute, MaxDuration: 3 * time.Minute}, NewMemoryLockoutStore(), logrus.New())
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+// fail records a failed validation of device and returns the lockout, if any.
+func fail(t *testing.T, l *Lockouts, ctx context.Context, device string) *domain.Lockout {
+	t.Helper()
+	lockout, err := l.Fail(ctx, device)
+	require.NoError(t, err)
+	return lockout
+}
+
+// remaining returns how long device stays locked out.
+func remaining(t *testing.T, l *Lockouts, ctx context.Context, device string) time.Duration {
+	t.Helper()
+	state, err := l.State(ctx, device)
+	require.NoError(t, err)
+	return l.Remaining(state)
+}
+
+// succeed records a successful validation of device.
+func succeed(t *testing.T, l *Lockouts, ctx context.Context, device string) {
+	t.Helper()
+	state, err := l.State(ctx, device)
+	require.NoError(t, err)
+	require.NoError(t, l.Succeed(ctx, device, state))
+}
+
+// list returns the devices that are locked out.
+func list(t *testing.T, l *Lockouts, ctx context.Context) []*domain.Lockout {
+	t.Helper()
+	lockouts, err := l.List(ctx)
+	require.NoError(t, err)
+	return lockouts
+}
+
+func TestLockouts_LockAfterThreshold(t *testing.T) {
+	l, now := newTestLockouts()
+	ctx := context.Background()
+	site := identity.WithTenant(ctx, "site")
+
+	assert.Nil(t, fail(t, l, ctx, "gate-01"))
+	assert.Nil(t, fail(t, l, ctx, "gate-01"))
+	lockout := fail(t, l, ctx, "gate-01")
+	require.NotNil(t, lockout)
+	assert.Equal(t, "gate-01", lockout.Device)
+	assert.Equal(t, 1, lockout.Level)
+	assert.Equal(t, now.Add(time.Minute), lockout.LockedUntil)
+
+	assert.Equal(t, time.Minute, remaining(t, l, ctx, "gate-01"))
+	assert.Zero(t, remaining(t, l, ctx, "gate-02"))
+	assert.Zero(t, remaining(t, l, site, "gate-01"), "devices of other tenants are tracked apart")
+	assert.Len(t, list(t, l, ctx), 1)
+	assert.Empty(t, list(t, l, site))
+
+	*now = now.Add(time.Minute)
+	assert.Zero(t, remaining(t, l, ctx, "gate-01"))
+	assert.Empty(t, list(t, l, ctx))
+}
+
+func TestLockouts_Progressive(t *testing.T) {
+	l, now := newTestLockouts()
+	ctx := context.Background()
+
+	var durations []time.Duration
+	for i := 0; i < 4; i++ {
+		lockout := fail(t, l, ctx, "gate-01")
+		for lockout == nil {
+			lockout = fail(t, l, ctx, "gate-01")
+		}
+		durations = append(durations, lockout.LockedUntil.Sub(*now))
+		*now = lockout.LockedUntil
+	}
+	assert.Equal(t, []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute}, durations)
+
+	// A quiet device starts over.
+	*now = now.Add(4 * time.Minute)
+	fail(t, l, ctx, "gate-01")
+	fail(t, l, ctx, "gate-01")
+	assert.Equal(t, 1, fail(t, l, ctx, "gate-01").Level)
+}
+
+func TestLockouts_WindowAndSuccessReset(t *testing.T) {
+	l, now := newTestLockouts()
+	ctx := context.Background()
+
+	fail(t, l, ctx, "gate-01")
+	fail(t, l, ctx, "gate-01")
+	*now = now.Add(2 * time.Minute)
+	assert.Nil(t, fail(t, l, ctx, "gate-01"), "failures outside the window are not counted")
+
+	fail(t, l, ctx, "gate-01")
+	succeed(t, l, ctx, "gate-01")
+	assert.Nil(t, fail(t, l, ctx, "gate-01"), "a success resets the failures")
+	state, err := l.store.GetLockoutState(ctx, "gate-01")
+	require.NoError(t, err)
+	assert.Equal(t, 1, state.Failures)
+}
+
+func TestLockouts_SuccessKeepsLevel(t *testing.T) {
+	l, now := newTestLockouts()
+	ctx := context.Background()
+
+	fail(t, l, ctx, "gate-01")
+	fail(t, l, ctx, "gate-01")
+	require.NotNil(t, fail(t, l, ctx, "gate-01"))
+	*now = now.Add(time.Minute)
+
+	// A match after the lockout does not start the escalation over.
+	fail(t, l, ctx, "gate-01")
+	succeed(t, l, ctx, "gate-01")
+	fail(t, l, ctx, "gate-01")
+	fail(t, l, ctx, "gate-01")
+	lockout := fail(t, l, ctx, "gate-01")
+	require.NotNil(t, lockout)
+	assert.Equal(t, 2, lockout.Level)
+	assert.Equal(t, 2*time.Minute, lockout.LockedUntil.Sub(*now))
+}
+
+// countingStore counts the updates of a LockoutStore.
+type countingStore struct {
+	LockoutStore
+	updates int
+}
+
+func (s *countingStore) UpdateLockoutState(ctx context.Context, device string, update func(*domain.LockoutState) bool) error {
+	s.updates++
+	return s.LockoutStore.UpdateLockoutState(ctx, device, update)
+}
+
+func TestLockouts_SucceedWithoutFailures(t *testing.T) {
+	store := &countingStore{LockoutStore: NewMemoryLockoutStore()}
+	l := NewLockouts(LockoutPolicy{Threshold: 2, Window: time.Minute, Duration: time.Minute, MaxDuration: time.Hour}, store, logrus.New())
+	ctx := context.Background()
+
+	// Devices without failures are not written to the store.
+	succeed(t, l, ctx, "gate-01")
+	assert.Zero(t, store.updates)
+
+	fail(t, l, ctx, "gate-01")
+	succeed(t, l, ctx, "gate-01")
+	assert.Equal(t, 2, store.updates)
+	state, err := store.GetLockoutState(ctx, "gate-01")
+	require.NoError(t, err)
+	assert.Nil(t, state, "a device without failures and lockouts has no state")
+	succeed(t, l, ctx, "gate-01")
+	assert.Equal(t, 2, store.updates)
+}
+
+func TestLockouts_Clear(t *testing.T) {
+	l, _ := newTestLockouts()
+	ctx := context.Background()
+
+	cleared, err := l.Clear(ctx, "gate-01")
+	require.NoError(t, err)
+	assert.False(t, cleared)
+	fail(t, l, ctx, "gate-01")
+	fail(t, l, ctx, "gate-01")
+	require.NotNil(t, fail(t, l, ctx, "gate-01"))
+	cleared, err = l.Clear(ctx, "gate-01")
+	require.NoError(t, err)
+	assert.True(t, cleared)
+	assert.Zero(t, remaining(t, l, ctx, "gate-01"))
+}
+
+func TestLockouts_SharedStore(t *testing.T) {
+	// Instances sharing a store agree on failures, lockouts and clears.
+	store := NewMemoryLockoutStore()
+	policy := LockoutPolicy{Threshold: 2, Window: time.Minute, Duration: time.Minute, MaxDuration: time.Hour}
+	a, b := NewLockouts(policy, store, logrus.New()), NewLockouts(policy, store, logrus.New())
+	ctx := context.Background()
+
+	assert.Nil(t, fail(t, a, ctx, "gate-01"))
+	require.NotNil(t, fail(t, b, ctx, "gate-01"))
+	assert.Positive(t, remaining(t, a, ctx, "gate-01"))
+	assert.Len(t, list(t, a, ctx), 1)
+
+	cleared, err := b.Clear(ctx, "gate-01")
+	require.NoError(t, err)
+	assert.True(t, cleared)
+	assert.Zero(t, remaining(t, a, ctx, "gate-01"))
+}
+
+func TestLockouts_Prune(t *testing.T) {
+	l, now := newTestLockouts()
+	ctx := context.Background()
+
+	fail(t, l, ctx, "gate-01")
+	fail(t, l, ctx, "gate-02")
+	fail(t, l, ctx, "gate-02")
+	require.NotNil(t, fail(t, l, ctx, "gate-02"))
+
+	// Failing devices are kept for the window, locked out ones for the
+	// maximum lockout after their lockout.
+	*now = now.Add(2 * time.Minute)
+	require.NoError(t, l.prune(ctx))
+	state, err := l.store.GetLockoutState(ctx, "gate-01")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+	state, err = l.store.GetLockoutState(ctx, "gate-02")
+	require.NoError(t, err)
+	assert.NotNil(t, state)
+
+	*now = now.Add(3 * time.Minute)
+	require.NoError(t, l.prune(ctx))
+	state, err = l.store.GetLockoutState(ctx, "gate-02")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func TestLockouts_Disabled(t *testing.T) {
+	l := NewLockouts(LockoutPolicy{}, NewMemoryLockoutStore(), logrus.New())
+	assert.Nil(t, l)
+	ctx := context.Background()
+
+	assert.Nil(t, fail(t, l, ctx, "gate-01"))
+	assert.Zero(t, remaining(t, l, ctx, "gate-01"))
+	assert.Empty(t, list(t, l, ctx))
+	cleared, err := l.Clear(ctx, "gate-01")
+	require.NoError(t, err)
+	assert.False(t, cleared)
+}
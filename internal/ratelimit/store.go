@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+)
+
+// memoryLockoutStore implements LockoutStore in memory, for a single server
+// instance.
+type memoryLockoutStore struct {
+	mu     sync.Mutex
+	states map[deviceKey]*domain.LockoutState
+}
+
+type deviceKey struct {
+	tenant string
+	device string
+}
+
+// NewMemoryLockoutStore creates a LockoutStore that keeps the lockout state
+// in memory. It is only shared by the Lockouts of one server instance.
+func NewMemoryLockoutStore() LockoutStore {
+	return &memoryLockoutStore{states: make(map[deviceKey]*domain.LockoutState)}
+}
+
+func (s *memoryLockoutStore) GetLockoutState(ctx context.Context, device string) (*domain.LockoutState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.states[deviceKey{identity.TenantFromContext(ctx), device}]
+	if !ok {
+		return nil, nil
+	}
+	copied := *state
+	return &copied, nil
+}
+
+func (s *memoryLockoutStore) UpdateLockoutState(ctx context.Context, device string, update func(state *domain.LockoutState) bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := deviceKey{identity.TenantFromContext(ctx), device}
+	state := &domain.LockoutState{Tenant: key.tenant, Device: device}
+	if stored, ok := s.states[key]; ok {
+		*state = *stored
+	}
+	if update(state) {
+		s.states[key] = state
+	} else {
+		delete(s.states, key)
+	}
+	return nil
+}
+
+func (s *memoryLockoutStore) ListLockoutStates(ctx context.Context, now time.Time) ([]*domain.LockoutState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenant := identity.TenantFromContext(ctx)
+	var states []*domain.LockoutState
+	for key, state := range s.states {
+		if key.tenant == tenant && state.LockedUntil.After(now) {
+			copied := *state
+			states = append(states, &copied)
+		}
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Device < states[j].Device })
+	return states, nil
+}
+
+func (s *memoryLockoutStore) DeleteLockoutState(ctx context.Context, device string) (*domain.LockoutState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := deviceKey{identity.TenantFromContext(ctx), device}
+	state, ok := s.states[key]
+	if !ok {
+		return nil, nil
+	}
+	delete(s.states, key)
+	return state, nil
+}
+
+func (s *memoryLockoutStore) DeleteStaleLockoutStates(_ context.Context, seenBefore, lockedBefore time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, state := range s.states {
+		if state.LastSeen.Before(seenBefore) && state.LockedUntil.Before(lockedBefore) {
+			delete(s.states, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+
+	"github.com/lib/pq"
+)
+
+//go:generate mockgen -destination=../mocks/repository/audit_mock.go -package=mocks . AuditRepository
+
+// AuditRepository defines the methods for managing the audit trail of
+// embeddings in the database. Entries are added in the transaction of the
+// change they record, see Transactor, and are kept when their embedding is
+// deleted. Only the entries of the tenant of ctx are read or added.
+type AuditRepository interface {
+	// AddEmbeddingAudit adds an audit entry and sets its ID and creation time.
+	AddEmbeddingAudit(ctx context.Context, audit *domain.EmbeddingAudit) error
+	// ListEmbeddingAudit returns the audit entries of an embedding, oldest first.
+	ListEmbeddingAudit(ctx context.Context, embeddingID int64) ([]*domain.EmbeddingAudit, error)
+}
+
+// auditRepository implements AuditRepository.
+type auditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository creates a new instance of auditRepository.
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) AddEmbeddingAudit(ctx context.Context, audit *domain.EmbeddingAudit) error {
+	const query = `
+		INSERT INTO embedding_audit (tenant_id, embedding_id, device, request_id, fields, old_version, new_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`
+	return conn(ctx, r.db).QueryRowContext(ctx, query, identity.TenantFromContext(ctx), audit.EmbeddingID, audit.Device,
+		audit.RequestID, pq.Array(audit.Fields), audit.OldVersion, audit.NewVersion).
+		Scan(&audit.ID, &audit.CreatedAt)
+}
+
+func (r *auditRepository) ListEmbeddingAudit(ctx context.Context, embeddingID int64) ([]*domain.EmbeddingAudit, error) {
+	const query = `
+		SELECT id, embedding_id, device, request_id, fields, old_version, new_version, created_at
+		FROM embedding_audit
+		WHERE tenant_id = $1 AND embedding_id = $2
+		ORDER BY id`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, identity.TenantFromContext(ctx), embeddingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var audits []*domain.EmbeddingAudit
+	for rows.Next() {
+		a := &domain.EmbeddingAudit{}
+		err := rows.Scan(&a.ID, &a.EmbeddingID, &a.Device, &a.RequestID, (*pq.StringArray)(&a.Fields),
+			&a.OldVersion, &a.NewVersion, &a.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		audits = append(audits, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return audits, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/client"
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+
+	_ "github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+func TestAuditRepository(t *testing.T) {
+	dbCfg, err := cfg.LoadTestDbCfg()
+	if err != nil {
+		t.Fatalf("failed to load test db config: %v", err)
+	}
+
+	db, err := client.ConnectDB(context.Background(), dbCfg, logrus.New())
+	if err != nil {
+		t.Fatalf("failed to connect to test db: %v", err)
+	}
+	defer db.Close()
+	db.Exec("DELETE FROM embedding_audit")
+	defer db.Exec("DELETE FROM embedding_audit")
+	ctx := context.Background()
+
+	repo := NewAuditRepository(db)
+	audit := &domain.EmbeddingAudit{EmbeddingID: 42, Device: "enroll-01", RequestID: "req-1",
+		Fields: []string{"name", "metadata"}, OldVersion: 1, NewVersion: 2}
+	if err := repo.AddEmbeddingAudit(ctx, audit); err != nil {
+		t.Fatalf("AddEmbeddingAudit failed: %v", err)
+	}
+	if audit.ID == 0 || audit.CreatedAt.IsZero() {
+		t.Errorf("AddEmbeddingAudit did not set the ID and creation time: %+v", audit)
+	}
+
+	// Entries are kept without their embedding and listed per tenant
+	audits, err := repo.ListEmbeddingAudit(ctx, 42)
+	if err != nil {
+		t.Fatalf("ListEmbeddingAudit failed: %v", err)
+	}
+	if len(audits) != 1 || audits[0].Device != "enroll-01" || !slices.Equal(audits[0].Fields, audit.Fields) ||
+		audits[0].OldVersion != 1 || audits[0].NewVersion != 2 {
+		t.Errorf("ListEmbeddingAudit returned %+v, want the added entry", audits)
+	}
+
+	other := identity.WithTenant(ctx, "other")
+	if audits, err := repo.ListEmbeddingAudit(other, 42); err != nil || len(audits) != 0 {
+		t.Errorf("ListEmbeddingAudit of another tenant returned %v, %v, want no entries", audits, err)
+	}
+}
@@ -0,0 +1,484 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/events"
+	"access-system-api/internal/health"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/index"
+
+	"github.com/pgvector/pgvector-go"
+	"go.etcd.io/bbolt"
+)
+
+//go:generate mockgen -destination=../mocks/repository/snapshot_mock.go -package=mocks . Snapshotter
+
+// Snapshotter writes backups of a store.
+type Snapshotter interface {
+	// Backup writes a consistent snapshot of the store to w and returns its size.
+	Backup(w io.Writer) (int64, error)
+}
+
+// Buckets of the bolt store, keyed by big-endian IDs so that keys sort like IDs.
+var (
+	embeddingBucket = []byte("embeddings")
+	eventBucket     = []byte("events")
+)
+
+// BoltStore is the embedded on-disk store of the bolt storage backend.
+type BoltStore struct {
+	db *bbolt.DB
+}
+
+// OpenBoltStore opens the bbolt file at path, creating it and its directory
+// if needed. A file is used by a single process at a time: opening fails
+// after timeout while another process has it open.
+func OpenBoltStore(path string, timeout time.Duration) (*BoltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: timeout})
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{embeddingBucket, eventBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+// Close closes the file, waiting for open transactions to finish.
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// Backup writes a consistent snapshot of the store to w and returns its
+// size. The store keeps serving reads and writes meanwhile; the snapshot is
+// a bbolt file that OpenBoltStore can open.
+func (s *BoltStore) Backup(w io.Writer) (int64, error) {
+	var size int64
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		size, err = tx.WriteTo(w)
+		return err
+	})
+	return size, err
+}
+
+// HealthCheck returns a critical readiness check reading the store.
+func (s *BoltStore) HealthCheck() health.Check {
+	return health.Check{
+		Name:     "bolt",
+		Critical: true,
+		Run: func(context.Context) (map[string]any, error) {
+			details := map[string]any{"path": s.db.Path()}
+			err := s.db.View(func(tx *bbolt.Tx) error {
+				details["size_bytes"] = tx.Size()
+				details["embeddings"] = tx.Bucket(embeddingBucket).Stats().KeyN
+				return nil
+			})
+			return details, err
+		},
+	}
+}
+
+// ImportEmbeddings stores embeddings with their IDs, for migrations from
+// another backend. It fails if the store already has embeddings, so that no
+// embedding is overwritten.
+func (s *BoltStore) ImportEmbeddings(_ context.Context, embeddings []*domain.Embedding) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(embeddingBucket)
+		if key, _ := bucket.Cursor().First(); key != nil {
+			return fmt.Errorf("%w: the bolt store already has embeddings", domain.ErrConflict)
+		}
+
+		var lastID int64
+		for _, embedding := range embeddings {
+			if err := putEmbedding(bucket, embedding); err != nil {
+				return err
+			}
+			lastID = max(lastID, embedding.ID)
+		}
+		// New embeddings continue after the imported ones.
+		return bucket.SetSequence(uint64(lastID))
+	})
+}
+
+// boltEmbedding is the stored form of an embedding. Embeddings stored
+// before tenants were introduced have no tenant and belong to the default
+// one; those stored before versions were introduced are at version 1.
+type boltEmbedding struct {
+	ID          int64             `json:"id"`
+	Tenant      string            `json:"tenant,omitempty"`
+	Name        string            `json:"name"`
+	Vector      []float32         `json:"vector"`
+	MinLiveness float64           `json:"min_liveness"`
+	MinQuality  float64           `json:"min_quality"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Version     int64             `json:"version,omitempty"`
+}
+
+func putEmbedding(bucket *bbolt.Bucket, embedding *domain.Embedding) error {
+	value, err := json.Marshal(boltEmbedding{
+		ID:          embedding.ID,
+		Tenant:      embedding.Tenant,
+		Name:        embedding.Name,
+		Vector:      embedding.Vector.Slice(),
+		MinLiveness: embedding.CaptureMinimums.Liveness,
+		MinQuality:  embedding.CaptureMinimums.Quality,
+		Metadata:    embedding.Metadata,
+		Version:     embedding.Version,
+	})
+	if err != nil {
+		return err
+	}
+	return bucket.Put(boltKey(embedding.ID), value)
+}
+
+// getEmbedding returns the embedding of tenant stored under id, or nil if
+// there is none.
+func getEmbedding(bucket *bbolt.Bucket, tenant string, id int64) (*domain.Embedding, error) {
+	value := bucket.Get(boltKey(id))
+	if value == nil {
+		return nil, nil
+	}
+	embedding, err := decodeEmbedding(value)
+	if err != nil || embedding.Tenant != tenant {
+		return nil, err
+	}
+	return embedding, nil
+}
+
+func decodeEmbedding(value []byte) (*domain.Embedding, error) {
+	var stored boltEmbedding
+	if err := json.Unmarshal(value, &stored); err != nil {
+		return nil, err
+	}
+	if stored.Tenant == "" {
+		stored.Tenant = identity.DefaultTenant
+	}
+	if stored.Version == 0 {
+		stored.Version = 1
+	}
+	return &domain.Embedding{
+		ID:              stored.ID,
+		Tenant:          stored.Tenant,
+		Name:            stored.Name,
+		Vector:          pgvector.NewVector(stored.Vector),
+		CaptureMinimums: domain.CaptureMinimums{Liveness: stored.MinLiveness, Quality: stored.MinQuality},
+		Metadata:        stored.Metadata,
+		Version:         stored.Version,
+	}, nil
+}
+
+func boltKey(id int64) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(id))
+}
+
+// boltEmbeddingRepository implements EmbeddingRepository on a BoltStore,
+// with the semantics of embeddingRepository. Similarity searches are
+// answered by an in-process index of the stored embeddings.
+type boltEmbeddingRepository struct {
+	store *BoltStore
+	// mu serializes writes, so that the index applies them in commit order.
+	mu        sync.Mutex
+	index     *index.Index
+	threshold float64
+}
+
+// NewBoltEmbeddingRepository creates an EmbeddingRepository keeping the
+// embeddings in store and loads them into its index. threshold is the
+// minimum cosine similarity for GetSimilarEmbeddingByVector to return a match.
+func NewBoltEmbeddingRepository(store *BoltStore, threshold float64) (EmbeddingRepository, error) {
+	r := &boltEmbeddingRepository{store: store, index: index.New(), threshold: threshold}
+	embeddings, err := r.ListAllEmbeddings(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	r.index.Replace(embeddings)
+	return r, nil
+}
+
+func (r *boltEmbeddingRepository) CreateEmbedding(ctx context.Context, embedding *domain.Embedding) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := &domain.Embedding{Tenant: identity.TenantFromContext(ctx), Name: embedding.Name, Vector: copyVector(embedding.Vector), Version: 1}
+	err := r.store.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(embeddingBucket)
+		id, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		stored.ID = int64(id)
+		return putEmbedding(bucket, stored)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.index.Upsert(stored)
+	return nil
+}
+
+func (r *boltEmbeddingRepository) GetEmbeddingById(ctx context.Context, id int64) (*domain.Embedding, error) {
+	var embedding *domain.Embedding
+	err := r.store.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		embedding, err = getEmbedding(tx.Bucket(embeddingBucket), identity.TenantFromContext(ctx), id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if embedding == nil {
+		return nil, fmt.Errorf("%w: embedding %d", domain.ErrNotFound, id)
+	}
+	return embedding, nil
+}
+
+func (r *boltEmbeddingRepository) ListEmbeddings(ctx context.Context) ([]*domain.Embedding, error) {
+	tenant := identity.TenantFromContext(ctx)
+	return r.list(func(embedding *domain.Embedding) bool { return embedding.Tenant == tenant })
+}
+
+func (r *boltEmbeddingRepository) ListAllEmbeddings(_ context.Context) ([]*domain.Embedding, error) {
+	return r.list(func(*domain.Embedding) bool { return true })
+}
+
+// list returns the stored embeddings for which keep returns true, by ID.
+func (r *boltEmbeddingRepository) list(keep func(*domain.Embedding) bool) ([]*domain.Embedding, error) {
+	var embeddings []*domain.Embedding
+	err := r.store.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(embeddingBucket).ForEach(func(_, value []byte) error {
+			embedding, err := decodeEmbedding(value)
+			if err != nil {
+				return err
+			}
+			if keep(embedding) {
+				embeddings = append(embeddings, embedding)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings, nil
+}
+
+func (r *boltEmbeddingRepository) GetSimilarEmbeddingByVector(ctx context.Context, vector pgvector.Vector) (*domain.Embedding, error) {
+	embedding, ok := r.index.Search(identity.TenantFromContext(ctx), vector.Slice(), r.threshold)
+	if !ok {
+		return nil, domain.ErrNoMatch
+	}
+	return embedding, nil
+}
+
+func (r *boltEmbeddingRepository) GetSimilarEmbeddingsByVectors(ctx context.Context, vectors []pgvector.Vector) ([]*domain.Embedding, error) {
+	return searchAll(r.index, identity.TenantFromContext(ctx), vectors, r.threshold), nil
+}
+
+func (r *boltEmbeddingRepository) UpdateEmbedding(ctx context.Context, embedding *domain.Embedding) error {
+	updated, err := r.update(ctx, embedding.ID, embedding.Version, func(stored *domain.Embedding) error {
+		stored.Name = embedding.Name
+		stored.Vector = copyVector(embedding.Vector)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	embedding.Version = updated.Version
+	return nil
+}
+
+func (r *boltEmbeddingRepository) PatchEmbedding(ctx context.Context, id int64, patch domain.EmbeddingPatch, version int64) (*domain.Embedding, error) {
+	if len(patch.Fields()) == 0 {
+		return nil, fmt.Errorf("%w: the patch changes no field", domain.ErrInvalidInput)
+	}
+	updated, err := r.update(ctx, id, version, func(stored *domain.Embedding) error {
+		patch.Apply(stored)
+		stored.Vector = copyVector(stored.Vector)
+		return checkMetadata(stored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyEmbedding(updated), nil
+}
+
+func (r *boltEmbeddingRepository) UpdateCaptureMinimums(ctx context.Context, id int64, minimums domain.CaptureMinimums, version int64) (int64, error) {
+	updated, err := r.update(ctx, id, version, func(stored *domain.Embedding) error {
+		stored.CaptureMinimums = minimums
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return updated.Version, nil
+}
+
+// update applies change to the embedding id of the tenant of ctx if it is at
+// version, or at any version if that is 0, and returns the changed embedding.
+// Nothing is changed if change fails.
+func (r *boltEmbeddingRepository) update(ctx context.Context, id int64, version int64, change func(*domain.Embedding) error) (*domain.Embedding, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var updated *domain.Embedding
+	err := r.store.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(embeddingBucket)
+		stored, err := getEmbedding(bucket, identity.TenantFromContext(ctx), id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(stored, id, version); err != nil {
+			return err
+		}
+		if err := change(stored); err != nil {
+			return err
+		}
+		stored.Version++
+		updated = stored
+		return putEmbedding(bucket, stored)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.index.Upsert(updated)
+	return updated, nil
+}
+
+func (r *boltEmbeddingRepository) DeleteEmbeddingById(ctx context.Context, id int64, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.store.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(embeddingBucket)
+		stored, err := getEmbedding(bucket, identity.TenantFromContext(ctx), id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(stored, id, version); err != nil {
+			return err
+		}
+		return bucket.Delete(boltKey(id))
+	})
+	if err != nil {
+		return err
+	}
+
+	r.index.Remove(id)
+	return nil
+}
+
+// boltEventRepository implements EventRepository on a BoltStore. Events are
+// kept in the order they were added.
+type boltEventRepository struct {
+	store *BoltStore
+}
+
+// NewBoltEventRepository creates an EventRepository keeping the events in store.
+func NewBoltEventRepository(store *BoltStore) EventRepository {
+	return &boltEventRepository{store: store}
+}
+
+func (r *boltEventRepository) AddEvents(_ context.Context, list []events.Event) error {
+	return r.store.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(eventBucket)
+		for _, event := range list {
+			value, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			id, err := bucket.NextSequence()
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put(boltKey(int64(id)), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListEvents lists the most recent events of the tenant of ctx. Events
+// recorded before tenants were introduced belong to the default tenant.
+func (r *boltEventRepository) ListEvents(ctx context.Context, limit int) ([]events.Event, error) {
+	tenant := identity.TenantFromContext(ctx)
+	var list []events.Event
+	err := r.store.db.View(func(tx *bbolt.Tx) error {
+		cursor := tx.Bucket(eventBucket).Cursor()
+		for key, value := cursor.Last(); key != nil && len(list) < limit; key, value = cursor.Prev() {
+			var event events.Event
+			if err := json.Unmarshal(value, &event); err != nil {
+				return err
+			}
+			if event.Tenant == "" {
+				event.Tenant = identity.DefaultTenant
+			}
+			if event.Tenant == tenant {
+				list = append(list, event)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// DeleteEventsBefore removes the oldest events, up to the first one that
+// happened at or after t.
+func (r *boltEventRepository) DeleteEventsBefore(_ context.Context, t time.Time) (int, error) {
+	var deleted int
+	err := r.store.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(eventBucket)
+		var keys [][]byte
+		cursor := bucket.Cursor()
+		for key, value := cursor.First(); key != nil; key, value = cursor.Next() {
+			var event events.Event
+			if err := json.Unmarshal(value, &event); err != nil {
+				return err
+			}
+			if !event.Time.Before(t) {
+				break
+			}
+			keys = append(keys, bytes.Clone(key))
+		}
+
+		for _, key := range keys {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		deleted = len(keys)
+		return nil
+	})
+	return deleted, err
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/events"
+	"access-system-api/internal/identity"
+
+	"github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openBoltStore(t *testing.T, path string) *BoltStore {
+	t.Helper()
+	store, err := OpenBoltStore(path, time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestBoltEmbeddingRepository_Persists(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "data", "access.db")
+
+	store, err := OpenBoltStore(path, time.Second)
+	require.NoError(t, err)
+	repo, err := NewBoltEmbeddingRepository(store, 0.5)
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateEmbedding(ctx, &domain.Embedding{Name: "alice", Vector: pgvector.NewVector([]float32{1, 0})}))
+	_, err = repo.UpdateCaptureMinimums(ctx, 1, domain.CaptureMinimums{Liveness: 0.8}, 0)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	// The index of a reopened store is loaded from the file.
+	repo, err = NewBoltEmbeddingRepository(openBoltStore(t, path), 0.5)
+	require.NoError(t, err)
+	match, err := repo.GetSimilarEmbeddingByVector(ctx, pgvector.NewVector([]float32{1, 0.1}))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), match.ID)
+	assert.Equal(t, "alice", match.Name)
+	assert.Equal(t, domain.CaptureMinimums{Liveness: 0.8}, match.CaptureMinimums)
+
+	// IDs are not reused after a restart.
+	require.NoError(t, repo.CreateEmbedding(ctx, &domain.Embedding{Name: "bob", Vector: pgvector.NewVector([]float32{0, 1})}))
+	embeddings, err := repo.ListEmbeddings(ctx)
+	require.NoError(t, err)
+	require.Len(t, embeddings, 2)
+	assert.Equal(t, int64(2), embeddings[1].ID)
+}
+
+func TestBoltStore_OpenLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.db")
+	openBoltStore(t, path)
+
+	_, err := OpenBoltStore(path, 10*time.Millisecond)
+	assert.Error(t, err)
+}
+
+func TestBoltStore_Backup(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := openBoltStore(t, filepath.Join(dir, "access.db"))
+	repo, err := NewBoltEmbeddingRepository(store, 0.5)
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateEmbedding(ctx, &domain.Embedding{Name: "alice", Vector: pgvector.NewVector([]float32{1, 0})}))
+
+	var backup bytes.Buffer
+	size, err := store.Backup(&backup)
+	require.NoError(t, err)
+	assert.Equal(t, int64(backup.Len()), size)
+
+	// The backup is a store of its own.
+	path := filepath.Join(dir, "restored.db")
+	require.NoError(t, os.WriteFile(path, backup.Bytes(), 0o600))
+	restored, err := NewBoltEmbeddingRepository(openBoltStore(t, path), 0.5)
+	require.NoError(t, err)
+	embedding, err := restored.GetEmbeddingById(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", embedding.Name)
+
+	details, err := store.HealthCheck().Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, details["embeddings"])
+}
+
+func TestBoltStore_ImportEmbeddings(t *testing.T) {
+	ctx := context.Background()
+	store := openBoltStore(t, filepath.Join(t.TempDir(), "access.db"))
+	source := NewMemoryEmbeddingRepository(0.5)
+	for _, name := range []string{"alice", "bob", "carol"} {
+		require.NoError(t, source.CreateEmbedding(ctx, &domain.Embedding{Name: name, Vector: pgvector.NewVector([]float32{1, 0})}))
+	}
+	require.NoError(t, source.DeleteEmbeddingById(ctx, 2, 0))
+
+	copied, err := MigrateEmbeddings(ctx, source, store)
+	require.NoError(t, err)
+	assert.Equal(t, 2, copied)
+
+	repo, err := NewBoltEmbeddingRepository(store, 0.5)
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateEmbedding(ctx, &domain.Embedding{Name: "dave", Vector: pgvector.NewVector([]float32{0, 1})}))
+	embeddings, err := repo.ListEmbeddings(ctx)
+	require.NoError(t, err)
+	var ids []int64
+	for _, embedding := range embeddings {
+		ids = append(ids, embedding.ID)
+	}
+	assert.Equal(t, []int64{1, 3, 4}, ids)
+
+	// A store with embeddings is not overwritten.
+	_, err = MigrateEmbeddings(ctx, source, store)
+	assert.ErrorIs(t, err, domain.ErrConflict)
+}
+
+func TestBoltEventRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewBoltEventRepository(openBoltStore(t, filepath.Join(t.TempDir(), "access.db")))
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var batch []events.Event
+	for i, id := range []string{"1", "2", "3"} {
+		batch = append(batch, events.Event{ID: id, Type: events.TypeValidation, Tenant: identity.DefaultTenant, Time: start.Add(time.Duration(i) * time.Minute)})
+	}
+	// Events of other tenants are recorded but not listed.
+	batch[1].Tenant = "site"
+	require.NoError(t, repo.AddEvents(ctx, batch[:2]))
+	require.NoError(t, repo.AddEvents(ctx, batch[2:]))
+
+	list, err := repo.ListEvents(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []events.Event{batch[2], batch[0]}, list)
+	list, err = repo.ListEvents(identity.WithTenant(ctx, "site"), 2)
+	require.NoError(t, err)
+	assert.Equal(t, []events.Event{batch[1]}, list)
+
+	deleted, err := repo.DeleteEventsBefore(ctx, start.Add(90*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	list, err = repo.ListEvents(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []events.Event{batch[2]}, list)
+}
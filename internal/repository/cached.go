@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/domain"
+	"access-system-api/internal/health"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/index"
+	"access-system-api/internal/metrics"
+
+	"github.com/lib/pq"
+	"github.com/pgvector/pgvector-go"
+	"github.com/sirupsen/logrus"
+)
+
+// changesChannel is the Postgres channel embedding changes are notified on,
+// see migration 006_embedding_changes.sql.
+const changesChannel = "embedding_changes"
+
+// Sources of similarity searches reported to ObserveIndexLookup.
+const (
+	lookupIndex    = "index"
+	lookupDatabase = "database"
+)
+
+// ErrIndexStale is reported by the health check while searches fall back to the database.
+var ErrIndexStale = errors.New("vector index is stale")
+
+// embeddingChange is the payload of a notification on changesChannel.
+type embeddingChange struct {
+	Op     string `json:"op"`
+	ID     int64  `json:"id"`
+	Tenant string `json:"tenant"`
+}
+
+// CachedEmbeddingRepository wraps an EmbeddingRepository and answers
+// similarity searches from an in-memory index of its embeddings. The index is
+// loaded by Run and kept in sync through Postgres LISTEN/NOTIFY. While it is
+// stale, i.e. before it is loaded or while changes may have been missed,
+// searches fall back to next. The index turns stale as soon as the listener
+// loses its connection, and is reloaded once it is back.
+type CachedEmbeddingRepository struct {
+	EmbeddingRepository
+	index     *index.Index
+	listener  *pq.Listener
+	threshold float64
+	cfg       cfg.IndexCfg
+	fresh     atomic.Bool
+	loadedAt  atomic.Pointer[time.Time]
+	metrics   *metrics.Metrics
+	log       *logrus.Logger
+
+	// mu orders the updates of fresh with the listener events, so that an
+	// index loaded while the listener was disconnected is not marked fresh.
+	mu           sync.Mutex
+	disconnected bool
+	disconnects  uint64
+}
+
+// NewCachedEmbeddingRepository creates a CachedEmbeddingRepository on top of
+// next. newListener creates the change listener, with the given callback
+// for its connection events, on the database of next. threshold is the
+// minimum cosine similarity for a match, as for next. Run must be running for
+// searches to use the index.
+func NewCachedEmbeddingRepository(next EmbeddingRepository, newListener func(pq.EventCallbackType) *pq.Listener, threshold float64, indexCfg cfg.IndexCfg, m *metrics.Metrics, log *logrus.Logger) *CachedEmbeddingRepository {
+	r := &CachedEmbeddingRepository{
+		EmbeddingRepository: next,
+		index:               index.New(),
+		threshold:           threshold,
+		cfg:                 indexCfg,
+		metrics:             m,
+		log:                 log,
+	}
+	if newListener != nil {
+		r.listener = newListener(r.listenerEvent)
+	}
+	return r
+}
+
+// GetSimilarEmbeddingByVector searches the index, or the database if the
+// index is stale or ctx carries a transaction that may have changed
+// embeddings.
+func (r *CachedEmbeddingRepository) GetSimilarEmbeddingByVector(ctx context.Context, vector pgvector.Vector) (*domain.Embedding, error) {
+	if !r.fresh.Load() || InTx(ctx) {
+		r.metrics.ObserveIndexLookup(lookupDatabase)
+		return r.EmbeddingRepository.GetSimilarEmbeddingByVector(ctx, vector)
+	}
+
+	r.metrics.ObserveIndexLookup(lookupIndex)
+	embedding, ok := r.index.Search(identity.TenantFromContext(ctx), vector.Slice(), r.threshold)
+	if !ok {
+		return nil, domain.ErrNoMatch
+	}
+	return embedding, nil
+}
+
+// GetSimilarEmbeddingsByVectors searches the index like
+// GetSimilarEmbeddingByVector, or the database in a single query.
+func (r *CachedEmbeddingRepository) GetSimilarEmbeddingsByVectors(ctx context.Context, vectors []pgvector.Vector) ([]*domain.Embedding, error) {
+	if !r.fresh.Load() || InTx(ctx) {
+		r.metrics.ObserveIndexLookup(lookupDatabase)
+		return r.EmbeddingRepository.GetSimilarEmbeddingsByVectors(ctx, vectors)
+	}
+
+	r.metrics.ObserveIndexLookup(lookupIndex)
+	return searchAll(r.index, identity.TenantFromContext(ctx), vectors, r.threshold), nil
+}
+
+// Run loads the index and applies the changes of every instance until ctx is
+// done. The index is reloaded when the listener reconnects, when a change
+// cannot be applied and every resync interval.
+func (r *CachedEmbeddingRepository) Run(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, func() { _ = r.listener.Close() })
+	defer stop()
+
+	// Listen before loading, so that no change made meanwhile is missed.
+	if err := r.listener.Listen(changesChannel); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("listen on %s: %w", changesChannel, err)
+	}
+	r.reload(ctx)
+
+	check := time.NewTicker(r.cfg.CheckInterval)
+	defer check.Stop()
+	resync := time.NewTicker(r.cfg.ResyncInterval)
+	defer resync.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case notification, ok := <-r.listener.Notify:
+			if !ok {
+				return nil
+			}
+			r.apply(ctx, notification)
+		case <-check.C:
+			if err := r.listener.Ping(); err != nil {
+				if r.fresh.Swap(false) {
+					r.log.WithError(err).Warn("Embedding change listener disconnected, searching the database")
+				}
+				continue
+			}
+			if !r.fresh.Load() {
+				r.reload(ctx)
+			}
+		case <-resync.C:
+			r.reload(ctx)
+		}
+	}
+}
+
+// HealthCheck returns a non-critical readiness check failing while the index is stale.
+func (r *CachedEmbeddingRepository) HealthCheck() health.Check {
+	return health.Check{
+		Name:     "memory_index",
+		Critical: false,
+		Run: func(context.Context) (map[string]any, error) {
+			if !r.fresh.Load() {
+				return nil, ErrIndexStale
+			}
+			details := map[string]any{"embeddings": r.index.Len()}
+			if loadedAt := r.loadedAt.Load(); loadedAt != nil {
+				details["loaded_at"] = *loadedAt
+			}
+			return details, nil
+		},
+	}
+}
+
+// listenerEvent marks the index stale when the listener loses its
+// connection: changes of other instances, such as deletes, are missed until
+// it reconnects and the index is reloaded.
+func (r *CachedEmbeddingRepository) listenerEvent(event pq.ListenerEventType, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch event {
+	case pq.ListenerEventDisconnected, pq.ListenerEventConnectionAttemptFailed:
+		r.disconnected = true
+		r.disconnects++
+		if r.fresh.Swap(false) {
+			r.log.WithError(err).Warn("Embedding change listener disconnected, searching the database")
+		}
+	case pq.ListenerEventConnected, pq.ListenerEventReconnected:
+		r.disconnected = false
+	}
+}
+
+// reload replaces the index with the embeddings of the database. The index
+// stays stale if they cannot be listed, or if the listener disconnected
+// meanwhile.
+func (r *CachedEmbeddingRepository) reload(ctx context.Context) {
+	r.mu.Lock()
+	disconnects := r.disconnects
+	r.mu.Unlock()
+
+	embeddings, err := r.EmbeddingRepository.ListAllEmbeddings(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.fresh.Store(false)
+			r.log.WithError(err).Error("Error loading vector index, searching the database")
+		}
+		return
+	}
+
+	r.index.Replace(embeddings)
+	now := time.Now()
+	r.loadedAt.Store(&now)
+	r.metrics.SetIndexSize(r.index.Len())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.disconnected || r.disconnects != disconnects {
+		return
+	}
+	if !r.fresh.Swap(true) {
+		r.log.WithField("embeddings", r.index.Len()).Info("Vector index loaded")
+	}
+}
+
+// apply updates the index with a notified change. A nil notification
+// signals a reconnect of the listener, after which changes may be missing.
+func (r *CachedEmbeddingRepository) apply(ctx context.Context, notification *pq.Notification) {
+	if notification == nil {
+		r.log.Info("Embedding change listener reconnected")
+		r.reload(ctx)
+		return
+	}
+
+	var change embeddingChange
+	if err := json.Unmarshal([]byte(notification.Extra), &change); err != nil || change.Op == "TRUNCATE" {
+		r.reload(ctx)
+		return
+	}
+
+	if change.Op == "DELETE" {
+		r.index.Remove(change.ID)
+		r.metrics.SetIndexSize(r.index.Len())
+		return
+	}
+
+	// The row is read again, so a change that is already outdated applies
+	// the latest state.
+	embedding, err := r.EmbeddingRepository.GetEmbeddingById(identity.WithTenant(ctx, change.Tenant), change.ID)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		r.index.Remove(change.ID)
+	case err != nil:
+		if ctx.Err() == nil {
+			r.fresh.Store(false)
+			r.log.WithError(err).WithField("embedding_id", change.ID).Error("Error applying embedding change, searching the database")
+		}
+		return
+	default:
+		r.index.Upsert(embedding)
+	}
+	r.metrics.SetIndexSize(r.index.Len())
+}
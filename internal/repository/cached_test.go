@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/metrics"
+	mocks "access-system-api/internal/mocks/repository"
+
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/pgvector/pgvector-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedEmbeddingRepository_GetSimilarEmbeddingByVector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingRepository(ctrl)
+	repo := NewCachedEmbeddingRepository(next, nil, 0.5, cfg.Default().Index, metrics.New(), logrus.New())
+	ctx := context.Background()
+	probe := pgvector.NewVector([]float32{1, 0.1})
+
+	// Before the index is loaded, searches use the database.
+	next.EXPECT().GetSimilarEmbeddingByVector(ctx, probe).Return(nil, domain.ErrNoMatch)
+	_, err := repo.GetSimilarEmbeddingByVector(ctx, probe)
+	assert.ErrorIs(t, err, domain.ErrNoMatch)
+	_, err = repo.HealthCheck().Run(ctx)
+	assert.ErrorIs(t, err, ErrIndexStale)
+
+	next.EXPECT().ListAllEmbeddings(ctx).Return([]*domain.Embedding{
+		{ID: 1, Tenant: identity.DefaultTenant, Name: "one", Vector: pgvector.NewVector([]float32{1, 0})},
+		{ID: 2, Tenant: identity.DefaultTenant, Name: "two", Vector: pgvector.NewVector([]float32{0, 1})},
+		{ID: 3, Tenant: "site", Name: "three", Vector: pgvector.NewVector([]float32{1, 0.1})},
+	}, nil)
+	repo.reload(ctx)
+
+	embedding, err := repo.GetSimilarEmbeddingByVector(ctx, probe)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), embedding.ID)
+	assert.InDelta(t, 0.995, embedding.Accuracy, 0.001)
+
+	_, err = repo.GetSimilarEmbeddingByVector(ctx, pgvector.NewVector([]float32{-1, 0}))
+	assert.ErrorIs(t, err, domain.ErrNoMatch)
+
+	// Only embeddings of the tenant of the probe match.
+	embedding, err = repo.GetSimilarEmbeddingByVector(identity.WithTenant(ctx, "site"), pgvector.NewVector([]float32{1, 0}))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), embedding.ID)
+
+	details, err := repo.HealthCheck().Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, details["embeddings"])
+}
+
+func TestCachedEmbeddingRepository_Apply(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingRepository(ctrl)
+	repo := NewCachedEmbeddingRepository(next, nil, 0.5, cfg.Default().Index, metrics.New(), logrus.New())
+	ctx := context.Background()
+
+	next.EXPECT().ListAllEmbeddings(ctx).Return([]*domain.Embedding{
+		{ID: 1, Tenant: identity.DefaultTenant, Name: "one", Vector: pgvector.NewVector([]float32{1, 0})},
+	}, nil)
+	repo.reload(ctx)
+
+	// Changed embeddings are read in the tenant of the change.
+	next.EXPECT().GetEmbeddingById(identity.WithTenant(ctx, identity.DefaultTenant), int64(2)).
+		Return(&domain.Embedding{ID: 2, Tenant: identity.DefaultTenant, Name: "two", Vector: pgvector.NewVector([]float32{0, 1})}, nil)
+	repo.apply(ctx, &pq.Notification{Extra: `{"op":"INSERT","id":2,"tenant":"default"}`})
+	embedding, err := repo.GetSimilarEmbeddingByVector(ctx, pgvector.NewVector([]float32{0, 1}))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), embedding.ID)
+
+	repo.apply(ctx, &pq.Notification{Extra: `{"op":"DELETE","id":1,"tenant":"default"}`})
+	_, err = repo.GetSimilarEmbeddingByVector(ctx, pgvector.NewVector([]float32{1, 0}))
+	assert.ErrorIs(t, err, domain.ErrNoMatch)
+
+	// A change that cannot be applied makes the index stale until it is reloaded.
+	next.EXPECT().GetEmbeddingById(gomock.Any(), int64(3)).Return(nil, errors.New("connection reset"))
+	repo.apply(ctx, &pq.Notification{Extra: `{"op":"UPDATE","id":3,"tenant":"default"}`})
+	next.EXPECT().GetSimilarEmbeddingByVector(ctx, gomock.Any()).Return(nil, domain.ErrNoMatch)
+	_, err = repo.GetSimilarEmbeddingByVector(ctx, pgvector.NewVector([]float32{1, 0}))
+	assert.ErrorIs(t, err, domain.ErrNoMatch)
+
+	next.EXPECT().ListAllEmbeddings(ctx).Return(nil, nil)
+	repo.apply(ctx, nil)
+	_, err = repo.HealthCheck().Run(ctx)
+	assert.NoError(t, err)
+}
+
+func TestCachedEmbeddingRepository_ListenerDisconnect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingRepository(ctrl)
+	repo := NewCachedEmbeddingRepository(next, nil, 0.5, cfg.Default().Index, metrics.New(), logrus.New())
+	ctx := context.Background()
+
+	next.EXPECT().ListAllEmbeddings(ctx).Return(nil, nil).Times(3)
+	repo.reload(ctx)
+	_, err := repo.HealthCheck().Run(ctx)
+	require.NoError(t, err)
+
+	// Deletes of other instances are missed while disconnected, so the
+	// index is stale right away, and stays stale until the listener is back.
+	repo.listenerEvent(pq.ListenerEventDisconnected, errors.New("connection reset"))
+	_, err = repo.HealthCheck().Run(ctx)
+	assert.ErrorIs(t, err, ErrIndexStale)
+
+	repo.reload(ctx)
+	_, err = repo.HealthCheck().Run(ctx)
+	assert.ErrorIs(t, err, ErrIndexStale)
+
+	repo.listenerEvent(pq.ListenerEventReconnected, nil)
+	repo.apply(ctx, nil)
+	_, err = repo.HealthCheck().Run(ctx)
+	assert.NoError(t, err)
+}
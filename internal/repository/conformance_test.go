@@ -0,0 +1,56 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/client"
+	"access-system-api/internal/repository"
+	"access-system-api/internal/repository/repotest"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddingRepository_Conformance(t *testing.T) {
+	dbCfg, err := cfg.LoadTestDbCfg()
+	if err != nil {
+		t.Fatalf("failed to load test db config: %v", err)
+	}
+
+	db, err := client.ConnectDB(context.Background(), dbCfg, logrus.New())
+	if err != nil {
+		t.Fatalf("failed to connect to test db: %v", err)
+	}
+	defer db.Close()
+
+	repotest.TestEmbeddingRepository(t, func(t *testing.T) repository.EmbeddingRepository {
+		_, err := db.Exec("DELETE FROM embedding")
+		require.NoError(t, err)
+		_, err = db.Exec("INSERT INTO tenant (id, name) VALUES ($1, $1) ON CONFLICT (id) DO NOTHING", repotest.Tenant)
+		require.NoError(t, err)
+		t.Cleanup(func() { _, _ = db.Exec("DELETE FROM embedding") })
+		return repository.NewEmbeddingsRepository(db, repotest.Threshold)
+	})
+}
+
+func TestMemoryEmbeddingRepository_Conformance(t *testing.T) {
+	repotest.TestEmbeddingRepository(t, func(t *testing.T) repository.EmbeddingRepository {
+		return repository.NewMemoryEmbeddingRepository(repotest.Threshold)
+	})
+}
+
+func TestBoltEmbeddingRepository_Conformance(t *testing.T) {
+	repotest.TestEmbeddingRepository(t, func(t *testing.T) repository.EmbeddingRepository {
+		store, err := repository.OpenBoltStore(filepath.Join(t.TempDir(), "access.db"), time.Second)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = store.Close() })
+
+		repo, err := repository.NewBoltEmbeddingRepository(store, repotest.Threshold)
+		require.NoError(t, err)
+		return repo
+	})
+}
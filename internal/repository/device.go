@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+)
+
+//go:generate mockgen -destination=../mocks/repository/device_mock.go -package=mocks . DeviceRepository
+
+// DeviceRepository defines the methods for managing the device registry in
+// the database. Every method is scoped to the tenant of ctx, and names are
+// unique within a tenant.
+type DeviceRepository interface {
+	// CreateDevice registers device, failing with domain.ErrConflict if its name is taken.
+	CreateDevice(ctx context.Context, device *domain.Device) error
+	GetDeviceById(ctx context.Context, id int64) (*domain.Device, error)
+	GetDeviceByName(ctx context.Context, name string) (*domain.Device, error)
+	ListDevices(ctx context.Context) ([]*domain.Device, error)
+	// UpdateSigningKey replaces the signing key of a device and returns the updated device.
+	UpdateSigningKey(ctx context.Context, id int64, signingKey string) (*domain.Device, error)
+	DeleteDeviceById(ctx context.Context, id int64) error
+}
+
+// deviceRepository implements DeviceRepository.
+type deviceRepository struct {
+	db *sql.DB
+}
+
+// NewDeviceRepository creates a new instance of deviceRepository.
+func NewDeviceRepository(db *sql.DB) DeviceRepository {
+	return &deviceRepository{db: db}
+}
+
+func (r *deviceRepository) CreateDevice(ctx context.Context, device *domain.Device) error {
+	const query = `
+		INSERT INTO device (tenant_id, name, signing_key) VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id, name) DO NOTHING
+		RETURNING id, created_at, key_rotated_at`
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, identity.TenantFromContext(ctx), device.Name, device.SigningKey).
+		Scan(&device.ID, &device.CreatedAt, &device.KeyRotatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: device %s is already registered", domain.ErrConflict, device.Name)
+	}
+	return err
+}
+
+func (r *deviceRepository) GetDeviceById(ctx context.Context, id int64) (*domain.Device, error) {
+	const query = "SELECT id, name, signing_key, created_at, key_rotated_at FROM device WHERE id = $1 AND tenant_id = $2"
+	return r.getDevice(ctx, query, id, fmt.Sprintf("device %d", id))
+}
+
+func (r *deviceRepository) GetDeviceByName(ctx context.Context, name string) (*domain.Device, error) {
+	const query = "SELECT id, name, signing_key, created_at, key_rotated_at FROM device WHERE name = $1 AND tenant_id = $2"
+	return r.getDevice(ctx, query, name, "device "+name)
+}
+
+func (r *deviceRepository) ListDevices(ctx context.Context) ([]*domain.Device, error) {
+	const query = "SELECT id, name, signing_key, created_at, key_rotated_at FROM device WHERE tenant_id = $1 ORDER BY name"
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, identity.TenantFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var devices []*domain.Device
+	for rows.Next() {
+		device := &domain.Device{}
+		if err := rows.Scan(&device.ID, &device.Name, &device.SigningKey, &device.CreatedAt, &device.KeyRotatedAt); err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return devices, nil
+}
+
+func (r *deviceRepository) UpdateSigningKey(ctx context.Context, id int64, signingKey string) (*domain.Device, error) {
+	const query = `
+		UPDATE device SET signing_key = $3, key_rotated_at = now()
+		WHERE id = $1 AND tenant_id = $2
+		RETURNING id, name, signing_key, created_at, key_rotated_at`
+	return r.getDevice(ctx, query, id, fmt.Sprintf("device %d", id), signingKey)
+}
+
+func (r *deviceRepository) DeleteDeviceById(ctx context.Context, id int64) error {
+	const query = "DELETE FROM device WHERE id = $1 AND tenant_id = $2"
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, identity.TenantFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	return requireRow(result, fmt.Errorf("%w: device %d", domain.ErrNotFound, id))
+}
+
+// getDevice scans the single device row returned by query, or fails with
+// domain.ErrNotFound mentioning what if there is none. query takes key, the
+// tenant of ctx and args as parameters.
+func (r *deviceRepository) getDevice(ctx context.Context, query string, key any, what string, args ...any) (*domain.Device, error) {
+	device := &domain.Device{}
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, append([]any{key, identity.TenantFromContext(ctx)}, args...)...).
+		Scan(&device.ID, &device.Name, &device.SigningKey, &device.CreatedAt, &device.KeyRotatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrNotFound, what)
+		}
+		return nil, err
+	}
+
+	return device, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/client"
+	"access-system-api/internal/domain"
+
+	_ "github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+func TestDeviceRepository(t *testing.T) {
+	dbCfg, err := cfg.LoadTestDbCfg()
+	if err != nil {
+		t.Fatalf("failed to load test db config: %v", err)
+	}
+
+	db, err := client.ConnectDB(context.Background(), dbCfg, logrus.New())
+	if err != nil {
+		t.Fatalf("failed to connect to test db: %v", err)
+	}
+	defer db.Close()
+	db.Exec("DELETE FROM device")
+	ctx := context.Background()
+
+	repo := NewDeviceRepository(db)
+
+	device := &domain.Device{Name: "gate-01", SigningKey: "0123456789abcdef0123456789abcdef"}
+	if err := repo.CreateDevice(ctx, device); err != nil {
+		t.Fatalf("CreateDevice failed: %v", err)
+	}
+
+	// Names are unique
+	err = repo.CreateDevice(ctx, &domain.Device{Name: "gate-01", SigningKey: "fedcba9876543210fedcba9876543210"})
+	if !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("CreateDevice of a taken name returned %v, want ErrConflict", err)
+	}
+
+	found, err := repo.GetDeviceByName(ctx, "gate-01")
+	if err != nil {
+		t.Fatalf("GetDeviceByName failed: %v", err)
+	}
+	if found.ID != device.ID || found.SigningKey != device.SigningKey {
+		t.Errorf("GetDeviceByName returned %+v, want %+v", found, device)
+	}
+
+	rotated, err := repo.UpdateSigningKey(ctx, device.ID, "fedcba9876543210fedcba9876543210")
+	if err != nil {
+		t.Fatalf("UpdateSigningKey failed: %v", err)
+	}
+	if rotated.SigningKey != "fedcba9876543210fedcba9876543210" || rotated.KeyRotatedAt.Before(device.KeyRotatedAt) {
+		t.Errorf("UpdateSigningKey returned %+v", rotated)
+	}
+
+	devices, err := repo.ListDevices(ctx)
+	if err != nil {
+		t.Fatalf("ListDevices failed: %v", err)
+	}
+	if len(devices) != 1 {
+		t.Errorf("ListDevices returned %d devices, want 1", len(devices))
+	}
+
+	if err := repo.DeleteDeviceById(ctx, device.ID); err != nil {
+		t.Fatalf("DeleteDeviceById failed: %v", err)
+	}
+	if _, err := repo.GetDeviceById(ctx, device.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("GetDeviceById after delete returned %v, want ErrNotFound", err)
+	}
+	if err := repo.DeleteDeviceById(ctx, device.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("DeleteDeviceById of a missing device returned %v, want ErrNotFound", err)
+	}
+}
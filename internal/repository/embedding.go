@@ -3,43 +3,115 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 
 	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
 
+	"github.com/lib/pq"
 	"github.com/pgvector/pgvector-go"
 )
 
 //go:generate mockgen -destination=../mocks/repository/embedding_mock.go -package=mocks . EmbeddingRepository
 
 // EmbeddingRepository defines the methods for managing embeddings in the database.
+// Every method but ListAllEmbeddings is scoped to the tenant of ctx, see
+// identity.TenantFromContext: embeddings of other tenants are never read,
+// matched or changed.
 type EmbeddingRepository interface {
 	CreateEmbedding(ctx context.Context, embedding *domain.Embedding) error
 	GetEmbeddingById(ctx context.Context, id int64) (*domain.Embedding, error)
 	ListEmbeddings(ctx context.Context) ([]*domain.Embedding, error)
+	// ListAllEmbeddings lists the embeddings of every tenant, for indexes and
+	// migrations between storage backends.
+	ListAllEmbeddings(ctx context.Context) ([]*domain.Embedding, error)
 	GetSimilarEmbeddingByVector(ctx context.Context, vector pgvector.Vector) (*domain.Embedding, error)
+	// GetSimilarEmbeddingsByVectors looks up the most similar embedding of
+	// every vector at once, as GetSimilarEmbeddingByVector does for one. The
+	// result has an entry per vector, nil for vectors without a match.
+	GetSimilarEmbeddingsByVectors(ctx context.Context, vectors []pgvector.Vector) ([]*domain.Embedding, error)
+	// UpdateEmbedding changes the name and vector of an embedding if it is at
+	// embedding.Version, or at any version if that is 0, and sets
+	// embedding.Version to the new version. It fails with domain.ErrNotFound
+	// if the embedding does not exist, and domain.ErrVersionConflict if it is
+	// at another version.
 	UpdateEmbedding(ctx context.Context, embedding *domain.Embedding) error
-	DeleteEmbeddingById(ctx context.Context, id int64) error
+	// PatchEmbedding changes the fields set in patch, which must not be empty,
+	// and returns the changed embedding. It fails like UpdateEmbedding.
+	PatchEmbedding(ctx context.Context, id int64, patch domain.EmbeddingPatch, version int64) (*domain.Embedding, error)
+	// UpdateCaptureMinimums sets the minimum capture scores of validations
+	// matching an embedding if it is at version, or at any version if that is
+	// 0, and returns the new version. It fails like UpdateEmbedding.
+	UpdateCaptureMinimums(ctx context.Context, id int64, minimums domain.CaptureMinimums, version int64) (int64, error)
+	// DeleteEmbeddingById removes an embedding if it is at version, or at any
+	// version if that is 0, failing like UpdateEmbedding.
+	DeleteEmbeddingById(ctx context.Context, id int64, version int64) error
+}
+
+// checkViolation is the Postgres error code of a row failing a CHECK constraint.
+const checkViolation = "23514"
+
+// metadataKeysConstraint bounds the number of metadata keys of an
+// embedding to domain.MaxMetadataKeys.
+const metadataKeysConstraint = "embedding_metadata_keys"
+
+// metadataColumn reads and writes the jsonb metadata of an embedding, nil if
+// it has no keys.
+type metadataColumn map[string]string
+
+func (m metadataColumn) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	encoded, err := json.Marshal(map[string]string(m))
+	return string(encoded), err
+}
+
+func (m *metadataColumn) Scan(src any) error {
+	var raw []byte
+	switch src := src.(type) {
+	case []byte:
+		raw = src
+	case string:
+		raw = []byte(src)
+	case nil:
+		*m = nil
+		return nil
+	default:
+		return fmt.Errorf("scan metadata: unsupported type %T", src)
+	}
+
+	var metadata map[string]string
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return fmt.Errorf("scan metadata: %w", err)
+	}
+	if len(metadata) == 0 {
+		metadata = nil
+	}
+	*m = metadata
+	return nil
 }
 
 // embeddingRepository implements EmbeddingRepository.
 type embeddingRepository struct {
-	db *sql.DB
+	db        *sql.DB
+	threshold float64
 }
 
 // NewEmbeddingsRepository creates a new instance of embeddingRepository.
-func NewEmbeddingsRepository(db *sql.DB) EmbeddingRepository {
-	return &embeddingRepository{db: db}
+// threshold is the minimum cosine similarity for GetSimilarEmbeddingByVector to return a match.
+func NewEmbeddingsRepository(db *sql.DB, threshold float64) EmbeddingRepository {
+	return &embeddingRepository{db: db, threshold: threshold}
 }
 
 // CreateEmbedding inserts a new embedding into the database.
 func (r *embeddingRepository) CreateEmbedding(ctx context.Context, embedding *domain.Embedding) error {
-	if err := r.db.Ping(); err != nil {
-		return err
-	}
-
-	const query = "INSERT INTO embedding (name, vector_) VALUES ($1, $2)"
-	_, err := r.db.ExecContext(ctx, query, embedding.Name, embedding.Vector)
+	const query = "INSERT INTO embedding (tenant_id, name, vector_) VALUES ($1, $2, $3)"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, identity.TenantFromContext(ctx), embedding.Name, embedding.Vector)
 	if err != nil {
 		return err
 	}
@@ -48,16 +120,13 @@ func (r *embeddingRepository) CreateEmbedding(ctx context.Context, embedding *do
 }
 
 func (r *embeddingRepository) GetEmbeddingById(ctx context.Context, id int64) (*domain.Embedding, error) {
-	if err := r.db.Ping(); err != nil {
-		return nil, err
-	}
-
-	const query = "SELECT id, name, vector_ FROM embedding WHERE id = $1"
+	const query = "SELECT id, tenant_id, name, vector_, min_liveness, min_quality, metadata, version FROM embedding WHERE id = $1 AND tenant_id = $2"
 	embedding := &domain.Embedding{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&embedding.ID, &embedding.Name, &embedding.Vector)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id, identity.TenantFromContext(ctx)).
+		Scan(&embedding.ID, &embedding.Tenant, &embedding.Name, &embedding.Vector, &embedding.CaptureMinimums.Liveness, &embedding.CaptureMinimums.Quality, (*metadataColumn)(&embedding.Metadata), &embedding.Version)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, sql.ErrNoRows
+			return nil, fmt.Errorf("%w: embedding %d", domain.ErrNotFound, id)
 		}
 		return nil, err
 	}
@@ -66,12 +135,17 @@ func (r *embeddingRepository) GetEmbeddingById(ctx context.Context, id int64) (*
 }
 
 func (r *embeddingRepository) ListEmbeddings(ctx context.Context) ([]*domain.Embedding, error) {
-	if err := r.db.Ping(); err != nil {
-		return nil, err
-	}
+	const query = "SELECT id, tenant_id, name, vector_, min_liveness, min_quality, metadata, version FROM embedding WHERE tenant_id = $1"
+	return r.list(ctx, query, identity.TenantFromContext(ctx))
+}
 
-	const query = "SELECT id, name, vector_ FROM embedding"
-	rows, err := r.db.QueryContext(ctx, query)
+func (r *embeddingRepository) ListAllEmbeddings(ctx context.Context) ([]*domain.Embedding, error) {
+	const query = "SELECT id, tenant_id, name, vector_, min_liveness, min_quality, metadata, version FROM embedding"
+	return r.list(ctx, query)
+}
+
+func (r *embeddingRepository) list(ctx context.Context, query string, args ...any) ([]*domain.Embedding, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -80,7 +154,7 @@ func (r *embeddingRepository) ListEmbeddings(ctx context.Context) ([]*domain.Emb
 	var embeddings []*domain.Embedding
 	for rows.Next() {
 		embedding := &domain.Embedding{}
-		if err := rows.Scan(&embedding.ID, &embedding.Name, &embedding.Vector); err != nil {
+		if err := rows.Scan(&embedding.ID, &embedding.Tenant, &embedding.Name, &embedding.Vector, &embedding.CaptureMinimums.Liveness, &embedding.CaptureMinimums.Quality, (*metadataColumn)(&embedding.Metadata), &embedding.Version); err != nil {
 			return nil, err
 		}
 		embeddings = append(embeddings, embedding)
@@ -93,18 +167,16 @@ func (r *embeddingRepository) ListEmbeddings(ctx context.Context) ([]*domain.Emb
 	return embeddings, nil
 }
 
-// GetSimilarEmbeddingByVector retrieves the most similar embedding from the database based on the provided vector.
+// GetSimilarEmbeddingByVector retrieves the most similar embedding of the
+// tenant from the database based on the provided vector.
 func (r *embeddingRepository) GetSimilarEmbeddingByVector(ctx context.Context, vector pgvector.Vector) (*domain.Embedding, error) {
-	if err := r.db.Ping(); err != nil {
-		return nil, err
-	}
-
-	const query = "SELECT id, name, vector_, (1 - (vector_ <=> $1)) AS accuracy FROM embedding WHERE (1 - (vector_ <=> $1)) > 0.58 ORDER BY (vector_ <=> $1) ASC LIMIT 1;"
+	const query = "SELECT id, tenant_id, name, vector_, min_liveness, min_quality, metadata, version, (1 - (vector_ <=> $1)) AS accuracy FROM embedding WHERE tenant_id = $3 AND (1 - (vector_ <=> $1)) > $2 ORDER BY (vector_ <=> $1) ASC LIMIT 1;"
 	embedding := &domain.Embedding{}
-	err := r.db.QueryRowContext(ctx, query, vector).Scan(&embedding.ID, &embedding.Name, &embedding.Vector, &embedding.Accuracy)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, vector, r.threshold, identity.TenantFromContext(ctx)).
+		Scan(&embedding.ID, &embedding.Tenant, &embedding.Name, &embedding.Vector, &embedding.CaptureMinimums.Liveness, &embedding.CaptureMinimums.Quality, (*metadataColumn)(&embedding.Metadata), &embedding.Version, &embedding.Accuracy)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, sql.ErrNoRows
+			return nil, domain.ErrNoMatch
 		}
 		return nil, err
 	}
@@ -112,31 +184,166 @@ func (r *embeddingRepository) GetSimilarEmbeddingByVector(ctx context.Context, v
 	return embedding, nil
 }
 
+// GetSimilarEmbeddingsByVectors matches every vector in a single statement: a
+// lateral join runs the search of GetSimilarEmbeddingByVector for each of them.
+func (r *embeddingRepository) GetSimilarEmbeddingsByVectors(ctx context.Context, vectors []pgvector.Vector) ([]*domain.Embedding, error) {
+	const query = `
+		SELECT p.ord, m.id, m.tenant_id, m.name, m.vector_, m.min_liveness, m.min_quality, m.metadata, m.version, m.accuracy
+		FROM unnest($1::vector[]) WITH ORDINALITY AS p (vector_, ord)
+		CROSS JOIN LATERAL (
+			SELECT e.id, e.tenant_id, e.name, e.vector_, e.min_liveness, e.min_quality, e.metadata, e.version, (1 - (e.vector_ <=> p.vector_)) AS accuracy
+			FROM embedding e
+			WHERE e.tenant_id = $3 AND (1 - (e.vector_ <=> p.vector_)) > $2
+			ORDER BY (e.vector_ <=> p.vector_) ASC LIMIT 1
+		) m`
+	probes := make([]string, len(vectors))
+	for i, vector := range vectors {
+		probes[i] = vector.String()
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, pq.Array(probes), r.threshold, identity.TenantFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make([]*domain.Embedding, len(vectors))
+	for rows.Next() {
+		var ord int
+		embedding := &domain.Embedding{}
+		if err := rows.Scan(&ord, &embedding.ID, &embedding.Tenant, &embedding.Name, &embedding.Vector, &embedding.CaptureMinimums.Liveness, &embedding.CaptureMinimums.Quality, (*metadataColumn)(&embedding.Metadata), &embedding.Version, &embedding.Accuracy); err != nil {
+			return nil, err
+		}
+		matches[ord-1] = embedding
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
 func (r *embeddingRepository) UpdateEmbedding(ctx context.Context, embedding *domain.Embedding) error {
-	if err := r.db.Ping(); err != nil {
-		return err
+	const query = `
+		UPDATE embedding SET name = $1, vector_ = $2, version = version + 1
+		WHERE id = $3 AND tenant_id = $4 AND $5::bigint IN (0, version)
+		RETURNING version`
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, embedding.Name, embedding.Vector, embedding.ID, identity.TenantFromContext(ctx), embedding.Version).
+		Scan(&embedding.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return r.unchanged(ctx, embedding.ID)
+	}
+	return err
+}
+
+// PatchEmbedding updates only the columns of the fields set in patch.
+func (r *embeddingRepository) PatchEmbedding(ctx context.Context, id int64, patch domain.EmbeddingPatch, version int64) (*domain.Embedding, error) {
+	args := []any{id, identity.TenantFromContext(ctx), version}
+	var sets []string
+	set := func(column string, value any) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	if patch.Name != nil {
+		set("name", *patch.Name)
+	}
+	if patch.Vector != nil {
+		set("vector_", *patch.Vector)
+	}
+	if patch.MinLiveness != nil {
+		set("min_liveness", *patch.MinLiveness)
+	}
+	if patch.MinQuality != nil {
+		set("min_quality", *patch.MinQuality)
+	}
+	if patch.Metadata != nil {
+		// Removed keys are subtracted, and the others merged into the object.
+		metadata := "metadata"
+		if patch.Metadata.Clear {
+			metadata = "'{}'::jsonb"
+		}
+		// A nil array would be NULL, and NULL the whole metadata.
+		removed := []string{}
+		changed := map[string]string{}
+		for key, value := range patch.Metadata.Set {
+			if value == nil {
+				removed = append(removed, key)
+			} else {
+				changed[key] = *value
+			}
+		}
+		args = append(args, pq.Array(removed), metadataColumn(changed))
+		sets = append(sets, fmt.Sprintf("metadata = (%s - $%d::text[]) || $%d::jsonb", metadata, len(args)-1, len(args)))
+	}
+	if len(sets) == 0 {
+		return nil, fmt.Errorf("%w: the patch changes no field", domain.ErrInvalidInput)
 	}
 
-	const query = "UPDATE embedding SET name = $1, vector_ = $2 WHERE id = $3"
-	_, err := r.db.ExecContext(ctx, query, embedding.Name, embedding.Vector, embedding.ID)
+	query := "UPDATE embedding SET " + strings.Join(sets, ", ") + ", version = version + 1" +
+		" WHERE id = $1 AND tenant_id = $2 AND $3::bigint IN (0, version)" +
+		" RETURNING id, tenant_id, name, vector_, min_liveness, min_quality, metadata, version"
+	embedding := &domain.Embedding{}
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).
+		Scan(&embedding.ID, &embedding.Tenant, &embedding.Name, &embedding.Vector, &embedding.CaptureMinimums.Liveness, &embedding.CaptureMinimums.Quality, (*metadataColumn)(&embedding.Metadata), &embedding.Version)
 	if err != nil {
-		return err
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, r.unchanged(ctx, id)
+		}
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == checkViolation && pqErr.Constraint == metadataKeysConstraint {
+			return nil, fmt.Errorf("%w: metadata must have at most %d keys", domain.ErrInvalidInput, domain.MaxMetadataKeys)
+		}
+		return nil, err
 	}
 
-	return nil
+	return embedding, nil
+}
+
+func (r *embeddingRepository) UpdateCaptureMinimums(ctx context.Context, id int64, minimums domain.CaptureMinimums, version int64) (int64, error) {
+	const query = `
+		UPDATE embedding SET min_liveness = $1, min_quality = $2, version = version + 1
+		WHERE id = $3 AND tenant_id = $4 AND $5::bigint IN (0, version)
+		RETURNING version`
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, minimums.Liveness, minimums.Quality, id, identity.TenantFromContext(ctx), version).
+		Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, r.unchanged(ctx, id)
+	}
+	return version, err
 }
 
 // DeleteEmbeddingById removes an embedding from the database by its ID.
-func (r *embeddingRepository) DeleteEmbeddingById(ctx context.Context, id int64) error {
-	if err := r.db.Ping(); err != nil {
+func (r *embeddingRepository) DeleteEmbeddingById(ctx context.Context, id int64, version int64) error {
+	const query = "DELETE FROM embedding WHERE id = $1 AND tenant_id = $2 AND $3::bigint IN (0, version)"
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, identity.TenantFromContext(ctx), version)
+	if err != nil {
 		return err
 	}
-
-	const query = "DELETE FROM embedding WHERE id = $1"
-	_, err := r.db.ExecContext(ctx, query, id)
+	affected, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
+	if affected == 0 {
+		return r.unchanged(ctx, id)
+	}
 
 	return nil
 }
+
+// unchanged returns why a conditional change of embedding id affected no
+// row: domain.ErrNotFound if it does not exist, and domain.ErrVersionConflict
+// with its current version otherwise.
+func (r *embeddingRepository) unchanged(ctx context.Context, id int64) error {
+	const query = "SELECT version FROM embedding WHERE id = $1 AND tenant_id = $2"
+	var version int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id, identity.TenantFromContext(ctx)).Scan(&version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: embedding %d", domain.ErrNotFound, id)
+		}
+		return err
+	}
+
+	return fmt.Errorf("%w: embedding %d is at version %d", domain.ErrVersionConflict, id, version)
+}
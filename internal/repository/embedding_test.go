@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"testing"
 
 	"access-system-api/internal/cfg"
@@ -11,6 +12,7 @@ import (
 
 	_ "github.com/lib/pq"
 	"github.com/pgvector/pgvector-go"
+	"github.com/sirupsen/logrus"
 )
 
 func cleanEmbeddingsTable(db *sql.DB) {
@@ -23,7 +25,7 @@ func TestEmbeddingRepository_CRUD(t *testing.T) {
 		t.Fatalf("failed to load test db config: %v", err)
 	}
 
-	db, err := client.ConnectDB(dbCfg)
+	db, err := client.ConnectDB(context.Background(), dbCfg, logrus.New())
 	if err != nil {
 		t.Fatalf("failed to connect to test db: %v", err)
 	}
@@ -31,7 +33,7 @@ func TestEmbeddingRepository_CRUD(t *testing.T) {
 	cleanEmbeddingsTable(db)
 	ctx := context.Background()
 
-	repo := NewEmbeddingsRepository(db)
+	repo := NewEmbeddingsRepository(db, cfg.Default().Matching.Threshold)
 
 	var vector []float32
 	for i := 0; i < 512; i++ {
@@ -57,18 +59,36 @@ func TestEmbeddingRepository_CRUD(t *testing.T) {
 		t.Errorf("GetSimilarEmbeddingByVector returned wrong embedding: got %+v", found)
 	}
 
+	// Test UpdateCaptureMinimums
+	if found != nil {
+		minimums := domain.CaptureMinimums{Liveness: 0.75, Quality: 0.5}
+		if _, err := repo.UpdateCaptureMinimums(ctx, found.ID, minimums, 0); err != nil {
+			t.Fatalf("UpdateCaptureMinimums failed: %v", err)
+		}
+		updated, err := repo.GetEmbeddingById(ctx, found.ID)
+		if err != nil {
+			t.Fatalf("GetEmbeddingById failed: %v", err)
+		}
+		if updated.CaptureMinimums != minimums {
+			t.Errorf("UpdateCaptureMinimums did not persist: got %+v", updated.CaptureMinimums)
+		}
+	}
+	if _, err := repo.UpdateCaptureMinimums(ctx, 999999, domain.CaptureMinimums{}, 0); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("UpdateCaptureMinimums for non-existent id should return ErrNotFound, got %v", err)
+	}
+
 	// Test DeleteEmbeddingById
 	if found != nil {
-		err = repo.DeleteEmbeddingById(ctx, found.ID)
+		err = repo.DeleteEmbeddingById(ctx, found.ID, 0)
 		if err != nil {
 			t.Fatalf("DeleteEmbeddingById failed: %v", err)
 		}
 	}
 
 	// Test DeleteEmbeddingById (non-existent)
-	err = repo.DeleteEmbeddingById(ctx, 999999)
-	if err != nil {
-		t.Errorf("DeleteEmbeddingById for non-existent id should not fail, got %v", err)
+	err = repo.DeleteEmbeddingById(ctx, 999999, 0)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("DeleteEmbeddingById for non-existent id should return ErrNotFound, got %v", err)
 	}
 
 	cleanEmbeddingsTable(db)
@@ -80,14 +100,14 @@ func TestEmbeddingRepository_DBError(t *testing.T) {
 		t.Fatalf("failed to load test db config: %v", err)
 	}
 
-	db, err := client.ConnectDB(dbCfg)
+	db, err := client.ConnectDB(context.Background(), dbCfg, logrus.New())
 	if err != nil {
 		t.Fatalf("failed to connect to test db: %v", err)
 	}
 	defer db.Close()
 	ctx := context.Background()
 
-	repo := NewEmbeddingsRepository(db)
+	repo := NewEmbeddingsRepository(db, cfg.Default().Matching.Threshold)
 	_ = db.Close() // forcibly close to simulate error
 
 	var vector []float32
@@ -102,7 +122,7 @@ func TestEmbeddingRepository_DBError(t *testing.T) {
 	if _, err := repo.GetSimilarEmbeddingByVector(ctx, emb.Vector); err == nil {
 		t.Error("expected error on GetSimilarEmbeddingByVector with closed db")
 	}
-	if err := repo.DeleteEmbeddingById(ctx, 1); err == nil {
+	if err := repo.DeleteEmbeddingById(ctx, 1, 0); err == nil {
 		t.Error("expected error on DeleteEmbeddingById with closed db")
 	}
 }
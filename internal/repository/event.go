@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"access-system-api/internal/events"
+)
+
+//go:generate mockgen -destination=../mocks/repository/event_mock.go -package=mocks . EventRepository
+
+// EventRepository defines the methods for keeping a log of access events.
+type EventRepository interface {
+	// AddEvents appends events to the log in a single write.
+	AddEvents(ctx context.Context, batch []events.Event) error
+	// ListEvents returns up to limit events, the most recent first.
+	ListEvents(ctx context.Context, limit int) ([]events.Event, error)
+	// DeleteEventsBefore removes the events that happened before t and
+	// returns the number of events removed.
+	DeleteEventsBefore(ctx context.Context, t time.Time) (int, error)
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/metrics"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// instrumentedEmbeddingRepository wraps an EmbeddingRepository and records call latencies.
+type instrumentedEmbeddingRepository struct {
+	next    EmbeddingRepository
+	metrics *metrics.Metrics
+}
+
+// NewInstrumentedEmbeddingRepository creates an EmbeddingRepository that reports
+// the duration of every call of next to m.
+func NewInstrumentedEmbeddingRepository(next EmbeddingRepository, m *metrics.Metrics) EmbeddingRepository {
+	return &instrumentedEmbeddingRepository{next: next, metrics: m}
+}
+
+func (r *instrumentedEmbeddingRepository) CreateEmbedding(ctx context.Context, embedding *domain.Embedding) error {
+	start := time.Now()
+	err := r.next.CreateEmbedding(ctx, embedding)
+	r.metrics.ObserveRepositoryCall("CreateEmbedding", time.Since(start), err)
+	return err
+}
+
+func (r *instrumentedEmbeddingRepository) GetEmbeddingById(ctx context.Context, id int64) (*domain.Embedding, error) {
+	start := time.Now()
+	embedding, err := r.next.GetEmbeddingById(ctx, id)
+	r.metrics.ObserveRepositoryCall("GetEmbeddingById", time.Since(start), err)
+	return embedding, err
+}
+
+func (r *instrumentedEmbeddingRepository) ListEmbeddings(ctx context.Context) ([]*domain.Embedding, error) {
+	start := time.Now()
+	embeddings, err := r.next.ListEmbeddings(ctx)
+	r.metrics.ObserveRepositoryCall("ListEmbeddings", time.Since(start), err)
+	return embeddings, err
+}
+
+func (r *instrumentedEmbeddingRepository) ListAllEmbeddings(ctx context.Context) ([]*domain.Embedding, error) {
+	start := time.Now()
+	embeddings, err := r.next.ListAllEmbeddings(ctx)
+	r.metrics.ObserveRepositoryCall("ListAllEmbeddings", time.Since(start), err)
+	return embeddings, err
+}
+
+func (r *instrumentedEmbeddingRepository) GetSimilarEmbeddingByVector(ctx context.Context, vector pgvector.Vector) (*domain.Embedding, error) {
+	start := time.Now()
+	embedding, err := r.next.GetSimilarEmbeddingByVector(ctx, vector)
+	r.metrics.ObserveRepositoryCall("GetSimilarEmbeddingByVector", time.Since(start), err)
+	return embedding, err
+}
+
+func (r *instrumentedEmbeddingRepository) GetSimilarEmbeddingsByVectors(ctx context.Context, vectors []pgvector.Vector) ([]*domain.Embedding, error) {
+	start := time.Now()
+	matches, err := r.next.GetSimilarEmbeddingsByVectors(ctx, vectors)
+	r.metrics.ObserveRepositoryCall("GetSimilarEmbeddingsByVectors", time.Since(start), err)
+	return matches, err
+}
+
+func (r *instrumentedEmbeddingRepository) UpdateEmbedding(ctx context.Context, embedding *domain.Embedding) error {
+	start := time.Now()
+	err := r.next.UpdateEmbedding(ctx, embedding)
+	r.metrics.ObserveRepositoryCall("UpdateEmbedding", time.Since(start), err)
+	return err
+}
+
+func (r *instrumentedEmbeddingRepository) PatchEmbedding(ctx context.Context, id int64, patch domain.EmbeddingPatch, version int64) (*domain.Embedding, error) {
+	start := time.Now()
+	embedding, err := r.next.PatchEmbedding(ctx, id, patch, version)
+	r.metrics.ObserveRepositoryCall("PatchEmbedding", time.Since(start), err)
+	return embedding, err
+}
+
+func (r *instrumentedEmbeddingRepository) UpdateCaptureMinimums(ctx context.Context, id int64, minimums domain.CaptureMinimums, version int64) (int64, error) {
+	start := time.Now()
+	version, err := r.next.UpdateCaptureMinimums(ctx, id, minimums, version)
+	r.metrics.ObserveRepositoryCall("UpdateCaptureMinimums", time.Since(start), err)
+	return version, err
+}
+
+func (r *instrumentedEmbeddingRepository) DeleteEmbeddingById(ctx context.Context, id int64, version int64) error {
+	start := time.Now()
+	err := r.next.DeleteEmbeddingById(ctx, id, version)
+	r.metrics.ObserveRepositoryCall("DeleteEmbeddingById", time.Since(start), err)
+	return err
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/ratelimit"
+)
+
+// lockoutRepository implements ratelimit.LockoutStore in the database,
+// shared by every server instance.
+type lockoutRepository struct {
+	db         *sql.DB
+	transactor Transactor
+}
+
+// NewLockoutRepository creates a new instance of lockoutRepository.
+func NewLockoutRepository(db *sql.DB) ratelimit.LockoutStore {
+	return &lockoutRepository{db: db, transactor: NewTransactor(db)}
+}
+
+const lockoutColumns = "tenant_id, device, failures, first_failure, level, locked_until, last_seen"
+
+func scanLockoutState(row interface{ Scan(...any) error }) (*domain.LockoutState, error) {
+	state := &domain.LockoutState{}
+	if err := row.Scan(&state.Tenant, &state.Device, &state.Failures, &state.FirstFailure, &state.Level, &state.LockedUntil, &state.LastSeen); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (r *lockoutRepository) GetLockoutState(ctx context.Context, device string) (*domain.LockoutState, error) {
+	query := "SELECT " + lockoutColumns + " FROM device_lockout WHERE tenant_id = $1 AND device = $2"
+	state, err := scanLockoutState(conn(ctx, r.db).QueryRowContext(ctx, query, identity.TenantFromContext(ctx), device))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return state, err
+}
+
+// UpdateLockoutState locks the row of device for the transaction, so that
+// concurrent updates from any instance are applied one after the other. The
+// row is created first, as there is nothing to lock otherwise.
+func (r *lockoutRepository) UpdateLockoutState(ctx context.Context, device string, update func(state *domain.LockoutState) bool) error {
+	tenant := identity.TenantFromContext(ctx)
+	return r.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		const insert = `
+			INSERT INTO device_lockout (` + lockoutColumns + `)
+			VALUES ($1, $2, 0, $3, 0, $3, $3)
+			ON CONFLICT (tenant_id, device) DO NOTHING`
+		if _, err := conn(ctx, r.db).ExecContext(ctx, insert, tenant, device, time.Time{}); err != nil {
+			return err
+		}
+
+		query := "SELECT " + lockoutColumns + " FROM device_lockout WHERE tenant_id = $1 AND device = $2 FOR UPDATE"
+		state, err := scanLockoutState(conn(ctx, r.db).QueryRowContext(ctx, query, tenant, device))
+		if err != nil {
+			return err
+		}
+
+		if !update(state) {
+			const remove = "DELETE FROM device_lockout WHERE tenant_id = $1 AND device = $2"
+			_, err := conn(ctx, r.db).ExecContext(ctx, remove, tenant, device)
+			return err
+		}
+		const store = `
+			UPDATE device_lockout
+			SET failures = $3, first_failure = $4, level = $5, locked_until = $6, last_seen = $7
+			WHERE tenant_id = $1 AND device = $2`
+		_, err = conn(ctx, r.db).ExecContext(ctx, store, tenant, device, state.Failures, state.FirstFailure, state.Level, state.LockedUntil, state.LastSeen)
+		return err
+	})
+}
+
+func (r *lockoutRepository) ListLockoutStates(ctx context.Context, now time.Time) ([]*domain.LockoutState, error) {
+	query := "SELECT " + lockoutColumns + " FROM device_lockout WHERE tenant_id = $1 AND locked_until > $2 ORDER BY device"
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, identity.TenantFromContext(ctx), now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var states []*domain.LockoutState
+	for rows.Next() {
+		state, err := scanLockoutState(rows)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	return states, rows.Err()
+}
+
+func (r *lockoutRepository) DeleteLockoutState(ctx context.Context, device string) (*domain.LockoutState, error) {
+	query := "DELETE FROM device_lockout WHERE tenant_id = $1 AND device = $2 RETURNING " + lockoutColumns
+	state, err := scanLockoutState(conn(ctx, r.db).QueryRowContext(ctx, query, identity.TenantFromContext(ctx), device))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return state, err
+}
+
+func (r *lockoutRepository) DeleteStaleLockoutStates(ctx context.Context, seenBefore, lockedBefore time.Time) error {
+	const query = "DELETE FROM device_lockout WHERE last_seen < $1 AND locked_until < $2"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, seenBefore, lockedBefore)
+	return err
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/client"
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+
+	_ "github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockoutRepository(t *testing.T) {
+	dbCfg, err := cfg.LoadTestDbCfg()
+	if err != nil {
+		t.Fatalf("failed to load test db config: %v", err)
+	}
+
+	db, err := client.ConnectDB(context.Background(), dbCfg, logrus.New())
+	if err != nil {
+		t.Fatalf("failed to connect to test db: %v", err)
+	}
+	defer db.Close()
+	db.Exec("DELETE FROM device_lockout")
+	ctx := context.Background()
+	site := identity.WithTenant(ctx, "site")
+
+	repo := NewLockoutRepository(db)
+	now := time.Now().Truncate(time.Microsecond)
+
+	state, err := repo.GetLockoutState(ctx, "gate-01")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	// A device without a state starts from a zero state.
+	require.NoError(t, repo.UpdateLockoutState(ctx, "gate-01", func(state *domain.LockoutState) bool {
+		assert.Zero(t, state.Failures)
+		assert.Zero(t, state.Level)
+		state.Failures = 1
+		state.FirstFailure = now
+		state.LastSeen = now
+		return true
+	}))
+	require.NoError(t, repo.UpdateLockoutState(ctx, "gate-01", func(state *domain.LockoutState) bool {
+		assert.Equal(t, 1, state.Failures)
+		state.Failures = 0
+		state.Level = 1
+		state.LockedUntil = now.Add(time.Minute)
+		return true
+	}))
+
+	state, err = repo.GetLockoutState(ctx, "gate-01")
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, "gate-01", state.Device)
+	assert.Equal(t, 1, state.Level)
+	assert.True(t, now.Add(time.Minute).Equal(state.LockedUntil))
+
+	// States are scoped to the tenant.
+	state, err = repo.GetLockoutState(site, "gate-01")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+	states, err := repo.ListLockoutStates(ctx, now)
+	require.NoError(t, err)
+	assert.Len(t, states, 1)
+	states, err = repo.ListLockoutStates(site, now)
+	require.NoError(t, err)
+	assert.Empty(t, states)
+	states, err = repo.ListLockoutStates(ctx, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, states, "expired lockouts are not listed")
+
+	// Returning false removes the state.
+	require.NoError(t, repo.UpdateLockoutState(site, "gate-02", func(state *domain.LockoutState) bool { return false }))
+	state, err = repo.GetLockoutState(site, "gate-02")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	state, err = repo.DeleteLockoutState(ctx, "gate-01")
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, 1, state.Level)
+	state, err = repo.DeleteLockoutState(ctx, "gate-01")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	require.NoError(t, repo.UpdateLockoutState(ctx, "gate-03", func(state *domain.LockoutState) bool {
+		state.LastSeen = now.Add(-time.Hour)
+		return true
+	}))
+	require.NoError(t, repo.DeleteStaleLockoutStates(ctx, now, now))
+	state, err = repo.GetLockoutState(ctx, "gate-03")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/index"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// memoryEmbeddingRepository implements EmbeddingRepository in memory, with
+// the semantics of embeddingRepository. Embeddings are lost on restart.
+type memoryEmbeddingRepository struct {
+	mu         sync.RWMutex
+	embeddings map[int64]*domain.Embedding
+	lastID     int64
+	index      *index.Index
+	threshold  float64
+}
+
+// NewMemoryEmbeddingRepository creates an EmbeddingRepository keeping the
+// embeddings in memory, for development and tests. threshold is the minimum
+// cosine similarity for GetSimilarEmbeddingByVector to return a match.
+func NewMemoryEmbeddingRepository(threshold float64) EmbeddingRepository {
+	return &memoryEmbeddingRepository{embeddings: map[int64]*domain.Embedding{}, index: index.New(), threshold: threshold}
+}
+
+func (r *memoryEmbeddingRepository) CreateEmbedding(ctx context.Context, embedding *domain.Embedding) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	stored := &domain.Embedding{ID: r.lastID, Tenant: identity.TenantFromContext(ctx), Name: embedding.Name, Vector: copyVector(embedding.Vector), Version: 1}
+	r.embeddings[stored.ID] = stored
+	r.index.Upsert(stored)
+	return nil
+}
+
+func (r *memoryEmbeddingRepository) GetEmbeddingById(ctx context.Context, id int64) (*domain.Embedding, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.get(ctx, id)
+	if !ok {
+		return nil, fmt.Errorf("%w: embedding %d", domain.ErrNotFound, id)
+	}
+	return copyEmbedding(stored), nil
+}
+
+func (r *memoryEmbeddingRepository) ListEmbeddings(ctx context.Context) ([]*domain.Embedding, error) {
+	tenant := identity.TenantFromContext(ctx)
+	return r.list(func(embedding *domain.Embedding) bool { return embedding.Tenant == tenant }), nil
+}
+
+func (r *memoryEmbeddingRepository) ListAllEmbeddings(_ context.Context) ([]*domain.Embedding, error) {
+	return r.list(func(*domain.Embedding) bool { return true }), nil
+}
+
+// list returns copies of the embeddings for which keep returns true, by ID.
+func (r *memoryEmbeddingRepository) list(keep func(*domain.Embedding) bool) []*domain.Embedding {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var embeddings []*domain.Embedding
+	for _, stored := range r.embeddings {
+		if keep(stored) {
+			embeddings = append(embeddings, copyEmbedding(stored))
+		}
+	}
+	slices.SortFunc(embeddings, func(a, b *domain.Embedding) int { return cmp.Compare(a.ID, b.ID) })
+	return embeddings
+}
+
+func (r *memoryEmbeddingRepository) GetSimilarEmbeddingByVector(ctx context.Context, vector pgvector.Vector) (*domain.Embedding, error) {
+	embedding, ok := r.index.Search(identity.TenantFromContext(ctx), vector.Slice(), r.threshold)
+	if !ok {
+		return nil, domain.ErrNoMatch
+	}
+	return embedding, nil
+}
+
+func (r *memoryEmbeddingRepository) GetSimilarEmbeddingsByVectors(ctx context.Context, vectors []pgvector.Vector) ([]*domain.Embedding, error) {
+	return searchAll(r.index, identity.TenantFromContext(ctx), vectors, r.threshold), nil
+}
+
+func (r *memoryEmbeddingRepository) UpdateEmbedding(ctx context.Context, embedding *domain.Embedding) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, _ := r.get(ctx, embedding.ID)
+	if err := checkVersion(stored, embedding.ID, embedding.Version); err != nil {
+		return err
+	}
+	updated := copyEmbedding(stored)
+	updated.Name = embedding.Name
+	updated.Vector = copyVector(embedding.Vector)
+	updated.Version++
+	r.embeddings[updated.ID] = updated
+	r.index.Upsert(updated)
+	embedding.Version = updated.Version
+	return nil
+}
+
+func (r *memoryEmbeddingRepository) PatchEmbedding(ctx context.Context, id int64, patch domain.EmbeddingPatch, version int64) (*domain.Embedding, error) {
+	if len(patch.Fields()) == 0 {
+		return nil, fmt.Errorf("%w: the patch changes no field", domain.ErrInvalidInput)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, _ := r.get(ctx, id)
+	if err := checkVersion(stored, id, version); err != nil {
+		return nil, err
+	}
+	updated := copyEmbedding(stored)
+	patch.Apply(updated)
+	if err := checkMetadata(updated); err != nil {
+		return nil, err
+	}
+	updated.Vector = copyVector(updated.Vector)
+	updated.Version++
+	r.embeddings[id] = updated
+	r.index.Upsert(updated)
+	return copyEmbedding(updated), nil
+}
+
+func (r *memoryEmbeddingRepository) UpdateCaptureMinimums(ctx context.Context, id int64, minimums domain.CaptureMinimums, version int64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, _ := r.get(ctx, id)
+	if err := checkVersion(stored, id, version); err != nil {
+		return 0, err
+	}
+	updated := copyEmbedding(stored)
+	updated.CaptureMinimums = minimums
+	updated.Version++
+	r.embeddings[id] = updated
+	r.index.Upsert(updated)
+	return updated.Version, nil
+}
+
+func (r *memoryEmbeddingRepository) DeleteEmbeddingById(ctx context.Context, id int64, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, _ := r.get(ctx, id)
+	if err := checkVersion(stored, id, version); err != nil {
+		return err
+	}
+	delete(r.embeddings, id)
+	r.index.Remove(id)
+	return nil
+}
+
+// get returns the stored embedding with id if it belongs to the tenant of
+// ctx. The caller must hold mu.
+func (r *memoryEmbeddingRepository) get(ctx context.Context, id int64) (*domain.Embedding, bool) {
+	stored, ok := r.embeddings[id]
+	if !ok || stored.Tenant != identity.TenantFromContext(ctx) {
+		return nil, false
+	}
+	return stored, true
+}
+
+// checkVersion returns the error of a change of embedding id requiring
+// version, or any version if that is 0, given the stored embedding or nil if
+// there is none, like embeddingRepository does.
+func checkVersion(stored *domain.Embedding, id, version int64) error {
+	if stored == nil {
+		return fmt.Errorf("%w: embedding %d", domain.ErrNotFound, id)
+	}
+	if version != 0 && stored.Version != version {
+		return fmt.Errorf("%w: embedding %d is at version %d", domain.ErrVersionConflict, id, stored.Version)
+	}
+	return nil
+}
+
+// copyEmbedding returns a copy of embedding that shares no memory with it.
+func copyEmbedding(embedding *domain.Embedding) *domain.Embedding {
+	copied := *embedding
+	copied.Vector = copyVector(embedding.Vector)
+	copied.Metadata = maps.Clone(embedding.Metadata)
+	return &copied
+}
+
+// checkMetadata enforces the limit on the number of metadata keys that the
+// embedding_metadata_keys constraint enforces in Postgres.
+func checkMetadata(embedding *domain.Embedding) error {
+	if len(embedding.Metadata) > domain.MaxMetadataKeys {
+		return fmt.Errorf("%w: metadata must have at most %d keys", domain.ErrInvalidInput, domain.MaxMetadataKeys)
+	}
+	return nil
+}
+
+func copyVector(vector pgvector.Vector) pgvector.Vector {
+	return pgvector.NewVector(slices.Clone(vector.Slice()))
+}
+
+// searchAll searches idx for every vector in tenant, with nil entries for
+// vectors without a match.
+func searchAll(idx *index.Index, tenant string, vectors []pgvector.Vector, threshold float64) []*domain.Embedding {
+	matches := make([]*domain.Embedding, len(vectors))
+	for i, vector := range vectors {
+		if embedding, ok := idx.Search(tenant, vector.Slice(), threshold); ok {
+			matches[i] = embedding
+		}
+	}
+	return matches
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"access-system-api/internal/domain"
+)
+
+// EmbeddingImporter stores embeddings with their IDs, for migrations between
+// storage backends. Importing fails if the target already has embeddings, so
+// that no embedding is overwritten or renumbered.
+type EmbeddingImporter interface {
+	ImportEmbeddings(ctx context.Context, embeddings []*domain.Embedding) error
+}
+
+// postgresImporter implements EmbeddingImporter.
+type postgresImporter struct {
+	db *sql.DB
+}
+
+// NewPostgresImporter creates an EmbeddingImporter writing to the embedding table of db.
+func NewPostgresImporter(db *sql.DB) EmbeddingImporter {
+	return &postgresImporter{db: db}
+}
+
+func (i *postgresImporter) ImportEmbeddings(ctx context.Context, embeddings []*domain.Embedding) error {
+	const exists = "SELECT EXISTS (SELECT 1 FROM embedding)"
+	// Tenants are registered on the fly, as the bolt backend has no registry.
+	const tenant = "INSERT INTO tenant (id, name) VALUES ($1, $1) ON CONFLICT (id) DO NOTHING"
+	const insert = `
+		INSERT INTO embedding (id, tenant_id, name, vector_, min_liveness, min_quality, metadata, version)
+		OVERRIDING SYSTEM VALUE VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	// New embeddings continue after the imported ones.
+	const sequence = "SELECT setval(pg_get_serial_sequence('embedding', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM embedding"
+
+	return NewTransactor(i.db).WithinTx(ctx, func(ctx context.Context) error {
+		var found bool
+		if err := conn(ctx, i.db).QueryRowContext(ctx, exists).Scan(&found); err != nil {
+			return err
+		}
+		if found {
+			return fmt.Errorf("%w: the embedding table is not empty", domain.ErrConflict)
+		}
+
+		for _, embedding := range embeddings {
+			if _, err := conn(ctx, i.db).ExecContext(ctx, tenant, embedding.Tenant); err != nil {
+				return fmt.Errorf("tenant %s: %w", embedding.Tenant, err)
+			}
+			_, err := conn(ctx, i.db).ExecContext(ctx, insert, embedding.ID, embedding.Tenant, embedding.Name, embedding.Vector,
+				embedding.CaptureMinimums.Liveness, embedding.CaptureMinimums.Quality, metadataColumn(embedding.Metadata), embedding.Version)
+			if err != nil {
+				return fmt.Errorf("embedding %d: %w", embedding.ID, err)
+			}
+		}
+
+		_, err := conn(ctx, i.db).ExecContext(ctx, sequence)
+		return err
+	})
+}
+
+// MigrateEmbeddings copies every embedding of from to to, of every tenant,
+// keeping their IDs, and returns the number of embeddings copied.
+func MigrateEmbeddings(ctx context.Context, from EmbeddingRepository, to EmbeddingImporter) (int, error) {
+	embeddings, err := from.ListAllEmbeddings(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("list embeddings: %w", err)
+	}
+	if err := to.ImportEmbeddings(ctx, embeddings); err != nil {
+		return 0, fmt.Errorf("import embeddings: %w", err)
+	}
+	return len(embeddings), nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"access-system-api/internal/identity"
+)
+
+// NonceRepository records the nonces of accepted signed requests in the
+// database, shared by every server instance. Every method but
+// DeleteExpiredNonces is scoped to the tenant of ctx.
+type NonceRepository interface {
+	// UseNonce records the nonce of device for ttl and reports whether it was
+	// unused. A nonce whose record expired can be used again.
+	UseNonce(ctx context.Context, device, nonce string, ttl time.Duration) (bool, error)
+	// DeleteExpiredNonces removes the expired nonce records of every tenant.
+	DeleteExpiredNonces(ctx context.Context) error
+}
+
+// nonceRepository implements NonceRepository.
+type nonceRepository struct {
+	db *sql.DB
+}
+
+// NewNonceRepository creates a new instance of nonceRepository.
+func NewNonceRepository(db *sql.DB) NonceRepository {
+	return &nonceRepository{db: db}
+}
+
+// UseNonce relies on the primary key: of concurrent requests with the same
+// nonce, only the first insert succeeds. Expiry uses the database clock, so
+// that the instances agree on it.
+func (r *nonceRepository) UseNonce(ctx context.Context, device, nonce string, ttl time.Duration) (bool, error) {
+	const query = `
+		INSERT INTO request_nonce (tenant_id, device, nonce, expires_at)
+		VALUES ($1, $2, $3, now() + make_interval(secs => $4))
+		ON CONFLICT (tenant_id, device, nonce) DO UPDATE SET expires_at = EXCLUDED.expires_at
+		WHERE request_nonce.expires_at <= now()`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, identity.TenantFromContext(ctx), device, nonce, ttl.Seconds())
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *nonceRepository) DeleteExpiredNonces(ctx context.Context) error {
+	const query = "DELETE FROM request_nonce WHERE expires_at <= now()"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query)
+	return err
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/client"
+	"access-system-api/internal/identity"
+
+	_ "github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+func TestNonceRepository(t *testing.T) {
+	dbCfg, err := cfg.LoadTestDbCfg()
+	if err != nil {
+		t.Fatalf("failed to load test db config: %v", err)
+	}
+
+	db, err := client.ConnectDB(context.Background(), dbCfg, logrus.New())
+	if err != nil {
+		t.Fatalf("failed to connect to test db: %v", err)
+	}
+	defer db.Close()
+	db.Exec("DELETE FROM request_nonce")
+	ctx := context.Background()
+
+	repo := NewNonceRepository(db)
+
+	use := func(ctx context.Context, device, nonce string, ttl time.Duration, want bool) {
+		t.Helper()
+		used, err := repo.UseNonce(ctx, device, nonce, ttl)
+		if err != nil {
+			t.Fatalf("UseNonce failed: %v", err)
+		}
+		if used != want {
+			t.Errorf("UseNonce(%s, %s) = %v, want %v", device, nonce, used, want)
+		}
+	}
+
+	use(ctx, "gate-01", "nonce-1", time.Minute, true)
+	use(ctx, "gate-01", "nonce-1", time.Minute, false)
+	// Nonces are scoped to the device and its tenant.
+	use(ctx, "gate-02", "nonce-1", time.Minute, true)
+	use(identity.WithTenant(ctx, "engineering"), "gate-01", "nonce-1", time.Minute, true)
+
+	// An expired nonce can be used again, and is removed.
+	use(ctx, "gate-01", "nonce-2", time.Millisecond, true)
+	time.Sleep(10 * time.Millisecond)
+	use(ctx, "gate-01", "nonce-2", time.Millisecond, true)
+	time.Sleep(10 * time.Millisecond)
+
+	if err := repo.DeleteExpiredNonces(ctx); err != nil {
+		t.Fatalf("DeleteExpiredNonces failed: %v", err)
+	}
+	var remaining int
+	if err := db.QueryRow("SELECT count(*) FROM request_nonce WHERE nonce = 'nonce-2'").Scan(&remaining); err != nil {
+		t.Fatalf("count nonces: %v", err)
+	}
+	if remaining != 0 {
+		t.Errorf("%d expired nonces remain", remaining)
+	}
+}
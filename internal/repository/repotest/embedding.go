@@ -0,0 +1,374 @@
+// Package repotest provides conformance tests that every implementation of
+// the repository interfaces must pass.
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/repository"
+
+	"github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// VectorSize is the size of the vectors stored by the conformance tests,
+// which must match the embedding column of the database.
+const VectorSize = 512
+
+// Threshold is the matching threshold the repositories under test must be created with.
+const Threshold = 0.5
+
+// Tenant is the tenant, besides identity.DefaultTenant, the conformance tests
+// store embeddings of. Repositories with a tenant registry must know it.
+const Tenant = "conformance"
+
+// NewEmbeddingRepository returns an empty EmbeddingRepository created with Threshold.
+type NewEmbeddingRepository func(t *testing.T) repository.EmbeddingRepository
+
+// TestEmbeddingRepository runs the EmbeddingRepository conformance tests
+// against the repositories returned by newRepo.
+func TestEmbeddingRepository(t *testing.T, newRepo NewEmbeddingRepository) {
+	t.Run("CreateGetList", func(t *testing.T) { testCreateGetList(t, newRepo(t)) })
+	t.Run("GetSimilar", func(t *testing.T) { testGetSimilar(t, newRepo(t)) })
+	t.Run("GetSimilarBatch", func(t *testing.T) { testGetSimilarBatch(t, newRepo(t)) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, newRepo(t)) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, newRepo(t)) })
+	t.Run("Versions", func(t *testing.T) { testVersions(t, newRepo(t)) })
+	t.Run("Patch", func(t *testing.T) { testPatch(t, newRepo(t)) })
+	t.Run("Tenants", func(t *testing.T) { testTenants(t, newRepo(t)) })
+}
+
+// vector returns a vector of VectorSize starting with head, zeros elsewhere.
+func vector(head ...float32) pgvector.Vector {
+	v := make([]float32, VectorSize)
+	copy(v, head)
+	return pgvector.NewVector(v)
+}
+
+// create stores an embedding and returns it with its ID.
+func create(t *testing.T, repo repository.EmbeddingRepository, name string, v pgvector.Vector) *domain.Embedding {
+	t.Helper()
+	return createIn(t, context.Background(), repo, name, v)
+}
+
+// createIn stores an embedding in the tenant of ctx and returns it with its ID.
+func createIn(t *testing.T, ctx context.Context, repo repository.EmbeddingRepository, name string, v pgvector.Vector) *domain.Embedding {
+	t.Helper()
+	require.NoError(t, repo.CreateEmbedding(ctx, &domain.Embedding{Name: name, Vector: v}))
+
+	embeddings, err := repo.ListEmbeddings(ctx)
+	require.NoError(t, err)
+	var created *domain.Embedding
+	for _, embedding := range embeddings {
+		if embedding.Name == name && (created == nil || embedding.ID > created.ID) {
+			created = embedding
+		}
+	}
+	require.NotNil(t, created, "embedding %q is not listed", name)
+	return created
+}
+
+func testCreateGetList(t *testing.T, repo repository.EmbeddingRepository) {
+	ctx := context.Background()
+
+	embeddings, err := repo.ListEmbeddings(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, embeddings)
+
+	alice := create(t, repo, "alice", vector(1, 2, 3))
+	bob := create(t, repo, "bob", vector(3, 2, 1))
+	assert.NotEqual(t, alice.ID, bob.ID)
+	assert.Equal(t, vector(1, 2, 3).Slice(), alice.Vector.Slice())
+	assert.Equal(t, domain.CaptureMinimums{}, alice.CaptureMinimums)
+	assert.Equal(t, identity.DefaultTenant, alice.Tenant)
+	assert.Zero(t, alice.Accuracy)
+
+	embeddings, err = repo.ListEmbeddings(ctx)
+	require.NoError(t, err)
+	assert.Len(t, embeddings, 2)
+
+	got, err := repo.GetEmbeddingById(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.Equal(t, bob, got)
+
+	_, err = repo.GetEmbeddingById(ctx, bob.ID+1000)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testGetSimilar(t *testing.T, repo repository.EmbeddingRepository) {
+	ctx := context.Background()
+
+	_, err := repo.GetSimilarEmbeddingByVector(ctx, vector(1))
+	assert.ErrorIs(t, err, domain.ErrNoMatch, "no embeddings")
+
+	x := create(t, repo, "x", vector(2))
+	y := create(t, repo, "y", vector(0, 1))
+	minimums := domain.CaptureMinimums{Liveness: 0.75, Quality: 0.5}
+	_, err = repo.UpdateCaptureMinimums(ctx, y.ID, minimums, 0)
+	require.NoError(t, err)
+
+	// Cosine similarity ignores the length of the vectors.
+	match, err := repo.GetSimilarEmbeddingByVector(ctx, vector(0.5))
+	require.NoError(t, err)
+	assert.Equal(t, x.ID, match.ID)
+	assert.Equal(t, "x", match.Name)
+	assert.Equal(t, vector(2).Slice(), match.Vector.Slice())
+	assert.InDelta(t, 1, match.Accuracy, 1e-5)
+
+	// The most similar embedding wins: similarity 0.89 to y, 0.45 to x.
+	match, err = repo.GetSimilarEmbeddingByVector(ctx, vector(1, 2))
+	require.NoError(t, err)
+	assert.Equal(t, y.ID, match.ID)
+	assert.InDelta(t, 0.894, match.Accuracy, 1e-3)
+	assert.Equal(t, minimums, match.CaptureMinimums)
+
+	// Similarity 0.55 is above the threshold, 0.45 is not.
+	match, err = repo.GetSimilarEmbeddingByVector(ctx, vector(0.55, 0, 0.835))
+	require.NoError(t, err)
+	assert.Equal(t, x.ID, match.ID)
+	assert.InDelta(t, 0.55, match.Accuracy, 1e-3)
+
+	_, err = repo.GetSimilarEmbeddingByVector(ctx, vector(0.45, 0, 0.893))
+	assert.ErrorIs(t, err, domain.ErrNoMatch)
+
+	_, err = repo.GetSimilarEmbeddingByVector(ctx, vector(-1, -1))
+	assert.ErrorIs(t, err, domain.ErrNoMatch, "opposite vectors")
+}
+
+func testGetSimilarBatch(t *testing.T, repo repository.EmbeddingRepository) {
+	ctx := context.Background()
+	tenantCtx := identity.WithTenant(ctx, Tenant)
+
+	matches, err := repo.GetSimilarEmbeddingsByVectors(ctx, []pgvector.Vector{vector(1), vector(0, 1)})
+	require.NoError(t, err)
+	assert.Equal(t, []*domain.Embedding{nil, nil}, matches, "no embeddings")
+
+	x := create(t, repo, "x", vector(2))
+	y := create(t, repo, "y", vector(0, 1))
+	alice := createIn(t, tenantCtx, repo, "alice", vector(1))
+
+	// Every vector gets the match GetSimilarEmbeddingByVector finds, in order.
+	matches, err = repo.GetSimilarEmbeddingsByVectors(ctx, []pgvector.Vector{vector(1, 2), vector(-1, -1), vector(0.5), vector(0.5)})
+	require.NoError(t, err)
+	require.Len(t, matches, 4)
+	assert.Equal(t, y.ID, matches[0].ID)
+	assert.InDelta(t, 0.894, matches[0].Accuracy, 1e-3)
+	assert.Nil(t, matches[1], "opposite vectors")
+	assert.Equal(t, x.ID, matches[2].ID)
+	assert.Equal(t, vector(2).Slice(), matches[2].Vector.Slice())
+	assert.Equal(t, x.ID, matches[3].ID)
+
+	// People never match across tenants.
+	matches, err = repo.GetSimilarEmbeddingsByVectors(tenantCtx, []pgvector.Vector{vector(1), vector(0, 1)})
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	assert.Equal(t, alice.ID, matches[0].ID)
+	assert.Nil(t, matches[1])
+}
+
+func testUpdate(t *testing.T, repo repository.EmbeddingRepository) {
+	ctx := context.Background()
+	embedding := create(t, repo, "before", vector(1))
+
+	require.NoError(t, repo.UpdateEmbedding(ctx, &domain.Embedding{ID: embedding.ID, Name: "after", Vector: vector(0, 1)}))
+	got, err := repo.GetEmbeddingById(ctx, embedding.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "after", got.Name)
+	assert.Equal(t, vector(0, 1).Slice(), got.Vector.Slice())
+
+	// Searches see the new vector.
+	_, err = repo.GetSimilarEmbeddingByVector(ctx, vector(1))
+	assert.ErrorIs(t, err, domain.ErrNoMatch)
+	match, err := repo.GetSimilarEmbeddingByVector(ctx, vector(0, 1))
+	require.NoError(t, err)
+	assert.Equal(t, embedding.ID, match.ID)
+
+	minimums := domain.CaptureMinimums{Liveness: 0.9}
+	_, err = repo.UpdateCaptureMinimums(ctx, embedding.ID, minimums, 0)
+	require.NoError(t, err)
+	got, err = repo.GetEmbeddingById(ctx, embedding.ID)
+	require.NoError(t, err)
+	assert.Equal(t, minimums, got.CaptureMinimums)
+	assert.Equal(t, "after", got.Name)
+
+	_, err = repo.UpdateCaptureMinimums(ctx, embedding.ID+1000, minimums, 0)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.ErrorIs(t, repo.UpdateEmbedding(ctx, &domain.Embedding{ID: embedding.ID + 1000, Name: "missing", Vector: vector(1)}), domain.ErrNotFound)
+}
+
+func testDelete(t *testing.T, repo repository.EmbeddingRepository) {
+	ctx := context.Background()
+	deleted := create(t, repo, "deleted", vector(1))
+	kept := create(t, repo, "kept", vector(0, 1))
+
+	require.NoError(t, repo.DeleteEmbeddingById(ctx, deleted.ID, 0))
+	_, err := repo.GetEmbeddingById(ctx, deleted.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = repo.GetSimilarEmbeddingByVector(ctx, vector(1))
+	assert.ErrorIs(t, err, domain.ErrNoMatch)
+
+	embeddings, err := repo.ListEmbeddings(ctx)
+	require.NoError(t, err)
+	require.Len(t, embeddings, 1)
+	assert.Equal(t, kept.ID, embeddings[0].ID)
+
+	assert.ErrorIs(t, repo.DeleteEmbeddingById(ctx, deleted.ID, 0), domain.ErrNotFound)
+}
+
+func testVersions(t *testing.T, repo repository.EmbeddingRepository) {
+	ctx := context.Background()
+	embedding := create(t, repo, "alice", vector(1))
+	assert.Equal(t, int64(1), embedding.Version)
+
+	// Every change increments the version, and updates return the new one.
+	update := &domain.Embedding{ID: embedding.ID, Name: "alice b.", Vector: vector(1), Version: 1}
+	require.NoError(t, repo.UpdateEmbedding(ctx, update))
+	assert.Equal(t, int64(2), update.Version)
+	version, err := repo.UpdateCaptureMinimums(ctx, embedding.ID, domain.CaptureMinimums{Liveness: 0.9}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), version)
+	got, err := repo.GetEmbeddingById(ctx, embedding.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), got.Version)
+
+	// Changes of a stale version are rejected and change nothing.
+	stale := &domain.Embedding{ID: embedding.ID, Name: "mallory", Vector: vector(0, 1), Version: 2}
+	assert.ErrorIs(t, repo.UpdateEmbedding(ctx, stale), domain.ErrVersionConflict)
+	assert.ErrorIs(t, repo.DeleteEmbeddingById(ctx, embedding.ID, 2), domain.ErrVersionConflict)
+	_, err = repo.UpdateCaptureMinimums(ctx, embedding.ID, domain.CaptureMinimums{Liveness: 0.1}, 2)
+	assert.ErrorIs(t, err, domain.ErrVersionConflict)
+	got, err = repo.GetEmbeddingById(ctx, embedding.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice b.", got.Name)
+	assert.Equal(t, int64(3), got.Version)
+
+	// A missing embedding is not found, whatever the version.
+	assert.ErrorIs(t, repo.UpdateEmbedding(ctx, &domain.Embedding{ID: embedding.ID + 1000, Name: "missing", Vector: vector(1), Version: 1}), domain.ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteEmbeddingById(ctx, embedding.ID+1000, 1), domain.ErrNotFound)
+	_, err = repo.UpdateCaptureMinimums(ctx, embedding.ID+1000, domain.CaptureMinimums{}, 1)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	require.NoError(t, repo.DeleteEmbeddingById(ctx, embedding.ID, 3))
+	_, err = repo.GetEmbeddingById(ctx, embedding.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testPatch(t *testing.T, repo repository.EmbeddingRepository) {
+	ctx := context.Background()
+	embedding := create(t, repo, "alice", vector(1))
+
+	// Only the fields of the patch change.
+	name := "alice b."
+	patched, err := repo.PatchEmbedding(ctx, embedding.ID, domain.EmbeddingPatch{Name: &name}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "alice b.", patched.Name)
+	assert.Equal(t, vector(1).Slice(), patched.Vector.Slice())
+	assert.Equal(t, int64(2), patched.Version)
+
+	v := vector(0, 1)
+	liveness := 0.8
+	patched, err = repo.PatchEmbedding(ctx, embedding.ID, domain.EmbeddingPatch{Vector: &v, MinLiveness: &liveness}, 0)
+	require.NoError(t, err)
+	got, err := repo.GetEmbeddingById(ctx, embedding.ID)
+	require.NoError(t, err)
+	assert.Equal(t, patched, got)
+	assert.Equal(t, "alice b.", got.Name)
+	assert.Equal(t, domain.CaptureMinimums{Liveness: 0.8}, got.CaptureMinimums)
+	assert.Equal(t, int64(3), got.Version)
+
+	// Searches see the new vector.
+	match, err := repo.GetSimilarEmbeddingByVector(ctx, vector(0, 1))
+	require.NoError(t, err)
+	assert.Equal(t, embedding.ID, match.ID)
+
+	// Metadata are merged: keys set to nil are removed, the others set.
+	employee, department, badge := "E-1042", "law", "B-7"
+	patched, err = repo.PatchEmbedding(ctx, embedding.ID, domain.EmbeddingPatch{Metadata: &domain.MetadataPatch{
+		Set: map[string]*string{"employee": &employee, "department": &department},
+	}}, 3)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"employee": "E-1042", "department": "law"}, patched.Metadata)
+	patched, err = repo.PatchEmbedding(ctx, embedding.ID, domain.EmbeddingPatch{Metadata: &domain.MetadataPatch{
+		Set: map[string]*string{"department": nil, "badge": &badge},
+	}}, 4)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"employee": "E-1042", "badge": "B-7"}, patched.Metadata)
+	got, err = repo.GetEmbeddingById(ctx, embedding.ID)
+	require.NoError(t, err)
+	assert.Equal(t, patched.Metadata, got.Metadata)
+	assert.Equal(t, "alice b.", got.Name)
+
+	// Matches carry the metadata too.
+	match, err = repo.GetSimilarEmbeddingByVector(ctx, vector(0, 1))
+	require.NoError(t, err)
+	assert.Equal(t, got.Metadata, match.Metadata)
+
+	tooMany := map[string]*string{}
+	for i := range domain.MaxMetadataKeys {
+		tooMany[fmt.Sprintf("key-%d", i)] = &badge
+	}
+	_, err = repo.PatchEmbedding(ctx, embedding.ID, domain.EmbeddingPatch{Metadata: &domain.MetadataPatch{Set: tooMany}}, 0)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+
+	// Clearing the metadata removes every key before the others are set.
+	patched, err = repo.PatchEmbedding(ctx, embedding.ID, domain.EmbeddingPatch{Metadata: &domain.MetadataPatch{Clear: true}}, 5)
+	require.NoError(t, err)
+	assert.Empty(t, patched.Metadata)
+	assert.Equal(t, int64(6), patched.Version)
+
+	_, err = repo.PatchEmbedding(ctx, embedding.ID, domain.EmbeddingPatch{Name: &name}, 2)
+	assert.ErrorIs(t, err, domain.ErrVersionConflict)
+	_, err = repo.PatchEmbedding(ctx, embedding.ID+1000, domain.EmbeddingPatch{Name: &name}, 0)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = repo.PatchEmbedding(ctx, embedding.ID, domain.EmbeddingPatch{}, 0)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+}
+
+func testTenants(t *testing.T, repo repository.EmbeddingRepository) {
+	ctx := context.Background()
+	tenantCtx := identity.WithTenant(ctx, Tenant)
+	bob := create(t, repo, "bob", vector(1))
+	alice := createIn(t, tenantCtx, repo, "alice", vector(1))
+	assert.Equal(t, Tenant, alice.Tenant)
+
+	embeddings, err := repo.ListEmbeddings(ctx)
+	require.NoError(t, err)
+	require.Len(t, embeddings, 1)
+	assert.Equal(t, bob.ID, embeddings[0].ID)
+	embeddings, err = repo.ListAllEmbeddings(ctx)
+	require.NoError(t, err)
+	assert.Len(t, embeddings, 2)
+
+	// People never match across tenants.
+	match, err := repo.GetSimilarEmbeddingByVector(ctx, vector(1))
+	require.NoError(t, err)
+	assert.Equal(t, bob.ID, match.ID)
+	match, err = repo.GetSimilarEmbeddingByVector(tenantCtx, vector(1))
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, match.ID)
+	_, err = repo.GetSimilarEmbeddingByVector(identity.WithTenant(ctx, "other"), vector(1))
+	assert.ErrorIs(t, err, domain.ErrNoMatch)
+
+	// Embeddings of other tenants are missing.
+	_, err = repo.GetEmbeddingById(ctx, alice.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = repo.UpdateCaptureMinimums(ctx, alice.ID, domain.CaptureMinimums{Liveness: 0.9}, 0)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.ErrorIs(t, repo.UpdateEmbedding(ctx, &domain.Embedding{ID: alice.ID, Name: "mallory", Vector: vector(0, 1)}), domain.ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteEmbeddingById(ctx, alice.ID, 0), domain.ErrNotFound)
+
+	got, err := repo.GetEmbeddingById(tenantCtx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, alice, got)
+
+	require.NoError(t, repo.DeleteEmbeddingById(tenantCtx, alice.ID, 0))
+	_, err = repo.GetSimilarEmbeddingByVector(tenantCtx, vector(1))
+	assert.ErrorIs(t, err, domain.ErrNoMatch)
+	_, err = repo.GetEmbeddingById(ctx, bob.ID)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+)
+
+//go:generate mockgen -destination=../mocks/repository/sample_mock.go -package=mocks . SampleRepository
+
+// SampleRepository defines the methods for managing the samples of adaptive
+// template updates in the database. Changing methods must run in a
+// transaction, see Transactor; they lock the embedding, so concurrent updates
+// of the same embedding are serialized. Only the samples of embeddings of the
+// tenant of ctx are read or changed.
+type SampleRepository interface {
+	// AddSample adds an adaptive sample. If the embedding has no active
+	// samples, its current vector is recorded as the enrollment sample first.
+	AddSample(ctx context.Context, sample *domain.EmbeddingSample) error
+	// EvictSamples marks all but the newest keep active adaptive samples of
+	// an embedding evicted and returns their IDs.
+	EvictSamples(ctx context.Context, embeddingID int64, keep int) ([]int64, error)
+	// RevertSample marks an active adaptive sample of an embedding reverted
+	// if the embedding is at version, or at any version if that is 0. It
+	// fails with domain.ErrVersionConflict if the embedding is at another
+	// version.
+	RevertSample(ctx context.Context, embeddingID, sampleID, version int64) error
+	// ResetSamples marks all active samples of an embedding reset.
+	ResetSamples(ctx context.Context, embeddingID int64) error
+	// UpdateCentroid sets the vector of an embedding to the normalized
+	// centroid of its active samples, if it has any.
+	UpdateCentroid(ctx context.Context, embeddingID int64) error
+	// ListSamples returns the samples of an embedding, oldest first, without their vectors.
+	ListSamples(ctx context.Context, embeddingID int64) ([]*domain.EmbeddingSample, error)
+}
+
+// sampleRepository implements SampleRepository.
+type sampleRepository struct {
+	db *sql.DB
+}
+
+// NewSampleRepository creates a new instance of sampleRepository.
+func NewSampleRepository(db *sql.DB) SampleRepository {
+	return &sampleRepository{db: db}
+}
+
+// lockEmbedding locks the row of an embedding of the tenant of ctx until the
+// end of the transaction of ctx and returns its version.
+func (r *sampleRepository) lockEmbedding(ctx context.Context, id int64) (int64, error) {
+	const query = "SELECT version FROM embedding WHERE id = $1 AND tenant_id = $2 FOR UPDATE"
+	var version int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id, identity.TenantFromContext(ctx)).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: embedding %d", domain.ErrNotFound, id)
+	}
+	return version, err
+}
+
+func (r *sampleRepository) AddSample(ctx context.Context, sample *domain.EmbeddingSample) error {
+	const enrollment = `
+		INSERT INTO embedding_sample (embedding_id, source, vector_)
+		SELECT e.id, 'enrollment', e.vector_ FROM embedding e
+		WHERE e.id = $1 AND NOT EXISTS (SELECT 1 FROM embedding_sample s WHERE s.embedding_id = e.id AND s.status = 'active')`
+	const query = `
+		INSERT INTO embedding_sample (embedding_id, source, vector_, accuracy, liveness, device, request_id)
+		VALUES ($1, 'adaptive', $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''))
+		RETURNING id, source, status, created_at`
+
+	if _, err := r.lockEmbedding(ctx, sample.EmbeddingID); err != nil {
+		return err
+	}
+	if _, err := conn(ctx, r.db).ExecContext(ctx, enrollment, sample.EmbeddingID); err != nil {
+		return err
+	}
+	return conn(ctx, r.db).QueryRowContext(ctx, query, sample.EmbeddingID, sample.Vector, sample.Accuracy, sample.Liveness, sample.Device, sample.RequestID).
+		Scan(&sample.ID, &sample.Source, &sample.Status, &sample.CreatedAt)
+}
+
+func (r *sampleRepository) EvictSamples(ctx context.Context, embeddingID int64, keep int) ([]int64, error) {
+	const query = `
+		UPDATE embedding_sample SET status = 'evicted', status_changed_at = now()
+		WHERE id IN (
+			SELECT id FROM embedding_sample
+			WHERE embedding_id = $1 AND source = 'adaptive' AND status = 'active'
+				AND embedding_id IN (SELECT id FROM embedding WHERE tenant_id = $3)
+			ORDER BY id DESC
+			OFFSET $2
+		)
+		RETURNING id`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, embeddingID, keep, identity.TenantFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var evicted []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		evicted = append(evicted, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return evicted, nil
+}
+
+func (r *sampleRepository) RevertSample(ctx context.Context, embeddingID, sampleID, version int64) error {
+	const query = `
+		UPDATE embedding_sample SET status = 'reverted', status_changed_at = now()
+		WHERE id = $1 AND embedding_id = $2 AND source = 'adaptive' AND status = 'active'`
+
+	current, err := r.lockEmbedding(ctx, embeddingID)
+	if err != nil {
+		return err
+	}
+	if version != 0 && version != current {
+		return fmt.Errorf("%w: embedding %d is at version %d", domain.ErrVersionConflict, embeddingID, current)
+	}
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, sampleID, embeddingID)
+	if err != nil {
+		return err
+	}
+
+	return requireRow(result, fmt.Errorf("%w: active adaptive sample %d of embedding %d", domain.ErrNotFound, sampleID, embeddingID))
+}
+
+func (r *sampleRepository) ResetSamples(ctx context.Context, embeddingID int64) error {
+	const query = `
+		UPDATE embedding_sample SET status = 'reset', status_changed_at = now()
+		WHERE embedding_id = $1 AND status = 'active'
+			AND embedding_id IN (SELECT id FROM embedding WHERE tenant_id = $2)`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, embeddingID, identity.TenantFromContext(ctx))
+	return err
+}
+
+func (r *sampleRepository) UpdateCentroid(ctx context.Context, embeddingID int64) error {
+	const query = `
+		UPDATE embedding e SET vector_ = c.centroid, version = e.version + 1
+		FROM (
+			SELECT l2_normalize(AVG(l2_normalize(vector_))) AS centroid FROM embedding_sample
+			WHERE embedding_id = $1 AND status = 'active'
+		) c
+		WHERE e.id = $1 AND e.tenant_id = $2 AND c.centroid IS NOT NULL`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, embeddingID, identity.TenantFromContext(ctx))
+	return err
+}
+
+func (r *sampleRepository) ListSamples(ctx context.Context, embeddingID int64) ([]*domain.EmbeddingSample, error) {
+	const query = `
+		SELECT id, embedding_id, source, status, COALESCE(accuracy, 0), liveness, COALESCE(device, ''),
+			COALESCE(request_id, ''), created_at, status_changed_at
+		FROM embedding_sample
+		WHERE embedding_id = $1 AND embedding_id IN (SELECT id FROM embedding WHERE tenant_id = $2)
+		ORDER BY id`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, embeddingID, identity.TenantFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []*domain.EmbeddingSample
+	for rows.Next() {
+		s := &domain.EmbeddingSample{}
+		err := rows.Scan(&s.ID, &s.EmbeddingID, &s.Source, &s.Status, &s.Accuracy, &s.Liveness, &s.Device,
+			&s.RequestID, &s.CreatedAt, &s.StatusChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return samples, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/client"
+	"access-system-api/internal/domain"
+
+	_ "github.com/lib/pq"
+	"github.com/pgvector/pgvector-go"
+	"github.com/sirupsen/logrus"
+)
+
+func unitVector(index int) pgvector.Vector {
+	vector := make([]float32, 512)
+	vector[index] = 1
+	return pgvector.NewVector(vector)
+}
+
+func TestSampleRepository_Adapt(t *testing.T) {
+	dbCfg, err := cfg.LoadTestDbCfg()
+	if err != nil {
+		t.Fatalf("failed to load test db config: %v", err)
+	}
+
+	db, err := client.ConnectDB(context.Background(), dbCfg, logrus.New())
+	if err != nil {
+		t.Fatalf("failed to connect to test db: %v", err)
+	}
+	defer db.Close()
+	cleanEmbeddingsTable(db)
+	defer cleanEmbeddingsTable(db)
+	ctx := context.Background()
+
+	embeddings := NewEmbeddingsRepository(db, cfg.Default().Matching.Threshold)
+	repo := NewSampleRepository(db)
+	tx := NewTransactor(db)
+
+	if err := embeddings.CreateEmbedding(ctx, &domain.Embedding{Name: "adaptive", Vector: unitVector(0)}); err != nil {
+		t.Fatalf("CreateEmbedding failed: %v", err)
+	}
+	list, err := embeddings.ListEmbeddings(ctx)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("ListEmbeddings returned %v, %v", list, err)
+	}
+	id := list[0].ID
+
+	// Adding two samples with a cap of one evicts the older one
+	var samples []*domain.EmbeddingSample
+	for _, index := range []int{1, 2} {
+		sample := &domain.EmbeddingSample{EmbeddingID: id, Vector: unitVector(index), Accuracy: 0.9, Device: "gate-01"}
+		err := tx.WithinTx(ctx, func(ctx context.Context) error {
+			if err := repo.AddSample(ctx, sample); err != nil {
+				return err
+			}
+			if _, err := repo.EvictSamples(ctx, id, 1); err != nil {
+				return err
+			}
+			return repo.UpdateCentroid(ctx, id)
+		})
+		if err != nil {
+			t.Fatalf("adding sample failed: %v", err)
+		}
+		samples = append(samples, sample)
+	}
+
+	listed, err := repo.ListSamples(ctx, id)
+	if err != nil {
+		t.Fatalf("ListSamples failed: %v", err)
+	}
+	var statuses []string
+	for _, sample := range listed {
+		statuses = append(statuses, sample.Source+"/"+sample.Status)
+	}
+	want := []string{"enrollment/active", "adaptive/evicted", "adaptive/active"}
+	if len(statuses) != len(want) || statuses[0] != want[0] || statuses[1] != want[1] || statuses[2] != want[2] {
+		t.Errorf("ListSamples returned %v, want %v", statuses, want)
+	}
+
+	// The vector is the centroid of the enrollment and the active sample
+	adapted, err := embeddings.GetEmbeddingById(ctx, id)
+	if err != nil {
+		t.Fatalf("GetEmbeddingById failed: %v", err)
+	}
+	if v := adapted.Vector.Slice(); v[0] < 0.7 || v[0] > 0.71 || v[2] < 0.7 || v[2] > 0.71 {
+		t.Errorf("centroid = %v..., want 0.707 at 0 and 2", v[:3])
+	}
+
+	// Reverting requires the current version of the embedding, if given
+	err = tx.WithinTx(ctx, func(ctx context.Context) error {
+		return repo.RevertSample(ctx, id, samples[1].ID, adapted.Version-1)
+	})
+	if !errors.Is(err, domain.ErrVersionConflict) {
+		t.Errorf("reverting at a stale version should return ErrVersionConflict, got %v", err)
+	}
+
+	// Reverting the active sample restores the enrollment vector
+	err = tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := repo.RevertSample(ctx, id, samples[1].ID, adapted.Version); err != nil {
+			return err
+		}
+		return repo.UpdateCentroid(ctx, id)
+	})
+	if err != nil {
+		t.Fatalf("RevertSample failed: %v", err)
+	}
+	reverted, _ := embeddings.GetEmbeddingById(ctx, id)
+	if v := reverted.Vector.Slice(); v[0] != 1 || v[2] != 0 {
+		t.Errorf("vector after revert = %v..., want the enrollment vector", v[:3])
+	}
+
+	err = tx.WithinTx(ctx, func(ctx context.Context) error {
+		return repo.RevertSample(ctx, id, samples[0].ID, 0)
+	})
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("reverting an evicted sample should return ErrNotFound, got %v", err)
+	}
+}
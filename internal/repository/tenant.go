@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"access-system-api/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+//go:generate mockgen -destination=../mocks/repository/tenant_mock.go -package=mocks . TenantRepository
+
+// foreignKeyViolation is the Postgres error code of a row still referenced by another.
+const foreignKeyViolation = "23503"
+
+// TenantRepository defines the methods for managing the tenant registry in the database.
+type TenantRepository interface {
+	// CreateTenant registers tenant, failing with domain.ErrConflict if its ID is taken.
+	CreateTenant(ctx context.Context, tenant *domain.Tenant) error
+	GetTenantById(ctx context.Context, id string) (*domain.Tenant, error)
+	ListTenants(ctx context.Context) ([]*domain.Tenant, error)
+	// DeleteTenantById removes a tenant, failing with domain.ErrConflict while
+	// it still has embeddings, devices or webhook subscriptions.
+	DeleteTenantById(ctx context.Context, id string) error
+}
+
+// tenantRepository implements TenantRepository.
+type tenantRepository struct {
+	db *sql.DB
+}
+
+// NewTenantRepository creates a new instance of tenantRepository.
+func NewTenantRepository(db *sql.DB) TenantRepository {
+	return &tenantRepository{db: db}
+}
+
+func (r *tenantRepository) CreateTenant(ctx context.Context, tenant *domain.Tenant) error {
+	const query = `
+		INSERT INTO tenant (id, name) VALUES ($1, $2)
+		ON CONFLICT (id) DO NOTHING
+		RETURNING created_at`
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, tenant.ID, tenant.Name).Scan(&tenant.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: tenant %s already exists", domain.ErrConflict, tenant.ID)
+	}
+	return err
+}
+
+func (r *tenantRepository) GetTenantById(ctx context.Context, id string) (*domain.Tenant, error) {
+	const query = "SELECT id, name, created_at FROM tenant WHERE id = $1"
+	tenant := &domain.Tenant{}
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(&tenant.ID, &tenant.Name, &tenant.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: tenant %s", domain.ErrNotFound, id)
+		}
+		return nil, err
+	}
+
+	return tenant, nil
+}
+
+func (r *tenantRepository) ListTenants(ctx context.Context) ([]*domain.Tenant, error) {
+	const query = "SELECT id, name, created_at FROM tenant ORDER BY id"
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tenants []*domain.Tenant
+	for rows.Next() {
+		tenant := &domain.Tenant{}
+		if err := rows.Scan(&tenant.ID, &tenant.Name, &tenant.CreatedAt); err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, tenant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tenants, nil
+}
+
+func (r *tenantRepository) DeleteTenantById(ctx context.Context, id string) error {
+	const query = "DELETE FROM tenant WHERE id = $1"
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+			return fmt.Errorf("%w: tenant %s still has embeddings, devices or webhooks", domain.ErrConflict, id)
+		}
+		return err
+	}
+
+	return requireRow(result, fmt.Errorf("%w: tenant %s", domain.ErrNotFound, id))
+}
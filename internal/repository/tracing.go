@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/tracing"
+
+	"github.com/pgvector/pgvector-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedEmbeddingRepository wraps an EmbeddingRepository and creates a span for every call.
+// Individual query spans are created below it by the instrumented SQL driver.
+type tracedEmbeddingRepository struct {
+	next   EmbeddingRepository
+	tracer trace.Tracer
+}
+
+// NewTracedEmbeddingRepository creates an EmbeddingRepository that traces every call of next.
+func NewTracedEmbeddingRepository(next EmbeddingRepository) EmbeddingRepository {
+	return &tracedEmbeddingRepository{next: next, tracer: tracing.Tracer()}
+}
+
+func (r *tracedEmbeddingRepository) start(ctx context.Context, method, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs,
+		semconv.DBSystemPostgreSQL,
+		semconv.DBOperationName(operation),
+		semconv.DBCollectionName("embedding"),
+	)
+	return r.tracer.Start(ctx, "EmbeddingRepository."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endSpan records err on the span unless it only means that no rows were found.
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, domain.ErrNotFound) && !errors.Is(err, domain.ErrNoMatch) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (r *tracedEmbeddingRepository) CreateEmbedding(ctx context.Context, embedding *domain.Embedding) error {
+	ctx, span := r.start(ctx, "CreateEmbedding", "INSERT")
+	err := r.next.CreateEmbedding(ctx, embedding)
+	endSpan(span, err)
+	return err
+}
+
+func (r *tracedEmbeddingRepository) GetEmbeddingById(ctx context.Context, id int64) (*domain.Embedding, error) {
+	ctx, span := r.start(ctx, "GetEmbeddingById", "SELECT", attribute.Int64("embedding.id", id))
+	embedding, err := r.next.GetEmbeddingById(ctx, id)
+	endSpan(span, err)
+	return embedding, err
+}
+
+func (r *tracedEmbeddingRepository) ListEmbeddings(ctx context.Context) ([]*domain.Embedding, error) {
+	ctx, span := r.start(ctx, "ListEmbeddings", "SELECT")
+	embeddings, err := r.next.ListEmbeddings(ctx)
+	span.SetAttributes(attribute.Int("db.response.rows", len(embeddings)))
+	endSpan(span, err)
+	return embeddings, err
+}
+
+func (r *tracedEmbeddingRepository) ListAllEmbeddings(ctx context.Context) ([]*domain.Embedding, error) {
+	ctx, span := r.start(ctx, "ListAllEmbeddings", "SELECT")
+	embeddings, err := r.next.ListAllEmbeddings(ctx)
+	span.SetAttributes(attribute.Int("db.response.rows", len(embeddings)))
+	endSpan(span, err)
+	return embeddings, err
+}
+
+func (r *tracedEmbeddingRepository) GetSimilarEmbeddingByVector(ctx context.Context, vector pgvector.Vector) (*domain.Embedding, error) {
+	ctx, span := r.start(ctx, "GetSimilarEmbeddingByVector", "SELECT",
+		attribute.Int("embedding.dimension", len(vector.Slice())),
+	)
+	embedding, err := r.next.GetSimilarEmbeddingByVector(ctx, vector)
+	endSpan(span, err)
+	return embedding, err
+}
+
+func (r *tracedEmbeddingRepository) GetSimilarEmbeddingsByVectors(ctx context.Context, vectors []pgvector.Vector) ([]*domain.Embedding, error) {
+	ctx, span := r.start(ctx, "GetSimilarEmbeddingsByVectors", "SELECT",
+		attribute.Int("embedding.probes", len(vectors)),
+	)
+	matches, err := r.next.GetSimilarEmbeddingsByVectors(ctx, vectors)
+	endSpan(span, err)
+	return matches, err
+}
+
+func (r *tracedEmbeddingRepository) UpdateEmbedding(ctx context.Context, embedding *domain.Embedding) error {
+	ctx, span := r.start(ctx, "UpdateEmbedding", "UPDATE", attribute.Int64("embedding.id", embedding.ID))
+	err := r.next.UpdateEmbedding(ctx, embedding)
+	endSpan(span, err)
+	return err
+}
+
+func (r *tracedEmbeddingRepository) PatchEmbedding(ctx context.Context, id int64, patch domain.EmbeddingPatch, version int64) (*domain.Embedding, error) {
+	ctx, span := r.start(ctx, "PatchEmbedding", "UPDATE", attribute.Int64("embedding.id", id))
+	embedding, err := r.next.PatchEmbedding(ctx, id, patch, version)
+	endSpan(span, err)
+	return embedding, err
+}
+
+func (r *tracedEmbeddingRepository) UpdateCaptureMinimums(ctx context.Context, id int64, minimums domain.CaptureMinimums, version int64) (int64, error) {
+	ctx, span := r.start(ctx, "UpdateCaptureMinimums", "UPDATE", attribute.Int64("embedding.id", id))
+	version, err := r.next.UpdateCaptureMinimums(ctx, id, minimums, version)
+	endSpan(span, err)
+	return version, err
+}
+
+func (r *tracedEmbeddingRepository) DeleteEmbeddingById(ctx context.Context, id int64, version int64) error {
+	ctx, span := r.start(ctx, "DeleteEmbeddingById", "DELETE", attribute.Int64("embedding.id", id))
+	err := r.next.DeleteEmbeddingById(ctx, id, version)
+	endSpan(span, err)
+	return err
+}
@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+
+	"github.com/lib/pq"
+)
+
+//go:generate mockgen -destination=../mocks/repository/webhook_mock.go -package=mocks . WebhookRepository
+
+// WebhookRepository defines the methods for managing webhook subscriptions,
+// the transactional outbox and webhook deliveries in the database.
+// Subscriptions, outbox events and the deliveries listed or redelivered
+// belong to the tenant of ctx; events are only delivered to the
+// subscriptions of their tenant.
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error
+	GetSubscriptionById(ctx context.Context, id int64) (*domain.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error)
+	DeleteSubscriptionById(ctx context.Context, id int64) error
+
+	// AddOutboxEvent writes event to the outbox, within the transaction of ctx if there is one.
+	AddOutboxEvent(ctx context.Context, event *domain.OutboxEvent) error
+	// FanOutEvents creates the deliveries of up to limit undispatched outbox
+	// events and returns the number of events dispatched.
+	FanOutEvents(ctx context.Context, limit int) (int, error)
+
+	// ClaimDeliveries returns up to limit due pending deliveries with their
+	// endpoint and payload, counting an attempt for each. Claimed deliveries
+	// are not due again before lease expires, so other instances skip them.
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id int64, statusCode int) error
+	// MarkFailed records a failed attempt and schedules the next one at
+	// nextAttemptAt, or moves the delivery to the dead-letter list if dead.
+	MarkFailed(ctx context.Context, id int64, statusCode int, lastError string, nextAttemptAt time.Time, dead bool) error
+	ListDeliveries(ctx context.Context, status string, limit int) ([]*domain.WebhookDelivery, error)
+	// RedeliverById schedules a delivery for immediate redelivery with a fresh attempt count.
+	RedeliverById(ctx context.Context, id int64) error
+	// DeleteDeliveredBefore removes delivered deliveries and dispatched outbox
+	// events without remaining deliveries last updated before t.
+	DeleteDeliveredBefore(ctx context.Context, t time.Time) error
+}
+
+// webhookRepository implements WebhookRepository.
+type webhookRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookRepository creates a new instance of webhookRepository.
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) CreateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	const query = "INSERT INTO webhook_subscription (tenant_id, url, event_types, secret) VALUES ($1, $2, $3, $4) RETURNING id, created_at"
+	return conn(ctx, r.db).QueryRowContext(ctx, query, identity.TenantFromContext(ctx), subscription.URL, pq.Array(subscription.EventTypes), subscription.Secret).
+		Scan(&subscription.ID, &subscription.CreatedAt)
+}
+
+func (r *webhookRepository) GetSubscriptionById(ctx context.Context, id int64) (*domain.WebhookSubscription, error) {
+	const query = "SELECT id, url, event_types, secret, created_at FROM webhook_subscription WHERE id = $1 AND tenant_id = $2"
+	subscription := &domain.WebhookSubscription{}
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id, identity.TenantFromContext(ctx)).
+		Scan(&subscription.ID, &subscription.URL, pq.Array(&subscription.EventTypes), &subscription.Secret, &subscription.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: webhook subscription %d", domain.ErrNotFound, id)
+		}
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (r *webhookRepository) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	const query = "SELECT id, url, event_types, secret, created_at FROM webhook_subscription WHERE tenant_id = $1 ORDER BY id"
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, identity.TenantFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []*domain.WebhookSubscription
+	for rows.Next() {
+		subscription := &domain.WebhookSubscription{}
+		if err := rows.Scan(&subscription.ID, &subscription.URL, pq.Array(&subscription.EventTypes), &subscription.Secret, &subscription.CreatedAt); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+// DeleteSubscriptionById removes a subscription together with its deliveries.
+func (r *webhookRepository) DeleteSubscriptionById(ctx context.Context, id int64) error {
+	const query = "DELETE FROM webhook_subscription WHERE id = $1 AND tenant_id = $2"
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, identity.TenantFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	return requireRow(result, fmt.Errorf("%w: webhook subscription %d", domain.ErrNotFound, id))
+}
+
+func (r *webhookRepository) AddOutboxEvent(ctx context.Context, event *domain.OutboxEvent) error {
+	const query = "INSERT INTO outbox_event (tenant_id, event_id, type, payload) VALUES ($1, $2, $3, $4)"
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, identity.TenantFromContext(ctx), event.EventID, event.Type, event.Payload)
+	return err
+}
+
+func (r *webhookRepository) FanOutEvents(ctx context.Context, limit int) (int, error) {
+	const query = `
+		WITH events AS (
+			SELECT id, tenant_id, type FROM outbox_event
+			WHERE dispatched_at IS NULL
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		), deliveries AS (
+			INSERT INTO webhook_delivery (subscription_id, outbox_event_id)
+			SELECT s.id, e.id FROM events e
+			JOIN webhook_subscription s ON s.tenant_id = e.tenant_id AND e.type = ANY (s.event_types)
+			ON CONFLICT DO NOTHING
+		)
+		UPDATE outbox_event SET dispatched_at = now()
+		WHERE id IN (SELECT id FROM events)`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	dispatched, err := result.RowsAffected()
+	return int(dispatched), err
+}
+
+func (r *webhookRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	const query = `
+		WITH due AS (
+			SELECT id FROM webhook_delivery
+			WHERE status = 'pending' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_delivery d
+		SET attempts = d.attempts + 1, next_attempt_at = now() + make_interval(secs => $2), updated_at = now()
+		FROM due, webhook_subscription s, outbox_event e
+		WHERE d.id = due.id AND s.id = d.subscription_id AND e.id = d.outbox_event_id
+		RETURNING d.id, d.subscription_id, e.event_id, e.type, d.status, d.attempts, d.next_attempt_at,
+			d.created_at, d.updated_at, s.url, s.secret, e.payload`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		d := &domain.WebhookDelivery{}
+		err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts, &d.NextAttemptAt,
+			&d.CreatedAt, &d.UpdatedAt, &d.URL, &d.Secret, &d.Payload)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookRepository) MarkDelivered(ctx context.Context, id int64, statusCode int) error {
+	const query = `
+		UPDATE webhook_delivery
+		SET status = 'delivered', last_status_code = $2, last_error = NULL, updated_at = now()
+		WHERE id = $1`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id, statusCode)
+	return err
+}
+
+func (r *webhookRepository) MarkFailed(ctx context.Context, id int64, statusCode int, lastError string, nextAttemptAt time.Time, dead bool) error {
+	const query = `
+		UPDATE webhook_delivery
+		SET status = CASE WHEN $5 THEN 'dead' ELSE 'pending' END,
+			last_status_code = NULLIF($2, 0), last_error = $3, next_attempt_at = $4, updated_at = now()
+		WHERE id = $1`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, id, statusCode, lastError, nextAttemptAt, dead)
+	return err
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, status string, limit int) ([]*domain.WebhookDelivery, error) {
+	const query = `
+		SELECT d.id, d.subscription_id, e.event_id, e.type, d.status, d.attempts, d.next_attempt_at,
+			COALESCE(d.last_status_code, 0), COALESCE(d.last_error, ''), d.created_at, d.updated_at
+		FROM webhook_delivery d
+		JOIN outbox_event e ON e.id = d.outbox_event_id
+		JOIN webhook_subscription s ON s.id = d.subscription_id
+		WHERE s.tenant_id = $3 AND ($1 = '' OR d.status = $1)
+		ORDER BY d.updated_at DESC, d.id DESC
+		LIMIT $2`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, status, limit, identity.TenantFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		d := &domain.WebhookDelivery{}
+		err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts, &d.NextAttemptAt,
+			&d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookRepository) RedeliverById(ctx context.Context, id int64) error {
+	const query = `
+		UPDATE webhook_delivery
+		SET status = 'pending', attempts = 0, next_attempt_at = now(), updated_at = now()
+		WHERE id = $1 AND subscription_id IN (SELECT id FROM webhook_subscription WHERE tenant_id = $2)`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, identity.TenantFromContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	return requireRow(result, fmt.Errorf("%w: webhook delivery %d", domain.ErrNotFound, id))
+}
+
+func (r *webhookRepository) DeleteDeliveredBefore(ctx context.Context, t time.Time) error {
+	const deliveries = "DELETE FROM webhook_delivery WHERE status = 'delivered' AND updated_at < $1"
+	const events = `
+		DELETE FROM outbox_event e
+		WHERE e.dispatched_at < $1
+			AND NOT EXISTS (SELECT 1 FROM webhook_delivery d WHERE d.outbox_event_id = e.id)`
+
+	if _, err := conn(ctx, r.db).ExecContext(ctx, deliveries, t); err != nil {
+		return err
+	}
+	_, err := conn(ctx, r.db).ExecContext(ctx, events, t)
+	return err
+}
+
+// requireRow returns notFound if result did not affect any row.
+func requireRow(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
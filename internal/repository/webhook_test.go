@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/client"
+	"access-system-api/internal/domain"
+
+	_ "github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+func cleanWebhookTables(db *sql.DB) {
+	db.Exec("DELETE FROM webhook_delivery")
+	db.Exec("DELETE FROM outbox_event")
+	db.Exec("DELETE FROM webhook_subscription")
+}
+
+func TestWebhookRepository_Outbox(t *testing.T) {
+	dbCfg, err := cfg.LoadTestDbCfg()
+	if err != nil {
+		t.Fatalf("failed to load test db config: %v", err)
+	}
+
+	db, err := client.ConnectDB(context.Background(), dbCfg, logrus.New())
+	if err != nil {
+		t.Fatalf("failed to connect to test db: %v", err)
+	}
+	defer db.Close()
+	cleanWebhookTables(db)
+	ctx := context.Background()
+
+	repo := NewWebhookRepository(db)
+	tx := NewTransactor(db)
+
+	subscription := &domain.WebhookSubscription{
+		URL:        "https://example.com/hook",
+		EventTypes: []string{domain.WebhookAccessDenied},
+		Secret:     "0123456789abcdef",
+	}
+	if err := repo.CreateSubscription(ctx, subscription); err != nil {
+		t.Fatalf("CreateSubscription failed: %v", err)
+	}
+
+	// Events written in a rolled back transaction are discarded
+	rollback := errors.New("rollback")
+	err = tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := repo.AddOutboxEvent(ctx, &domain.OutboxEvent{EventID: "evt-0", Type: domain.WebhookAccessDenied, Payload: []byte(`{}`)}); err != nil {
+			return err
+		}
+		return rollback
+	})
+	if !errors.Is(err, rollback) {
+		t.Fatalf("WithinTx returned %v, want rollback error", err)
+	}
+
+	// Only events matching the subscription become deliveries
+	for _, event := range []*domain.OutboxEvent{
+		{EventID: "evt-1", Type: domain.WebhookAccessDenied, Payload: []byte(`{"id":"evt-1"}`)},
+		{EventID: "evt-2", Type: domain.WebhookAccessGranted, Payload: []byte(`{"id":"evt-2"}`)},
+	} {
+		if err := repo.AddOutboxEvent(ctx, event); err != nil {
+			t.Fatalf("AddOutboxEvent failed: %v", err)
+		}
+	}
+
+	dispatched, err := repo.FanOutEvents(ctx, 10)
+	if err != nil {
+		t.Fatalf("FanOutEvents failed: %v", err)
+	}
+	if dispatched != 2 {
+		t.Errorf("FanOutEvents dispatched %d events, want 2", dispatched)
+	}
+
+	claimed, err := repo.ClaimDeliveries(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimDeliveries failed: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].EventID != "evt-1" || claimed[0].Attempts != 1 || claimed[0].Secret != subscription.Secret {
+		t.Fatalf("ClaimDeliveries returned wrong deliveries: got %+v", claimed)
+	}
+
+	// A claimed delivery is leased and not claimed again
+	again, err := repo.ClaimDeliveries(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimDeliveries failed: %v", err)
+	}
+	if len(again) != 0 {
+		t.Errorf("ClaimDeliveries returned leased deliveries: got %+v", again)
+	}
+
+	if err := repo.MarkFailed(ctx, claimed[0].ID, 500, "unexpected response status 500", time.Now(), true); err != nil {
+		t.Fatalf("MarkFailed failed: %v", err)
+	}
+	dead, err := repo.ListDeliveries(ctx, domain.DeliveryDead, 10)
+	if err != nil {
+		t.Fatalf("ListDeliveries failed: %v", err)
+	}
+	if len(dead) != 1 || dead[0].LastStatusCode != 500 {
+		t.Fatalf("ListDeliveries returned wrong deliveries: got %+v", dead)
+	}
+
+	if err := repo.RedeliverById(ctx, dead[0].ID); err != nil {
+		t.Fatalf("RedeliverById failed: %v", err)
+	}
+	claimed, err = repo.ClaimDeliveries(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimDeliveries failed: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].Attempts != 1 {
+		t.Fatalf("ClaimDeliveries did not return the redelivery: got %+v", claimed)
+	}
+
+	if err := repo.RedeliverById(ctx, -1); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("RedeliverById returned %v, want ErrNotFound", err)
+	}
+
+	// Deleting the subscription removes its deliveries
+	if err := repo.DeleteSubscriptionById(ctx, subscription.ID); err != nil {
+		t.Fatalf("DeleteSubscriptionById failed: %v", err)
+	}
+	all, err := repo.ListDeliveries(ctx, "", 10)
+	if err != nil {
+		t.Fatalf("ListDeliveries failed: %v", err)
+	}
+	if len(all) != 0 {
+		t.Errorf("ListDeliveries returned deliveries of a deleted subscription: got %+v", all)
+	}
+}
@@ -1,56 +1,205 @@
 package router
 
 import (
+	"fmt"
 	"net/http"
 
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/domain"
 	"access-system-api/internal/handler"
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/metrics"
+	"access-system-api/internal/middleware"
+	"access-system-api/internal/openapi"
+	"access-system-api/internal/service"
+	"access-system-api/internal/signing"
 
+	"github.com/getkin/kin-openapi/routers"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
+// serverName is reported as the HTTP server name on request spans.
+const serverName = "access-system-server"
+
 // Router struct to hold the Gin engine and handlers
 type Router struct {
-	engine *gin.Engine
-	v1     handler.V1Handler
-	admin  handler.AdminHandler
-	log    *logrus.Logger
+	engine   *gin.Engine
+	config   *cfg.Config
+	v1       handler.V1Handler
+	batch    handler.BatchHandler
+	admin    handler.AdminHandler
+	events   handler.EventsHandler
+	webhook  handler.WebhookHandler
+	lockout  handler.LockoutHandler
+	device   handler.DeviceHandler
+	template handler.TemplateHandler
+	audit    handler.AuditHandler
+	storage  handler.StorageHandler
+	tenant   handler.TenantHandler
+	// signatures verifies signed terminal requests, if request signing is enabled.
+	signatures *signing.Verifier
+	tenants    service.TenantChecker
+	health     handler.HealthHandler
+	spec       routers.Router
+	metrics    *metrics.Metrics
+	log        *logrus.Logger
 }
 
-// NewRouter initializes a new Router instance
-func NewRouter(v1 handler.V1Handler, admin handler.AdminHandler, log *logrus.Logger) *Router {
-	return &Router{
-		engine: gin.Default(),
-		v1:     v1,
-		admin:  admin,
-		log:    log,
+// NewRouter initializes a new Router instance and sets up the routes.
+func NewRouter(config *cfg.Config, v1 handler.V1Handler, batch handler.BatchHandler, admin handler.AdminHandler, events handler.EventsHandler, webhook handler.WebhookHandler, lockout handler.LockoutHandler, device handler.DeviceHandler, template handler.TemplateHandler, audit handler.AuditHandler, storage handler.StorageHandler, tenant handler.TenantHandler, signatures *signing.Verifier, tenants service.TenantChecker, health handler.HealthHandler, spec routers.Router, m *metrics.Metrics, log *logrus.Logger) *Router {
+	gin.SetMode(gin.ReleaseMode)
+	r := &Router{
+		engine:     gin.New(),
+		config:     config,
+		v1:         v1,
+		batch:      batch,
+		admin:      admin,
+		events:     events,
+		webhook:    webhook,
+		lockout:    lockout,
+		device:     device,
+		template:   template,
+		audit:      audit,
+		storage:    storage,
+		tenant:     tenant,
+		signatures: signatures,
+		tenants:    tenants,
+		health:     health,
+		spec:       spec,
+		metrics:    m,
+		log:        log,
 	}
+	r.setupRoutes()
+	return r
+}
+
+// Handler returns the HTTP handler serving all routes.
+func (r *Router) Handler() http.Handler {
+	return r.engine
 }
 
-// Run starts the Gin server and sets up the routes
-func (r *Router) Run() {
-	v1 := r.engine.Group("/api/v1")
+// setupRoutes registers the middleware and the routes on the Gin engine.
+func (r *Router) setupRoutes() {
+	r.engine.Use(
+		otelgin.Middleware(serverName),
+		middleware.RequestID(),
+		middleware.Identity(r.config.Auth.ClientSubjectHeader),
+		middleware.Logger(r.log),
+		middleware.Metrics(r.metrics),
+		middleware.Recovery(r.log),
+	)
+
+	api := r.engine.Group("/api/v1", middleware.ValidateRequest(r.spec))
+
+	// Every route but the super-admin ones serves registered tenants only.
+	tenantAPI := api.Group("", middleware.RequireTenant(r.tenants))
+
+	v1 := tenantAPI.Group("", middleware.Timeout(r.config.Server.RequestTimeout))
+
+	terminal := v1.Group("", r.terminalAuth()...)
 	{
-		v1.POST("/embedding", r.v1.AddEmbeddingHandler)
-		v1.POST("/embedding/validate", r.v1.ValidateEmbeddingHandler)
-		v1.DELETE("/embedding", r.v1.DeleteEmbeddingHandler)
+		terminal.POST("/embedding", r.v1.AddEmbeddingHandler)
+		terminal.POST("/embedding/validate", r.v1.ValidateEmbeddingHandler)
+		terminal.POST("/embedding/validate/batch", r.batch.ValidateBatchHandler)
+		terminal.DELETE("/embedding", r.v1.DeleteEmbeddingHandler)
 	}
 
-	admin := v1.Group("/admin")
+	admin := v1.Group("/admin", middleware.RestrictClients(r.config.Auth.AdminSubjects))
 	{
 		admin.POST("/embedding", r.admin.AddEmbeddingHandler)
 		admin.GET("/embedding/:id", r.admin.GetEmbeddingHandler)
 		admin.GET("/embeddings", r.admin.ListEmbeddingsHandler)
+		admin.GET("/embeddings/invalid", r.admin.ListInvalidEmbeddingsHandler)
 		admin.PUT("/embedding", r.admin.UpdateEmbeddingHandler)
+		admin.PATCH("/embedding/:id", r.admin.PatchEmbeddingHandler)
+		admin.PUT("/embedding/:id/capture-minimums", r.admin.SetCaptureMinimumsHandler)
 		admin.DELETE("/embedding", r.admin.DeleteEmbeddingHandler)
+
+		admin.GET("/lockouts", r.lockout.ListLockoutsHandler)
+		admin.DELETE("/lockouts/:device", r.lockout.ClearLockoutHandler)
+	}
+
+	// The features keeping their state in Postgres have no handlers with
+	// other storage backends.
+	if r.template != nil {
+		admin.GET("/embedding/:id/samples", r.template.ListSamplesHandler)
+		admin.POST("/embedding/:id/samples/:sample/revert", r.template.RevertSampleHandler)
+	}
+	if r.audit != nil {
+		admin.GET("/embedding/:id/audit", r.audit.ListEmbeddingAuditHandler)
+	}
+	if r.webhook != nil {
+		admin.POST("/webhooks", r.webhook.CreateWebhookHandler)
+		admin.GET("/webhooks", r.webhook.ListWebhooksHandler)
+		admin.GET("/webhooks/:id", r.webhook.GetWebhookHandler)
+		admin.DELETE("/webhooks/:id", r.webhook.DeleteWebhookHandler)
+		admin.GET("/webhooks/deliveries", r.webhook.ListDeliveriesHandler)
+		admin.POST("/webhooks/deliveries/:id/redeliver", r.webhook.RedeliverHandler)
+	}
+	if r.device != nil {
+		admin.POST("/devices", r.device.RegisterDeviceHandler)
+		admin.GET("/devices", r.device.ListDevicesHandler)
+		admin.GET("/devices/:id", r.device.GetDeviceHandler)
+		admin.DELETE("/devices/:id", r.device.DeleteDeviceHandler)
+		admin.POST("/devices/:id/rotate-key", r.device.RotateKeyHandler)
 	}
 
-	r.engine.GET("/health", func(c *gin.Context) {
-		c.Status(http.StatusOK)
+	// The event log and backups are only kept by the bolt storage backend.
+	if r.storage != nil {
+		admin.GET("/events/history", r.storage.ListEventsHandler)
+	}
+
+	// Streams stay open for as long as the client watches, and backups take
+	// as long as the store takes to copy, so they are not bounded by the
+	// request timeout.
+	adminStream := tenantAPI.Group("/admin", middleware.RestrictClients(r.config.Auth.AdminSubjects))
+	{
+		adminStream.GET("/events", r.events.StreamEventsHandler)
+		if r.storage != nil {
+			adminStream.GET("/backup", r.storage.BackupHandler)
+		}
+	}
+
+	// The tenant registry is kept in Postgres, and only managed by the
+	// clients listed as super-admins: without any, the routes do not exist.
+	if r.tenant != nil && len(r.config.Auth.SuperAdminSubjects) > 0 {
+		superAdmin := api.Group("/superadmin",
+			middleware.Timeout(r.config.Server.RequestTimeout),
+			middleware.RequireClients(r.config.Auth.SuperAdminSubjects),
+		)
+		superAdmin.POST("/tenants", r.tenant.CreateTenantHandler)
+		superAdmin.GET("/tenants", r.tenant.ListTenantsHandler)
+		superAdmin.GET("/tenants/:id", r.tenant.GetTenantHandler)
+		superAdmin.DELETE("/tenants/:id", r.tenant.DeleteTenantHandler)
+	}
+
+	health := r.engine.Group("/health")
+	{
+		health.GET("", r.health.LiveHandler)
+		health.GET("/live", r.health.LiveHandler)
+		health.GET("/ready", r.health.ReadyHandler)
+	}
+	r.engine.GET("/metrics", gin.WrapH(r.metrics.Handler()))
+	r.engine.GET("/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", openapi.Spec())
 	})
 
-	gin.SetMode(gin.ReleaseMode)
-	if err := r.engine.Run(":8081"); err != nil {
-		r.log.Fatalf("Failed to start server: %v", err)
+	r.engine.NoRoute(func(c *gin.Context) {
+		httperr.Respond(c, fmt.Errorf("%w: route %s %s", domain.ErrNotFound, c.Request.Method, c.Request.URL.Path))
+	})
+}
+
+// terminalAuth returns the middleware authenticating the requests of
+// terminals beyond their client certificate, according to the request
+// signing mode.
+func (r *Router) terminalAuth() []gin.HandlerFunc {
+	auth := r.config.Auth
+	if auth.RequestSigning == cfg.RequestSigningOff {
+		return nil
+	}
+	return []gin.HandlerFunc{
+		middleware.VerifySignature(r.signatures, auth.RequestSigning == cfg.RequestSigningRequired),
 	}
 }
@@ -0,0 +1,312 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/domain"
+	"access-system-api/internal/events"
+	"access-system-api/internal/handler"
+	"access-system-api/internal/health"
+	"access-system-api/internal/httperr"
+	"access-system-api/internal/metrics"
+	"access-system-api/internal/middleware"
+	mocks "access-system-api/internal/mocks/service"
+	"access-system-api/internal/openapi"
+	"access-system-api/internal/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var ginParam = regexp.MustCompile(`:(\w+)`)
+
+func setupRouter(t *testing.T) (*Router, *mocks.MockEmbeddingService) {
+	return setupRouterWithConfig(t, cfg.Default())
+}
+
+func setupRouterWithConfig(t *testing.T, config *cfg.Config) (*Router, *mocks.MockEmbeddingService) {
+	r, service, _ := setupRouterWithTenants(t, config, false)
+	return r, service
+}
+
+// setupRouterWithTenants sets up a router whose tenants are checked by the
+// returned tenant service if checkTenants is set.
+func setupRouterWithTenants(t *testing.T, config *cfg.Config, checkTenants bool) (*Router, *mocks.MockEmbeddingService, *mocks.MockTenantService) {
+	ctrl := gomock.NewController(t)
+	tenants := mocks.NewMockTenantService(ctrl)
+	var checker service.TenantChecker
+	if checkTenants {
+		checker = tenants
+	}
+
+	service := mocks.NewMockEmbeddingService(ctrl)
+	log := logrus.New()
+
+	spec, err := openapi.NewRouter()
+	require.NoError(t, err)
+
+	devices := mocks.NewMockDeviceService(ctrl)
+
+	r := NewRouter(
+		config,
+		handler.NewV1Handler(service, log),
+		handler.NewBatchHandler(mocks.NewMockBatchService(ctrl), log),
+		handler.NewAdminHandler(service, log),
+		handler.NewEventsHandler(events.NewBus(1), time.Second, log),
+		handler.NewWebhookHandler(mocks.NewMockWebhookService(ctrl), log),
+		handler.NewLockoutHandler(mocks.NewMockLockoutService(ctrl), log),
+		handler.NewDeviceHandler(devices, log),
+		handler.NewTemplateHandler(mocks.NewMockTemplateService(ctrl), log),
+		handler.NewAuditHandler(mocks.NewMockAuditService(ctrl), log),
+		handler.NewStorageHandler(mocks.NewMockStorageService(ctrl), log),
+		handler.NewTenantHandler(tenants, log),
+		nil,
+		checker,
+		handler.NewHealthHandler(health.NewChecker(time.Second)),
+		spec,
+		metrics.New(),
+		log,
+	)
+	return r, service, tenants
+}
+
+func TestRoutesMatchSpec(t *testing.T) {
+	// Every optional route is registered.
+	config := cfg.Default()
+	config.Auth.SuperAdminSubjects = []string{"default/root-01"}
+	r, _ := setupRouterWithConfig(t, config)
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+
+	registered := make(map[string]bool)
+	for _, route := range r.engine.Routes() {
+		path := ginParam.ReplaceAllString(route.Path, "{$1}")
+		registered[route.Method+" "+path] = true
+	}
+
+	documented := make(map[string]bool)
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			documented[method+" "+path] = true
+		}
+	}
+
+	for route := range registered {
+		assert.True(t, documented[route], "route %s is not documented in openapi.json", route)
+	}
+	for route := range documented {
+		assert.True(t, registered[route], "documented route %s is not registered", route)
+	}
+}
+
+func TestServeSpec(t *testing.T) {
+	r, _ := setupRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/openapi.json", nil)
+	r.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, openapi.Spec(), w.Body.Bytes())
+}
+
+func TestValidateRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		reason string
+	}{
+		{"empty vector", "POST", "/api/v1/embedding/validate", `{"vector":[]}`, "vector"},
+		{"missing name", "POST", "/api/v1/embedding", `{"vector":[0.1]}`, "name"},
+		{"wrong type", "POST", "/api/v1/embedding", `{"name":1,"vector":[0.1]}`, "name"},
+		{"non-positive id", "DELETE", "/api/v1/embedding", `{"id":0}`, "id"},
+		{"non-numeric path id", "GET", "/api/v1/admin/embedding/abc", "", "id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The service mock has no expectations: a request reaching it fails the test.
+			r, _ := setupRouter(t)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.Handler().ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var body httperr.Body
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, httperr.CodeInvalidInput, body.Error.Code)
+			assert.Contains(t, body.Error.Message, tt.reason)
+		})
+	}
+}
+
+func TestValidateRequest_PassesValidBody(t *testing.T) {
+	r, service := setupRouter(t)
+	service.EXPECT().AddEmbedding(gomock.Any(), "test", []float32{0.1, 0.2}).Return(nil)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":   "test",
+		"vector": []float32{0.1, 0.2},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/embedding", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.Handler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestValidateRequest_MergePatch(t *testing.T) {
+	r, service := setupRouter(t)
+	name := "renamed"
+	service.EXPECT().PatchEmbedding(gomock.Any(), int64(1), domain.EmbeddingPatch{Name: &name}, int64(0)).
+		Return(&domain.Embedding{ID: 1, Name: name, Version: 2}, nil)
+
+	patch := func(contentType, body string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PATCH", "/api/v1/admin/embedding/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		r.Handler().ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, patch("application/merge-patch+json", `{"name":"renamed"}`))
+	assert.Equal(t, http.StatusBadRequest, patch("application/merge-patch+json", `{"min_quality":2}`))
+	assert.Equal(t, http.StatusBadRequest, patch("application/json", `{"name":"renamed"}`))
+}
+
+func TestRequestSigning_OnlyTerminalRoutes(t *testing.T) {
+	config := cfg.Default()
+	config.Auth.RequestSigning = cfg.RequestSigningRequired
+	r, service := setupRouterWithConfig(t, config)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/embedding", strings.NewReader(`{"name":"test","vector":[0.1]}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.Handler().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	service.EXPECT().ListEmbeddings(gomock.Any()).Return(nil, nil)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/admin/embeddings", nil)
+	r.Handler().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRoutesWithoutPostgresFeatures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	service := mocks.NewMockEmbeddingService(ctrl)
+	log := logrus.New()
+	spec, err := openapi.NewRouter()
+	require.NoError(t, err)
+
+	r := NewRouter(
+		cfg.Default(),
+		handler.NewV1Handler(service, log),
+		handler.NewBatchHandler(mocks.NewMockBatchService(ctrl), log),
+		handler.NewAdminHandler(service, log),
+		handler.NewEventsHandler(events.NewBus(1), time.Second, log),
+		nil,
+		handler.NewLockoutHandler(mocks.NewMockLockoutService(ctrl), log),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		handler.NewHealthHandler(health.NewChecker(time.Second)),
+		spec,
+		metrics.New(),
+		log,
+	)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/admin/webhooks", nil)
+	r.Handler().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	service.EXPECT().ListEmbeddings(gomock.Any()).Return(nil, nil)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/admin/embeddings", nil)
+	r.Handler().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequireTenant(t *testing.T) {
+	config := cfg.Default()
+	config.Auth.SuperAdminSubjects = []string{"law/root-01"}
+	r, service, tenants := setupRouterWithTenants(t, config, true)
+
+	tenants.EXPECT().CheckTenant(gomock.Any(), "law").Return(fmt.Errorf("%w: tenant law is not registered", domain.ErrForbidden))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/admin/embeddings", nil)
+	req.Header.Set(middleware.ClientSubjectHeader, "CN=admin-01,OU=law,O=University")
+	r.Handler().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	tenants.EXPECT().CheckTenant(gomock.Any(), "engineering").Return(nil)
+	service.EXPECT().ListEmbeddings(gomock.Any()).Return(nil, nil)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/admin/embeddings", nil)
+	req.Header.Set(middleware.ClientSubjectHeader, "CN=admin-01,OU=engineering,O=University")
+	r.Handler().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Super-admins manage tenants whatever their own tenant.
+	tenants.EXPECT().ListTenants(gomock.Any()).Return(nil, nil)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/superadmin/tenants", nil)
+	req.Header.Set(middleware.ClientSubjectHeader, "CN=root-01,OU=law,O=University")
+	r.Handler().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSuperAdmin(t *testing.T) {
+	superAdmin := func(r *Router, subject string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/superadmin/tenants", nil)
+		req.Header.Set(middleware.ClientSubjectHeader, subject)
+		r.Handler().ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Without super-admins, nobody manages the tenants.
+	r, _ := setupRouter(t)
+	assert.Equal(t, http.StatusNotFound, superAdmin(r, "CN=root-01,O=University"))
+
+	config := cfg.Default()
+	config.Auth.SuperAdminSubjects = []string{"platform/root-01"}
+	r, _, tenants := setupRouterWithTenants(t, config, false)
+
+	tenants.EXPECT().ListTenants(gomock.Any()).Return(nil, nil)
+	assert.Equal(t, http.StatusOK, superAdmin(r, "CN=root-01,OU=platform,O=University"))
+
+	// The common name only matches in the listed tenant.
+	assert.Equal(t, http.StatusForbidden, superAdmin(r, "CN=root-01,OU=law,O=University"))
+	assert.Equal(t, http.StatusForbidden, superAdmin(r, "CN=root-01,O=University"))
+	assert.Equal(t, http.StatusForbidden, superAdmin(r, "CN=gate-01,OU=platform,O=University"))
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/logging"
+	"access-system-api/internal/repository"
+)
+
+//go:generate mockgen -destination=../mocks/service/audit_mock.go -package=mocks . AuditService
+
+// AuditService reads the audit trail of embeddings, see
+// NewAuditedEmbeddingService.
+type AuditService interface {
+	// ListEmbeddingAudit returns the audit entries of an embedding, oldest
+	// first, including those of a deleted embedding.
+	ListEmbeddingAudit(ctx context.Context, embeddingID int64) ([]*domain.EmbeddingAudit, error)
+}
+
+// auditService implements AuditService.
+type auditService struct {
+	auditRepo repository.AuditRepository
+}
+
+// NewAuditService creates a new instance of AuditService.
+func NewAuditService(auditRepo repository.AuditRepository) AuditService {
+	return &auditService{auditRepo: auditRepo}
+}
+
+func (s *auditService) ListEmbeddingAudit(ctx context.Context, embeddingID int64) ([]*domain.EmbeddingAudit, error) {
+	return s.auditRepo.ListEmbeddingAudit(ctx, embeddingID)
+}
+
+// auditedEmbeddingService wraps an EmbeddingService and records every change
+// of an embedding in the audit trail, in the transaction of the change, so a
+// change is committed if and only if its entry is.
+type auditedEmbeddingService struct {
+	EmbeddingService
+	auditRepo repository.AuditRepository
+	tx        repository.Transactor
+}
+
+// NewAuditedEmbeddingService creates an EmbeddingService that records the
+// changes of embeddings made through next in auditRepo.
+func NewAuditedEmbeddingService(next EmbeddingService, auditRepo repository.AuditRepository, tx repository.Transactor) EmbeddingService {
+	return &auditedEmbeddingService{EmbeddingService: next, auditRepo: auditRepo, tx: tx}
+}
+
+// record adds the audit entry of a change of fields of an embedding that
+// brought it to version. Every change increments the version by one.
+func (s *auditedEmbeddingService) record(ctx context.Context, id int64, fields []string, version int64) error {
+	return s.auditRepo.AddEmbeddingAudit(ctx, &domain.EmbeddingAudit{
+		EmbeddingID: id,
+		Device:      identity.DeviceFromContext(ctx),
+		RequestID:   logging.RequestIDFromContext(ctx),
+		Fields:      fields,
+		OldVersion:  version - 1,
+		NewVersion:  version,
+	})
+}
+
+func (s *auditedEmbeddingService) UpdateEmbedding(ctx context.Context, id int64, name string, vector []float32, version int64) (int64, error) {
+	var updated int64
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if updated, err = s.EmbeddingService.UpdateEmbedding(ctx, id, name, vector, version); err != nil {
+			return err
+		}
+		return s.record(ctx, id, []string{"name", "vector"}, updated)
+	})
+	return updated, err
+}
+
+func (s *auditedEmbeddingService) PatchEmbedding(ctx context.Context, id int64, patch domain.EmbeddingPatch, version int64) (*domain.Embedding, error) {
+	var embedding *domain.Embedding
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if embedding, err = s.EmbeddingService.PatchEmbedding(ctx, id, patch, version); err != nil {
+			return err
+		}
+		if len(patch.Fields()) == 0 {
+			return nil
+		}
+		return s.record(ctx, id, patch.Fields(), embedding.Version)
+	})
+	return embedding, err
+}
+
+func (s *auditedEmbeddingService) SetCaptureMinimums(ctx context.Context, id int64, minimums domain.CaptureMinimums, version int64) (int64, error) {
+	var updated int64
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if updated, err = s.EmbeddingService.SetCaptureMinimums(ctx, id, minimums, version); err != nil {
+			return err
+		}
+		return s.record(ctx, id, []string{"min_liveness", "min_quality"}, updated)
+	})
+	return updated, err
+}
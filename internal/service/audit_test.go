@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/logging"
+	repomocks "access-system-api/internal/mocks/repository"
+	mocks "access-system-api/internal/mocks/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditedEmbeddingService_PatchEmbedding(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingService(ctrl)
+	repo := repomocks.NewMockAuditRepository(ctrl)
+	tx := repomocks.NewMockTransactor(ctrl)
+	service := NewAuditedEmbeddingService(next, repo, tx)
+
+	ctx := logging.WithRequestID(identity.WithDevice(context.Background(), "enroll-01"), "req-1")
+	name := "renamed"
+	patch := domain.EmbeddingPatch{Name: &name, Metadata: &domain.MetadataPatch{Clear: true}}
+	runInTx(tx)
+	next.EXPECT().PatchEmbedding(gomock.Any(), int64(1), patch, int64(0)).Return(&domain.Embedding{ID: 1, Version: 4}, nil)
+
+	var recorded *domain.EmbeddingAudit
+	repo.EXPECT().AddEmbeddingAudit(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, audit *domain.EmbeddingAudit) error {
+			recorded = audit
+			return nil
+		})
+
+	_, err := service.PatchEmbedding(ctx, 1, patch, 0)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.EmbeddingAudit{EmbeddingID: 1, Device: "enroll-01", RequestID: "req-1",
+		Fields: []string{"name", "metadata"}, OldVersion: 3, NewVersion: 4}, recorded)
+
+	// An empty patch changes nothing and is not recorded.
+	runInTx(tx)
+	next.EXPECT().PatchEmbedding(gomock.Any(), int64(1), domain.EmbeddingPatch{}, int64(0)).Return(&domain.Embedding{ID: 1, Version: 4}, nil)
+	_, err = service.PatchEmbedding(ctx, 1, domain.EmbeddingPatch{}, 0)
+	assert.NoError(t, err)
+}
+
+func TestAuditedEmbeddingService_RecordFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingService(ctrl)
+	repo := repomocks.NewMockAuditRepository(ctrl)
+	tx := repomocks.NewMockTransactor(ctrl)
+	service := NewAuditedEmbeddingService(next, repo, tx)
+
+	runInTx(tx)
+	next.EXPECT().SetCaptureMinimums(gomock.Any(), int64(1), domain.CaptureMinimums{Quality: 0.5}, int64(0)).Return(int64(2), nil)
+	repo.EXPECT().AddEmbeddingAudit(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+
+	// The error rolls the change back together with its entry.
+	_, err := service.SetCaptureMinimums(context.Background(), 1, domain.CaptureMinimums{Quality: 0.5}, 0)
+	assert.EqualError(t, err, "db error")
+}
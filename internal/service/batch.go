@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"access-system-api/internal/capture"
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/domain"
+	"access-system-api/internal/repository"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+//go:generate mockgen -destination=../mocks/service/batch_mock.go -package=mocks . BatchService
+
+// BatchService validates the faces of a frame together.
+type BatchService interface {
+	// ValidateBatch validates every probe as a single validation would and
+	// returns the results in the order of probes. The probes are matched in
+	// a single repository call and then validated a few at a time. It only
+	// fails as a whole if the batch itself is invalid, or is rejected by
+	// guardedBatchService.
+	ValidateBatch(ctx context.Context, probes []domain.Probe) ([]domain.ProbeResult, error)
+}
+
+// batchService implements BatchService on top of EmbeddingService, so that
+// every probe is gated, recorded and published like a single validation. The
+// matches are looked up beforehand with GetSimilarEmbeddingsByVectors and
+// handed to ValidateMatchedEmbedding, so that a batch takes one query instead
+// of one per probe.
+type batchService struct {
+	embeddingService EmbeddingService
+	embeddingRepo    repository.EmbeddingRepository
+	matchingCfg      cfg.MatchingCfg
+}
+
+// NewBatchService creates a new instance of BatchService.
+func NewBatchService(embeddingService EmbeddingService, embeddingRepo repository.EmbeddingRepository, matchingCfg cfg.MatchingCfg) BatchService {
+	return &batchService{embeddingService: embeddingService, embeddingRepo: embeddingRepo, matchingCfg: matchingCfg}
+}
+
+func (s *batchService) ValidateBatch(ctx context.Context, probes []domain.Probe) ([]domain.ProbeResult, error) {
+	if len(probes) == 0 {
+		return nil, fmt.Errorf("%w: probes are required", domain.ErrInvalidInput)
+	}
+	if len(probes) > s.matchingCfg.MaxBatchSize {
+		return nil, fmt.Errorf("%w: at most %d probes are allowed, got %d", domain.ErrInvalidInput, s.matchingCfg.MaxBatchSize, len(probes))
+	}
+	seen := make(map[string]bool, len(probes))
+	for i, probe := range probes {
+		if probe.ID == "" {
+			return nil, fmt.Errorf("%w: probes[%d].id is required", domain.ErrInvalidInput, i)
+		}
+		if seen[probe.ID] {
+			return nil, fmt.Errorf("%w: probe id %q is not unique", domain.ErrInvalidInput, probe.ID)
+		}
+		seen[probe.ID] = true
+	}
+
+	matches := s.match(ctx, probes)
+	results := make([]domain.ProbeResult, len(probes))
+	slots := make(chan struct{}, s.matchingCfg.BatchConcurrency)
+	var wg sync.WaitGroup
+	for i, probe := range probes {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			results[i] = s.validate(ctx, probe, matches[i])
+		}()
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// match looks up the matches of all probes with valid capture scores and
+// vectors in a single repository call. The matches are in the order of probes,
+// zero for the invalid probes, whose validation reports why.
+func (s *batchService) match(ctx context.Context, probes []domain.Probe) []domain.Match {
+	matches := make([]domain.Match, len(probes))
+	vectors := make([]pgvector.Vector, 0, len(probes))
+	indexes := make([]int, 0, len(probes))
+	for i, probe := range probes {
+		if capture.Validate(probe.Capture) != nil {
+			continue
+		}
+		vector, err := prepareVector(probe.Vector, s.matchingCfg)
+		if err != nil {
+			continue
+		}
+		vectors = append(vectors, pgvector.NewVector(vector))
+		indexes = append(indexes, i)
+	}
+	if len(vectors) == 0 {
+		return matches
+	}
+
+	// A failed lookup fails every probe, as it would have one at a time.
+	embeddings, err := s.embeddingRepo.GetSimilarEmbeddingsByVectors(ctx, vectors)
+	for j, i := range indexes {
+		if err != nil {
+			matches[i] = domain.Match{Err: err}
+		} else {
+			matches[i] = domain.Match{Embedding: embeddings[j]}
+		}
+	}
+	return matches
+}
+
+// validate validates a single probe with its capture scores and its match.
+func (s *batchService) validate(ctx context.Context, probe domain.Probe, match domain.Match) domain.ProbeResult {
+	result := domain.ProbeResult{ID: probe.ID}
+	if err := capture.Validate(probe.Capture); err != nil {
+		result.Err = err
+		return result
+	}
+	ctx = capture.WithCapture(ctx, probe.Capture)
+	result.Embedding, result.Err = s.embeddingService.ValidateMatchedEmbedding(ctx, probe.Vector, match)
+	return result
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"access-system-api/internal/capture"
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/domain"
+	repomocks "access-system-api/internal/mocks/repository"
+	mocks "access-system-api/internal/mocks/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testBatchCfg = cfg.MatchingCfg{VectorSize: 1, MaxBatchSize: 3, BatchConcurrency: 2}
+
+func TestBatchService_ValidateBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingService(ctrl)
+	repo := repomocks.NewMockEmbeddingRepository(ctrl)
+	service := NewBatchService(next, repo, testBatchCfg)
+
+	// The valid probes are matched together, and every probe is validated
+	// with its match.
+	found := &domain.Embedding{ID: 1, Name: "test", Accuracy: 0.9}
+	repo.EXPECT().GetSimilarEmbeddingsByVectors(gomock.Any(), []pgvector.Vector{pgvector.NewVector([]float32{1}), pgvector.NewVector([]float32{2})}).
+		Return([]*domain.Embedding{found, nil}, nil)
+	next.EXPECT().ValidateMatchedEmbedding(gomock.Any(), []float32{1}, domain.Match{Embedding: found}).
+		DoAndReturn(func(ctx context.Context, _ []float32, m domain.Match) (*domain.Embedding, error) {
+			assert.Equal(t, score(0.95), capture.FromContext(ctx).Liveness)
+			return m.Embedding, nil
+		})
+	next.EXPECT().ValidateMatchedEmbedding(gomock.Any(), []float32{2}, domain.Match{}).
+		Return(nil, domain.ErrNoMatch)
+
+	results, err := service.ValidateBatch(context.Background(), []domain.Probe{
+		{ID: "a", Vector: []float32{1}, Capture: domain.Capture{Liveness: score(0.95)}},
+		{ID: "b", Vector: []float32{2}},
+		{ID: "c", Vector: []float32{3}, Capture: domain.Capture{Quality: score(1.5)}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, domain.ProbeResult{ID: "a", Embedding: found}, results[0])
+	assert.Equal(t, "b", results[1].ID)
+	assert.ErrorIs(t, results[1].Err, domain.ErrNoMatch)
+	assert.Equal(t, "c", results[2].ID)
+	assert.ErrorIs(t, results[2].Err, domain.ErrInvalidInput)
+}
+
+func TestBatchService_InvalidBatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		probes []domain.Probe
+	}{
+		{"empty", nil},
+		{"too many probes", []domain.Probe{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}},
+		{"missing id", []domain.Probe{{ID: "a"}, {}}},
+		{"duplicate id", []domain.Probe{{ID: "a"}, {ID: "a"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			service := NewBatchService(mocks.NewMockEmbeddingService(ctrl), repomocks.NewMockEmbeddingRepository(ctrl), testBatchCfg)
+
+			_, err := service.ValidateBatch(context.Background(), tt.probes)
+			assert.True(t, errors.Is(err, domain.ErrInvalidInput), err)
+		})
+	}
+}
+
+func TestBatchService_LookupError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingService(ctrl)
+	repo := repomocks.NewMockEmbeddingRepository(ctrl)
+	service := NewBatchService(next, repo, testBatchCfg)
+
+	// Invalid vectors are left to the validation of their probe.
+	lookupErr := errors.New("connection refused")
+	repo.EXPECT().GetSimilarEmbeddingsByVectors(gomock.Any(), []pgvector.Vector{pgvector.NewVector([]float32{1})}).
+		Return(nil, lookupErr)
+	next.EXPECT().ValidateMatchedEmbedding(gomock.Any(), []float32{1}, domain.Match{Err: lookupErr}).
+		Return(nil, lookupErr)
+	next.EXPECT().ValidateMatchedEmbedding(gomock.Any(), []float32{1, 2}, domain.Match{}).
+		Return(nil, domain.ErrInvalidDimension)
+
+	results, err := service.ValidateBatch(context.Background(), []domain.Probe{
+		{ID: "a", Vector: []float32{1}},
+		{ID: "b", Vector: []float32{1, 2}},
+	})
+	require.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, lookupErr)
+	assert.ErrorIs(t, results[1].Err, domain.ErrInvalidDimension)
+}
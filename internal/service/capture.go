@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+
+	"access-system-api/internal/capture"
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+)
+
+// captureGatedEmbeddingService wraps an EmbeddingService and rejects
+// enrollments and validations whose capture scores are below their minimums.
+type captureGatedEmbeddingService struct {
+	EmbeddingService
+	captureCfg   cfg.CaptureCfg
+	accessPoints map[string]string
+}
+
+// NewCaptureGatedEmbeddingService creates an EmbeddingService that checks the
+// capture scores of requests to next against the minimums of captureCfg.
+// accessPoints maps devices to the access point whose minimums apply to them.
+func NewCaptureGatedEmbeddingService(next EmbeddingService, captureCfg cfg.CaptureCfg, accessPoints map[string]string) EmbeddingService {
+	return &captureGatedEmbeddingService{EmbeddingService: next, captureCfg: captureCfg, accessPoints: accessPoints}
+}
+
+// minimums returns the capture minimums of the access point of device, which
+// like for door actuation is the access point named after device unless mapped.
+func (s *captureGatedEmbeddingService) minimums(device string) domain.CaptureMinimums {
+	minimums := domain.CaptureMinimums{Liveness: s.captureCfg.MinLiveness, Quality: s.captureCfg.MinQuality}
+	accessPoint, ok := s.accessPoints[device]
+	if !ok {
+		accessPoint = device
+	}
+	if liveness, ok := s.captureCfg.AccessPointMinLiveness[accessPoint]; ok {
+		minimums.Liveness = liveness
+	}
+	if quality, ok := s.captureCfg.AccessPointMinQuality[accessPoint]; ok {
+		minimums.Quality = quality
+	}
+	return minimums
+}
+
+func (s *captureGatedEmbeddingService) AddEmbedding(ctx context.Context, name string, vector []float32) error {
+	if err := capture.Check(capture.FromContext(ctx), s.minimums(identity.DeviceFromContext(ctx))); err != nil {
+		return err
+	}
+	return s.EmbeddingService.AddEmbedding(ctx, name, vector)
+}
+
+func (s *captureGatedEmbeddingService) ValidateEmbedding(ctx context.Context, vector []float32) (*domain.Embedding, error) {
+	return s.validate(ctx, func(ctx context.Context) (*domain.Embedding, error) {
+		return s.EmbeddingService.ValidateEmbedding(ctx, vector)
+	})
+}
+
+func (s *captureGatedEmbeddingService) ValidateMatchedEmbedding(ctx context.Context, vector []float32, match domain.Match) (*domain.Embedding, error) {
+	return s.validate(ctx, func(ctx context.Context) (*domain.Embedding, error) {
+		return s.EmbeddingService.ValidateMatchedEmbedding(ctx, vector, match)
+	})
+}
+
+// validate checks the minimums of the access point before matching and the
+// stricter minimums of the matched embedding after. The rejection does not
+// tell which embedding matched.
+func (s *captureGatedEmbeddingService) validate(ctx context.Context, next validation) (*domain.Embedding, error) {
+	captured := capture.FromContext(ctx)
+	minimums := s.minimums(identity.DeviceFromContext(ctx))
+	if err := capture.Check(captured, minimums); err != nil {
+		return nil, err
+	}
+
+	embedding, err := next(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := capture.Check(captured, embedding.CaptureMinimums.Max(minimums)); err != nil {
+		return nil, err
+	}
+	return embedding, nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"access-system-api/internal/capture"
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+	mocks "access-system-api/internal/mocks/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func score(v float64) *float64 {
+	return &v
+}
+
+var testCaptureCfg = cfg.CaptureCfg{
+	MinLiveness:            0.5,
+	AccessPointMinLiveness: map[string]float64{"north-door": 0.9},
+	AccessPointMinQuality:  map[string]float64{"north-door": 0.6},
+}
+
+func TestCaptureGatedEmbeddingService_ValidateEmbedding(t *testing.T) {
+	tests := []struct {
+		name      string
+		device    string
+		capture   domain.Capture
+		embedding *domain.Embedding // nil if next is not called
+		err       error
+	}{
+		{"default minimums", "gate-03", domain.Capture{Liveness: score(0.6)}, &domain.Embedding{ID: 1}, nil},
+		{"below default minimum", "gate-03", domain.Capture{Liveness: score(0.4)}, nil, domain.ErrCaptureRejected},
+		{"missing score", "gate-03", domain.Capture{}, nil, domain.ErrCaptureRejected},
+		{"access point minimums", "gate-01", domain.Capture{Liveness: score(0.95), Quality: score(0.7)}, &domain.Embedding{ID: 1}, nil},
+		{"below access point minimum", "gate-01", domain.Capture{Liveness: score(0.6), Quality: score(0.7)}, nil, domain.ErrCaptureRejected},
+		{"below embedding minimum", "gate-03", domain.Capture{Liveness: score(0.6)},
+			&domain.Embedding{ID: 1, CaptureMinimums: domain.CaptureMinimums{Liveness: 0.8}}, domain.ErrCaptureRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			next := mocks.NewMockEmbeddingService(ctrl)
+			service := NewCaptureGatedEmbeddingService(next, testCaptureCfg, map[string]string{"gate-01": "north-door"})
+
+			ctx := capture.WithCapture(identity.WithDevice(context.Background(), tt.device), tt.capture)
+			if tt.embedding != nil {
+				next.EXPECT().ValidateEmbedding(ctx, []float32{1}).Return(tt.embedding, nil)
+			}
+
+			embedding, err := service.ValidateEmbedding(ctx, []float32{1})
+			assert.ErrorIs(t, err, tt.err)
+			if tt.err != nil {
+				assert.Nil(t, embedding)
+			} else {
+				assert.Equal(t, tt.embedding, embedding)
+			}
+		})
+	}
+}
+
+func TestCaptureGatedEmbeddingService_ValidateEmbeddingNoMatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingService(ctrl)
+	service := NewCaptureGatedEmbeddingService(next, testCaptureCfg, nil)
+
+	ctx := capture.WithCapture(context.Background(), domain.Capture{Liveness: score(0.6)})
+	next.EXPECT().ValidateEmbedding(ctx, []float32{1}).Return(nil, domain.ErrNoMatch)
+
+	_, err := service.ValidateEmbedding(ctx, []float32{1})
+	assert.ErrorIs(t, err, domain.ErrNoMatch)
+}
+
+func TestCaptureGatedEmbeddingService_AddEmbedding(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingService(ctrl)
+	service := NewCaptureGatedEmbeddingService(next, testCaptureCfg, map[string]string{"gate-01": "north-door"})
+
+	ctx := capture.WithCapture(identity.WithDevice(context.Background(), "gate-01"), domain.Capture{Liveness: score(0.95)})
+	err := service.AddEmbedding(ctx, "test", []float32{1})
+	assert.ErrorIs(t, err, domain.ErrCaptureRejected, "quality is required at north-door")
+
+	ctx = capture.WithCapture(identity.WithDevice(context.Background(), "gate-01"), domain.Capture{Liveness: score(0.95), Quality: score(0.6)})
+	next.EXPECT().AddEmbedding(ctx, "test", []float32{1}).Return(nil)
+	assert.NoError(t, service.AddEmbedding(ctx, "test", []float32{1}))
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/repository"
+)
+
+//go:generate mockgen -destination=../mocks/service/device_mock.go -package=mocks . DeviceService
+
+// minSigningKeyLength is the minimum length of a client supplied device signing key.
+const minSigningKeyLength = 32
+
+// DeviceService defines the interface for managing the device registry.
+type DeviceService interface {
+	// RegisterDevice registers the device with the given certificate common
+	// name. A signing key is generated if signingKey is empty.
+	RegisterDevice(ctx context.Context, name string, signingKey string) (*domain.Device, error)
+	GetDevice(ctx context.Context, id int64) (*domain.Device, error)
+	ListDevices(ctx context.Context) ([]*domain.Device, error)
+	// RotateSigningKey replaces the signing key of a device with a generated one.
+	RotateSigningKey(ctx context.Context, id int64) (*domain.Device, error)
+	DeleteDevice(ctx context.Context, id int64) error
+	// SigningKey returns the signing key of the device with the given name,
+	// or domain.ErrNotFound if it is not registered.
+	SigningKey(ctx context.Context, name string) (string, error)
+}
+
+// deviceService is the concrete implementation of DeviceService.
+type deviceService struct {
+	deviceRepo repository.DeviceRepository
+}
+
+// NewDeviceService creates a new instance of DeviceService.
+func NewDeviceService(deviceRepo repository.DeviceRepository) DeviceService {
+	return &deviceService{deviceRepo: deviceRepo}
+}
+
+func (s *deviceService) RegisterDevice(ctx context.Context, name string, signingKey string) (*domain.Device, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == identity.UnknownDevice {
+		return nil, fmt.Errorf("%w: name must be the common name of the device certificate", domain.ErrInvalidInput)
+	}
+
+	switch {
+	case signingKey == "":
+		signingKey = newSecret()
+	case len(signingKey) < minSigningKeyLength:
+		return nil, fmt.Errorf("%w: signing key must be at least %d characters", domain.ErrInvalidInput, minSigningKeyLength)
+	}
+
+	device := &domain.Device{Name: name, SigningKey: signingKey}
+	if err := s.deviceRepo.CreateDevice(ctx, device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func (s *deviceService) GetDevice(ctx context.Context, id int64) (*domain.Device, error) {
+	return s.deviceRepo.GetDeviceById(ctx, id)
+}
+
+func (s *deviceService) ListDevices(ctx context.Context) ([]*domain.Device, error) {
+	return s.deviceRepo.ListDevices(ctx)
+}
+
+func (s *deviceService) RotateSigningKey(ctx context.Context, id int64) (*domain.Device, error) {
+	return s.deviceRepo.UpdateSigningKey(ctx, id, newSecret())
+}
+
+func (s *deviceService) DeleteDevice(ctx context.Context, id int64) error {
+	return s.deviceRepo.DeleteDeviceById(ctx, id)
+}
+
+func (s *deviceService) SigningKey(ctx context.Context, name string) (string, error) {
+	device, err := s.deviceRepo.GetDeviceByName(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	return device.SigningKey, nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"access-system-api/internal/domain"
+	repomocks "access-system-api/internal/mocks/repository"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceService_RegisterDevice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := repomocks.NewMockDeviceRepository(ctrl)
+	service := NewDeviceService(repo)
+
+	repo.EXPECT().CreateDevice(gomock.Any(), gomock.Any()).Return(nil)
+
+	device, err := service.RegisterDevice(context.Background(), " gate-01 ", "")
+	require.NoError(t, err)
+	assert.Equal(t, "gate-01", device.Name)
+	assert.Len(t, device.SigningKey, 64)
+}
+
+func TestDeviceService_RegisterDevice_Invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		device     string
+		signingKey string
+	}{
+		{"empty name", "", ""},
+		{"unknown device", "unknown", ""},
+		{"short key", "gate-01", "short"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewDeviceService(repomocks.NewMockDeviceRepository(gomock.NewController(t)))
+
+			_, err := service.RegisterDevice(context.Background(), tt.device, tt.signingKey)
+			assert.ErrorIs(t, err, domain.ErrInvalidInput)
+		})
+	}
+}
+
+func TestDeviceService_RotateSigningKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := repomocks.NewMockDeviceRepository(ctrl)
+	service := NewDeviceService(repo)
+
+	repo.EXPECT().UpdateSigningKey(gomock.Any(), int64(1), gomock.Any()).
+		DoAndReturn(func(_ context.Context, id int64, signingKey string) (*domain.Device, error) {
+			return &domain.Device{ID: id, Name: "gate-01", SigningKey: signingKey}, nil
+		})
+
+	device, err := service.RotateSigningKey(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Len(t, device.SigningKey, 64)
+}
+
+func TestDeviceService_SigningKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := repomocks.NewMockDeviceRepository(ctrl)
+	service := NewDeviceService(repo)
+	key := strings.Repeat("k", 32)
+
+	repo.EXPECT().GetDeviceByName(gomock.Any(), "gate-01").Return(&domain.Device{Name: "gate-01", SigningKey: key}, nil)
+	repo.EXPECT().GetDeviceByName(gomock.Any(), "gate-02").Return(nil, fmt.Errorf("%w: device gate-02", domain.ErrNotFound))
+
+	got, err := service.SigningKey(context.Background(), "gate-01")
+	require.NoError(t, err)
+	assert.Equal(t, key, got)
+
+	_, err = service.SigningKey(context.Background(), "gate-02")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
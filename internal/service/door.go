@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/door"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/logging"
+
+	"github.com/sirupsen/logrus"
+)
+
+// actuatingEmbeddingService wraps an EmbeddingService and sends door commands
+// for the access decisions of validations.
+type actuatingEmbeddingService struct {
+	EmbeddingService
+	actuator      door.Actuator
+	alarmOnDenied bool
+}
+
+// NewActuatingEmbeddingService creates an EmbeddingService that unlocks the
+// door of the calling device on a match and, if alarmOnDenied is set, raises
+// its alarm when there is no match.
+func NewActuatingEmbeddingService(next EmbeddingService, actuator door.Actuator, alarmOnDenied bool) EmbeddingService {
+	return &actuatingEmbeddingService{EmbeddingService: next, actuator: actuator, alarmOnDenied: alarmOnDenied}
+}
+
+func (s *actuatingEmbeddingService) ValidateEmbedding(ctx context.Context, vector []float32) (*domain.Embedding, error) {
+	return s.validate(ctx, func(ctx context.Context) (*domain.Embedding, error) {
+		return s.EmbeddingService.ValidateEmbedding(ctx, vector)
+	})
+}
+
+func (s *actuatingEmbeddingService) ValidateMatchedEmbedding(ctx context.Context, vector []float32, match domain.Match) (*domain.Embedding, error) {
+	return s.validate(ctx, func(ctx context.Context) (*domain.Embedding, error) {
+		return s.EmbeddingService.ValidateMatchedEmbedding(ctx, vector, match)
+	})
+}
+
+// validate sends the door command of the decision. Failing to send it does
+// not change the decision returned to the terminal.
+func (s *actuatingEmbeddingService) validate(ctx context.Context, next validation) (*domain.Embedding, error) {
+	embedding, err := next(ctx)
+
+	device := identity.DeviceFromContext(ctx)
+	var actErr error
+	switch {
+	case err == nil:
+		actErr = s.actuator.Unlock(ctx, device)
+	case errors.Is(err, domain.ErrNoMatch) && s.alarmOnDenied:
+		actErr = s.actuator.Alarm(ctx, device)
+	}
+
+	if actErr != nil {
+		entry := logging.FromContext(ctx, nil).WithError(actErr)
+		if errors.Is(actErr, door.ErrUnknownAccessPoint) {
+			entry.Warn("Door command not sent")
+		} else {
+			entry.WithFields(logrus.Fields{"device": device}).Error("Error sending door command")
+		}
+	}
+	return embedding, err
+}
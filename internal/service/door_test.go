@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/door"
+	"access-system-api/internal/identity"
+	mocks "access-system-api/internal/mocks/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingActuator struct {
+	commands []string
+	err      error
+}
+
+func (a *recordingActuator) Unlock(_ context.Context, device string) error {
+	a.commands = append(a.commands, door.CommandUnlock+" "+device)
+	return a.err
+}
+
+func (a *recordingActuator) Alarm(_ context.Context, device string) error {
+	a.commands = append(a.commands, door.CommandAlarm+" "+device)
+	return a.err
+}
+
+func TestActuatingEmbeddingService_ValidateEmbedding(t *testing.T) {
+	tests := []struct {
+		name          string
+		embedding     *domain.Embedding
+		err           error
+		alarmOnDenied bool
+		actErr        error
+		commands      []string
+	}{
+		{"match", &domain.Embedding{ID: 1}, nil, false, nil, []string{"unlock gate-01"}},
+		{"no match", nil, domain.ErrNoMatch, false, nil, nil},
+		{"no match with alarm", nil, domain.ErrNoMatch, true, nil, []string{"alarm gate-01"}},
+		{"error", nil, errors.New("db error"), true, nil, nil},
+		{"command failed", &domain.Embedding{ID: 1}, nil, false, door.ErrNotConnected, []string{"unlock gate-01"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			next := mocks.NewMockEmbeddingService(ctrl)
+			actuator := &recordingActuator{err: tt.actErr}
+			service := NewActuatingEmbeddingService(next, actuator, tt.alarmOnDenied)
+
+			ctx := identity.WithDevice(context.Background(), "gate-01")
+			next.EXPECT().ValidateEmbedding(ctx, []float32{1}).Return(tt.embedding, tt.err)
+
+			embedding, err := service.ValidateEmbedding(ctx, []float32{1})
+			assert.Equal(t, tt.embedding, embedding)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.commands, actuator.commands)
+		})
+	}
+}
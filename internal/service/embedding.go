@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"fmt"
+	"math"
 
+	"access-system-api/internal/capture"
+	"access-system-api/internal/cfg"
 	"access-system-api/internal/domain"
 	"access-system-api/internal/repository"
 
@@ -18,24 +21,50 @@ type EmbeddingService interface {
 	GetEmbedding(ctx context.Context, id int64) (*domain.Embedding, error)
 	ListEmbeddings(ctx context.Context) ([]*domain.Embedding, error)
 	ValidateEmbedding(ctx context.Context, vector []float32) (*domain.Embedding, error)
-	UpdateEmbedding(ctx context.Context, id int64, name string, vector []float32) error
-	DeleteEmbedding(ctx context.Context, id int64) error
+	// ValidateMatchedEmbedding validates vector like ValidateEmbedding, but
+	// with the match BatchService looked up for it together with the other
+	// probes of its frame instead of looking it up. It is neither rate limited
+	// nor counted towards lockouts, which BatchService does for the frame.
+	ValidateMatchedEmbedding(ctx context.Context, vector []float32, match domain.Match) (*domain.Embedding, error)
+	// UpdateEmbedding changes the name and vector of an embedding if it is at
+	// version, or at any version if that is 0, and returns its new version.
+	UpdateEmbedding(ctx context.Context, id int64, name string, vector []float32, version int64) (int64, error)
+	// PatchEmbedding changes the fields set in patch if the embedding is at
+	// version, or at any version if that is 0, and returns the embedding. Only
+	// the fields set are validated; an empty patch changes nothing.
+	PatchEmbedding(ctx context.Context, id int64, patch domain.EmbeddingPatch, version int64) (*domain.Embedding, error)
+	// SetCaptureMinimums sets the minimum capture scores of validations
+	// matching an embedding if it is at version, or at any version if that is
+	// 0, and returns its new version.
+	SetCaptureMinimums(ctx context.Context, id int64, minimums domain.CaptureMinimums, version int64) (int64, error)
+	// ListInvalidEmbeddings reports the stored embeddings whose vectors fail the
+	// checks of new vectors, e.g. rows written before the checks existed.
+	ListInvalidEmbeddings(ctx context.Context) ([]*domain.InvalidEmbedding, error)
+	// DeleteEmbedding removes an embedding if it is at version, or at any
+	// version if that is 0.
+	DeleteEmbedding(ctx context.Context, id int64, version int64) error
 }
 
+// validation validates a probe, see ValidateEmbedding and
+// ValidateMatchedEmbedding. Decorators wrap both alike with it.
+type validation func(ctx context.Context) (*domain.Embedding, error)
+
 // embeddingService is the concrete implementation of EmbeddingService.
 type embeddingService struct {
 	embeddingRepo repository.EmbeddingRepository
+	matchingCfg   cfg.MatchingCfg
 }
 
 // NewEmbeddingService creates a new instance of EmbeddingService.
-func NewEmbeddingService(embeddingRepo repository.EmbeddingRepository) EmbeddingService {
-	return &embeddingService{embeddingRepo: embeddingRepo}
+func NewEmbeddingService(embeddingRepo repository.EmbeddingRepository, matchingCfg cfg.MatchingCfg) EmbeddingService {
+	return &embeddingService{embeddingRepo: embeddingRepo, matchingCfg: matchingCfg}
 }
 
 // AddEmbedding adds a new embedding to the repository.
 func (s *embeddingService) AddEmbedding(ctx context.Context, name string, vector []float32) error {
-	if len(vector) != 512 {
-		return fmt.Errorf("vector size must be 512, got %d", len(vector))
+	vector, err := prepareVector(vector, s.matchingCfg)
+	if err != nil {
+		return err
 	}
 	embedding := &domain.Embedding{
 		Name:   name,
@@ -54,8 +83,9 @@ func (s *embeddingService) ListEmbeddings(ctx context.Context) ([]*domain.Embedd
 
 // ValidateEmbedding checks if a similar embedding exists in the repository.
 func (s *embeddingService) ValidateEmbedding(ctx context.Context, vector []float32) (*domain.Embedding, error) {
-	if len(vector) != 512 {
-		return nil, fmt.Errorf("vector size must be 512, got %d", len(vector))
+	vector, err := prepareVector(vector, s.matchingCfg)
+	if err != nil {
+		return nil, err
 	}
 	embedding, err := s.embeddingRepo.GetSimilarEmbeddingByVector(ctx, pgvector.NewVector(vector))
 	if err != nil {
@@ -64,19 +94,129 @@ func (s *embeddingService) ValidateEmbedding(ctx context.Context, vector []float
 	return embedding, nil
 }
 
-func (s *embeddingService) UpdateEmbedding(ctx context.Context, id int64, name string, vector []float32) error {
-	if len(vector) != 512 {
-		return fmt.Errorf("vector size must be 512, got %d", len(vector))
+// ValidateMatchedEmbedding checks vector like ValidateEmbedding and returns
+// its match.
+func (s *embeddingService) ValidateMatchedEmbedding(_ context.Context, vector []float32, match domain.Match) (*domain.Embedding, error) {
+	if _, err := prepareVector(vector, s.matchingCfg); err != nil {
+		return nil, err
+	}
+	if match.Err != nil {
+		return nil, match.Err
+	}
+	if match.Embedding == nil {
+		return nil, domain.ErrNoMatch
+	}
+	return match.Embedding, nil
+}
+
+func (s *embeddingService) UpdateEmbedding(ctx context.Context, id int64, name string, vector []float32, version int64) (int64, error) {
+	vector, err := prepareVector(vector, s.matchingCfg)
+	if err != nil {
+		return 0, err
 	}
 	embedding := &domain.Embedding{
-		ID:     id,
-		Name:   name,
-		Vector: pgvector.NewVector(vector),
+		ID:      id,
+		Name:    name,
+		Vector:  pgvector.NewVector(vector),
+		Version: version,
+	}
+	if err := s.embeddingRepo.UpdateEmbedding(ctx, embedding); err != nil {
+		return 0, err
+	}
+	return embedding.Version, nil
+}
+
+func (s *embeddingService) PatchEmbedding(ctx context.Context, id int64, patch domain.EmbeddingPatch, version int64) (*domain.Embedding, error) {
+	if len(patch.Fields()) == 0 {
+		embedding, err := s.embeddingRepo.GetEmbeddingById(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if version != 0 && embedding.Version != version {
+			return nil, fmt.Errorf("%w: embedding %d is at version %d", domain.ErrVersionConflict, id, embedding.Version)
+		}
+		return embedding, nil
+	}
+
+	if patch.Name != nil && *patch.Name == "" {
+		return nil, fmt.Errorf("%w: name must not be empty", domain.ErrInvalidInput)
+	}
+	if patch.Vector != nil {
+		vector, err := prepareVector(patch.Vector.Slice(), s.matchingCfg)
+		if err != nil {
+			return nil, err
+		}
+		prepared := pgvector.NewVector(vector)
+		patch.Vector = &prepared
+	}
+	var minimums domain.CaptureMinimums
+	if patch.MinLiveness != nil {
+		minimums.Liveness = *patch.MinLiveness
+	}
+	if patch.MinQuality != nil {
+		minimums.Quality = *patch.MinQuality
+	}
+	if err := capture.ValidateMinimums(minimums); err != nil {
+		return nil, err
+	}
+	if patch.Metadata != nil {
+		if err := validateMetadataPatch(*patch.Metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.embeddingRepo.PatchEmbedding(ctx, id, patch, version)
+}
+
+// validateMetadataPatch checks the keys and values of a metadata patch
+// against the limits in domain. The number of keys of the patched metadata is
+// checked by the repository.
+func validateMetadataPatch(patch domain.MetadataPatch) error {
+	if len(patch.Set) > domain.MaxMetadataKeys {
+		return fmt.Errorf("%w: metadata must have at most %d keys", domain.ErrInvalidInput, domain.MaxMetadataKeys)
+	}
+	for key, value := range patch.Set {
+		if key == "" || len(key) > domain.MaxMetadataKeyLength {
+			return fmt.Errorf("%w: metadata keys must have 1 to %d bytes", domain.ErrInvalidInput, domain.MaxMetadataKeyLength)
+		}
+		if value != nil && len(*value) > domain.MaxMetadataValueLength {
+			return fmt.Errorf("%w: metadata value of %q must have at most %d bytes", domain.ErrInvalidInput, key, domain.MaxMetadataValueLength)
+		}
+	}
+	return nil
+}
+
+func (s *embeddingService) SetCaptureMinimums(ctx context.Context, id int64, minimums domain.CaptureMinimums, version int64) (int64, error) {
+	if err := capture.ValidateMinimums(minimums); err != nil {
+		return 0, err
+	}
+	return s.embeddingRepo.UpdateCaptureMinimums(ctx, id, minimums, version)
+}
+
+func (s *embeddingService) ListInvalidEmbeddings(ctx context.Context) ([]*domain.InvalidEmbedding, error) {
+	embeddings, err := s.embeddingRepo.ListEmbeddings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var invalid []*domain.InvalidEmbedding
+	for _, embedding := range embeddings {
+		norm, err := checkVector(embedding.Vector.Slice(), s.matchingCfg.VectorSize)
+		var problem string
+		switch {
+		case err != nil:
+			problem = err.Error()
+		case s.matchingCfg.Normalize && math.Abs(norm-1) > normTolerance:
+			problem = fmt.Sprintf("vector is not normalized, its norm is %g", norm)
+		default:
+			continue
+		}
+		invalid = append(invalid, &domain.InvalidEmbedding{ID: embedding.ID, Name: embedding.Name, Problem: problem})
 	}
-	return s.embeddingRepo.UpdateEmbedding(ctx, embedding)
+	return invalid, nil
 }
 
 // DeleteEmbedding removes an embedding from the repository by its ID.
-func (s *embeddingService) DeleteEmbedding(ctx context.Context, id int64) error {
-	return s.embeddingRepo.DeleteEmbeddingById(ctx, id)
+func (s *embeddingService) DeleteEmbedding(ctx context.Context, id int64, version int64) error {
+	return s.embeddingRepo.DeleteEmbeddingById(ctx, id, version)
 }
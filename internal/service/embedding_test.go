@@ -2,8 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
+	"math"
+	"strings"
 	"testing"
 
+	"access-system-api/internal/cfg"
 	"access-system-api/internal/domain"
 	"access-system-api/internal/mocks/repository"
 
@@ -17,7 +21,7 @@ func TestEmbeddingService_AddEmbedding(t *testing.T) {
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockEmbeddingRepository(ctrl)
-	service := NewEmbeddingService(repo)
+	service := NewEmbeddingService(repo, cfg.Default().Matching)
 
 	ctx := context.Background()
 	name := "test"
@@ -42,14 +46,14 @@ func TestEmbeddingService_AddEmbedding_InvalidVectorSize(t *testing.T) {
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockEmbeddingRepository(ctrl)
-	service := NewEmbeddingService(repo)
+	service := NewEmbeddingService(repo, cfg.Default().Matching)
 
 	ctx := context.Background()
 	name := "test"
 	vector := make([]float32, 100) // Invalid size
 
 	err := service.AddEmbedding(ctx, name, vector)
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, domain.ErrInvalidDimension)
 	assert.Contains(t, err.Error(), "vector size must be 512")
 }
 
@@ -58,7 +62,7 @@ func TestEmbeddingService_ValidateEmbedding(t *testing.T) {
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockEmbeddingRepository(ctrl)
-	service := NewEmbeddingService(repo)
+	service := NewEmbeddingService(repo, cfg.Default().Matching)
 
 	ctx := context.Background()
 	vector := make([]float32, 512)
@@ -72,12 +76,40 @@ func TestEmbeddingService_ValidateEmbedding(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestEmbeddingService_ValidateMatchedEmbedding(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockEmbeddingRepository(ctrl)
+	service := NewEmbeddingService(repo, cfg.Default().Matching)
+
+	vector := make([]float32, 512)
+	vector[0] = 1
+
+	// A probe matched as part of a batch is not looked up again.
+	found := &domain.Embedding{ID: 1}
+	got, err := service.ValidateMatchedEmbedding(context.Background(), vector, domain.Match{Embedding: found})
+	assert.NoError(t, err)
+	assert.Equal(t, found, got)
+
+	_, err = service.ValidateMatchedEmbedding(context.Background(), vector, domain.Match{})
+	assert.ErrorIs(t, err, domain.ErrNoMatch)
+
+	lookupErr := errors.New("connection refused")
+	_, err = service.ValidateMatchedEmbedding(context.Background(), vector, domain.Match{Err: lookupErr})
+	assert.ErrorIs(t, err, lookupErr)
+
+	// The vector is still checked.
+	_, err = service.ValidateMatchedEmbedding(context.Background(), vector[:100], domain.Match{Embedding: found})
+	assert.ErrorIs(t, err, domain.ErrInvalidDimension)
+}
+
 func TestEmbeddingService_ValidateEmbedding_InvalidVectorSize(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockEmbeddingRepository(ctrl)
-	service := NewEmbeddingService(repo)
+	service := NewEmbeddingService(repo, cfg.Default().Matching)
 
 	ctx := context.Background()
 	vector := make([]float32, 100) // Invalid size
@@ -92,14 +124,14 @@ func TestEmbeddingService_DeleteEmbedding(t *testing.T) {
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockEmbeddingRepository(ctrl)
-	service := NewEmbeddingService(repo)
+	service := NewEmbeddingService(repo, cfg.Default().Matching)
 
 	ctx := context.Background()
 	id := int64(123)
 
-	repo.EXPECT().DeleteEmbeddingById(ctx, id).Return(nil)
+	repo.EXPECT().DeleteEmbeddingById(ctx, id, int64(0)).Return(nil)
 
-	err := service.DeleteEmbedding(ctx, id)
+	err := service.DeleteEmbedding(ctx, id, 0)
 	assert.NoError(t, err)
 }
 
@@ -108,7 +140,7 @@ func TestEmbeddingService_GetEmbedding(t *testing.T) {
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockEmbeddingRepository(ctrl)
-	service := NewEmbeddingService(repo)
+	service := NewEmbeddingService(repo, cfg.Default().Matching)
 
 	ctx := context.Background()
 	id := int64(123)
@@ -125,7 +157,7 @@ func TestEmbeddingService_ListEmbeddings(t *testing.T) {
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockEmbeddingRepository(ctrl)
-	service := NewEmbeddingService(repo)
+	service := NewEmbeddingService(repo, cfg.Default().Matching)
 
 	ctx := context.Background()
 
@@ -141,7 +173,7 @@ func TestEmbeddingService_UpdateEmbedding(t *testing.T) {
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockEmbeddingRepository(ctrl)
-	service := NewEmbeddingService(repo)
+	service := NewEmbeddingService(repo, cfg.Default().Matching)
 
 	ctx := context.Background()
 	id := int64(123)
@@ -152,15 +184,20 @@ func TestEmbeddingService_UpdateEmbedding(t *testing.T) {
 	}
 
 	embedding := &domain.Embedding{
-		ID:     id,
-		Name:   name,
-		Vector: pgvector.NewVector(vector),
+		ID:      id,
+		Name:    name,
+		Vector:  pgvector.NewVector(vector),
+		Version: 4,
 	}
 
-	repo.EXPECT().UpdateEmbedding(ctx, embedding).Return(nil)
+	repo.EXPECT().UpdateEmbedding(ctx, embedding).DoAndReturn(func(_ context.Context, embedding *domain.Embedding) error {
+		embedding.Version++
+		return nil
+	})
 
-	err := service.UpdateEmbedding(ctx, id, name, vector)
+	version, err := service.UpdateEmbedding(ctx, id, name, vector, 4)
 	assert.NoError(t, err)
+	assert.Equal(t, int64(5), version)
 }
 
 func TestEmbeddingService_UpdateEmbedding_InvalidVectorSize(t *testing.T) {
@@ -168,14 +205,14 @@ func TestEmbeddingService_UpdateEmbedding_InvalidVectorSize(t *testing.T) {
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockEmbeddingRepository(ctrl)
-	service := NewEmbeddingService(repo)
+	service := NewEmbeddingService(repo, cfg.Default().Matching)
 
 	ctx := context.Background()
 	id := int64(123)
 	name := "updated"
 	vector := make([]float32, 100) // Invalid size
 
-	err := service.UpdateEmbedding(ctx, id, name, vector)
+	_, err := service.UpdateEmbedding(ctx, id, name, vector, 0)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "vector size must be 512")
 }
@@ -185,7 +222,7 @@ func TestEmbeddingService_ValidateEmbedding_RepoError(t *testing.T) {
 	defer ctrl.Finish()
 
 	repo := mocks.NewMockEmbeddingRepository(ctrl)
-	service := NewEmbeddingService(repo)
+	service := NewEmbeddingService(repo, cfg.Default().Matching)
 
 	ctx := context.Background()
 	vector := make([]float32, 512)
@@ -199,3 +236,126 @@ func TestEmbeddingService_ValidateEmbedding_RepoError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, emb)
 }
+
+func TestEmbeddingService_SetCaptureMinimums(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockEmbeddingRepository(ctrl)
+	service := NewEmbeddingService(repo, cfg.Default().Matching)
+
+	ctx := context.Background()
+	minimums := domain.CaptureMinimums{Liveness: 0.8, Quality: 0.5}
+	repo.EXPECT().UpdateCaptureMinimums(ctx, int64(1), minimums, int64(2)).Return(int64(3), nil)
+	version, err := service.SetCaptureMinimums(ctx, 1, minimums, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), version)
+
+	_, err = service.SetCaptureMinimums(ctx, 1, domain.CaptureMinimums{Liveness: 1.5}, 0)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+}
+
+func TestEmbeddingService_ValidateEmbedding_InvalidVector(t *testing.T) {
+	matchingCfg := cfg.MatchingCfg{VectorSize: 3, Threshold: 0.58}
+	tests := []struct {
+		name   string
+		vector []float32
+	}{
+		{"nan", []float32{0.1, float32(math.NaN()), 0.2}},
+		{"inf", []float32{0.1, float32(math.Inf(-1)), 0.2}},
+		{"zero", []float32{0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			service := NewEmbeddingService(mocks.NewMockEmbeddingRepository(ctrl), matchingCfg)
+
+			_, err := service.ValidateEmbedding(context.Background(), tt.vector)
+			assert.ErrorIs(t, err, domain.ErrInvalidVector)
+		})
+	}
+}
+
+func TestEmbeddingService_Normalize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockEmbeddingRepository(ctrl)
+	service := NewEmbeddingService(repo, cfg.MatchingCfg{VectorSize: 2, Threshold: 0.58, Normalize: true})
+
+	ctx := context.Background()
+	normalized := pgvector.NewVector([]float32{0.6, 0.8})
+	repo.EXPECT().CreateEmbedding(ctx, &domain.Embedding{Name: "test", Vector: normalized}).Return(nil)
+	repo.EXPECT().GetSimilarEmbeddingByVector(ctx, normalized).Return(&domain.Embedding{ID: 1}, nil)
+
+	assert.NoError(t, service.AddEmbedding(ctx, "test", []float32{3, 4}))
+	_, err := service.ValidateEmbedding(ctx, []float32{3, 4})
+	assert.NoError(t, err)
+}
+
+func TestEmbeddingService_ListInvalidEmbeddings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockEmbeddingRepository(ctrl)
+	service := NewEmbeddingService(repo, cfg.MatchingCfg{VectorSize: 2, Threshold: 0.58, Normalize: true})
+
+	ctx := context.Background()
+	repo.EXPECT().ListEmbeddings(ctx).Return([]*domain.Embedding{
+		{ID: 1, Name: "valid", Vector: pgvector.NewVector([]float32{0.6, 0.8})},
+		{ID: 2, Name: "zero", Vector: pgvector.NewVector([]float32{0, 0})},
+		{ID: 3, Name: "unnormalized", Vector: pgvector.NewVector([]float32{3, 4})},
+		{ID: 4, Name: "short", Vector: pgvector.NewVector([]float32{1})},
+	}, nil)
+
+	invalid, err := service.ListInvalidEmbeddings(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.InvalidEmbedding{
+		{ID: 2, Name: "zero", Problem: "invalid vector: vector norm 0 is too close to zero"},
+		{ID: 3, Name: "unnormalized", Problem: "vector is not normalized, its norm is 5"},
+		{ID: 4, Name: "short", Problem: "invalid vector dimension: vector size must be 2, got 1"},
+	}, invalid)
+}
+
+func TestEmbeddingService_PatchEmbedding(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockEmbeddingRepository(ctrl)
+	service := NewEmbeddingService(repo, cfg.Default().Matching)
+	ctx := context.Background()
+
+	// A rename is not checked against the vector settings.
+	name := "renamed"
+	patch := domain.EmbeddingPatch{Name: &name}
+	patched := &domain.Embedding{ID: 1, Name: name, Version: 3}
+	repo.EXPECT().PatchEmbedding(ctx, int64(1), patch, int64(2)).Return(patched, nil)
+	embedding, err := service.PatchEmbedding(ctx, 1, patch, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, patched, embedding)
+
+	// Only the fields that are set are validated.
+	empty := ""
+	short := pgvector.NewVector(make([]float32, 100))
+	liveness := 1.5
+	long := strings.Repeat("x", domain.MaxMetadataValueLength+1)
+	invalidPatches := []domain.EmbeddingPatch{
+		{Name: &empty},
+		{Vector: &short},
+		{MinLiveness: &liveness},
+		{Metadata: &domain.MetadataPatch{Set: map[string]*string{"": &name}}},
+		{Metadata: &domain.MetadataPatch{Set: map[string]*string{long[:domain.MaxMetadataKeyLength+1]: &name}}},
+		{Metadata: &domain.MetadataPatch{Set: map[string]*string{"badge": &long}}},
+	}
+	for _, invalid := range invalidPatches {
+		_, err := service.PatchEmbedding(ctx, 1, invalid, 0)
+		assert.Error(t, err)
+	}
+
+	// An empty patch changes nothing but still checks the version.
+	repo.EXPECT().GetEmbeddingById(ctx, int64(1)).Return(patched, nil).Times(2)
+	embedding, err = service.PatchEmbedding(ctx, 1, domain.EmbeddingPatch{}, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, patched, embedding)
+	_, err = service.PatchEmbedding(ctx, 1, domain.EmbeddingPatch{}, 2)
+	assert.ErrorIs(t, err, domain.ErrVersionConflict)
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/events"
+)
+
+// publishingEmbeddingService wraps an EmbeddingService and publishes an access
+// event for every validation and every successful change of an embedding.
+type publishingEmbeddingService struct {
+	EmbeddingService
+	publisher events.Publisher
+	zones     map[string]string
+}
+
+// NewPublishingEmbeddingService creates an EmbeddingService that publishes the
+// events of next to publisher. zones maps devices to the zone reported in their events.
+func NewPublishingEmbeddingService(next EmbeddingService, publisher events.Publisher, zones map[string]string) EmbeddingService {
+	return &publishingEmbeddingService{EmbeddingService: next, publisher: publisher, zones: zones}
+}
+
+func (s *publishingEmbeddingService) publish(ctx context.Context, event events.Event) {
+	event.Zone = s.zones[event.Device]
+	s.publisher.Publish(ctx, event)
+}
+
+func (s *publishingEmbeddingService) AddEmbedding(ctx context.Context, name string, vector []float32) error {
+	err := s.EmbeddingService.AddEmbedding(ctx, name, vector)
+	if err == nil {
+		event := events.New(ctx, events.TypeEmbeddingCreated)
+		event.Name = name
+		s.publish(ctx, event)
+	}
+	return err
+}
+
+func (s *publishingEmbeddingService) ValidateEmbedding(ctx context.Context, vector []float32) (*domain.Embedding, error) {
+	return s.validate(ctx, func(ctx context.Context) (*domain.Embedding, error) {
+		return s.EmbeddingService.ValidateEmbedding(ctx, vector)
+	})
+}
+
+func (s *publishingEmbeddingService) ValidateMatchedEmbedding(ctx context.Context, vector []float32, match domain.Match) (*domain.Embedding, error) {
+	return s.validate(ctx, func(ctx context.Context) (*domain.Embedding, error) {
+		return s.EmbeddingService.ValidateMatchedEmbedding(ctx, vector, match)
+	})
+}
+
+// validate publishes the decision of a validation.
+func (s *publishingEmbeddingService) validate(ctx context.Context, next validation) (*domain.Embedding, error) {
+	embedding, err := next(ctx)
+
+	event := events.New(ctx, events.TypeValidation)
+	switch {
+	case err == nil:
+		event.Decision = events.DecisionMatch
+		event.EmbeddingID = embedding.ID
+		event.Name = embedding.Name
+		event.Accuracy = embedding.Accuracy
+	case errors.Is(err, domain.ErrNoMatch):
+		event.Decision = events.DecisionNoMatch
+	case errors.Is(err, domain.ErrCaptureRejected):
+		event.Decision = events.DecisionRejected
+	default:
+		event.Decision = events.DecisionError
+	}
+	s.publish(ctx, event)
+
+	return embedding, err
+}
+
+func (s *publishingEmbeddingService) UpdateEmbedding(ctx context.Context, id int64, name string, vector []float32, version int64) (int64, error) {
+	updated, err := s.EmbeddingService.UpdateEmbedding(ctx, id, name, vector, version)
+	if err == nil {
+		event := events.New(ctx, events.TypeEmbeddingUpdated)
+		event.EmbeddingID = id
+		event.Name = name
+		s.publish(ctx, event)
+	}
+	return updated, err
+}
+
+func (s *publishingEmbeddingService) PatchEmbedding(ctx context.Context, id int64, patch domain.EmbeddingPatch, version int64) (*domain.Embedding, error) {
+	embedding, err := s.EmbeddingService.PatchEmbedding(ctx, id, patch, version)
+	if err == nil && len(patch.Fields()) > 0 {
+		s.publish(ctx, patchEvent(ctx, id, patch))
+	}
+	return embedding, err
+}
+
+// patchEvent returns the embedding.updated event of patch, which names only
+// the changed fields.
+func patchEvent(ctx context.Context, id int64, patch domain.EmbeddingPatch) events.Event {
+	event := events.New(ctx, events.TypeEmbeddingUpdated)
+	event.EmbeddingID = id
+	event.Fields = patch.Fields()
+	if patch.Name != nil {
+		event.Name = *patch.Name
+	}
+	return event
+}
+
+func (s *publishingEmbeddingService) DeleteEmbedding(ctx context.Context, id int64, version int64) error {
+	err := s.EmbeddingService.DeleteEmbedding(ctx, id, version)
+	if err == nil {
+		event := events.New(ctx, events.TypeEmbeddingDeleted)
+		event.EmbeddingID = id
+		s.publish(ctx, event)
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/events"
+	"access-system-api/internal/identity"
+	mocks "access-system-api/internal/mocks/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingPublisher struct {
+	events []events.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event events.Event) {
+	p.events = append(p.events, event)
+}
+
+func TestPublishingEmbeddingService_ValidateEmbedding(t *testing.T) {
+	tests := []struct {
+		name      string
+		embedding *domain.Embedding
+		err       error
+		decision  string
+	}{
+		{"match", &domain.Embedding{ID: 1, Name: "test", Accuracy: 0.9}, nil, events.DecisionMatch},
+		{"no match", nil, domain.ErrNoMatch, events.DecisionNoMatch},
+		{"capture rejected", nil, domain.ErrCaptureRejected, events.DecisionRejected},
+		{"error", nil, errors.New("db error"), events.DecisionError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			next := mocks.NewMockEmbeddingService(ctrl)
+			publisher := &recordingPublisher{}
+			service := NewPublishingEmbeddingService(next, publisher, map[string]string{"gate-01": "north"})
+
+			ctx := identity.WithDevice(context.Background(), "gate-01")
+			next.EXPECT().ValidateEmbedding(ctx, []float32{1}).Return(tt.embedding, tt.err)
+
+			embedding, err := service.ValidateEmbedding(ctx, []float32{1})
+			assert.Equal(t, tt.embedding, embedding)
+			assert.Equal(t, tt.err, err)
+
+			require.Len(t, publisher.events, 1)
+			event := publisher.events[0]
+			assert.Equal(t, events.TypeValidation, event.Type)
+			assert.Equal(t, "gate-01", event.Device)
+			assert.Equal(t, "north", event.Zone)
+			assert.Equal(t, tt.decision, event.Decision)
+			if tt.embedding != nil {
+				assert.Equal(t, tt.embedding.Name, event.Name)
+				assert.Equal(t, tt.embedding.Accuracy, event.Accuracy)
+			}
+		})
+	}
+}
+
+func TestPublishingEmbeddingService_Mutations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingService(ctrl)
+	publisher := &recordingPublisher{}
+	service := NewPublishingEmbeddingService(next, publisher, nil)
+	ctx := context.Background()
+
+	next.EXPECT().AddEmbedding(ctx, "test", []float32{1}).Return(nil)
+	next.EXPECT().UpdateEmbedding(ctx, int64(1), "renamed", []float32{1}, int64(0)).Return(int64(2), nil)
+	next.EXPECT().DeleteEmbedding(ctx, int64(1), int64(0)).Return(nil)
+	next.EXPECT().DeleteEmbedding(ctx, int64(2), int64(0)).Return(errors.New("db error"))
+
+	assert.NoError(t, service.AddEmbedding(ctx, "test", []float32{1}))
+	_, err := service.UpdateEmbedding(ctx, 1, "renamed", []float32{1}, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, service.DeleteEmbedding(ctx, 1, 0))
+	assert.Error(t, service.DeleteEmbedding(ctx, 2, 0))
+
+	require.Len(t, publisher.events, 3)
+	assert.Equal(t, events.TypeEmbeddingCreated, publisher.events[0].Type)
+	assert.Equal(t, events.TypeEmbeddingUpdated, publisher.events[1].Type)
+	assert.Equal(t, int64(1), publisher.events[1].EmbeddingID)
+	assert.Equal(t, events.TypeEmbeddingDeleted, publisher.events[2].Type)
+}
+
+func TestPublishingEmbeddingService_PatchEmbedding(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingService(ctrl)
+	publisher := &recordingPublisher{}
+	service := NewPublishingEmbeddingService(next, publisher, nil)
+	ctx := context.Background()
+
+	liveness := 0.8
+	patch := domain.EmbeddingPatch{MinLiveness: &liveness}
+	next.EXPECT().PatchEmbedding(ctx, int64(1), patch, int64(0)).Return(&domain.Embedding{ID: 1, Name: "alice"}, nil)
+	next.EXPECT().PatchEmbedding(ctx, int64(1), domain.EmbeddingPatch{}, int64(0)).Return(&domain.Embedding{ID: 1, Name: "alice"}, nil)
+
+	_, err := service.PatchEmbedding(ctx, 1, patch, 0)
+	assert.NoError(t, err)
+	_, err = service.PatchEmbedding(ctx, 1, domain.EmbeddingPatch{}, 0)
+	assert.NoError(t, err)
+
+	// Only the changed fields are recorded, and empty patches are not.
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, events.TypeEmbeddingUpdated, publisher.events[0].Type)
+	assert.Equal(t, []string{"min_liveness"}, publisher.events[0].Fields)
+	assert.Empty(t, publisher.events[0].Name)
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/events"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/logging"
+	"access-system-api/internal/metrics"
+	"access-system-api/internal/ratelimit"
+
+	"github.com/sirupsen/logrus"
+)
+
+// globalKey is the single bucket of the global limiter.
+const globalKey = ""
+
+// guard protects validations against brute force: it enforces per-device and
+// global rate limits and locks out devices after repeated validations without
+// a match.
+type guard struct {
+	devices   *ratelimit.Limiter
+	global    *ratelimit.Limiter
+	lockouts  *ratelimit.Lockouts
+	publisher events.Publisher
+	zones     map[string]string
+	metrics   *metrics.Metrics
+}
+
+// admit rejects the calling device if it is locked out or rate limited, and
+// returns its lockout state otherwise. The lockout is checked first, so a
+// locked out device costs no rate limit tokens.
+func (g *guard) admit(ctx context.Context) (*domain.LockoutState, error) {
+	tenant, device := identity.TenantFromContext(ctx), identity.DeviceFromContext(ctx)
+
+	state, err := g.lockouts.State(ctx, device)
+	if err != nil {
+		return nil, fmt.Errorf("check lockout of %s: %w", device, err)
+	}
+	if remaining := g.lockouts.Remaining(state); remaining > 0 {
+		return nil, domain.RetryAfter(fmt.Errorf("%w: too many validations without a match from %s", domain.ErrLockedOut, device), remaining)
+	}
+	// Devices with the same name in different tenants have their own limits.
+	if ok, wait := g.devices.Allow(tenant + "/" + device); !ok {
+		return nil, domain.RetryAfter(fmt.Errorf("%w: too many validations from %s", domain.ErrRateLimited, device), wait)
+	}
+	if ok, wait := g.global.Allow(globalKey); !ok {
+		return nil, domain.RetryAfter(fmt.Errorf("%w: too many validations", domain.ErrRateLimited), wait)
+	}
+	return state, nil
+}
+
+// record counts the outcome of an admitted validation towards the lockout of
+// the calling device, whose state admit returned: err is nil for a match.
+func (g *guard) record(ctx context.Context, state *domain.LockoutState, err error) {
+	device := identity.DeviceFromContext(ctx)
+	switch {
+	case err == nil:
+		if err := g.lockouts.Succeed(ctx, device, state); err != nil {
+			logging.FromContext(ctx, nil).WithError(err).Error("Error resetting device lockout")
+		}
+	case errors.Is(err, domain.ErrNoMatch):
+		lockout, lockoutErr := g.lockouts.Fail(ctx, device)
+		if lockoutErr != nil {
+			logging.FromContext(ctx, nil).WithError(lockoutErr).Error("Error recording failed validation for lockout")
+		}
+		if lockout != nil {
+			g.alert(ctx, lockout)
+		}
+	}
+}
+
+// alert reports a new lockout.
+func (g *guard) alert(ctx context.Context, lockout *domain.Lockout) {
+	g.metrics.ObserveLockout(lockout.Device)
+	logging.FromContext(ctx, nil).WithFields(logrus.Fields{
+		"locked_until":  lockout.LockedUntil,
+		"lockout_level": lockout.Level,
+	}).Warn("Device locked out after repeated validations without a match")
+
+	event := events.New(ctx, events.TypeDeviceLockedOut)
+	event.Zone = g.zones[event.Device]
+	event.LockedUntil = &lockout.LockedUntil
+	event.LockoutLevel = lockout.Level
+	g.publisher.Publish(ctx, event)
+}
+
+// guardedEmbeddingService wraps an EmbeddingService and guards its
+// validations. The validations of a batch are guarded as a whole by
+// guardedBatchService instead.
+type guardedEmbeddingService struct {
+	EmbeddingService
+	guard *guard
+}
+
+// NewGuardedEmbeddingService creates an EmbeddingService that limits the
+// validations of next. Lockouts are published to publisher as alert events;
+// zones maps devices to the zone reported in these events. Nil limiters and
+// lockouts are disabled.
+func NewGuardedEmbeddingService(next EmbeddingService, devices, global *ratelimit.Limiter, lockouts *ratelimit.Lockouts, publisher events.Publisher, zones map[string]string, m *metrics.Metrics) EmbeddingService {
+	return &guardedEmbeddingService{
+		EmbeddingService: next,
+		guard:            &guard{devices: devices, global: global, lockouts: lockouts, publisher: publisher, zones: zones, metrics: m},
+	}
+}
+
+func (s *guardedEmbeddingService) ValidateEmbedding(ctx context.Context, vector []float32) (*domain.Embedding, error) {
+	state, err := s.guard.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	embedding, err := s.EmbeddingService.ValidateEmbedding(ctx, vector)
+	s.guard.record(ctx, state, err)
+	return embedding, err
+}
+
+// guardedBatchService wraps a BatchService and guards each batch like a
+// single validation: a frame takes one rate limit token, and counts as one
+// failure towards the lockout if none of its faces matched, so a crowd of
+// unknown faces does not lock out the camera.
+type guardedBatchService struct {
+	BatchService
+	guard *guard
+}
+
+// NewGuardedBatchService creates a BatchService that limits the batches of
+// next, see NewGuardedEmbeddingService. Give it the limiters and lockouts of
+// the guarded EmbeddingService, so batches and single validations share them.
+func NewGuardedBatchService(next BatchService, devices, global *ratelimit.Limiter, lockouts *ratelimit.Lockouts, publisher events.Publisher, zones map[string]string, m *metrics.Metrics) BatchService {
+	return &guardedBatchService{
+		BatchService: next,
+		guard:        &guard{devices: devices, global: global, lockouts: lockouts, publisher: publisher, zones: zones, metrics: m},
+	}
+}
+
+// ValidateBatch admits the batch before its probes are matched. The batch
+// counts as a match if any probe matched, and as a failure if none did and
+// any had no match.
+func (s *guardedBatchService) ValidateBatch(ctx context.Context, probes []domain.Probe) ([]domain.ProbeResult, error) {
+	state, err := s.guard.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results, err := s.BatchService.ValidateBatch(ctx, probes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case slices.ContainsFunc(results, func(result domain.ProbeResult) bool { return result.Err == nil }):
+		s.guard.record(ctx, state, nil)
+	case slices.ContainsFunc(results, func(result domain.ProbeResult) bool { return errors.Is(result.Err, domain.ErrNoMatch) }):
+		s.guard.record(ctx, state, domain.ErrNoMatch)
+	}
+	return results, nil
+}
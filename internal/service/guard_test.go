@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/events"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/metrics"
+	repomocks "access-system-api/internal/mocks/repository"
+	mocks "access-system-api/internal/mocks/service"
+	"access-system-api/internal/ratelimit"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuardedEmbeddingService_DeviceRateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingService(ctrl)
+	service := NewGuardedEmbeddingService(next, ratelimit.NewLimiter(1, 2), nil, nil, &recordingPublisher{}, nil, metrics.New())
+
+	ctx := identity.WithDevice(context.Background(), "gate-01")
+	next.EXPECT().ValidateEmbedding(ctx, []float32{1}).Return(&domain.Embedding{ID: 1}, nil).Times(2)
+
+	for i := 0; i < 2; i++ {
+		_, err := service.ValidateEmbedding(ctx, []float32{1})
+		require.NoError(t, err)
+	}
+
+	_, err := service.ValidateEmbedding(ctx, []float32{1})
+	assert.ErrorIs(t, err, domain.ErrRateLimited)
+	var retry *domain.RetryAfterError
+	require.ErrorAs(t, err, &retry)
+	assert.Positive(t, retry.After)
+}
+
+func TestGuardedEmbeddingService_GlobalRateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingService(ctrl)
+	service := NewGuardedEmbeddingService(next, nil, ratelimit.NewLimiter(1, 1), nil, &recordingPublisher{}, nil, metrics.New())
+
+	next.EXPECT().ValidateEmbedding(gomock.Any(), []float32{1}).Return(&domain.Embedding{ID: 1}, nil)
+
+	_, err := service.ValidateEmbedding(identity.WithDevice(context.Background(), "gate-01"), []float32{1})
+	require.NoError(t, err)
+
+	_, err = service.ValidateEmbedding(identity.WithDevice(context.Background(), "gate-02"), []float32{1})
+	assert.ErrorIs(t, err, domain.ErrRateLimited)
+}
+
+var testLockoutPolicy = ratelimit.LockoutPolicy{Threshold: 2, Window: time.Minute, Duration: time.Minute, MaxDuration: time.Hour}
+
+func TestGuardedEmbeddingService_Lockout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingService(ctrl)
+	publisher := &recordingPublisher{}
+	lockouts := ratelimit.NewLockouts(testLockoutPolicy, ratelimit.NewMemoryLockoutStore(), logrus.New())
+	service := NewGuardedEmbeddingService(next, nil, nil, lockouts, publisher, map[string]string{"gate-01": "north"}, metrics.New())
+
+	ctx := identity.WithDevice(context.Background(), "gate-01")
+	next.EXPECT().ValidateEmbedding(ctx, []float32{1}).Return(nil, domain.ErrNoMatch).Times(2)
+
+	for i := 0; i < 2; i++ {
+		_, err := service.ValidateEmbedding(ctx, []float32{1})
+		assert.ErrorIs(t, err, domain.ErrNoMatch)
+	}
+
+	// The locked out device is rejected without reaching next.
+	_, err := service.ValidateEmbedding(ctx, []float32{1})
+	assert.ErrorIs(t, err, domain.ErrLockedOut)
+
+	require.Len(t, publisher.events, 1)
+	event := publisher.events[0]
+	assert.Equal(t, events.TypeDeviceLockedOut, event.Type)
+	assert.Equal(t, "gate-01", event.Device)
+	assert.Equal(t, "north", event.Zone)
+	assert.Equal(t, 1, event.LockoutLevel)
+	require.NotNil(t, event.LockedUntil)
+
+	// Other devices are not affected.
+	next.EXPECT().ValidateEmbedding(gomock.Any(), []float32{1}).Return(&domain.Embedding{ID: 1}, nil)
+	_, err = service.ValidateEmbedding(identity.WithDevice(context.Background(), "gate-02"), []float32{1})
+	assert.NoError(t, err)
+
+	listed, err := NewLockoutService(lockouts).ListLockouts(context.Background())
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "gate-01", listed[0].Device)
+
+	// Lifting the lockout lets the device validate again.
+	require.NoError(t, NewLockoutService(lockouts).ClearLockout(context.Background(), "gate-01"))
+	next.EXPECT().ValidateEmbedding(ctx, []float32{1}).Return(&domain.Embedding{ID: 1}, nil)
+	_, err = service.ValidateEmbedding(ctx, []float32{1})
+	assert.NoError(t, err)
+}
+
+func TestLockoutService_ClearLockout_NotLockedOut(t *testing.T) {
+	lockouts := ratelimit.NewLockouts(testLockoutPolicy, ratelimit.NewMemoryLockoutStore(), logrus.New())
+
+	err := NewLockoutService(lockouts).ClearLockout(context.Background(), "gate-01")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+// failingLockoutStore fails every call, like an unreachable database.
+type failingLockoutStore struct {
+	ratelimit.LockoutStore
+}
+
+func (failingLockoutStore) GetLockoutState(context.Context, string) (*domain.LockoutState, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestGuardedEmbeddingService_LockoutStoreError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingService(ctrl)
+	lockouts := ratelimit.NewLockouts(testLockoutPolicy, failingLockoutStore{}, logrus.New())
+	service := NewGuardedEmbeddingService(next, nil, nil, lockouts, &recordingPublisher{}, nil, metrics.New())
+
+	// A device whose lockout cannot be checked is not let through.
+	_, err := service.ValidateEmbedding(identity.WithDevice(context.Background(), "gate-01"), []float32{1})
+	assert.ErrorContains(t, err, "connection refused")
+}
+
+func TestGuardedBatchService_RateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockBatchService(ctrl)
+	service := NewGuardedBatchService(next, ratelimit.NewLimiter(1, 2), nil, nil, &recordingPublisher{}, nil, metrics.New())
+
+	// A batch takes one token, however many probes it has.
+	ctx := identity.WithDevice(context.Background(), "cam-01")
+	probes := []domain.Probe{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	next.EXPECT().ValidateBatch(ctx, probes).Return([]domain.ProbeResult{{ID: "a"}, {ID: "b"}, {ID: "c"}}, nil).Times(2)
+
+	for i := 0; i < 2; i++ {
+		_, err := service.ValidateBatch(ctx, probes)
+		require.NoError(t, err)
+	}
+
+	_, err := service.ValidateBatch(ctx, probes)
+	assert.ErrorIs(t, err, domain.ErrRateLimited)
+}
+
+func TestGuardedBatchService_Lockout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingService(ctrl)
+	repo := repomocks.NewMockEmbeddingRepository(ctrl)
+	publisher := &recordingPublisher{}
+	lockouts := ratelimit.NewLockouts(testLockoutPolicy, ratelimit.NewMemoryLockoutStore(), logrus.New())
+	service := NewGuardedBatchService(NewBatchService(next, repo, testBatchCfg), nil, nil, lockouts, publisher, nil, metrics.New())
+
+	ctx := identity.WithDevice(context.Background(), "cam-01")
+	probes := []domain.Probe{{ID: "a", Vector: []float32{1}}, {ID: "b", Vector: []float32{2}}}
+	unmatched := func(times int) {
+		repo.EXPECT().GetSimilarEmbeddingsByVectors(ctx, gomock.Any()).Return([]*domain.Embedding{nil, nil}, nil).Times(times)
+		next.EXPECT().ValidateMatchedEmbedding(gomock.Any(), gomock.Any(), domain.Match{}).Return(nil, domain.ErrNoMatch).Times(2 * times)
+	}
+
+	// A frame with a known face is a match, even among unknown faces.
+	found := &domain.Embedding{ID: 1}
+	repo.EXPECT().GetSimilarEmbeddingsByVectors(ctx, gomock.Any()).Return([]*domain.Embedding{nil, found}, nil)
+	next.EXPECT().ValidateMatchedEmbedding(gomock.Any(), []float32{1}, domain.Match{}).Return(nil, domain.ErrNoMatch)
+	next.EXPECT().ValidateMatchedEmbedding(gomock.Any(), []float32{2}, domain.Match{Embedding: found}).Return(found, nil)
+	_, err := service.ValidateBatch(ctx, probes)
+	require.NoError(t, err)
+
+	// A frame without a match counts once, whatever the number of faces.
+	unmatched(1)
+	_, err = service.ValidateBatch(ctx, probes)
+	require.NoError(t, err)
+	assert.Empty(t, publisher.events)
+
+	unmatched(1)
+	_, err = service.ValidateBatch(ctx, probes)
+	require.NoError(t, err)
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, events.TypeDeviceLockedOut, publisher.events[0].Type)
+
+	// The locked out device is rejected before its probes are looked up.
+	_, err = service.ValidateBatch(ctx, probes)
+	assert.ErrorIs(t, err, domain.ErrLockedOut)
+}
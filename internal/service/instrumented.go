@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/metrics"
+)
+
+// instrumentedEmbeddingService wraps an EmbeddingService and records validation outcomes.
+type instrumentedEmbeddingService struct {
+	EmbeddingService
+	metrics *metrics.Metrics
+}
+
+// NewInstrumentedEmbeddingService creates an EmbeddingService that reports
+// validation outcomes and match accuracy of next to m.
+func NewInstrumentedEmbeddingService(next EmbeddingService, m *metrics.Metrics) EmbeddingService {
+	return &instrumentedEmbeddingService{EmbeddingService: next, metrics: m}
+}
+
+// ValidateEmbedding validates the embedding and records the outcome for the calling device.
+func (s *instrumentedEmbeddingService) ValidateEmbedding(ctx context.Context, vector []float32) (*domain.Embedding, error) {
+	return s.validate(ctx, func(ctx context.Context) (*domain.Embedding, error) {
+		return s.EmbeddingService.ValidateEmbedding(ctx, vector)
+	})
+}
+
+// ValidateMatchedEmbedding validates the embedding of a batch and records the
+// outcome for the calling device.
+func (s *instrumentedEmbeddingService) ValidateMatchedEmbedding(ctx context.Context, vector []float32, match domain.Match) (*domain.Embedding, error) {
+	return s.validate(ctx, func(ctx context.Context) (*domain.Embedding, error) {
+		return s.EmbeddingService.ValidateMatchedEmbedding(ctx, vector, match)
+	})
+}
+
+func (s *instrumentedEmbeddingService) validate(ctx context.Context, next validation) (*domain.Embedding, error) {
+	embedding, err := next(ctx)
+
+	device := identity.DeviceFromContext(ctx)
+	switch {
+	case err == nil:
+		s.metrics.ObserveValidation(device, metrics.OutcomeMatch, embedding.Accuracy)
+	case errors.Is(err, domain.ErrNoMatch):
+		s.metrics.ObserveValidation(device, metrics.OutcomeNoMatch, 0)
+	case errors.Is(err, domain.ErrRateLimited), errors.Is(err, domain.ErrLockedOut), errors.Is(err, domain.ErrCaptureRejected):
+		s.metrics.ObserveValidation(device, metrics.OutcomeRejected, 0)
+	default:
+		s.metrics.ObserveValidation(device, metrics.OutcomeError, 0)
+	}
+
+	return embedding, err
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/ratelimit"
+)
+
+//go:generate mockgen -destination=../mocks/service/lockout_mock.go -package=mocks . LockoutService
+
+// LockoutService defines the interface for inspecting and lifting the device
+// lockouts of the tenant of ctx.
+type LockoutService interface {
+	ListLockouts(ctx context.Context) ([]*domain.Lockout, error)
+	// ClearLockout lifts the lockout of device, or returns ErrNotFound if it is not locked out.
+	ClearLockout(ctx context.Context, device string) error
+}
+
+// lockoutService is the concrete implementation of LockoutService.
+type lockoutService struct {
+	lockouts *ratelimit.Lockouts
+}
+
+// NewLockoutService creates a new instance of LockoutService.
+func NewLockoutService(lockouts *ratelimit.Lockouts) LockoutService {
+	return &lockoutService{lockouts: lockouts}
+}
+
+func (s *lockoutService) ListLockouts(ctx context.Context) ([]*domain.Lockout, error) {
+	return s.lockouts.List(ctx)
+}
+
+func (s *lockoutService) ClearLockout(ctx context.Context, device string) error {
+	cleared, err := s.lockouts.Clear(ctx, device)
+	if err != nil {
+		return err
+	}
+	if !cleared {
+		return fmt.Errorf("%w: device %s is not locked out", domain.ErrNotFound, device)
+	}
+	return nil
+}
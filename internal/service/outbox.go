@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/events"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/logging"
+	"access-system-api/internal/repository"
+)
+
+// webhookPayload is the JSON body delivered to webhook subscribers.
+type webhookPayload struct {
+	ID        string       `json:"id"`
+	Type      string       `json:"type"`
+	CreatedAt time.Time    `json:"created_at"`
+	Data      events.Event `json:"data"`
+}
+
+// outboxEmbeddingService wraps an EmbeddingService and writes webhook events
+// to the transactional outbox. Changes of embeddings and their events are
+// written in the same transaction, so an event is recorded if and only if
+// the change is committed.
+type outboxEmbeddingService struct {
+	EmbeddingService
+	webhookRepo repository.WebhookRepository
+	tx          repository.Transactor
+	zones       map[string]string
+}
+
+// NewOutboxEmbeddingService creates an EmbeddingService that records the
+// webhook events of next in the outbox of webhookRepo. zones maps devices to
+// the zone reported in their events.
+func NewOutboxEmbeddingService(next EmbeddingService, webhookRepo repository.WebhookRepository, tx repository.Transactor, zones map[string]string) EmbeddingService {
+	return &outboxEmbeddingService{EmbeddingService: next, webhookRepo: webhookRepo, tx: tx, zones: zones}
+}
+
+// record writes an event of type eventType carrying data to the outbox.
+func (s *outboxEmbeddingService) record(ctx context.Context, eventType string, data events.Event) error {
+	return recordWebhook(ctx, s.webhookRepo, s.zones, eventType, data)
+}
+
+// recordWebhook writes an event of type eventType carrying data to the outbox
+// of webhookRepo.
+func recordWebhook(ctx context.Context, webhookRepo repository.WebhookRepository, zones map[string]string, eventType string, data events.Event) error {
+	data.Zone = zones[data.Device]
+	payload, err := json.Marshal(webhookPayload{ID: data.ID, Type: eventType, CreatedAt: data.Time, Data: data})
+	if err != nil {
+		return err
+	}
+	return webhookRepo.AddOutboxEvent(ctx, &domain.OutboxEvent{EventID: data.ID, Type: eventType, Payload: payload})
+}
+
+// webhookTypes maps the published events that are delivered to webhook
+// subscribers to their webhook event type. Validations and changes of
+// embeddings are recorded by outboxEmbeddingService instead, within the
+// transaction of the change.
+var webhookTypes = map[events.Type]string{
+	events.TypeDeviceLockedOut: domain.WebhookDeviceLockedOut,
+	events.TypeDeviceOffline:   domain.WebhookDeviceOffline,
+}
+
+// outboxPublisher wraps a Publisher and writes the events in webhookTypes to
+// the outbox before passing them on.
+type outboxPublisher struct {
+	next        events.Publisher
+	webhookRepo repository.WebhookRepository
+	zones       map[string]string
+}
+
+// NewOutboxPublisher creates a Publisher that records device lockouts and
+// offline door controllers in the outbox of webhookRepo and publishes every
+// event to next. Only give it to the publishers of these events, so events
+// relayed from other instances are not recorded twice.
+func NewOutboxPublisher(next events.Publisher, webhookRepo repository.WebhookRepository, zones map[string]string) events.Publisher {
+	return &outboxPublisher{next: next, webhookRepo: webhookRepo, zones: zones}
+}
+
+// Publish records event if it is delivered to webhook subscribers. Failing
+// to record it is logged and does not keep it from being published.
+func (p *outboxPublisher) Publish(ctx context.Context, event events.Event) {
+	if eventType, ok := webhookTypes[event.Type]; ok {
+		if err := recordWebhook(identity.WithTenant(ctx, event.Tenant), p.webhookRepo, p.zones, eventType, event); err != nil {
+			logging.FromContext(ctx, nil).WithError(err).WithField("event_type", eventType).Error("Error recording webhook event")
+		}
+	}
+	p.next.Publish(ctx, event)
+}
+
+func (s *outboxEmbeddingService) AddEmbedding(ctx context.Context, name string, vector []float32) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.EmbeddingService.AddEmbedding(ctx, name, vector); err != nil {
+			return err
+		}
+		data := events.New(ctx, events.TypeEmbeddingCreated)
+		data.Name = name
+		return s.record(ctx, domain.WebhookEnrollmentCreated, data)
+	})
+}
+
+func (s *outboxEmbeddingService) ValidateEmbedding(ctx context.Context, vector []float32) (*domain.Embedding, error) {
+	return s.validate(ctx, func(ctx context.Context) (*domain.Embedding, error) {
+		return s.EmbeddingService.ValidateEmbedding(ctx, vector)
+	})
+}
+
+func (s *outboxEmbeddingService) ValidateMatchedEmbedding(ctx context.Context, vector []float32, match domain.Match) (*domain.Embedding, error) {
+	return s.validate(ctx, func(ctx context.Context) (*domain.Embedding, error) {
+		return s.EmbeddingService.ValidateMatchedEmbedding(ctx, vector, match)
+	})
+}
+
+// validate records access granted or denied events. Validations do not
+// change the database, and failing to record the event does not change the
+// access decision.
+func (s *outboxEmbeddingService) validate(ctx context.Context, next validation) (*domain.Embedding, error) {
+	embedding, err := next(ctx)
+
+	data := events.New(ctx, events.TypeValidation)
+	var eventType string
+	switch {
+	case err == nil:
+		eventType = domain.WebhookAccessGranted
+		data.Decision = events.DecisionMatch
+		data.EmbeddingID = embedding.ID
+		data.Name = embedding.Name
+		data.Accuracy = embedding.Accuracy
+	case errors.Is(err, domain.ErrNoMatch):
+		eventType = domain.WebhookAccessDenied
+		data.Decision = events.DecisionNoMatch
+	case errors.Is(err, domain.ErrCaptureRejected):
+		eventType = domain.WebhookAccessDenied
+		data.Decision = events.DecisionRejected
+	default:
+		return embedding, err
+	}
+
+	if recordErr := s.record(ctx, eventType, data); recordErr != nil {
+		logging.FromContext(ctx, nil).WithError(recordErr).Error("Error recording webhook event")
+	}
+	return embedding, err
+}
+
+func (s *outboxEmbeddingService) UpdateEmbedding(ctx context.Context, id int64, name string, vector []float32, version int64) (int64, error) {
+	var updated int64
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if updated, err = s.EmbeddingService.UpdateEmbedding(ctx, id, name, vector, version); err != nil {
+			return err
+		}
+		data := events.New(ctx, events.TypeEmbeddingUpdated)
+		data.EmbeddingID = id
+		data.Name = name
+		return s.record(ctx, domain.WebhookEnrollmentUpdated, data)
+	})
+	return updated, err
+}
+
+func (s *outboxEmbeddingService) PatchEmbedding(ctx context.Context, id int64, patch domain.EmbeddingPatch, version int64) (*domain.Embedding, error) {
+	var embedding *domain.Embedding
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if embedding, err = s.EmbeddingService.PatchEmbedding(ctx, id, patch, version); err != nil {
+			return err
+		}
+		if len(patch.Fields()) == 0 {
+			return nil
+		}
+		return s.record(ctx, domain.WebhookEnrollmentUpdated, patchEvent(ctx, id, patch))
+	})
+	return embedding, err
+}
+
+func (s *outboxEmbeddingService) DeleteEmbedding(ctx context.Context, id int64, version int64) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.EmbeddingService.DeleteEmbedding(ctx, id, version); err != nil {
+			return err
+		}
+		data := events.New(ctx, events.TypeEmbeddingDeleted)
+		data.EmbeddingID = id
+		return s.record(ctx, domain.WebhookEnrollmentDeleted, data)
+	})
+}
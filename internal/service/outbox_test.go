@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/events"
+	"access-system-api/internal/identity"
+	repomocks "access-system-api/internal/mocks/repository"
+	mocks "access-system-api/internal/mocks/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runInTx makes the mock Transactor run fn directly.
+func runInTx(tx *repomocks.MockTransactor) *gomock.Call {
+	return tx.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, fn func(context.Context) error) error {
+			return fn(ctx)
+		})
+}
+
+func TestOutboxEmbeddingService_AddEmbedding(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingService(ctrl)
+	repo := repomocks.NewMockWebhookRepository(ctrl)
+	tx := repomocks.NewMockTransactor(ctrl)
+	service := NewOutboxEmbeddingService(next, repo, tx, map[string]string{"gate-01": "north"})
+
+	ctx := identity.WithDevice(context.Background(), "gate-01")
+	runInTx(tx)
+	next.EXPECT().AddEmbedding(gomock.Any(), "test", []float32{1}).Return(nil)
+
+	var recorded *domain.OutboxEvent
+	repo.EXPECT().AddOutboxEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, event *domain.OutboxEvent) error {
+			recorded = event
+			return nil
+		})
+
+	require.NoError(t, service.AddEmbedding(ctx, "test", []float32{1}))
+
+	require.NotNil(t, recorded)
+	assert.Equal(t, domain.WebhookEnrollmentCreated, recorded.Type)
+
+	var payload webhookPayload
+	require.NoError(t, json.Unmarshal(recorded.Payload, &payload))
+	assert.Equal(t, recorded.EventID, payload.ID)
+	assert.Equal(t, domain.WebhookEnrollmentCreated, payload.Type)
+	assert.Equal(t, "gate-01", payload.Data.Device)
+	assert.Equal(t, "north", payload.Data.Zone)
+	assert.Equal(t, "test", payload.Data.Name)
+}
+
+func TestOutboxEmbeddingService_ChangeFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingService(ctrl)
+	repo := repomocks.NewMockWebhookRepository(ctrl)
+	tx := repomocks.NewMockTransactor(ctrl)
+	service := NewOutboxEmbeddingService(next, repo, tx, nil)
+
+	runInTx(tx)
+	next.EXPECT().DeleteEmbedding(gomock.Any(), int64(1), int64(0)).Return(domain.ErrNotFound)
+
+	err := service.DeleteEmbedding(context.Background(), 1, 0)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestOutboxEmbeddingService_RecordFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingService(ctrl)
+	repo := repomocks.NewMockWebhookRepository(ctrl)
+	tx := repomocks.NewMockTransactor(ctrl)
+	service := NewOutboxEmbeddingService(next, repo, tx, nil)
+
+	runInTx(tx)
+	next.EXPECT().UpdateEmbedding(gomock.Any(), int64(1), "test", []float32{1}, int64(0)).Return(int64(2), nil)
+	repo.EXPECT().AddOutboxEvent(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+
+	// The error rolls the update back together with the event.
+	_, err := service.UpdateEmbedding(context.Background(), 1, "test", []float32{1}, 0)
+	assert.EqualError(t, err, "db error")
+}
+
+func TestOutboxEmbeddingService_ValidateEmbedding(t *testing.T) {
+	tests := []struct {
+		name      string
+		embedding *domain.Embedding
+		err       error
+		eventType string
+	}{
+		{"granted", &domain.Embedding{ID: 1, Name: "test", Accuracy: 0.9}, nil, domain.WebhookAccessGranted},
+		{"denied", nil, domain.ErrNoMatch, domain.WebhookAccessDenied},
+		{"capture rejected", nil, domain.ErrCaptureRejected, domain.WebhookAccessDenied},
+		{"error", nil, errors.New("db error"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			next := mocks.NewMockEmbeddingService(ctrl)
+			repo := repomocks.NewMockWebhookRepository(ctrl)
+			service := NewOutboxEmbeddingService(next, repo, repomocks.NewMockTransactor(ctrl), nil)
+
+			next.EXPECT().ValidateEmbedding(gomock.Any(), []float32{1}).Return(tt.embedding, tt.err)
+			if tt.eventType != "" {
+				// A failed write is logged and does not change the decision.
+				repo.EXPECT().AddOutboxEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ context.Context, event *domain.OutboxEvent) error {
+						assert.Equal(t, tt.eventType, event.Type)
+						return errors.New("db error")
+					})
+			}
+
+			embedding, err := service.ValidateEmbedding(context.Background(), []float32{1})
+			assert.Equal(t, tt.embedding, embedding)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestOutboxPublisher_Publish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := repomocks.NewMockWebhookRepository(ctrl)
+	next := &recordingPublisher{}
+	publisher := NewOutboxPublisher(next, repo, map[string]string{"gate-01": "north"})
+
+	event := events.New(context.Background(), events.TypeDeviceOffline)
+	event.Tenant = "acme"
+	event.Device = "gate-01"
+	event.AccessPoint = "north-door"
+
+	var recorded *domain.OutboxEvent
+	repo.EXPECT().AddOutboxEvent(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, event *domain.OutboxEvent) error {
+			assert.Equal(t, "acme", identity.TenantFromContext(ctx))
+			recorded = event
+			return nil
+		})
+
+	publisher.Publish(context.Background(), event)
+
+	require.NotNil(t, recorded)
+	assert.Equal(t, domain.WebhookDeviceOffline, recorded.Type)
+	var payload webhookPayload
+	require.NoError(t, json.Unmarshal(recorded.Payload, &payload))
+	assert.Equal(t, "north-door", payload.Data.AccessPoint)
+	assert.Equal(t, "north", payload.Data.Zone)
+	assert.Equal(t, []events.Event{event}, next.events)
+}
+
+func TestOutboxPublisher_Publish_NotWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := repomocks.NewMockWebhookRepository(ctrl)
+	next := &recordingPublisher{}
+	publisher := NewOutboxPublisher(next, repo, nil)
+
+	// Door states are not delivered to webhooks, so nothing is recorded.
+	event := events.New(context.Background(), events.TypeDoorState)
+	publisher.Publish(context.Background(), event)
+
+	assert.Equal(t, []events.Event{event}, next.events)
+}
+
+func TestOutboxPublisher_Publish_RecordFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := repomocks.NewMockWebhookRepository(ctrl)
+	next := &recordingPublisher{}
+	publisher := NewOutboxPublisher(next, repo, nil)
+
+	repo.EXPECT().AddOutboxEvent(gomock.Any(), gomock.Any()).Return(errors.New("db down"))
+
+	event := events.New(context.Background(), events.TypeDeviceLockedOut)
+	publisher.Publish(context.Background(), event)
+
+	assert.Equal(t, []events.Event{event}, next.events)
+}
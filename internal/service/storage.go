@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"io"
+
+	"access-system-api/internal/events"
+	"access-system-api/internal/repository"
+)
+
+//go:generate mockgen -destination=../mocks/service/storage_mock.go -package=mocks . StorageService
+
+// StorageService defines the interface for the event log and backups of the
+// bolt storage backend.
+type StorageService interface {
+	// ListEvents returns up to limit recorded access events, the most recent first.
+	ListEvents(ctx context.Context, limit int) ([]events.Event, error)
+	// Backup writes a snapshot of the store to w and returns its size.
+	Backup(ctx context.Context, w io.Writer) (int64, error)
+}
+
+// storageService is the concrete implementation of StorageService.
+type storageService struct {
+	eventRepo   repository.EventRepository
+	snapshotter repository.Snapshotter
+}
+
+// NewStorageService creates a new instance of StorageService.
+func NewStorageService(eventRepo repository.EventRepository, snapshotter repository.Snapshotter) StorageService {
+	return &storageService{eventRepo: eventRepo, snapshotter: snapshotter}
+}
+
+func (s *storageService) ListEvents(ctx context.Context, limit int) ([]events.Event, error) {
+	return s.eventRepo.ListEvents(ctx, limit)
+}
+
+func (s *storageService) Backup(_ context.Context, w io.Writer) (int64, error) {
+	return s.snapshotter.Backup(w)
+}
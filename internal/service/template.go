@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"access-system-api/internal/capture"
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/logging"
+	"access-system-api/internal/metrics"
+	"access-system-api/internal/repository"
+
+	"github.com/pgvector/pgvector-go"
+	"github.com/sirupsen/logrus"
+)
+
+//go:generate mockgen -destination=../mocks/service/template_mock.go -package=mocks . TemplateService
+
+// TemplateService manages adaptive template updates: the probes of
+// high-confidence matches are added as samples of the matched embedding,
+// whose vector becomes the normalized centroid of its active samples.
+type TemplateService interface {
+	// Adapt adds probe as a sample of the matched embedding if the match is
+	// confident enough and the probe live enough, and reports whether it did.
+	Adapt(ctx context.Context, embedding *domain.Embedding, probe []float32) (bool, error)
+	ListSamples(ctx context.Context, embeddingID int64) ([]*domain.EmbeddingSample, error)
+	// RevertSample removes an active adaptive sample from the template of an
+	// embedding if it is at version, or at any version if that is 0, and
+	// returns its new version.
+	RevertSample(ctx context.Context, embeddingID, sampleID, version int64) (int64, error)
+	// ResetSamples drops all samples of an embedding from its template, e.g.
+	// because it was enrolled again. It does not change the vector.
+	ResetSamples(ctx context.Context, embeddingID int64) error
+}
+
+// templateService implements TemplateService.
+type templateService struct {
+	embeddingRepo repository.EmbeddingRepository
+	sampleRepo    repository.SampleRepository
+	tx            repository.Transactor
+	adaptiveCfg   cfg.AdaptiveCfg
+	metrics       *metrics.Metrics
+}
+
+// NewTemplateService creates a new instance of TemplateService.
+func NewTemplateService(embeddingRepo repository.EmbeddingRepository, sampleRepo repository.SampleRepository, tx repository.Transactor, adaptiveCfg cfg.AdaptiveCfg, m *metrics.Metrics) TemplateService {
+	return &templateService{embeddingRepo: embeddingRepo, sampleRepo: sampleRepo, tx: tx, adaptiveCfg: adaptiveCfg, metrics: m}
+}
+
+func (s *templateService) Adapt(ctx context.Context, embedding *domain.Embedding, probe []float32) (bool, error) {
+	liveness := capture.FromContext(ctx).Liveness
+	if float64(embedding.Accuracy) < s.adaptiveCfg.Threshold || liveness == nil || *liveness < s.adaptiveCfg.MinLiveness {
+		return false, nil
+	}
+
+	// Samples are kept normalized, like enrolled vectors, so that the
+	// centroid does not lean towards the probes of larger norm.
+	norm, err := checkVector(probe, len(probe))
+	if err != nil {
+		return false, err
+	}
+	sample := &domain.EmbeddingSample{
+		EmbeddingID: embedding.ID,
+		Vector:      pgvector.NewVector(normalize(probe, norm)),
+		Accuracy:    embedding.Accuracy,
+		Liveness:    liveness,
+		Device:      identity.DeviceFromContext(ctx),
+		RequestID:   logging.RequestIDFromContext(ctx),
+	}
+	var evicted []int64
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.sampleRepo.AddSample(ctx, sample); err != nil {
+			return err
+		}
+		var err error
+		if evicted, err = s.sampleRepo.EvictSamples(ctx, embedding.ID, s.adaptiveCfg.MaxSamples); err != nil {
+			return err
+		}
+		return s.sampleRepo.UpdateCentroid(ctx, embedding.ID)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	s.metrics.ObserveTemplateSamples(domain.SampleActive, 1)
+	s.metrics.ObserveTemplateSamples(domain.SampleEvicted, len(evicted))
+	logging.FromContext(ctx, nil).WithFields(logrus.Fields{
+		"embedding_id":    embedding.ID,
+		"sample_id":       sample.ID,
+		"accuracy":        embedding.Accuracy,
+		"liveness":        *liveness,
+		"evicted_samples": evicted,
+	}).Info("Template adapted")
+	return true, nil
+}
+
+func (s *templateService) ListSamples(ctx context.Context, embeddingID int64) ([]*domain.EmbeddingSample, error) {
+	if _, err := s.embeddingRepo.GetEmbeddingById(ctx, embeddingID); err != nil {
+		return nil, err
+	}
+	return s.sampleRepo.ListSamples(ctx, embeddingID)
+}
+
+func (s *templateService) RevertSample(ctx context.Context, embeddingID, sampleID, version int64) (int64, error) {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.sampleRepo.RevertSample(ctx, embeddingID, sampleID, version); err != nil {
+			return err
+		}
+		if err := s.sampleRepo.UpdateCentroid(ctx, embeddingID); err != nil {
+			return err
+		}
+		embedding, err := s.embeddingRepo.GetEmbeddingById(ctx, embeddingID)
+		if err != nil {
+			return err
+		}
+		version = embedding.Version
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.metrics.ObserveTemplateSamples(domain.SampleReverted, 1)
+	logging.FromContext(ctx, nil).WithFields(logrus.Fields{
+		"embedding_id": embeddingID,
+		"sample_id":    sampleID,
+		"device":       identity.DeviceFromContext(ctx),
+	}).Info("Template sample reverted")
+	return version, nil
+}
+
+func (s *templateService) ResetSamples(ctx context.Context, embeddingID int64) error {
+	return s.sampleRepo.ResetSamples(ctx, embeddingID)
+}
+
+// adaptBuffer bounds the matches waiting to be adapted.
+const adaptBuffer = 1024
+
+// adaptation is a match waiting to be adapted.
+type adaptation struct {
+	ctx       context.Context
+	embedding *domain.Embedding
+	probe     []float32
+}
+
+// TemplateAdapter adapts templates in the background, so that the
+// transaction of an adaptation does not delay the validation it follows.
+type TemplateAdapter struct {
+	templates TemplateService
+	pending   chan adaptation
+	log       *logrus.Logger
+}
+
+// NewTemplateAdapter creates a TemplateAdapter passing matches to templates.
+// Run must be running for them to be adapted.
+func NewTemplateAdapter(templates TemplateService, log *logrus.Logger) *TemplateAdapter {
+	return &TemplateAdapter{templates: templates, pending: make(chan adaptation, adaptBuffer), log: log}
+}
+
+// Queue queues the match of probe with embedding. The match is dropped if
+// too many are waiting already.
+func (a *TemplateAdapter) Queue(ctx context.Context, embedding *domain.Embedding, probe []float32) {
+	select {
+	case a.pending <- adaptation{ctx: context.WithoutCancel(ctx), embedding: embedding, probe: slices.Clone(probe)}:
+	default:
+		logging.FromContext(ctx, a.log).WithField("embedding_id", embedding.ID).Warn("Template adapter is behind, match not adapted")
+	}
+}
+
+// Run adapts queued matches until ctx is done. Matches still queued when ctx
+// is done are adapted before Run returns.
+func (a *TemplateAdapter) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case queued := <-a.pending:
+					a.adapt(queued)
+				default:
+					return nil
+				}
+			}
+		case queued := <-a.pending:
+			a.adapt(queued)
+		}
+	}
+}
+
+// adapt adapts a queued match. Failing to adapt it is only logged: the
+// decision was made already.
+func (a *TemplateAdapter) adapt(queued adaptation) {
+	if _, err := a.templates.Adapt(queued.ctx, queued.embedding, queued.probe); err != nil {
+		logging.FromContext(queued.ctx, a.log).WithError(err).WithField("embedding_id", queued.embedding.ID).Error("Error adapting template")
+	}
+}
+
+// adaptiveEmbeddingService wraps an EmbeddingService and adapts the template
+// of matched embeddings.
+type adaptiveEmbeddingService struct {
+	EmbeddingService
+	templates TemplateService
+	adapter   *TemplateAdapter
+	tx        repository.Transactor
+}
+
+// NewAdaptiveEmbeddingService creates an EmbeddingService that queues the
+// matches of next to adapter, and resets the templates of re-enrolled
+// embeddings. It must wrap the capture gate, so that rejected probes are
+// never added.
+func NewAdaptiveEmbeddingService(next EmbeddingService, templates TemplateService, adapter *TemplateAdapter, tx repository.Transactor) EmbeddingService {
+	return &adaptiveEmbeddingService{EmbeddingService: next, templates: templates, adapter: adapter, tx: tx}
+}
+
+func (s *adaptiveEmbeddingService) ValidateEmbedding(ctx context.Context, vector []float32) (*domain.Embedding, error) {
+	return s.validate(ctx, vector, func(ctx context.Context) (*domain.Embedding, error) {
+		return s.EmbeddingService.ValidateEmbedding(ctx, vector)
+	})
+}
+
+func (s *adaptiveEmbeddingService) ValidateMatchedEmbedding(ctx context.Context, vector []float32, match domain.Match) (*domain.Embedding, error) {
+	return s.validate(ctx, vector, func(ctx context.Context) (*domain.Embedding, error) {
+		return s.EmbeddingService.ValidateMatchedEmbedding(ctx, vector, match)
+	})
+}
+
+// validate queues a match with vector for adaptation.
+func (s *adaptiveEmbeddingService) validate(ctx context.Context, vector []float32, next validation) (*domain.Embedding, error) {
+	embedding, err := next(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.adapter.Queue(ctx, embedding, vector)
+	return embedding, nil
+}
+
+// UpdateEmbedding enrolls the embedding again, which drops its samples so
+// that the next adaptive update starts from the new vector.
+func (s *adaptiveEmbeddingService) UpdateEmbedding(ctx context.Context, id int64, name string, vector []float32, version int64) (int64, error) {
+	var updated int64
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if updated, err = s.EmbeddingService.UpdateEmbedding(ctx, id, name, vector, version); err != nil {
+			return err
+		}
+		return s.templates.ResetSamples(ctx, id)
+	})
+	return updated, err
+}
+
+// PatchEmbedding drops the samples of the embedding if the patch changes its
+// vector, like UpdateEmbedding.
+func (s *adaptiveEmbeddingService) PatchEmbedding(ctx context.Context, id int64, patch domain.EmbeddingPatch, version int64) (*domain.Embedding, error) {
+	if patch.Vector == nil {
+		return s.EmbeddingService.PatchEmbedding(ctx, id, patch, version)
+	}
+
+	var embedding *domain.Embedding
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if embedding, err = s.EmbeddingService.PatchEmbedding(ctx, id, patch, version); err != nil {
+			return err
+		}
+		return s.templates.ResetSamples(ctx, id)
+	})
+	return embedding, err
+}
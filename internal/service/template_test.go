@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"access-system-api/internal/capture"
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/metrics"
+	repomocks "access-system-api/internal/mocks/repository"
+	mocks "access-system-api/internal/mocks/service"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pgvector/pgvector-go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testAdaptiveCfg = cfg.AdaptiveCfg{Enabled: true, Threshold: 0.85, MinLiveness: 0.9, MaxSamples: 2}
+
+func TestTemplateService_Adapt(t *testing.T) {
+	tests := []struct {
+		name     string
+		accuracy float32
+		liveness *float64
+		adapted  bool
+	}{
+		{"confident live match", 0.9, score(0.95), true},
+		{"below threshold", 0.8, score(0.95), false},
+		{"low liveness", 0.9, score(0.5), false},
+		{"missing liveness", 0.9, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			samples := repomocks.NewMockSampleRepository(ctrl)
+			tx := repomocks.NewMockTransactor(ctrl)
+			service := NewTemplateService(repomocks.NewMockEmbeddingRepository(ctrl), samples, tx, testAdaptiveCfg, metrics.New())
+
+			ctx := capture.WithCapture(identity.WithDevice(context.Background(), "gate-01"), domain.Capture{Liveness: tt.liveness})
+			if tt.adapted {
+				runInTx(tx)
+				gomock.InOrder(
+					samples.EXPECT().AddSample(gomock.Any(), gomock.Any()).DoAndReturn(
+						func(_ context.Context, sample *domain.EmbeddingSample) error {
+							assert.Equal(t, int64(1), sample.EmbeddingID)
+							// The sample is the normalized probe.
+							assert.InDeltaSlice(t, []float32{0.6, 0.8}, sample.Vector.Slice(), 1e-6)
+							assert.Equal(t, tt.accuracy, sample.Accuracy)
+							assert.Equal(t, "gate-01", sample.Device)
+							return nil
+						}),
+					samples.EXPECT().EvictSamples(gomock.Any(), int64(1), 2).Return([]int64{3}, nil),
+					samples.EXPECT().UpdateCentroid(gomock.Any(), int64(1)).Return(nil),
+				)
+			}
+
+			adapted, err := service.Adapt(ctx, &domain.Embedding{ID: 1, Accuracy: tt.accuracy}, []float32{3, 4})
+			require.NoError(t, err)
+			assert.Equal(t, tt.adapted, adapted)
+		})
+	}
+}
+
+func TestTemplateService_RevertSample(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	samples := repomocks.NewMockSampleRepository(ctrl)
+	embeddings := repomocks.NewMockEmbeddingRepository(ctrl)
+	tx := repomocks.NewMockTransactor(ctrl)
+	service := NewTemplateService(embeddings, samples, tx, testAdaptiveCfg, metrics.New())
+
+	runInTx(tx)
+	samples.EXPECT().RevertSample(gomock.Any(), int64(1), int64(5), int64(0)).Return(domain.ErrNotFound)
+	_, err := service.RevertSample(context.Background(), 1, 5, 0)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	runInTx(tx)
+	samples.EXPECT().RevertSample(gomock.Any(), int64(1), int64(4), int64(2)).Return(domain.ErrVersionConflict)
+	_, err = service.RevertSample(context.Background(), 1, 4, 2)
+	assert.ErrorIs(t, err, domain.ErrVersionConflict)
+
+	// The new version of the embedding is returned.
+	runInTx(tx)
+	gomock.InOrder(
+		samples.EXPECT().RevertSample(gomock.Any(), int64(1), int64(4), int64(3)).Return(nil),
+		samples.EXPECT().UpdateCentroid(gomock.Any(), int64(1)).Return(nil),
+		embeddings.EXPECT().GetEmbeddingById(gomock.Any(), int64(1)).Return(&domain.Embedding{ID: 1, Version: 4}, nil),
+	)
+	version, err := service.RevertSample(context.Background(), 1, 4, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), version)
+}
+
+func TestAdaptiveEmbeddingService_ValidateEmbedding(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingService(ctrl)
+	templates := mocks.NewMockTemplateService(ctrl)
+	adapter := NewTemplateAdapter(templates, logrus.New())
+	service := NewAdaptiveEmbeddingService(next, templates, adapter, repomocks.NewMockTransactor(ctrl))
+
+	// Matches are adapted by the adapter, after the validation returned.
+	match := &domain.Embedding{ID: 1, Accuracy: 0.9}
+	ctx, cancel := context.WithCancel(identity.WithDevice(context.Background(), "gate-01"))
+	next.EXPECT().ValidateEmbedding(ctx, []float32{1}).Return(match, nil)
+	embedding, err := service.ValidateEmbedding(ctx, []float32{1})
+	require.NoError(t, err)
+	assert.Equal(t, match, embedding)
+
+	next.EXPECT().ValidateEmbedding(ctx, []float32{2}).Return(nil, domain.ErrNoMatch)
+	_, err = service.ValidateEmbedding(ctx, []float32{2})
+	assert.ErrorIs(t, err, domain.ErrNoMatch)
+
+	// The match is adapted once the request is over, and failing to adapt it
+	// does not stop the adapter.
+	cancel()
+	templates.EXPECT().Adapt(gomock.Any(), match, []float32{1}).DoAndReturn(
+		func(ctx context.Context, _ *domain.Embedding, _ []float32) (bool, error) {
+			assert.NoError(t, ctx.Err())
+			assert.Equal(t, "gate-01", identity.DeviceFromContext(ctx))
+			return false, errors.New("connection reset")
+		})
+	stopped, stop := context.WithCancel(context.Background())
+	stop()
+	require.NoError(t, adapter.Run(stopped))
+}
+
+func TestTemplateAdapter_Queue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	templates := mocks.NewMockTemplateService(ctrl)
+	adapter := NewTemplateAdapter(templates, logrus.New())
+
+	// Matches beyond the buffer are dropped instead of blocking validations.
+	match := &domain.Embedding{ID: 1}
+	for range adaptBuffer + 1 {
+		adapter.Queue(context.Background(), match, []float32{1})
+	}
+	templates.EXPECT().Adapt(gomock.Any(), match, []float32{1}).Return(true, nil).Times(adaptBuffer)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, adapter.Run(ctx))
+}
+
+func TestAdaptiveEmbeddingService_UpdateEmbedding(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingService(ctrl)
+	templates := mocks.NewMockTemplateService(ctrl)
+	tx := repomocks.NewMockTransactor(ctrl)
+	service := NewAdaptiveEmbeddingService(next, templates, nil, tx)
+
+	runInTx(tx)
+	gomock.InOrder(
+		next.EXPECT().UpdateEmbedding(gomock.Any(), int64(1), "test", []float32{1}, int64(3)).Return(int64(4), nil),
+		templates.EXPECT().ResetSamples(gomock.Any(), int64(1)).Return(nil),
+	)
+	version, err := service.UpdateEmbedding(context.Background(), 1, "test", []float32{1}, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), version)
+}
+
+func TestAdaptiveEmbeddingService_PatchEmbedding(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := mocks.NewMockEmbeddingService(ctrl)
+	templates := mocks.NewMockTemplateService(ctrl)
+	tx := repomocks.NewMockTransactor(ctrl)
+	service := NewAdaptiveEmbeddingService(next, templates, nil, tx)
+	ctx := context.Background()
+
+	// Samples are kept when the vector does not change.
+	name := "renamed"
+	rename := domain.EmbeddingPatch{Name: &name}
+	next.EXPECT().PatchEmbedding(ctx, int64(1), rename, int64(0)).Return(&domain.Embedding{ID: 1}, nil)
+	_, err := service.PatchEmbedding(ctx, 1, rename, 0)
+	assert.NoError(t, err)
+
+	vector := pgvector.NewVector([]float32{1})
+	revector := domain.EmbeddingPatch{Vector: &vector}
+	runInTx(tx)
+	gomock.InOrder(
+		next.EXPECT().PatchEmbedding(gomock.Any(), int64(1), revector, int64(0)).Return(&domain.Embedding{ID: 1}, nil),
+		templates.EXPECT().ResetSamples(gomock.Any(), int64(1)).Return(nil),
+	)
+	_, err = service.PatchEmbedding(ctx, 1, revector, 0)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/identity"
+	"access-system-api/internal/repository"
+)
+
+//go:generate mockgen -destination=../mocks/service/tenant_mock.go -package=mocks . TenantService
+
+// maxTenantIDLength bounds the length of tenant IDs.
+const maxTenantIDLength = 64
+
+// tenantCacheTTL is how long CheckTenant remembers whether a tenant is
+// registered. Tenants created or deleted on another instance are seen after
+// at most this long.
+const tenantCacheTTL = 30 * time.Second
+
+// TenantService defines the interface for managing the tenant registry.
+type TenantService interface {
+	// CreateTenant registers the tenant with the given ID, the organizational
+	// unit of the certificates of its devices. name defaults to the ID.
+	CreateTenant(ctx context.Context, id, name string) (*domain.Tenant, error)
+	GetTenant(ctx context.Context, id string) (*domain.Tenant, error)
+	ListTenants(ctx context.Context) ([]*domain.Tenant, error)
+	// DeleteTenant removes a tenant without embeddings, devices or webhooks.
+	// The default tenant cannot be deleted.
+	DeleteTenant(ctx context.Context, id string) error
+	// CheckTenant returns domain.ErrForbidden if tenant is not registered.
+	CheckTenant(ctx context.Context, tenant string) error
+}
+
+// TenantChecker checks the tenant a client belongs to. The HTTP and gRPC
+// APIs reject clients of unregistered tenants with it.
+type TenantChecker interface {
+	// CheckTenant returns domain.ErrForbidden if tenant is not registered.
+	CheckTenant(ctx context.Context, tenant string) error
+}
+
+// tenantService is the concrete implementation of TenantService.
+type tenantService struct {
+	tenantRepo repository.TenantRepository
+	now        func() time.Time
+
+	mu      sync.Mutex
+	checked map[string]tenantCheck
+}
+
+// tenantCheck is a cached lookup of a tenant.
+type tenantCheck struct {
+	registered bool
+	expires    time.Time
+}
+
+// NewTenantService creates a new instance of TenantService.
+func NewTenantService(tenantRepo repository.TenantRepository) TenantService {
+	return &tenantService{tenantRepo: tenantRepo, now: time.Now, checked: map[string]tenantCheck{}}
+}
+
+func (s *tenantService) CreateTenant(ctx context.Context, id, name string) (*domain.Tenant, error) {
+	id = strings.TrimSpace(id)
+	if id == "" || len(id) > maxTenantIDLength || strings.ContainsAny(id, ",=") {
+		return nil, fmt.Errorf("%w: id must be the organizational unit of the device certificates, at most %d characters", domain.ErrInvalidInput, maxTenantIDLength)
+	}
+	if name = strings.TrimSpace(name); name == "" {
+		name = id
+	}
+
+	tenant := &domain.Tenant{ID: id, Name: name}
+	if err := s.tenantRepo.CreateTenant(ctx, tenant); err != nil {
+		return nil, err
+	}
+	s.forget(id)
+	return tenant, nil
+}
+
+func (s *tenantService) GetTenant(ctx context.Context, id string) (*domain.Tenant, error) {
+	return s.tenantRepo.GetTenantById(ctx, id)
+}
+
+func (s *tenantService) ListTenants(ctx context.Context) ([]*domain.Tenant, error) {
+	return s.tenantRepo.ListTenants(ctx)
+}
+
+func (s *tenantService) DeleteTenant(ctx context.Context, id string) error {
+	if id == identity.DefaultTenant {
+		return fmt.Errorf("%w: the default tenant cannot be deleted", domain.ErrConflict)
+	}
+	if err := s.tenantRepo.DeleteTenantById(ctx, id); err != nil {
+		return err
+	}
+	s.forget(id)
+	return nil
+}
+
+func (s *tenantService) CheckTenant(ctx context.Context, tenant string) error {
+	now := s.now()
+	s.mu.Lock()
+	check, ok := s.checked[tenant]
+	s.mu.Unlock()
+
+	if !ok || now.After(check.expires) {
+		_, err := s.tenantRepo.GetTenantById(ctx, tenant)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+		check = tenantCheck{registered: err == nil, expires: now.Add(tenantCacheTTL)}
+
+		s.mu.Lock()
+		s.checked[tenant] = check
+		s.mu.Unlock()
+	}
+
+	if !check.registered {
+		return fmt.Errorf("%w: tenant %s is not registered", domain.ErrForbidden, tenant)
+	}
+	return nil
+}
+
+// forget drops the cached lookup of a tenant changed by this instance.
+func (s *tenantService) forget(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.checked, id)
+}
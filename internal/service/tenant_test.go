@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"access-system-api/internal/domain"
+	repomocks "access-system-api/internal/mocks/repository"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantService_CreateTenant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := repomocks.NewMockTenantRepository(ctrl)
+	service := NewTenantService(repo)
+
+	repo.EXPECT().CreateTenant(gomock.Any(), &domain.Tenant{ID: "engineering", Name: "engineering"}).Return(nil)
+
+	tenant, err := service.CreateTenant(context.Background(), " engineering ", "")
+	require.NoError(t, err)
+	assert.Equal(t, "engineering", tenant.Name)
+
+	for _, id := range []string{"", "OU=law", "law,medicine"} {
+		_, err := service.CreateTenant(context.Background(), id, "Law")
+		assert.ErrorIs(t, err, domain.ErrInvalidInput, id)
+	}
+}
+
+func TestTenantService_DeleteTenant_Default(t *testing.T) {
+	service := NewTenantService(repomocks.NewMockTenantRepository(gomock.NewController(t)))
+
+	err := service.DeleteTenant(context.Background(), "default")
+	assert.ErrorIs(t, err, domain.ErrConflict)
+}
+
+func TestTenantService_CheckTenant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := repomocks.NewMockTenantRepository(ctrl)
+	service := NewTenantService(repo).(*tenantService)
+	now := time.Unix(1700000000, 0)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	repo.EXPECT().GetTenantById(ctx, "engineering").Return(&domain.Tenant{ID: "engineering"}, nil)
+	repo.EXPECT().GetTenantById(ctx, "law").Return(nil, fmt.Errorf("%w: tenant law", domain.ErrNotFound))
+
+	// Lookups are cached, whether the tenant is registered or not.
+	for range 2 {
+		assert.NoError(t, service.CheckTenant(ctx, "engineering"))
+		assert.ErrorIs(t, service.CheckTenant(ctx, "law"), domain.ErrForbidden)
+	}
+
+	// Tenants created on this instance are allowed at once.
+	repo.EXPECT().CreateTenant(ctx, gomock.Any()).Return(nil)
+	_, err := service.CreateTenant(ctx, "law", "Law")
+	require.NoError(t, err)
+	repo.EXPECT().GetTenantById(ctx, "law").Return(&domain.Tenant{ID: "law"}, nil)
+	assert.NoError(t, service.CheckTenant(ctx, "law"))
+
+	// Expired lookups are repeated; database errors are not cached.
+	now = now.Add(tenantCacheTTL + time.Second)
+	repo.EXPECT().GetTenantById(ctx, "engineering").Return(nil, errors.New("connection reset"))
+	assert.EqualError(t, service.CheckTenant(ctx, "engineering"), "connection reset")
+	repo.EXPECT().GetTenantById(ctx, "engineering").Return(nil, fmt.Errorf("%w: tenant engineering", domain.ErrNotFound))
+	assert.ErrorIs(t, service.CheckTenant(ctx, "engineering"), domain.ErrForbidden)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedEmbeddingService wraps an EmbeddingService and creates a span for every call.
+type tracedEmbeddingService struct {
+	next   EmbeddingService
+	tracer trace.Tracer
+}
+
+// NewTracedEmbeddingService creates an EmbeddingService that traces every call of next.
+func NewTracedEmbeddingService(next EmbeddingService) EmbeddingService {
+	return &tracedEmbeddingService{next: next, tracer: tracing.Tracer()}
+}
+
+func (s *tracedEmbeddingService) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, "EmbeddingService."+method, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err on the span unless it only means that nothing matched.
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, domain.ErrNotFound) && !errors.Is(err, domain.ErrNoMatch) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (s *tracedEmbeddingService) AddEmbedding(ctx context.Context, name string, vector []float32) error {
+	ctx, span := s.start(ctx, "AddEmbedding", attribute.Int("embedding.dimension", len(vector)))
+	err := s.next.AddEmbedding(ctx, name, vector)
+	endSpan(span, err)
+	return err
+}
+
+func (s *tracedEmbeddingService) GetEmbedding(ctx context.Context, id int64) (*domain.Embedding, error) {
+	ctx, span := s.start(ctx, "GetEmbedding", attribute.Int64("embedding.id", id))
+	embedding, err := s.next.GetEmbedding(ctx, id)
+	endSpan(span, err)
+	return embedding, err
+}
+
+func (s *tracedEmbeddingService) ListEmbeddings(ctx context.Context) ([]*domain.Embedding, error) {
+	ctx, span := s.start(ctx, "ListEmbeddings")
+	embeddings, err := s.next.ListEmbeddings(ctx)
+	span.SetAttributes(attribute.Int("embedding.count", len(embeddings)))
+	endSpan(span, err)
+	return embeddings, err
+}
+
+func (s *tracedEmbeddingService) ValidateEmbedding(ctx context.Context, vector []float32) (*domain.Embedding, error) {
+	ctx, span := s.start(ctx, "ValidateEmbedding", attribute.Int("embedding.dimension", len(vector)))
+	embedding, err := s.next.ValidateEmbedding(ctx, vector)
+	endValidationSpan(span, embedding, err)
+	return embedding, err
+}
+
+func (s *tracedEmbeddingService) ValidateMatchedEmbedding(ctx context.Context, vector []float32, match domain.Match) (*domain.Embedding, error) {
+	ctx, span := s.start(ctx, "ValidateMatchedEmbedding", attribute.Int("embedding.dimension", len(vector)))
+	embedding, err := s.next.ValidateMatchedEmbedding(ctx, vector, match)
+	endValidationSpan(span, embedding, err)
+	return embedding, err
+}
+
+// endValidationSpan records the decision of a validation on the span.
+func endValidationSpan(span trace.Span, embedding *domain.Embedding, err error) {
+	span.SetAttributes(attribute.Bool("embedding.matched", err == nil))
+	if err == nil {
+		span.SetAttributes(
+			attribute.Int64("embedding.id", embedding.ID),
+			attribute.Float64("embedding.accuracy", float64(embedding.Accuracy)),
+		)
+	}
+	endSpan(span, err)
+}
+
+func (s *tracedEmbeddingService) UpdateEmbedding(ctx context.Context, id int64, name string, vector []float32, version int64) (int64, error) {
+	ctx, span := s.start(ctx, "UpdateEmbedding",
+		attribute.Int64("embedding.id", id),
+		attribute.Int("embedding.dimension", len(vector)),
+		attribute.Int64("embedding.version", version),
+	)
+	updated, err := s.next.UpdateEmbedding(ctx, id, name, vector, version)
+	endSpan(span, err)
+	return updated, err
+}
+
+func (s *tracedEmbeddingService) PatchEmbedding(ctx context.Context, id int64, patch domain.EmbeddingPatch, version int64) (*domain.Embedding, error) {
+	ctx, span := s.start(ctx, "PatchEmbedding",
+		attribute.Int64("embedding.id", id),
+		attribute.StringSlice("embedding.fields", patch.Fields()),
+		attribute.Int64("embedding.version", version),
+	)
+	embedding, err := s.next.PatchEmbedding(ctx, id, patch, version)
+	endSpan(span, err)
+	return embedding, err
+}
+
+func (s *tracedEmbeddingService) SetCaptureMinimums(ctx context.Context, id int64, minimums domain.CaptureMinimums, version int64) (int64, error) {
+	ctx, span := s.start(ctx, "SetCaptureMinimums",
+		attribute.Int64("embedding.id", id),
+		attribute.Int64("embedding.version", version),
+	)
+	updated, err := s.next.SetCaptureMinimums(ctx, id, minimums, version)
+	endSpan(span, err)
+	return updated, err
+}
+
+func (s *tracedEmbeddingService) ListInvalidEmbeddings(ctx context.Context) ([]*domain.InvalidEmbedding, error) {
+	ctx, span := s.start(ctx, "ListInvalidEmbeddings")
+	invalid, err := s.next.ListInvalidEmbeddings(ctx)
+	span.SetAttributes(attribute.Int("embedding.invalid_count", len(invalid)))
+	endSpan(span, err)
+	return invalid, err
+}
+
+func (s *tracedEmbeddingService) DeleteEmbedding(ctx context.Context, id int64, version int64) error {
+	ctx, span := s.start(ctx, "DeleteEmbedding", attribute.Int64("embedding.id", id), attribute.Int64("embedding.version", version))
+	err := s.next.DeleteEmbedding(ctx, id, version)
+	endSpan(span, err)
+	return err
+}
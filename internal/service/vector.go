@@ -0,0 +1,62 @@
+package service
+
+import (
+	"fmt"
+	"math"
+
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/domain"
+)
+
+const (
+	// minNorm is the L2 norm below which a vector has no usable direction,
+	// so its cosine distance to any other vector is undefined.
+	minNorm = 1e-6
+	// normTolerance is how far the norm of a stored vector may be from 1
+	// when vectors are normalized.
+	normTolerance = 1e-3
+)
+
+// prepareVector checks vector and normalizes it if configured.
+func prepareVector(vector []float32, matchingCfg cfg.MatchingCfg) ([]float32, error) {
+	norm, err := checkVector(vector, matchingCfg.VectorSize)
+	if err != nil {
+		return nil, err
+	}
+	if matchingCfg.Normalize {
+		return normalize(vector, norm), nil
+	}
+	return vector, nil
+}
+
+// checkVector verifies that vector has the given dimension, only finite
+// values and a usable direction. It returns the L2 norm of vector.
+func checkVector(vector []float32, size int) (float64, error) {
+	if len(vector) != size {
+		return 0, fmt.Errorf("%w: vector size must be %d, got %d", domain.ErrInvalidDimension, size, len(vector))
+	}
+
+	var sum float64
+	for i, value := range vector {
+		v := float64(value)
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return 0, fmt.Errorf("%w: vector[%d] is not a finite number", domain.ErrInvalidVector, i)
+		}
+		sum += v * v
+	}
+
+	norm := math.Sqrt(sum)
+	if norm < minNorm {
+		return 0, fmt.Errorf("%w: vector norm %g is too close to zero", domain.ErrInvalidVector, norm)
+	}
+	return norm, nil
+}
+
+// normalize returns a copy of vector scaled to unit L2 norm.
+func normalize(vector []float32, norm float64) []float32 {
+	normalized := make([]float32, len(vector))
+	for i, value := range vector {
+		normalized[i] = float32(float64(value) / norm)
+	}
+	return normalized
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+
+	"access-system-api/internal/domain"
+	"access-system-api/internal/repository"
+	"access-system-api/internal/webhook"
+)
+
+//go:generate mockgen -destination=../mocks/service/webhook_mock.go -package=mocks . WebhookService
+
+// minSecretLength is the minimum length of a client supplied signing secret.
+const minSecretLength = 16
+
+// WebhookService defines the interface for managing webhook subscriptions and deliveries.
+type WebhookService interface {
+	// CreateSubscription registers a webhook endpoint. A signing secret is
+	// generated if secret is empty.
+	CreateSubscription(ctx context.Context, rawURL string, eventTypes []string, secret string) (*domain.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id int64) (*domain.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int64) error
+	// ListDeliveries lists the most recently updated deliveries with the given
+	// status, or of every status if status is empty.
+	ListDeliveries(ctx context.Context, status string, limit int) ([]*domain.WebhookDelivery, error)
+	RedeliverDelivery(ctx context.Context, id int64) error
+}
+
+// webhookService is the concrete implementation of WebhookService.
+type webhookService struct {
+	webhookRepo repository.WebhookRepository
+	hosts       *webhook.HostPolicy
+}
+
+// NewWebhookService creates a new instance of WebhookService. Subscriptions
+// to internal hosts are rejected unless hosts allows them.
+func NewWebhookService(webhookRepo repository.WebhookRepository, hosts *webhook.HostPolicy) WebhookService {
+	return &webhookService{webhookRepo: webhookRepo, hosts: hosts}
+}
+
+func (s *webhookService) CreateSubscription(ctx context.Context, rawURL string, eventTypes []string, secret string) (*domain.WebhookSubscription, error) {
+	endpoint, err := url.Parse(rawURL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute http or https URL", domain.ErrInvalidInput)
+	}
+	if err := s.hosts.CheckHost(endpoint.Hostname()); err != nil {
+		return nil, fmt.Errorf("%w: %w, add it to the allowed hosts to deliver to it", domain.ErrInvalidInput, err)
+	}
+
+	if len(eventTypes) == 0 {
+		return nil, fmt.Errorf("%w: at least one event type is required", domain.ErrInvalidInput)
+	}
+	var types []string
+	for _, eventType := range eventTypes {
+		if !slices.Contains(domain.WebhookEventTypes, eventType) {
+			return nil, fmt.Errorf("%w: unknown event type %q", domain.ErrInvalidInput, eventType)
+		}
+		if !slices.Contains(types, eventType) {
+			types = append(types, eventType)
+		}
+	}
+
+	switch {
+	case secret == "":
+		secret = newSecret()
+	case len(secret) < minSecretLength:
+		return nil, fmt.Errorf("%w: secret must be at least %d characters", domain.ErrInvalidInput, minSecretLength)
+	}
+
+	subscription := &domain.WebhookSubscription{URL: endpoint.String(), EventTypes: types, Secret: secret}
+	if err := s.webhookRepo.CreateSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *webhookService) GetSubscription(ctx context.Context, id int64) (*domain.WebhookSubscription, error) {
+	return s.webhookRepo.GetSubscriptionById(ctx, id)
+}
+
+func (s *webhookService) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	return s.webhookRepo.ListSubscriptions(ctx)
+}
+
+func (s *webhookService) DeleteSubscription(ctx context.Context, id int64) error {
+	return s.webhookRepo.DeleteSubscriptionById(ctx, id)
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, status string, limit int) ([]*domain.WebhookDelivery, error) {
+	switch status {
+	case "", domain.DeliveryPending, domain.DeliveryDelivered, domain.DeliveryDead:
+	default:
+		return nil, fmt.Errorf("%w: unknown delivery status %q", domain.ErrInvalidInput, status)
+	}
+	return s.webhookRepo.ListDeliveries(ctx, status, limit)
+}
+
+func (s *webhookService) RedeliverDelivery(ctx context.Context, id int64) error {
+	return s.webhookRepo.RedeliverById(ctx, id)
+}
+
+func newSecret() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
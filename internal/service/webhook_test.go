@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"access-system-api/internal/domain"
+	repomocks "access-system-api/internal/mocks/repository"
+	"access-system-api/internal/webhook"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookService_CreateSubscription(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := repomocks.NewMockWebhookRepository(ctrl)
+	service := NewWebhookService(repo, webhook.NewHostPolicy(nil))
+
+	repo.EXPECT().CreateSubscription(gomock.Any(), gomock.Any()).Return(nil)
+
+	subscription, err := service.CreateSubscription(context.Background(), "https://example.com/hook",
+		[]string{domain.WebhookAccessDenied, domain.WebhookAccessDenied, domain.WebhookAccessGranted}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/hook", subscription.URL)
+	assert.Equal(t, []string{domain.WebhookAccessDenied, domain.WebhookAccessGranted}, subscription.EventTypes)
+	assert.Len(t, subscription.Secret, 64)
+}
+
+func TestWebhookService_CreateSubscription_Invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		eventTypes []string
+		secret     string
+	}{
+		{"relative url", "/hook", []string{domain.WebhookAccessGranted}, ""},
+		{"unsupported scheme", "ftp://example.com", []string{domain.WebhookAccessGranted}, ""},
+		{"no event types", "https://example.com", nil, ""},
+		{"unknown event type", "https://example.com", []string{"access.unknown"}, ""},
+		{"loopback address", "http://127.0.0.1:8080/hook", []string{domain.WebhookAccessGranted}, ""},
+		{"localhost", "http://localhost/hook", []string{domain.WebhookAccessGranted}, ""},
+		{"private address", "https://10.1.2.3/hook", []string{domain.WebhookAccessGranted}, ""},
+		{"link-local address", "http://169.254.169.254/latest/meta-data", []string{domain.WebhookAccessGranted}, ""},
+		{"ipv6 loopback", "http://[::1]/hook", []string{domain.WebhookAccessGranted}, ""},
+		{"unspecified address", "http://0.0.0.0/hook", []string{domain.WebhookAccessGranted}, ""},
+		{"short secret", "https://example.com", []string{domain.WebhookAccessGranted}, "short"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewWebhookService(repomocks.NewMockWebhookRepository(gomock.NewController(t)), webhook.NewHostPolicy(nil))
+
+			_, err := service.CreateSubscription(context.Background(), tt.url, tt.eventTypes, tt.secret)
+			assert.ErrorIs(t, err, domain.ErrInvalidInput)
+		})
+	}
+}
+
+func TestWebhookService_CreateSubscription_AllowedHosts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repo := repomocks.NewMockWebhookRepository(ctrl)
+	service := NewWebhookService(repo, webhook.NewHostPolicy([]string{"10.0.0.0/8", "helpdesk.internal"}))
+
+	repo.EXPECT().CreateSubscription(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	_, err := service.CreateSubscription(context.Background(), "https://10.1.2.3/hook", []string{domain.WebhookAccessDenied}, "")
+	assert.NoError(t, err)
+	_, err = service.CreateSubscription(context.Background(), "https://helpdesk.internal/hook", []string{domain.WebhookAccessDenied}, "")
+	assert.NoError(t, err)
+
+	_, err = service.CreateSubscription(context.Background(), "https://192.168.1.2/hook", []string{domain.WebhookAccessDenied}, "")
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+}
+
+func TestWebhookService_ListDeliveries_InvalidStatus(t *testing.T) {
+	service := NewWebhookService(repomocks.NewMockWebhookRepository(gomock.NewController(t)), webhook.NewHostPolicy(nil))
+
+	_, err := service.ListDeliveries(context.Background(), "failed", 10)
+	assert.ErrorIs(t, err, domain.ErrInvalidInput)
+}
@@ -0,0 +1,59 @@
+package signing
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// pruneInterval is how often expired nonces are removed.
+const pruneInterval = time.Minute
+
+// NonceStore records the nonces of accepted requests. It is shared by every
+// server instance, so that a request accepted by one instance is rejected as
+// a replay by the others.
+type NonceStore interface {
+	// UseNonce records the nonce of device for ttl and reports whether it
+	// was unused.
+	UseNonce(ctx context.Context, device, nonce string, ttl time.Duration) (bool, error)
+	// DeleteExpiredNonces removes the expired nonce records.
+	DeleteExpiredNonces(ctx context.Context) error
+}
+
+// Nonces remembers the nonces of accepted requests for as long as their
+// timestamps are within the skew window, so that a request can be used once.
+type Nonces struct {
+	store NonceStore
+	ttl   time.Duration
+	log   *logrus.Logger
+}
+
+// NewNonces creates a nonce register for requests accepted within skew of
+// the current time. A nonce is remembered for twice the skew, the time its
+// request stays acceptable.
+func NewNonces(store NonceStore, skew time.Duration, log *logrus.Logger) *Nonces {
+	return &Nonces{store: store, ttl: 2 * skew, log: log}
+}
+
+// Use records the nonce of device and reports whether it was unused.
+func (n *Nonces) Use(ctx context.Context, device, nonce string) (bool, error) {
+	return n.store.UseNonce(ctx, device, nonce, n.ttl)
+}
+
+// Run removes the expired nonces every minute until ctx is done.
+func (n *Nonces) Run(ctx context.Context) error {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := n.store.DeleteExpiredNonces(ctx); err != nil {
+				n.log.WithError(err).Error("Error removing expired request nonces")
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Package signing authenticates terminal requests signed with per-device keys
+// and rejects replayed requests.
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Headers carrying the signature of a request.
+const (
+	TimestampHeader = "X-Signature-Timestamp"
+	NonceHeader     = "X-Signature-Nonce"
+	SignatureHeader = "X-Signature"
+)
+
+// Nonce length bounds. Terminals should send at least 128 random bits.
+const (
+	MinNonceLength = 16
+	MaxNonceLength = 128
+)
+
+// ErrInvalidSignature is returned by Verify for unsigned, tampered or stale requests.
+var ErrInvalidSignature = errors.New("invalid request signature")
+
+// Sign returns the signature header value of a request: the hex HMAC-SHA256
+// with key of
+//
+//	<method>\n<path>\n<unix seconds>\n<nonce>\n<hex SHA-256 of body>
+//
+// The method and path bind the signature to the endpoint, the timestamp and
+// nonce to a single use.
+func Sign(key, method, path string, t time.Time, nonce string, body []byte) string {
+	return mac(key, method, path, strconv.FormatInt(t.Unix(), 10), nonce, body)
+}
+
+// Verify checks a signature created by Sign over the request with the given
+// timestamp header value, and that the timestamp is within skew of now.
+func Verify(key, method, path, timestamp, nonce, signature string, body []byte, skew time.Duration, now time.Time) error {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: malformed timestamp", ErrInvalidSignature)
+	}
+	if len(nonce) < MinNonceLength || len(nonce) > MaxNonceLength {
+		return fmt.Errorf("%w: nonce must be %d to %d characters", ErrInvalidSignature, MinNonceLength, MaxNonceLength)
+	}
+	if offset := now.Sub(time.Unix(unix, 0)); offset > skew || offset < -skew {
+		return fmt.Errorf("%w: timestamp outside the allowed skew of %s", ErrInvalidSignature, skew)
+	}
+	if !hmac.Equal([]byte(signature), []byte(mac(key, method, path, timestamp, nonce, body))) {
+		return fmt.Errorf("%w: signature mismatch", ErrInvalidSignature)
+	}
+	return nil
+}
+
+func mac(key, method, path, timestamp, nonce string, body []byte) string {
+	digest := sha256.Sum256(body)
+	h := hmac.New(sha256.New, []byte(key))
+	for _, part := range []string{method, path, timestamp, nonce} {
+		h.Write([]byte(part))
+		h.Write([]byte("\n"))
+	}
+	h.Write([]byte(hex.EncodeToString(digest[:])))
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,82 @@
+package signing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	const key = "0123456789abcdef0123456789abcdef"
+	const nonce = "6f1c2a9e4b7d4e0f"
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"vector":[0.1]}`)
+	signature := Sign(key, "POST", "/api/v1/embedding/validate", now, nonce, body)
+	timestamp := "1700000000"
+
+	assert.NoError(t, Verify(key, "POST", "/api/v1/embedding/validate", timestamp, nonce, signature, body, 30*time.Second, now.Add(30*time.Second)))
+
+	tests := []struct {
+		name      string
+		key       string
+		path      string
+		timestamp string
+		nonce     string
+		body      []byte
+		now       time.Time
+	}{
+		{"wrong key", "fedcba9876543210fedcba9876543210", "/api/v1/embedding/validate", timestamp, nonce, body, now},
+		{"other endpoint", key, "/api/v1/embedding", timestamp, nonce, body, now},
+		{"malformed timestamp", key, "/api/v1/embedding/validate", "yesterday", nonce, body, now},
+		{"changed timestamp", key, "/api/v1/embedding/validate", "1700000001", nonce, body, now},
+		{"changed nonce", key, "/api/v1/embedding/validate", timestamp, "6f1c2a9e4b7d4e0e", body, now},
+		{"short nonce", key, "/api/v1/embedding/validate", timestamp, "1", body, now},
+		{"tampered body", key, "/api/v1/embedding/validate", timestamp, nonce, []byte(`{"vector":[0.2]}`), now},
+		{"stale", key, "/api/v1/embedding/validate", timestamp, nonce, body, now.Add(31 * time.Second)},
+		{"future", key, "/api/v1/embedding/validate", timestamp, nonce, body, now.Add(-31 * time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.key, "POST", tt.path, tt.timestamp, tt.nonce, signature, tt.body, 30*time.Second, tt.now)
+			assert.True(t, errors.Is(err, ErrInvalidSignature), "got %v", err)
+		})
+	}
+}
+
+// nonceStore is a NonceStore backed by a map, ignoring expiry.
+type nonceStore struct {
+	used map[string]time.Duration
+}
+
+func (s *nonceStore) UseNonce(_ context.Context, device, nonce string, ttl time.Duration) (bool, error) {
+	key := device + "/" + nonce
+	if _, ok := s.used[key]; ok {
+		return false, nil
+	}
+	s.used[key] = ttl
+	return true, nil
+}
+
+func (s *nonceStore) DeleteExpiredNonces(context.Context) error {
+	return nil
+}
+
+func TestNonces_Use(t *testing.T) {
+	store := &nonceStore{used: make(map[string]time.Duration)}
+	n := NewNonces(store, 30*time.Second, logrus.New())
+	ctx := context.Background()
+
+	used, err := n.Use(ctx, "gate-01", "nonce-1")
+	assert.NoError(t, err)
+	assert.True(t, used)
+	used, _ = n.Use(ctx, "gate-01", "nonce-1")
+	assert.False(t, used)
+
+	// A nonce is remembered while its request could still be accepted.
+	assert.Equal(t, time.Minute, store.used["gate-01/nonce-1"])
+}
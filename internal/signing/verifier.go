@@ -0,0 +1,68 @@
+package signing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"access-system-api/internal/domain"
+)
+
+// Keys looks up the key a registered device signs its requests with.
+type Keys interface {
+	// SigningKey returns domain.ErrNotFound if the device is not registered.
+	SigningKey(ctx context.Context, device string) (string, error)
+}
+
+// Request is a signed request as received by any of the APIs.
+type Request struct {
+	Method    string
+	Path      string
+	Timestamp string
+	Nonce     string
+	Signature string
+	Body      []byte
+}
+
+// Verifier authenticates requests signed by the calling device with its
+// registered key and rejects replayed requests. It is shared by the HTTP and
+// gRPC APIs.
+type Verifier struct {
+	keys   Keys
+	nonces *Nonces
+	skew   time.Duration
+}
+
+// NewVerifier creates a Verifier accepting requests with a timestamp within
+// skew of the current time and a nonce unused according to nonces.
+func NewVerifier(keys Keys, nonces *Nonces, skew time.Duration) *Verifier {
+	return &Verifier{keys: keys, nonces: nonces, skew: skew}
+}
+
+// Verify checks the signature of a request of device and records its nonce.
+// It returns domain.ErrUnauthorized if the device is not registered or the
+// signature is invalid, and domain.ErrReplayed if the nonce was already used.
+func (v *Verifier) Verify(ctx context.Context, device string, r Request) error {
+	key, err := v.keys.SigningKey(ctx, device)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("%w: device %s is not registered", domain.ErrUnauthorized, device)
+		}
+		return err
+	}
+
+	err = Verify(key, r.Method, r.Path, r.Timestamp, r.Nonce, r.Signature, r.Body, v.skew, time.Now())
+	if err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrUnauthorized, err)
+	}
+
+	unused, err := v.nonces.Use(ctx, device, r.Nonce)
+	if err != nil {
+		return fmt.Errorf("record request nonce: %w", err)
+	}
+	if !unused {
+		return fmt.Errorf("%w: nonce was already used", domain.ErrReplayed)
+	}
+	return nil
+}
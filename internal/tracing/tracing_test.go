@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"access-system-api/internal/cfg"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetup_FileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.json")
+	shutdown, err := Setup(context.Background(), &cfg.TracingCfg{
+		ServiceName: "test",
+		Exporter:    cfg.TracingExporterFile,
+		FilePath:    path,
+		SampleRatio: 1,
+	})
+	assert.NoError(t, err)
+
+	_, span := Tracer().Start(context.Background(), "test-span")
+	span.End()
+
+	assert.NoError(t, shutdown(context.Background()))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"Name":"test-span"`)
+}
+
+func TestSetup_AcceptsIncomingTraceContext(t *testing.T) {
+	shutdown, err := Setup(context.Background(), &cfg.TracingCfg{Exporter: cfg.TracingExporterNone})
+	assert.NoError(t, err)
+	defer shutdown(context.Background())
+
+	carrier := propagation.MapCarrier{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), carrier)
+
+	spanCtx := trace.SpanContextFromContext(ctx)
+	assert.True(t, spanCtx.IsRemote())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spanCtx.TraceID().String())
+}
@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/domain"
+	"access-system-api/internal/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+// cleanupInterval is how often delivered deliveries past retention are removed.
+const cleanupInterval = time.Hour
+
+// maxResponseBody bounds the part of a response body read before closing it.
+const maxResponseBody = 64 << 10
+
+// Dispatcher fans out outbox events to the matching subscriptions and sends
+// the deliveries, retrying failed ones with exponential backoff until they
+// succeed or are dead-lettered. Several instances may run a Dispatcher on the
+// same database; every delivery is claimed by one of them at a time.
+type Dispatcher struct {
+	webhookRepo repository.WebhookRepository
+	client      *http.Client
+	cfg         cfg.WebhookCfg
+	log         *logrus.Logger
+	now         func() time.Time
+}
+
+// NewDispatcher creates a new Dispatcher. Deliveries to internal hosts not
+// allowed by webhookCfg.AllowedHosts fail, see HostPolicy.
+func NewDispatcher(webhookRepo repository.WebhookRepository, webhookCfg cfg.WebhookCfg, log *logrus.Logger) *Dispatcher {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = NewHostPolicy(webhookCfg.AllowedHosts).DialContext(&net.Dialer{Timeout: webhookCfg.Timeout, KeepAlive: 30 * time.Second})
+	return &Dispatcher{
+		webhookRepo: webhookRepo,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   webhookCfg.Timeout,
+			// Redirects are not followed, so deliveries only go to registered URLs.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		cfg: webhookCfg,
+		log: log,
+		now: time.Now,
+	}
+}
+
+// Run polls the outbox and due deliveries until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	poll := time.NewTicker(d.cfg.PollInterval)
+	defer poll.Stop()
+	cleanup := time.NewTicker(cleanupInterval)
+	defer cleanup.Stop()
+
+	for {
+		d.Poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-poll.C:
+		case <-cleanup.C:
+			if err := d.webhookRepo.DeleteDeliveredBefore(ctx, d.now().Add(-d.cfg.Retention)); err != nil {
+				d.log.WithError(err).Error("Error removing old webhook deliveries")
+			}
+		}
+	}
+}
+
+// Poll fans out the pending outbox events and sends one batch of due deliveries.
+func (d *Dispatcher) Poll(ctx context.Context) {
+	for {
+		dispatched, err := d.webhookRepo.FanOutEvents(ctx, d.cfg.BatchSize)
+		if err != nil {
+			d.log.WithError(err).Error("Error fanning out outbox events")
+			return
+		}
+		if dispatched < d.cfg.BatchSize {
+			break
+		}
+	}
+
+	// The lease outlasts the attempts, which all finish within the timeout.
+	deliveries, err := d.webhookRepo.ClaimDeliveries(ctx, d.cfg.BatchSize, 2*d.cfg.Timeout)
+	if err != nil {
+		d.log.WithError(err).Error("Error claiming webhook deliveries")
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.deliver(ctx, delivery)
+		}()
+	}
+	wg.Wait()
+}
+
+// deliver sends a claimed delivery and records the outcome.
+func (d *Dispatcher) deliver(ctx context.Context, delivery *domain.WebhookDelivery) {
+	entry := d.log.WithFields(logrus.Fields{
+		"delivery_id":     delivery.ID,
+		"subscription_id": delivery.SubscriptionID,
+		"event_id":        delivery.EventID,
+		"event_type":      delivery.EventType,
+		"attempt":         delivery.Attempts,
+	})
+
+	statusCode, err := d.send(ctx, delivery)
+	if err == nil {
+		if err := d.webhookRepo.MarkDelivered(ctx, delivery.ID, statusCode); err != nil {
+			entry.WithError(err).Error("Error recording webhook delivery")
+			return
+		}
+		entry.Info("Webhook delivered")
+		return
+	}
+
+	dead := delivery.Attempts >= d.cfg.MaxAttempts
+	next := d.now().Add(d.backoff(delivery.Attempts))
+	if markErr := d.webhookRepo.MarkFailed(ctx, delivery.ID, statusCode, err.Error(), next, dead); markErr != nil {
+		entry.WithError(markErr).Error("Error recording failed webhook delivery")
+		return
+	}
+	if dead {
+		entry.WithError(err).Error("Webhook delivery dead-lettered")
+		return
+	}
+	entry.WithError(err).WithField("next_attempt_at", next).Warn("Webhook delivery failed")
+}
+
+// send posts the signed payload and returns the response status code.
+func (d *Dispatcher) send(ctx context.Context, delivery *domain.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "access-system-webhooks")
+	req.Header.Set(IDHeader, delivery.EventID)
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, d.now(), delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay after the given failed attempt.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.cfg.InitialBackoff
+	for i := 1; i < attempt && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.cfg.MaxBackoff)
+}
@@ -0,0 +1,153 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"access-system-api/internal/cfg"
+	"access-system-api/internal/domain"
+	mocks "access-system-api/internal/mocks/repository"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "0123456789abcdef"
+
+func newTestDispatcher(t *testing.T, allowedHosts ...string) (*Dispatcher, *mocks.MockWebhookRepository, time.Time) {
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockWebhookRepository(ctrl)
+
+	webhookCfg := cfg.Default().Webhook
+	webhookCfg.BatchSize = 10
+	webhookCfg.MaxAttempts = 3
+	webhookCfg.AllowedHosts = allowedHosts
+
+	now := time.Unix(1700000000, 0)
+	d := NewDispatcher(repo, webhookCfg, logrus.New())
+	d.now = func() time.Time { return now }
+	return d, repo, now
+}
+
+func TestDispatcher_Poll_Delivered(t *testing.T) {
+	// The test receiver listens on the loopback interface.
+	d, repo, now := newTestDispatcher(t, "127.0.0.1")
+	payload := []byte(`{"id":"evt-1","type":"access.granted"}`)
+
+	var received http.Header
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = r.Header
+		if err := Verify(testSecret, r.Header.Get(SignatureHeader), body, time.Minute, now); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	repo.EXPECT().FanOutEvents(gomock.Any(), 10).Return(1, nil)
+	repo.EXPECT().ClaimDeliveries(gomock.Any(), 10, 2*d.cfg.Timeout).Return([]*domain.WebhookDelivery{{
+		ID:        7,
+		EventID:   "evt-1",
+		EventType: domain.WebhookAccessGranted,
+		Attempts:  1,
+		URL:       receiver.URL,
+		Secret:    testSecret,
+		Payload:   payload,
+	}}, nil)
+	repo.EXPECT().MarkDelivered(gomock.Any(), int64(7), http.StatusNoContent).Return(nil)
+
+	d.Poll(context.Background())
+
+	assert.Equal(t, "evt-1", received.Get(IDHeader))
+	assert.Equal(t, domain.WebhookAccessGranted, received.Get(EventHeader))
+}
+
+func TestDispatcher_Poll_InternalHost(t *testing.T) {
+	d, repo, now := newTestDispatcher(t)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("delivery sent to an internal host")
+	}))
+	defer receiver.Close()
+
+	// Deliveries to internal addresses are never sent, even for subscriptions
+	// created before the address was internal or resolving to it by name.
+	repo.EXPECT().FanOutEvents(gomock.Any(), 10).Return(0, nil)
+	repo.EXPECT().ClaimDeliveries(gomock.Any(), 10, gomock.Any()).Return([]*domain.WebhookDelivery{{
+		ID: 7, Attempts: 1, URL: receiver.URL, Secret: testSecret,
+	}}, nil)
+	repo.EXPECT().MarkFailed(gomock.Any(), int64(7), 0, gomock.Any(), now.Add(d.cfg.InitialBackoff), false).
+		DoAndReturn(func(_ context.Context, _ int64, _ int, lastErr string, _ time.Time, _ bool) error {
+			assert.Contains(t, lastErr, ErrInternalHost.Error())
+			return nil
+		})
+
+	d.Poll(context.Background())
+}
+
+func TestDispatcher_Poll_FanOutUntilDrained(t *testing.T) {
+	d, repo, _ := newTestDispatcher(t)
+
+	gomock.InOrder(
+		repo.EXPECT().FanOutEvents(gomock.Any(), 10).Return(10, nil),
+		repo.EXPECT().FanOutEvents(gomock.Any(), 10).Return(3, nil),
+	)
+	repo.EXPECT().ClaimDeliveries(gomock.Any(), 10, gomock.Any()).Return(nil, nil)
+
+	d.Poll(context.Background())
+}
+
+func TestDispatcher_Poll_Failed(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	tests := []struct {
+		name     string
+		attempts int
+		backoff  time.Duration
+		dead     bool
+	}{
+		{"first attempt", 1, 10 * time.Second, false},
+		{"second attempt", 2, 20 * time.Second, false},
+		{"last attempt", 3, 40 * time.Second, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, repo, now := newTestDispatcher(t, "127.0.0.1")
+
+			repo.EXPECT().FanOutEvents(gomock.Any(), 10).Return(0, nil)
+			repo.EXPECT().ClaimDeliveries(gomock.Any(), 10, gomock.Any()).Return([]*domain.WebhookDelivery{{
+				ID:       7,
+				Attempts: tt.attempts,
+				URL:      receiver.URL,
+				Secret:   testSecret,
+				Payload:  []byte(`{}`),
+			}}, nil)
+			repo.EXPECT().MarkFailed(gomock.Any(), int64(7), http.StatusInternalServerError,
+				"unexpected response status 500", now.Add(tt.backoff), tt.dead).Return(nil)
+
+			d.Poll(context.Background())
+		})
+	}
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	d, _, _ := newTestDispatcher(t)
+	d.cfg.InitialBackoff = time.Second
+	d.cfg.MaxBackoff = 5 * time.Second
+
+	assert.Equal(t, time.Second, d.backoff(1))
+	assert.Equal(t, 2*time.Second, d.backoff(2))
+	assert.Equal(t, 4*time.Second, d.backoff(3))
+	assert.Equal(t, 5*time.Second, d.backoff(4))
+	assert.Equal(t, 5*time.Second, d.backoff(40))
+}
@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// ErrInternalHost is returned for webhook hosts on the internal network.
+var ErrInternalHost = errors.New("webhook host is internal")
+
+// HostPolicy decides which hosts webhooks may be sent to. Loopback,
+// link-local, private and unspecified addresses are internal: subscriptions
+// could reach the services next to the server through them, so they are
+// rejected unless they are allowed explicitly.
+type HostPolicy struct {
+	names    []string
+	prefixes []netip.Prefix
+}
+
+// NewHostPolicy creates a HostPolicy allowing the internal hosts of allowed,
+// given as host names, IP addresses or CIDR ranges. Invalid entries are
+// rejected by cfg.Validate and ignored here.
+func NewHostPolicy(allowed []string) *HostPolicy {
+	p := &HostPolicy{}
+	for _, host := range allowed {
+		if prefix, err := netip.ParsePrefix(host); err == nil {
+			p.prefixes = append(p.prefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(host); err == nil {
+			p.prefixes = append(p.prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+		} else {
+			p.names = append(p.names, strings.ToLower(host))
+		}
+	}
+	return p
+}
+
+// CheckHost rejects host, the host of a webhook URL without its port, if it
+// is an internal address or a name of the local host. Other names are
+// checked when they are resolved, see DialContext.
+func (p *HostPolicy) CheckHost(host string) error {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if slices.Contains(p.names, host) {
+		return nil
+	}
+	if addr, err := netip.ParseAddr(strings.Trim(host, "[]")); err == nil {
+		return p.checkAddr(addr)
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrInternalHost, host)
+	}
+	return nil
+}
+
+// DialContext returns a dial function for an http.Transport that refuses
+// connections to internal addresses, whatever name resolved to them, unless
+// the name or the address is allowed.
+func (p *HostPolicy) DialContext(dialer *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	checked := *dialer
+	checked.Control = func(_, address string, _ syscall.RawConn) error {
+		addrPort, err := netip.ParseAddrPort(address)
+		if err != nil {
+			return err
+		}
+		return p.checkAddr(addrPort.Addr())
+	}
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(address)
+		if err == nil && slices.Contains(p.names, strings.ToLower(host)) {
+			return dialer.DialContext(ctx, network, address)
+		}
+		return checked.DialContext(ctx, network, address)
+	}
+}
+
+func (p *HostPolicy) checkAddr(addr netip.Addr) error {
+	addr = addr.Unmap()
+	internal := addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsPrivate() || addr.IsUnspecified()
+	if !internal {
+		return nil
+	}
+	for _, prefix := range p.prefixes {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrInternalHost, addr)
+}
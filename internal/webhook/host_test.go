@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostPolicy_CheckHost(t *testing.T) {
+	policy := NewHostPolicy([]string{"10.20.0.0/16", "192.168.1.5", "Helpdesk.Internal"})
+
+	tests := []struct {
+		host     string
+		internal bool
+	}{
+		{"example.com", false},
+		{"93.184.215.14", false},
+		{"2606:2800:21f:cb07:6820:80da:af6b:8b2c", false},
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"0.0.0.0", true},
+		{"169.254.169.254", true},
+		{"fe80::1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"fd00::1", true},
+		{"localhost", true},
+		{"LOCALHOST.", true},
+		{"api.localhost", true},
+		// Allowed explicitly.
+		{"10.20.3.4", false},
+		{"192.168.1.5", false},
+		{"helpdesk.internal", false},
+		{"192.168.1.6", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			err := policy.CheckHost(tt.host)
+			if tt.internal {
+				assert.ErrorIs(t, err, ErrInternalHost)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHostPolicy_DialContext(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	_, err = NewHostPolicy(nil).DialContext(&net.Dialer{})(context.Background(), "tcp", listener.Addr().String())
+	assert.ErrorIs(t, err, ErrInternalHost)
+
+	conn, err := NewHostPolicy([]string{"127.0.0.0/8"}).DialContext(&net.Dialer{})(context.Background(), "tcp", listener.Addr().String())
+	require.NoError(t, err)
+	conn.Close()
+}
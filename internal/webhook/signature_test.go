@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"1"}`)
+	header := Sign("0123456789abcdef", now, body)
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+		ok     bool
+	}{
+		{"valid", "0123456789abcdef", header, body, now, true},
+		{"within tolerance", "0123456789abcdef", header, body, now.Add(4 * time.Minute), true},
+		{"wrong secret", "fedcba9876543210", header, body, now, false},
+		{"tampered body", "0123456789abcdef", header, []byte(`{"id":"2"}`), now, false},
+		{"stale", "0123456789abcdef", header, body, now.Add(10 * time.Minute), false},
+		{"malformed", "0123456789abcdef", "v1=abc", body, now, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.header, tt.body, 5*time.Minute, tt.now)
+			if tt.ok {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidSignature)
+			}
+		})
+	}
+}